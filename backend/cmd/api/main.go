@@ -40,7 +40,7 @@ func main() {
 		logger.WithError(err).Fatal("failed to create jwt manager")
 	}
 
-	tokenVerifier, err := auth.NewTokenVerifier(conf, dbPacket.AuthDB, dbPacket.AdminDB)
+	tokenVerifier, err := auth.NewTokenVerifier(conf, dbPacket.AuthDB, dbPacket.AdminDB, dbPacket.SubmissionDB)
 	if err != nil {
 		logger.WithError(err).Fatal("failed to create token verifier")
 	}
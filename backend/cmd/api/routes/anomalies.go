@@ -0,0 +1,131 @@
+package routes
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/auth"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+// Anomaly routes, unusual outflows are flagged by the tracker for admins to review
+
+// GET /api/v1/anomalies - Get flagged outflows, newest first. Filter with status=open|acknowledged|dismissed
+func (rh *RouteHandler) GetAnomalies(c *gin.Context) {
+	status := types.AnomalyStatus(c.Query("status"))
+	switch status {
+	case "", types.AnomalyStatusOpen, types.AnomalyStatusAcknowledged, types.AnomalyStatusDismissed:
+	default:
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid status parameter (open, acknowledged or dismissed)"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 1000 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid limit parameter (1-1000)"})
+		return
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid offset parameter"})
+		return
+	}
+
+	anomalies, err := rh.anomalyDB.GetAnomalies(c, status, limit, offset)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to get anomalies")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve anomalies"})
+		return
+	}
+
+	c.JSON(http.StatusOK, anomalies)
+}
+
+// GET /api/v1/anomalies/:id - Get a flagged outflow by ID
+func (rh *RouteHandler) GetAnomalyByID(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid anomaly ID format"})
+		return
+	}
+
+	listing, err := rh.anomalyDB.GetAnomalyByID(c, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Anomaly not found"})
+			return
+		}
+		rh.log.WithError(err).Error("failed to get anomaly")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve anomaly"})
+		return
+	}
+
+	c.JSON(http.StatusOK, listing)
+}
+
+// PUT /api/v1/anomalies/:id - Acknowledge, dismiss or reopen a flagged outflow, and/or annotate it
+func (rh *RouteHandler) ReviewAnomaly(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid anomaly ID format"})
+		return
+	}
+
+	var req types.ReviewAnomalyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		rh.log.WithError(err).Warn("failed to bind review anomaly request")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Status == "" && !req.Note.Valid {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "A status or a note is required"})
+		return
+	}
+
+	status := null.NewString(string(req.Status), req.Status != "")
+	if err := rh.anomalyDB.ReviewAnomaly(c, id, status, req.Note, auth.MustUserID(c)); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Anomaly not found"})
+			return
+		}
+		rh.log.WithError(err).Error("failed to review anomaly")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to review anomaly"})
+		return
+	}
+
+	adminAction := types.AdminAction{
+		AdminAddress: auth.MustUserID(c),
+		Action:       "review_anomaly",
+		ResourceType: "transfer_anomaly",
+		ResourceID:   id.String(),
+		Details: types.AdminActionDetails{
+			"status": req.Status,
+			"note":   req.Note,
+		},
+		CreatedAt: time.Now(),
+	}
+
+	err = rh.adminActionDB.RecordAdminAction(c, adminAction)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to record admin action")
+		// Don't fail the request for logging errors
+	}
+
+	listing, err := rh.anomalyDB.GetAnomalyByID(c, id)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to get anomaly")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve anomaly"})
+		return
+	}
+
+	c.JSON(http.StatusOK, listing)
+}
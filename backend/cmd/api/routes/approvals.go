@@ -0,0 +1,42 @@
+package routes
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ETHCF/ethutils"
+	"github.com/gin-gonic/gin"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/approvals"
+)
+
+// Approval routes
+
+// GET /api/v1/approvals - Get the open token approvals of treasury wallets, flagging unlimited and stale ones.
+// Filter to one wallet with owner, revoked approvals are left out unless includeRevoked=true
+func (rh *RouteHandler) GetApprovals(c *gin.Context) {
+	owner := c.Query("owner")
+	if owner != "" {
+		var err error
+		owner, err = ethutils.SanitizeEthAddr(owner)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid Ethereum address"})
+			return
+		}
+	}
+	includeRevoked := c.Query("includeRevoked") == "true"
+
+	listings, err := rh.approvalDB.GetApprovals(c, owner, includeRevoked)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to get approvals")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve approvals"})
+		return
+	}
+
+	now := time.Now()
+	for i := range listings {
+		approvals.Flag(&listings[i], rh.conf.ApprovalStaleAge, now)
+	}
+
+	c.JSON(http.StatusOK, listings)
+}
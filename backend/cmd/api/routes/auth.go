@@ -1,6 +1,7 @@
 package routes
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/ETHCF/ethutils"
@@ -46,6 +47,16 @@ func (rh *RouteHandler) GenerateChallenge(c *gin.Context) {
 }
 
 func (rh *RouteHandler) VerifyChallenge(c *gin.Context) {
+	rh.login(c, auth.RoleAdmin, rh.tokenVerifier.Verify)
+}
+
+// POST /api/v1/recipient/auth/login - Log a grant recipient in with a signed SIWE message
+func (rh *RouteHandler) RecipientLogin(c *gin.Context) {
+	rh.login(c, auth.RoleRecipient, rh.tokenVerifier.VerifyRecipient)
+}
+
+// login issues a token with the role once the signed message is verified
+func (rh *RouteHandler) login(c *gin.Context, role string, verify func(context.Context, auth.Authentication) (bool, error)) {
 	var req auth.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		rh.log.WithError(err).Warn("failed to bind request")
@@ -66,7 +77,9 @@ func (rh *RouteHandler) VerifyChallenge(c *gin.Context) {
 		return
 	}
 
-	valid, err := rh.tokenVerifier.Verify(c, authnReq)
+	authnReq.Role = role
+
+	valid, err := verify(c, authnReq)
 	if err != nil {
 		rh.log.WithFields(logrus.Fields{
 			"address": authnReq.UserAddress,
@@ -91,6 +104,7 @@ func (rh *RouteHandler) VerifyChallenge(c *gin.Context) {
 	rh.log.WithFields(logrus.Fields{
 		"address": authnReq.UserAddress,
 		"ip":      authnReq.IPAddr,
+		"role":    role,
 	}).Info("login successful")
 	c.JSON(http.StatusOK, gin.H{"token": token})
 }
@@ -103,5 +117,5 @@ func (rh *RouteHandler) CheckAuth(c *gin.Context) {
 		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"address": user})
+	c.JSON(http.StatusOK, gin.H{"address": user, "role": c.GetString(auth.RoleKey)})
 }
@@ -0,0 +1,305 @@
+package routes
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ETHCF/ethutils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/auth"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/disbursement"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+// Disbursement matching routes, the tracker matches outgoing transfers to the grants they paid for admins to review
+
+// GET /api/v1/grants/:id/addresses - Get the other addresses a grant recipient is paid at
+func (rh *RouteHandler) GetGrantAddresses(c *gin.Context) {
+	grantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid grant ID format"})
+		return
+	}
+
+	addresses, err := rh.matchDB.GetGrantAddresses(c, grantID)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to get grant addresses")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve grant addresses"})
+		return
+	}
+
+	c.JSON(http.StatusOK, addresses)
+}
+
+// POST /api/v1/grants/:id/addresses - Add another address a grant recipient is paid at
+func (rh *RouteHandler) AddGrantAddress(c *gin.Context) {
+	grantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid grant ID format"})
+		return
+	}
+
+	var req types.AddGrantAddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		rh.log.WithError(err).Warn("failed to bind add grant address request")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	address, err := ethutils.SanitizeEthAddr(req.Address)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid address"})
+		return
+	}
+
+	grantAddress := types.GrantAddress{GrantID: grantID, Address: address, Label: req.Label}
+	if err := rh.matchDB.AddGrantAddress(c, grantAddress); err != nil {
+		if strings.Contains(err.Error(), "duplicate key value") {
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "Address already added to the grant"})
+			return
+		}
+		if strings.Contains(err.Error(), "violates foreign key constraint") {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Grant not found"})
+			return
+		}
+		rh.log.WithError(err).Error("failed to add grant address")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to add grant address"})
+		return
+	}
+
+	adminAction := types.AdminAction{
+		AdminAddress: auth.MustUserID(c),
+		Action:       "add_grant_address",
+		ResourceType: "grant",
+		ResourceID:   grantID.String(),
+		Details: types.AdminActionDetails{
+			"address": address,
+			"label":   req.Label,
+		},
+		CreatedAt: time.Now(),
+	}
+
+	err = rh.adminActionDB.RecordAdminAction(c, adminAction)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to record admin action")
+		// Don't fail the request for logging errors
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Grant address added successfully"})
+}
+
+// DELETE /api/v1/grants/:id/addresses/:address - Remove another address of a grant recipient
+func (rh *RouteHandler) DeleteGrantAddress(c *gin.Context) {
+	grantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid grant ID format"})
+		return
+	}
+
+	address, err := ethutils.SanitizeEthAddr(c.Param("address"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid address"})
+		return
+	}
+
+	if err := rh.matchDB.DeleteGrantAddress(c, grantID, address); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Grant address not found"})
+			return
+		}
+		rh.log.WithError(err).Error("failed to delete grant address")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete grant address"})
+		return
+	}
+
+	adminAction := types.AdminAction{
+		AdminAddress: auth.MustUserID(c),
+		Action:       "delete_grant_address",
+		ResourceType: "grant",
+		ResourceID:   grantID.String(),
+		Details: types.AdminActionDetails{
+			"address": address,
+		},
+		CreatedAt: time.Now(),
+	}
+
+	err = rh.adminActionDB.RecordAdminAction(c, adminAction)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to record admin action")
+		// Don't fail the request for logging errors
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GET /api/v1/disbursement-matches - Get transfers matched to grants, newest first. Filter with status=proposed|confirmed|rejected
+func (rh *RouteHandler) GetDisbursementMatches(c *gin.Context) {
+	status := types.DisbursementMatchStatus(c.Query("status"))
+	switch status {
+	case "", types.DisbursementMatchProposed, types.DisbursementMatchConfirmed, types.DisbursementMatchRejected:
+	default:
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid status parameter (proposed, confirmed or rejected)"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 1000 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid limit parameter (1-1000)"})
+		return
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid offset parameter"})
+		return
+	}
+
+	matches, err := rh.matchDB.GetMatches(c, status, limit, offset)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to get disbursement matches")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve disbursement matches"})
+		return
+	}
+
+	c.JSON(http.StatusOK, matches)
+}
+
+// GET /api/v1/disbursement-matches/:id - Get a transfer matched to a grant by ID
+func (rh *RouteHandler) GetDisbursementMatchByID(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid match ID format"})
+		return
+	}
+
+	match, err := rh.matchDB.GetMatchByID(c, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Disbursement match not found"})
+			return
+		}
+		rh.log.WithError(err).Error("failed to get disbursement match")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve disbursement match"})
+		return
+	}
+
+	c.JSON(http.StatusOK, match)
+}
+
+// POST /api/v1/disbursement-matches/:id/confirm - Disburse the whole transfer of a proposed match to its grant
+func (rh *RouteHandler) ConfirmDisbursementMatch(c *gin.Context) {
+	rh.reviewDisbursementMatch(c, "confirm_disbursement_match", rh.matchDB.ConfirmMatch)
+}
+
+// POST /api/v1/disbursement-matches/:id/reject - Reject a proposed match, the transfer didn't pay the grant
+func (rh *RouteHandler) RejectDisbursementMatch(c *gin.Context) {
+	rh.reviewDisbursementMatch(c, "reject_disbursement_match", rh.matchDB.RejectMatch)
+}
+
+func (rh *RouteHandler) reviewDisbursementMatch(c *gin.Context, action string, review func(ctx context.Context, id uuid.UUID, reviewer string) error) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid match ID format"})
+		return
+	}
+
+	if err := review(c, id, auth.MustUserID(c)); err != nil {
+		rh.abortDisbursementMatchError(c, err)
+		return
+	}
+
+	adminAction := types.AdminAction{
+		AdminAddress: auth.MustUserID(c),
+		Action:       action,
+		ResourceType: "disbursement_match",
+		ResourceID:   id.String(),
+		Details:      types.AdminActionDetails{},
+		CreatedAt:    time.Now(),
+	}
+
+	err = rh.adminActionDB.RecordAdminAction(c, adminAction)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to record admin action")
+		// Don't fail the request for logging errors
+	}
+
+	rh.GetDisbursementMatchByID(c)
+}
+
+// POST /api/v1/disbursement-matches/:id/split - Divide the transfer of a proposed match between grants
+func (rh *RouteHandler) SplitDisbursementMatch(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid match ID format"})
+		return
+	}
+
+	var req types.SplitMatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		rh.log.WithError(err).Warn("failed to bind split disbursement match request")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	match, err := rh.matchDB.GetMatchByID(c, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Disbursement match not found"})
+			return
+		}
+		rh.log.WithError(err).Error("failed to get disbursement match")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve disbursement match"})
+		return
+	}
+
+	if err := disbursement.ValidateSplit(match.Amount, req.Allocations); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := rh.matchDB.SplitMatch(c, id, req.Allocations, auth.MustUserID(c)); err != nil {
+		rh.abortDisbursementMatchError(c, err)
+		return
+	}
+
+	adminAction := types.AdminAction{
+		AdminAddress: auth.MustUserID(c),
+		Action:       "split_disbursement_match",
+		ResourceType: "disbursement_match",
+		ResourceID:   id.String(),
+		Details: types.AdminActionDetails{
+			"transfer_id": match.TransferID.String(),
+			"allocations": req.Allocations,
+		},
+		CreatedAt: time.Now(),
+	}
+
+	err = rh.adminActionDB.RecordAdminAction(c, adminAction)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to record admin action")
+		// Don't fail the request for logging errors
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Transfer split successfully"})
+}
+
+func (rh *RouteHandler) abortDisbursementMatchError(c *gin.Context, err error) {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Disbursement match not found"})
+	case strings.Contains(err.Error(), "already"):
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "violates foreign key constraint"):
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Allocations must be to existing grants"})
+	default:
+		rh.log.WithError(err).Error("failed to review disbursement match")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to review disbursement match"})
+	}
+}
@@ -0,0 +1,46 @@
+package routes
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Exchange account routes, read from the exchanges' APIs by the tracker
+
+// GET /api/v1/exchange/balances - Get the balances held in exchange accounts
+func (rh *RouteHandler) GetExchangeBalances(c *gin.Context) {
+	balances, err := rh.treasuryDB.GetExchangeBalances(c)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to get exchange balances")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve exchange balances"})
+		return
+	}
+
+	c.JSON(http.StatusOK, balances)
+}
+
+// GET /api/v1/exchange/movements - Get exchange deposits and withdrawals with their matching transfers
+func (rh *RouteHandler) GetExchangeMovements(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 1000 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid limit parameter (1-1000)"})
+		return
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid offset parameter"})
+		return
+	}
+
+	movements, err := rh.exchangeDB.GetMovements(c, limit, offset)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to get exchange movements")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve exchange movements"})
+		return
+	}
+
+	c.JSON(http.StatusOK, movements)
+}
@@ -359,8 +359,13 @@ func (rh *RouteHandler) UploadExpenseReceipt(c *gin.Context) {
 	}
 	defer file.Close()
 
-	// TODO: Implement file storage logic here
-	// For now, create a receipt record without actual file storage
+	storageID, err := rh.storage.Put(c, file)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to store receipt")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload receipt"})
+		return
+	}
+
 	receipt := types.Receipt{
 		ID:        uuid.New(),
 		ExpenseID: expenseID,
@@ -368,12 +373,15 @@ func (rh *RouteHandler) UploadExpenseReceipt(c *gin.Context) {
 		FileName:  null.StringFrom(header.Filename),
 		FileSize:  header.Size,
 		MimeType:  null.StringFrom(header.Header.Get("Content-Type")),
-		StorageID: uuid.New().String(), // Placeholder storage ID
+		StorageID: storageID,
 		CreatedAt: time.Now(),
 	}
 
 	if err := rh.expenseDB.CreateReceipt(c, receipt); err != nil {
 		rh.log.WithError(err).Error("failed to create receipt record")
+		if err := rh.storage.Delete(c, storageID); err != nil {
+			rh.log.WithError(err).Error("failed to delete stored receipt")
+		}
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload receipt"})
 		return
 	}
@@ -444,7 +452,12 @@ func (rh *RouteHandler) DeleteReceipt(c *gin.Context) {
 		return
 	}
 
-	// TODO: Remove file from storage before deleting database record
+	receipt, err := rh.expenseDB.GetReceiptByID(c, receiptID)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to get receipt by ID")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete receipt"})
+		return
+	}
 
 	if err := rh.expenseDB.DeleteReceipt(c, receiptID); err != nil {
 		rh.log.WithError(err).Error("failed to delete receipt")
@@ -452,6 +465,11 @@ func (rh *RouteHandler) DeleteReceipt(c *gin.Context) {
 		return
 	}
 
+	// Receipts uploaded before files were stored have nothing to remove
+	if err := rh.storage.Delete(c, receipt.StorageID); err != nil {
+		rh.log.WithError(err).WithField("storageID", receipt.StorageID).Error("failed to delete stored receipt")
+	}
+
 	// Get current admin for logging
 	currentAdminAddr := auth.MustUserID(c)
 
@@ -0,0 +1,321 @@
+package routes
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/auth"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+// Grant document routes. Documents are files attached to a grant or one of its milestones, private ones are only
+// listed and downloadable by admins.
+
+func documentDownloadURL(id uuid.UUID) string {
+	return "/api/v1/grant-documents/" + id.String() + "/download"
+}
+
+// GET /api/v1/grants/{id}/documents - Get the documents of a grant, admins also get the private ones
+func (rh *RouteHandler) GetGrantDocuments(c *gin.Context) {
+	grantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid grant ID format"})
+		return
+	}
+
+	var milestoneID uuid.NullUUID
+	if c.Query("milestoneId") != "" {
+		milestoneID.UUID, err = uuid.Parse(c.Query("milestoneId"))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid milestone ID format"})
+			return
+		}
+		milestoneID.Valid = true
+	}
+
+	documents, err := rh.documentDB.GetDocumentsByGrantID(c, grantID, auth.IsAdmin(c))
+	if err != nil {
+		rh.log.WithError(err).Error("failed to get grant documents")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve grant documents"})
+		return
+	}
+
+	out := []types.GrantDocument{}
+	for _, document := range documents {
+		if milestoneID.Valid && document.MilestoneID != milestoneID {
+			continue
+		}
+		document.DownloadURL = documentDownloadURL(document.ID)
+		out = append(out, document)
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+// POST /api/v1/grants/{id}/documents - Upload a document for a grant or one of its milestones
+func (rh *RouteHandler) UploadGrantDocument(c *gin.Context) {
+	grantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid grant ID format"})
+		return
+	}
+
+	exists, err := rh.grantDB.GrantExists(c, grantID)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to check if grant exists")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify grant exists"})
+		return
+	}
+	if !exists {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Grant not found"})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, rh.conf.MaxUploadSize)
+	var req types.UploadGrantDocumentRequest
+	if err := c.ShouldBind(&req); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": "File is too large"})
+			return
+		}
+		rh.log.WithError(err).Warn("failed to bind upload grant document request")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	document := types.GrantDocument{
+		ID:         uuid.New(),
+		GrantID:    grantID,
+		Category:   req.Category,
+		Name:       strings.TrimSpace(req.Name),
+		Visibility: req.Visibility,
+		UploadedBy: auth.MustUserID(c),
+		CreatedAt:  time.Now(),
+	}
+	if req.MilestoneID != "" {
+		milestoneID, err := uuid.Parse(req.MilestoneID)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid milestone ID format"})
+			return
+		}
+		if _, err := rh.grantDB.GetMilestone(c, grantID, milestoneID); err != nil {
+			rh.abortMilestoneError(c, err, "get")
+			return
+		}
+		document.MilestoneID = uuid.NullUUID{UUID: milestoneID, Valid: true}
+	}
+	if document.Category == "" {
+		document.Category = types.GrantDocumentOther
+		if document.MilestoneID.Valid {
+			document.Category = types.GrantDocumentMilestoneEvidence
+		}
+	}
+	if document.Visibility == "" {
+		document.Visibility = types.DocumentPrivate
+	}
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "No file uploaded or file upload error"})
+		return
+	}
+	defer file.Close()
+
+	document.FileName = null.StringFrom(header.Filename)
+	document.FileSize = header.Size
+	document.MimeType = null.StringFrom(header.Header.Get("Content-Type"))
+	document.StorageID, err = rh.storage.Put(c, file)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to store grant document")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload document"})
+		return
+	}
+
+	if err := rh.documentDB.CreateDocument(c, document); err != nil {
+		rh.log.WithError(err).Error("failed to create grant document record")
+		if err := rh.storage.Delete(c, document.StorageID); err != nil {
+			rh.log.WithError(err).Error("failed to delete stored grant document")
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload document"})
+		return
+	}
+	document.DownloadURL = documentDownloadURL(document.ID)
+
+	adminAction := types.AdminAction{
+		AdminAddress: document.UploadedBy,
+		Action:       "upload_grant_document",
+		ResourceType: "grant_document",
+		ResourceID:   document.ID.String(),
+		Details: types.AdminActionDetails{
+			"grant_id":      grantID.String(),
+			"milestone_id":  document.MilestoneID,
+			"document_name": document.Name,
+			"category":      document.Category,
+			"visibility":    document.Visibility,
+			"storage_id":    document.StorageID,
+		},
+		CreatedAt: time.Now(),
+	}
+
+	err = rh.adminActionDB.RecordAdminAction(c, adminAction)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to record admin action")
+		// Don't fail the request for logging errors
+	}
+
+	c.JSON(http.StatusCreated, document)
+}
+
+// GET /api/v1/grant-documents/{id}/download - Download a grant document, private ones only by admins
+func (rh *RouteHandler) DownloadGrantDocument(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid document ID format"})
+		return
+	}
+
+	document, err := rh.documentDB.GetDocument(c, id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Grant document not found"})
+			return
+		}
+		rh.log.WithError(err).Error("failed to get grant document")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve grant document"})
+		return
+	}
+	// Private documents aren't acknowledged to exist
+	if document.Visibility != types.DocumentPublic && !auth.IsAdmin(c) {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Grant document not found"})
+		return
+	}
+
+	rh.serveStoredFile(c, document.StorageID, document.Name, document.FileName, document.MimeType, document.FileSize)
+}
+
+// serveStoredFile responds with a file kept in storage as an attachment, named after its original file name
+func (rh *RouteHandler) serveStoredFile(c *gin.Context, storageID, name string, fileName, mimeType null.String, size int64) {
+	file, err := rh.storage.Get(c, storageID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "File not found"})
+			return
+		}
+		rh.log.WithError(err).WithField("storageID", storageID).Error("failed to open stored file")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve file"})
+		return
+	}
+	defer file.Close()
+
+	contentType := mimeType.String
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	if fileName.String != "" {
+		name = fileName.String
+	}
+
+	c.DataFromReader(http.StatusOK, size, contentType, file, map[string]string{
+		"Content-Disposition": mime.FormatMediaType("attachment", map[string]string{"filename": name}),
+	})
+}
+
+// PUT /api/v1/grant-documents/{id}/visibility - Make a grant document public or private
+func (rh *RouteHandler) UpdateGrantDocumentVisibility(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid document ID format"})
+		return
+	}
+
+	var req types.UpdateDocumentVisibilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		rh.log.WithError(err).Warn("failed to bind update document visibility request")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := rh.documentDB.SetDocumentVisibility(c, id, req.Visibility); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Grant document not found"})
+			return
+		}
+		rh.log.WithError(err).Error("failed to update grant document visibility")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to update grant document"})
+		return
+	}
+
+	adminAction := types.AdminAction{
+		AdminAddress: auth.MustUserID(c),
+		Action:       "update_grant_document_visibility",
+		ResourceType: "grant_document",
+		ResourceID:   id.String(),
+		Details: types.AdminActionDetails{
+			"visibility": req.Visibility,
+		},
+		CreatedAt: time.Now(),
+	}
+
+	err = rh.adminActionDB.RecordAdminAction(c, adminAction)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to record admin action")
+		// Don't fail the request for logging errors
+	}
+
+	c.JSON(http.StatusOK, gin.H{"id": id, "visibility": req.Visibility})
+}
+
+// DELETE /api/v1/grant-documents/{id} - Delete a grant document and its file
+func (rh *RouteHandler) DeleteGrantDocument(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid document ID format"})
+		return
+	}
+
+	document, err := rh.documentDB.DeleteDocument(c, id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Grant document not found"})
+			return
+		}
+		rh.log.WithError(err).Error("failed to delete grant document")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete document"})
+		return
+	}
+
+	// The record is gone, so a file left behind is only wasted space
+	if err := rh.storage.Delete(c, document.StorageID); err != nil {
+		rh.log.WithError(err).WithField("storageID", document.StorageID).Error("failed to delete stored grant document")
+	}
+
+	adminAction := types.AdminAction{
+		AdminAddress: auth.MustUserID(c),
+		Action:       "delete_grant_document",
+		ResourceType: "grant_document",
+		ResourceID:   id.String(),
+		Details: types.AdminActionDetails{
+			"grant_id":      document.GrantID.String(),
+			"milestone_id":  document.MilestoneID,
+			"document_name": document.Name,
+			"storage_id":    document.StorageID,
+		},
+		CreatedAt: time.Now(),
+	}
+
+	err = rh.adminActionDB.RecordAdminAction(c, adminAction)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to record admin action")
+		// Don't fail the request for logging errors
+	}
+
+	c.Status(http.StatusNoContent)
+}
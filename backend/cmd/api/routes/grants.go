@@ -1,7 +1,11 @@
 package routes
 
 import (
+	"database/sql"
+	"errors"
 	"net/http"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -11,14 +15,115 @@ import (
 	"gopkg.in/guregu/null.v4"
 
 	"github.com/ETHCF/transparency-dashboard/backend/pkg/auth"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/constants"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/disbursement"
 	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
 )
 
 // Grant management routes
 
+var decimalAmount = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+
 // GET /api/v1/grants - Get grants with optional filtering
 func (rh *RouteHandler) GetGrants(c *gin.Context) {
-	grants, err := rh.grantDB.GetGrants(c)
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit < 1 || limit > 1000 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid limit parameter (1-1000)"})
+		return
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid offset parameter"})
+		return
+	}
+
+	filter := types.GrantFilter{
+		Search:     strings.TrimSpace(c.Query("search")),
+		Overdue:    c.Query("overdue") == "true",
+		Sort:       types.GrantSort(c.DefaultQuery("sort", string(types.GrantSortCreatedAt))),
+		Descending: c.DefaultQuery("order", "desc") == "desc",
+		Limit:      limit,
+		Offset:     offset,
+	}
+
+	// Previous selects every grant which is over
+	switch status := types.GrantStatus(c.Query("status")); {
+	case status == "":
+	case status == "previous":
+		filter.Statuses = types.PreviousGrantStatuses
+	case status.Valid():
+		filter.Statuses = []types.GrantStatus{status}
+	default:
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid status parameter"})
+		return
+	}
+
+	switch filter.Sort {
+	case types.GrantSortCreatedAt, types.GrantSortName, types.GrantSortStartDate, types.GrantSortCompletionDate,
+		types.GrantSortTotalGrantAmount, types.GrantSortAmountGivenSoFar:
+	default:
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid sort parameter"})
+		return
+	}
+
+	if order := c.Query("order"); order != "" && order != "asc" && order != "desc" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid order parameter (asc, desc)"})
+		return
+	}
+
+	if recipient := c.Query("recipient"); recipient != "" {
+		filter.RecipientAddress, err = ethutils.SanitizeEthAddr(recipient)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid recipient address"})
+			return
+		}
+		filter.RecipientAddress = strings.ToLower(filter.RecipientAddress)
+	}
+
+	if asset := c.Query("asset"); asset != "" {
+		filter.Asset, err = ethutils.SanitizeEthAddr(asset)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid asset address"})
+			return
+		}
+		filter.Asset = strings.ToLower(filter.Asset)
+	}
+
+	for param, date := range map[string]*null.Time{
+		"startFrom":      &filter.StartFrom,
+		"startTo":        &filter.StartTo,
+		"completionFrom": &filter.CompletionFrom,
+		"completionTo":   &filter.CompletionTo,
+	} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid " + param + " date format (expected YYYY-MM-DD)"})
+			return
+		}
+		*date = null.TimeFrom(parsed)
+	}
+
+	for param, amount := range map[string]*null.String{
+		"minAmount": &filter.MinAmount,
+		"maxAmount": &filter.MaxAmount,
+	} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		if !decimalAmount.MatchString(value) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid " + param + " parameter"})
+			return
+		}
+		*amount = null.StringFrom(value)
+	}
+
+	grants, err := rh.grantDB.GetGrants(c, filter)
 	if err != nil {
 		rh.log.WithError(err).Error("failed to get grants")
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve grants"})
@@ -63,6 +168,21 @@ func (rh *RouteHandler) CreateGrant(c *gin.Context) {
 		return
 	}
 
+	// Grants can be created in any status, so that past grants can be recorded
+	if !types.GrantStatus(req.Status).Valid() {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid grant status"})
+		return
+	}
+
+	asset := constants.EtherAddress
+	if req.Asset != "" {
+		known, ok := rh.knownAsset(c, req.Asset)
+		if !ok {
+			return
+		}
+		asset = known.Address
+	}
+
 	// Create grant object
 	grant := types.CreateGrant{
 		Name:                   strings.TrimSpace(req.Name),
@@ -71,11 +191,12 @@ func (rh *RouteHandler) CreateGrant(c *gin.Context) {
 		Description:            strings.TrimSpace(req.Description),
 		TeamURL:                null.StringFromPtr(stringPtrIfNotEmpty(strings.TrimSpace(req.TeamURL))),
 		ProjectURL:             null.StringFromPtr(stringPtrIfNotEmpty(strings.TrimSpace(req.ProjectURL))),
+		Asset:                  asset,
 		TotalGrantAmount:       req.TotalGrantAmount,
 		InitialGrantAmount:     req.InitialGrantAmount,
 		StartDate:              req.StartDate,
 		ExpectedCompletionDate: req.ExpectedCompletionDate,
-		AmountGivenSoFar:       req.InitialGrantAmount,
+		AmountGivenSoFar:       "0", // Kept in sync with the grant's disbursements
 		Status:                 types.GrantStatus(req.Status),
 	}
 
@@ -100,7 +221,8 @@ func (rh *RouteHandler) CreateGrant(c *gin.Context) {
 			"grant_status":              grant.Status,
 			"grant_start_date":          grant.StartDate.Format("2006-01-02"),
 			"grant_expected_completion": grant.ExpectedCompletionDate.Format("2006-01-02"),
-			"initial_grant_amount":      grant.AmountGivenSoFar,
+			"initial_grant_amount":      grant.InitialGrantAmount,
+			"asset":                     grant.Asset,
 		},
 		CreatedAt: time.Now(),
 	}
@@ -152,9 +274,31 @@ func (rh *RouteHandler) UpdateGrant(c *gin.Context) {
 		updates.RecipientAddress = null.StringFrom(sanitizedAddr)
 	}
 
-	if err := rh.grantDB.UpdateGrant(c, id, updates); err != nil {
-		rh.log.WithError(err).Error("failed to update grant")
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to update grant"})
+	if updates.Asset.Valid {
+		known, ok := rh.knownAsset(c, updates.Asset.String)
+		if !ok {
+			return
+		}
+		updates.Asset = null.StringFrom(known.Address)
+	}
+
+	if updates.Status.Valid && !types.GrantStatus(updates.Status.String).Valid() {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid grant status"})
+		return
+	}
+
+	if err := rh.grantDB.UpdateGrant(c, id, updates, auth.MustUserID(c)); err != nil {
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Grant not found"})
+		case strings.Contains(err.Error(), "invalid grant status transition"):
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case strings.Contains(err.Error(), "reason is required"):
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "statusReason is required to change the grant status"})
+		default:
+			rh.log.WithError(err).Error("failed to update grant")
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to update grant"})
+		}
 		return
 	}
 
@@ -181,6 +325,7 @@ func (rh *RouteHandler) UpdateGrant(c *gin.Context) {
 			"grant_start_date":          updatedGrant.StartDate.Format("2006-01-02"),
 			"grant_expected_completion": updatedGrant.ExpectedCompletionDate.Format("2006-01-02"),
 			"amount_given_so_far":       updatedGrant.AmountGivenSoFar,
+			"asset":                     updatedGrant.Asset,
 		},
 		CreatedAt: time.Now(),
 	}
@@ -231,22 +376,41 @@ func (rh *RouteHandler) UpdateGrantMilestones(c *gin.Context) {
 			}
 		}
 
+		// Left empty for the grant's asset
+		var asset string
+		if m.Asset != "" {
+			known, ok := rh.knownAsset(c, m.Asset)
+			if !ok {
+				return
+			}
+			asset = known.Address
+		}
+
 		milestones[i] = types.Milestone{
 			ID:          milestoneID,
 			GrantID:     id,
 			Name:        strings.TrimSpace(m.Title),
 			Description: strings.TrimSpace(m.Description),
+			Asset:       asset,
 			GrantAmount: m.Amount,
-			Status:      types.MilestoneStatus(m.Status),
-			Completed:   m.Completed,
-			SignedOff:   m.SignedOff,
+			TargetDate:  m.TargetDate,
+			Status:      types.MilestoneStatusPending, // Only for new milestones, existing ones keep their sign-off
 			OrderIndex:  i,
 			CreatedAt:   time.Now(),
 			UpdatedAt:   time.Now(),
 		}
 	}
 
-	if err := rh.grantDB.UpdateGrantMilestones(c, id, milestones); err != nil {
+	reset, err := rh.grantDB.UpdateGrantMilestones(c, id, milestones)
+	if err != nil {
+		if strings.Contains(err.Error(), "belongs to another grant") {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if strings.Contains(err.Error(), "can't be") {
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		rh.log.WithError(err).Error("failed to update grant milestones")
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to update grant milestones"})
 		return
@@ -279,7 +443,11 @@ func (rh *RouteHandler) UpdateGrantMilestones(c *gin.Context) {
 		Action:       "update_grant_milestones",
 		ResourceType: "grant",
 		ResourceID:   id.String(),
-		CreatedAt:    time.Now(),
+		Details: types.AdminActionDetails{
+			"milestones":       len(milestones),
+			"reset_milestones": reset, // Repriced after completion, their approvals and proposed payments were cleared
+		},
+		CreatedAt: time.Now(),
 	}
 
 	err = rh.adminActionDB.RecordAdminAction(c, adminAction)
@@ -291,6 +459,24 @@ func (rh *RouteHandler) UpdateGrantMilestones(c *gin.Context) {
 	c.JSON(http.StatusOK, updatedGrant)
 }
 
+// GET /api/v1/grants/{id}/timeline - Get the status transitions of a grant
+func (rh *RouteHandler) GetGrantTimeline(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid grant ID format"})
+		return
+	}
+
+	events, err := rh.grantDB.GetStatusEvents(c, id)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to get grant timeline")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve grant timeline"})
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
+
 // GET /api/v1/grants/{id}/milestones - Get grant milestones
 func (rh *RouteHandler) GetGrantMilestones(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("id"))
@@ -342,20 +528,11 @@ func (rh *RouteHandler) CreateDisbursement(c *gin.Context) {
 		return
 	}
 
-	req.TxHash, err = ethutils.SanitizeEthHash(req.TxHash)
-	if err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid transaction hash"})
+	disbursement, ok := rh.disbursementFromRequest(c, grantID, req)
+	if !ok {
 		return
 	}
 
-	// Create disbursement object
-	disbursement := types.CreateDisbursement{
-		Amount:         req.Amount,
-		TxHash:         strings.ToLower(req.TxHash),
-		BlockNumber:    req.BlockNumber,
-		BlockTimestamp: req.BlockTimestamp,
-	}
-
 	if err := rh.grantDB.InsertDisbursement(c, grantID, disbursement); err != nil {
 		rh.log.WithError(err).Error("failed to create disbursement")
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to create disbursement"})
@@ -374,8 +551,10 @@ func (rh *RouteHandler) CreateDisbursement(c *gin.Context) {
 		Details: types.AdminActionDetails{
 			"grant_id":            grantID.String(),
 			"disbursement_amount": disbursement.Amount,
+			"asset":               disbursement.Asset,
 			"tx_hash":             disbursement.TxHash,
 			"block_number":        disbursement.BlockNumber,
+			"verification_status": disbursement.VerificationStatus,
 		},
 		CreatedAt: time.Now(),
 	}
@@ -386,7 +565,11 @@ func (rh *RouteHandler) CreateDisbursement(c *gin.Context) {
 		// Don't fail the request for logging errors
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"message": "Disbursement created successfully"})
+	c.JSON(http.StatusCreated, gin.H{
+		"message":            "Disbursement created successfully",
+		"verificationStatus": disbursement.VerificationStatus,
+		"verificationReason": disbursement.VerificationReason,
+	})
 }
 
 // PUT /api/v1/grants/{id}/disbursements/{disbursementId} - Update a disbursement
@@ -410,22 +593,15 @@ func (rh *RouteHandler) UpdateDisbursement(c *gin.Context) {
 		return
 	}
 
-	req.TxHash, err = ethutils.SanitizeEthHash(req.TxHash)
-	if err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid transaction hash"})
+	disbursement, ok := rh.disbursementFromRequest(c, grantID, types.CreateDisbursementRequest(req))
+	if !ok {
 		return
 	}
 
 	// Create updated disbursement object
 	updatedDisbursement := types.Disbursement{
-		ID: disbursementID,
-		CreateDisbursement: types.CreateDisbursement{
-			GrantID:        grantID,
-			Amount:         req.Amount,
-			TxHash:         strings.ToLower(req.TxHash),
-			BlockNumber:    req.BlockNumber,
-			BlockTimestamp: req.BlockTimestamp,
-		},
+		ID:                 disbursementID,
+		CreateDisbursement: disbursement,
 	}
 
 	if err := rh.grantDB.UpdateDisbursement(c, disbursementID, updatedDisbursement); err != nil {
@@ -451,8 +627,82 @@ func (rh *RouteHandler) UpdateDisbursement(c *gin.Context) {
 			"grant_id":            grantID.String(),
 			"disbursement_id":     disbursementID.String(),
 			"disbursement_amount": updatedDisbursement.Amount,
+			"asset":               updatedDisbursement.Asset,
 			"tx_hash":             updatedDisbursement.TxHash,
 			"block_number":        updatedDisbursement.BlockNumber,
+			"verification_status": updatedDisbursement.VerificationStatus,
+		},
+		CreatedAt: time.Now(),
+	}
+
+	err = rh.adminActionDB.RecordAdminAction(c, adminAction)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to record admin action")
+		// Don't fail the request for logging errors
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":            "Disbursement updated successfully",
+		"verificationStatus": updatedDisbursement.VerificationStatus,
+		"verificationReason": updatedDisbursement.VerificationReason,
+	})
+}
+
+// POST /api/v1/grants/{id}/disbursements/{disbursementId}/verify - Check a disbursement against its transaction again
+func (rh *RouteHandler) VerifyDisbursement(c *gin.Context) {
+	grantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid grant ID format"})
+		return
+	}
+
+	disbursementID, err := uuid.Parse(c.Param("disbursementId"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid disbursement ID format"})
+		return
+	}
+
+	if rh.ethClient == nil {
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "No RPC endpoint is configured"})
+		return
+	}
+
+	disbursements, err := rh.grantDB.GetDisbursementsByGrantID(c, grantID)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to get grant disbursements")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve grant disbursements"})
+		return
+	}
+	var found *types.Disbursement
+	for i := range disbursements {
+		if disbursements[i].ID == disbursementID {
+			found = &disbursements[i]
+		}
+	}
+	if found == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Disbursement not found"})
+		return
+	}
+
+	if !rh.verifyDisbursement(c, grantID, &found.CreateDisbursement) {
+		return
+	}
+
+	if err := rh.grantDB.UpdateDisbursement(c, disbursementID, *found); err != nil {
+		rh.log.WithError(err).Error("failed to update disbursement")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to update disbursement"})
+		return
+	}
+
+	adminAction := types.AdminAction{
+		AdminAddress: auth.MustUserID(c),
+		Action:       "verify_disbursement",
+		ResourceType: "disbursement",
+		ResourceID:   disbursementID.String(),
+		Details: types.AdminActionDetails{
+			"grant_id":            grantID.String(),
+			"verification_status": found.VerificationStatus,
+			"verification_reason": found.VerificationReason,
 		},
 		CreatedAt: time.Now(),
 	}
@@ -463,7 +713,136 @@ func (rh *RouteHandler) UpdateDisbursement(c *gin.Context) {
 		// Don't fail the request for logging errors
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Disbursement updated successfully"})
+	c.JSON(http.StatusOK, found)
+}
+
+// disbursementFromRequest validates a disbursement request and checks it against the chain, aborting when it is invalid
+func (rh *RouteHandler) disbursementFromRequest(c *gin.Context, grantID uuid.UUID, req types.CreateDisbursementRequest) (types.CreateDisbursement, bool) {
+	txHash, err := ethutils.SanitizeEthHash(req.TxHash)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid transaction hash"})
+		return types.CreateDisbursement{}, false
+	}
+
+	grant, err := rh.grantDB.GetGrantByID(c, grantID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Grant not found"})
+			return types.CreateDisbursement{}, false
+		}
+		rh.log.WithError(err).Error("failed to get grant")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve grant"})
+		return types.CreateDisbursement{}, false
+	}
+
+	asset := grant.Asset
+	if req.Asset != "" {
+		known, ok := rh.knownAsset(c, req.Asset)
+		if !ok {
+			return types.CreateDisbursement{}, false
+		}
+		asset = known.Address
+	}
+
+	if rh.ethClient == nil && (req.BlockNumber == 0 || req.BlockTimestamp == 0) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "blockNumber and blockTimestamp are required when no RPC endpoint is configured"})
+		return types.CreateDisbursement{}, false
+	}
+
+	disbursement := types.CreateDisbursement{
+		GrantID:        grantID,
+		Asset:          asset,
+		Amount:         req.Amount,
+		TxHash:         strings.ToLower(txHash),
+		BlockNumber:    req.BlockNumber,
+		BlockTimestamp: req.BlockTimestamp,
+		UsdPrice:       req.UsdPrice,
+	}
+	if !rh.verifyDisbursement(c, grantID, &disbursement) {
+		return types.CreateDisbursement{}, false
+	}
+	return disbursement, true
+}
+
+// verifyDisbursement checks a disbursement against its transaction, aborting when the grant or asset can't be found.
+// The disbursement is left unverified when the chain can't be read.
+func (rh *RouteHandler) verifyDisbursement(c *gin.Context, grantID uuid.UUID, d *types.CreateDisbursement) bool {
+	asset, ok := rh.knownAsset(c, d.Asset)
+	if !ok {
+		return false
+	}
+	if _, err := disbursement.ToRawUnits(d.Amount, asset.Decimals); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+
+	if rh.ethClient == nil {
+		d.DisbursementVerification = disbursement.Unverified("No RPC endpoint is configured")
+		return true
+	}
+
+	grant, err := rh.grantDB.GetGrantByID(c, grantID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Grant not found"})
+			return false
+		}
+		rh.log.WithError(err).Error("failed to get grant")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve grant"})
+		return false
+	}
+	addresses, err := rh.matchDB.GetGrantAddresses(c, grantID)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to get grant addresses")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve grant addresses"})
+		return false
+	}
+	recipients := []string{grant.RecipientAddress}
+	for _, address := range addresses {
+		recipients = append(recipients, address.Address)
+	}
+
+	// Ether paid through a contract wallet only shows in the transfers ingested for the transaction
+	ingested, err := rh.treasuryDB.GetTransfersByTxHash(c, 1, d.TxHash)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to get transfers of transaction")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve transfers"})
+		return false
+	}
+
+	if err := disbursement.Verify(c, rh.ethClient, d, recipients, asset.Decimals, ingested, time.Now()); err != nil {
+		rh.log.WithError(err).WithField("txHash", d.TxHash).Warn("failed to verify disbursement")
+		d.DisbursementVerification = disbursement.Unverified("The transaction couldn't be read from the chain")
+		if d.BlockNumber == 0 || d.BlockTimestamp == 0 {
+			c.AbortWithStatusJSON(http.StatusBadGateway, gin.H{"error": "The transaction couldn't be read from the chain, provide blockNumber and blockTimestamp"})
+			return false
+		}
+	}
+	return true
+}
+
+// knownAsset looks up a mainnet asset by address, aborting when it isn't one of the treasury assets
+func (rh *RouteHandler) knownAsset(c *gin.Context, address string) (types.Asset, bool) {
+	address, err := ethutils.SanitizeEthAddr(address)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid asset address"})
+		return types.Asset{}, false
+	}
+	address = strings.ToLower(address)
+
+	assets, err := rh.treasuryDB.GetAssets(c)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to get assets")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve assets"})
+		return types.Asset{}, false
+	}
+	for _, asset := range assets {
+		if asset.ChainID == 1 && asset.Address == address {
+			return asset, true
+		}
+	}
+	c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Unknown asset, add it to the treasury assets first"})
+	return types.Asset{}, false
 }
 
 // GET /api/v1/grants/{id}/funds-usage - Get grant funds usage
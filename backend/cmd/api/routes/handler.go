@@ -7,6 +7,9 @@ import (
 	"github.com/ETHCF/transparency-dashboard/backend/pkg/auth"
 	"github.com/ETHCF/transparency-dashboard/backend/pkg/config"
 	"github.com/ETHCF/transparency-dashboard/backend/pkg/db"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/eth"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/explorer"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/storage"
 )
 
 type RouteHandler struct {
@@ -15,13 +18,27 @@ type RouteHandler struct {
 	// Databases
 	adminActionDB db.AdminActionDB
 	adminDB       db.AdminDB
+	anomalyDB     db.AnomalyDB
 	authDB        db.AuthDB
+	exchangeDB    db.ExchangeDB
 	expenseDB     db.ExpenseDB
 	grantDB       db.GrantDB
+	documentDB    db.GrantDocumentDB
+	submissionDB  db.GrantSubmissionDB
 	settingsDB    db.SettingsDB
 	treasuryDB    db.TreasuryDB
 	budgetDB      db.BudgetDB
 	categoryDB    db.CategoryDB
+	labelDB       db.LabelDB
+	approvalDB    db.ApprovalDB
+	offchainDB    db.OffchainDB
+	policyDB      db.PolicyDB
+	reconDB       db.ReconciliationDB
+	matchDB       db.DisbursementMatchDB
+
+	ethClient eth.Client
+	importer  explorer.Importer
+	storage   storage.Storage
 
 	// Auth
 	authMiddleware auth.Middleware
@@ -36,19 +53,37 @@ type AuthPacket struct {
 }
 
 func NewRouteHandler(conf *config.Config, dbPacket db.DatabasePacket, authPacket AuthPacket) *RouteHandler {
+	var ethClient eth.Client
+	if conf.RPCURL != "" {
+		ethClient = eth.NewClient(conf)
+	}
 	return &RouteHandler{
 		log:  conf.GetLogger(),
 		conf: conf,
 
 		adminActionDB: dbPacket.AdminActionDB,
 		adminDB:       dbPacket.AdminDB,
+		anomalyDB:     dbPacket.AnomalyDB,
 		authDB:        dbPacket.AuthDB,
+		exchangeDB:    dbPacket.ExchangeDB,
 		expenseDB:     dbPacket.ExpenseDB,
 		grantDB:       dbPacket.GrantDB,
+		documentDB:    dbPacket.DocumentDB,
+		submissionDB:  dbPacket.SubmissionDB,
 		settingsDB:    dbPacket.SettingsDB,
 		treasuryDB:    dbPacket.TreasuryDB,
 		budgetDB:      dbPacket.BudgetDB,
 		categoryDB:    dbPacket.CategoryDB,
+		labelDB:       dbPacket.LabelDB,
+		approvalDB:    dbPacket.ApprovalDB,
+		offchainDB:    dbPacket.OffchainDB,
+		policyDB:      dbPacket.PolicyDB,
+		reconDB:       dbPacket.ReconDB,
+		matchDB:       dbPacket.MatchDB,
+
+		ethClient: ethClient,
+		importer:  explorer.NewImporter(conf, dbPacket.TreasuryDB, ethClient),
+		storage:   storage.NewStorage(conf),
 
 		authMiddleware: authPacket.AuthMiddleware,
 		tokenVerifier:  authPacket.TokenVerifier,
@@ -65,19 +100,39 @@ func (rh *RouteHandler) ApplyRoutes(r *gin.Engine) {
 	api.POST("/auth/login", rh.VerifyChallenge)
 	api.GET("/auth/check", rh.authMiddleware.Handle, rh.CheckAuth)
 	api.GET("/health", func(c *gin.Context) { c.Status(200) })
+	api.POST("/recipient/auth/login", rh.RecipientLogin)
+	api.GET("/recipient/auth/check", rh.authMiddleware.HandleRecipient, rh.CheckAuth)
 
 	// Public routes (no auth middleware needed)
 	api.GET("/grants", rh.GetGrants)
 	api.GET("/grants/:id", rh.GetGrantByID)
 	api.GET("/grants/:id/milestones", rh.GetGrantMilestones)
+	api.GET("/grants/:id/milestone-events", rh.GetMilestoneEvents)
+	api.GET("/milestones/overdue", rh.GetOverdueMilestones)
+	api.POST("/grants/:id/milestones/:milestoneId/completion-challenge", rh.CreateMilestoneCompletionChallenge)
+	api.POST("/grants/:id/milestones/:milestoneId/recipient-completion", rh.RecipientCompleteMilestone)
+	api.GET("/grants/:id/timeline", rh.GetGrantTimeline)
 	api.GET("/grants/:id/disbursements", rh.GetGrantDisbursements)
 	api.GET("/grants/:id/funds-usage", rh.GetGrantFundsUsage)
+	api.GET("/grants/:id/submissions", rh.GetGrantSubmissions)
+	api.GET("/grants/:id/documents", rh.authMiddleware.HandleOptional, rh.GetGrantDocuments)
+	api.GET("/grant-documents/:id/download", rh.authMiddleware.HandleOptional, rh.DownloadGrantDocument)
+	api.GET("/grants/:id/addresses", rh.GetGrantAddresses)
 	api.GET("/treasury", rh.GetTreasury)
 	api.GET("/treasury/assets", rh.GetTreasuryAssets)
+	api.GET("/treasury/composition", rh.GetTreasuryComposition)
 	api.GET("/treasury/wallets", rh.GetTreasuryWallets)
+	api.GET("/treasury/positions", rh.GetTreasuryPositions)
+	api.GET("/treasury/asset-statuses", rh.GetAssetStatuses)
+	api.GET("/treasury/valuation-policies", rh.GetValuationPolicies)
+	api.GET("/treasury/wallet-groups", rh.GetWalletGroups)
+	api.GET("/treasury/wallet-groups/:id", rh.GetWalletGroupByID)
+	api.GET("/exchange/balances", rh.GetExchangeBalances)
+	api.GET("/exchange/movements", rh.GetExchangeMovements)
 	api.GET("/transfers", rh.GetTransfers)
 	api.GET("/transfer-parties", rh.GetTransferParties)
 	api.GET("/transfer-parties/:address", rh.GetTransferPartyByAddress)
+	api.GET("/transfer-parties/:address/labels", rh.GetTransferPartyLabels)
 	api.GET("/expenses", rh.GetExpenses)
 	api.GET("/expenses/by-tx-hash/:txHash", rh.GetExpenseByTxHash)
 	api.GET("/expenses/:id", rh.GetExpenseByID)
@@ -89,41 +144,114 @@ func (rh *RouteHandler) ApplyRoutes(r *gin.Engine) {
 	api.GET("/settings/organization-name", rh.GetOrganizationName)
 	api.GET("/settings/total-funds-raised", rh.GetTotalFundsRaised)
 	api.GET("/settings/total-funds-raised-unit", rh.GetTotalFundsRaisedUnit)
+	api.GET("/settings/dust-thresholds", rh.GetDustThresholds)
+	api.GET("/settings/milestone-sign-off", rh.GetMilestoneSignOffSettings)
 	api.GET("/breakdown/expenses", rh.GetSpendingBreakdown)
+	api.GET("/offchain-accounts", rh.GetOffchainAccounts)
+	api.GET("/offchain-accounts/:id", rh.GetOffchainAccountByID)
+	api.GET("/offchain-accounts/:id/statements", rh.authMiddleware.HandleOptional, rh.GetOffchainStatements)
+	api.GET("/offchain-documents/:id/download", rh.authMiddleware.HandleOptional, rh.DownloadOffchainDocument)
+	api.GET("/policy-rules", rh.GetPolicyRules)
+	api.GET("/policy-violations", rh.GetPolicyViolations)
+	api.GET("/reconciliation", rh.GetReconciliation)
+	api.GET("/reconciliation/starting-balances", rh.GetStartingBalances)
+
+	// Grant recipient routes (require recipient auth middleware)
+	api.GET("/recipient/grants", rh.authMiddleware.HandleRecipient, rh.GetRecipientGrants)
+	api.GET("/recipient/submissions", rh.authMiddleware.HandleRecipient, rh.GetRecipientSubmissions)
+	api.POST("/recipient/grants/:id/milestones/:milestoneId/progress", rh.authMiddleware.HandleRecipient, rh.SubmitMilestoneProgress)
+	api.POST("/recipient/grants/:id/funds-usage", rh.authMiddleware.HandleRecipient, rh.SubmitFundsUsage)
+	api.POST("/recipient/grants/:id/documents", rh.authMiddleware.HandleRecipient, rh.SubmitDocument)
 
 	// Admin routes (require auth middleware)
 	api.GET("/admins", rh.authMiddleware.Handle, rh.GetAdmins)
 	api.POST("/admins", rh.authMiddleware.Handle, rh.AddAdmin)
 	api.DELETE("/admins/:address", rh.authMiddleware.Handle, rh.RemoveAdmin)
 	api.GET("/admin-actions", rh.authMiddleware.Handle, rh.GetAdminActions)
+	api.GET("/approvals", rh.authMiddleware.Handle, rh.GetApprovals)
+	api.GET("/anomalies", rh.authMiddleware.Handle, rh.GetAnomalies)
+	api.GET("/anomalies/:id", rh.authMiddleware.Handle, rh.GetAnomalyByID)
+	api.PUT("/anomalies/:id", rh.authMiddleware.Handle, rh.ReviewAnomaly)
+	api.GET("/disbursement-matches", rh.authMiddleware.Handle, rh.GetDisbursementMatches)
+	api.GET("/disbursement-matches/:id", rh.authMiddleware.Handle, rh.GetDisbursementMatchByID)
+	api.POST("/disbursement-matches/:id/confirm", rh.authMiddleware.Handle, rh.ConfirmDisbursementMatch)
+	api.POST("/disbursement-matches/:id/reject", rh.authMiddleware.Handle, rh.RejectDisbursementMatch)
+	api.POST("/disbursement-matches/:id/split", rh.authMiddleware.Handle, rh.SplitDisbursementMatch)
+	api.GET("/grant-submissions", rh.authMiddleware.Handle, rh.GetSubmissionsForReview)
+	api.GET("/grant-submissions/:id", rh.authMiddleware.Handle, rh.GetSubmissionByID)
+	api.PUT("/grant-submissions/:id", rh.authMiddleware.Handle, rh.ReviewGrantSubmission)
 
 	// Admin-only content management routes (require auth middleware)
 	api.POST("/grants", rh.authMiddleware.Handle, rh.CreateGrant)
 	api.PUT("/grants/:id", rh.authMiddleware.Handle, rh.UpdateGrant)
 	api.PUT("/grants/:id/milestones", rh.authMiddleware.Handle, rh.UpdateGrantMilestones)
+	api.POST("/grants/:id/milestones/:milestoneId/complete", rh.authMiddleware.Handle, rh.CompleteMilestone)
+	api.GET("/grants/:id/milestones/:milestoneId/approval-challenge", rh.authMiddleware.Handle, rh.GetMilestoneApprovalChallenge)
+	api.POST("/grants/:id/milestones/:milestoneId/approvals", rh.authMiddleware.Handle, rh.ApproveMilestone)
+	api.GET("/grants/:id/disbursement-proposals", rh.authMiddleware.Handle, rh.GetDisbursementProposals)
+	api.PUT("/grants/:id/disbursement-proposals/:proposalId", rh.authMiddleware.Handle, rh.ReviewDisbursementProposal)
 	api.POST("/expenses", rh.authMiddleware.Handle, rh.CreateExpense)
 	api.PUT("/expenses/:id", rh.authMiddleware.Handle, rh.UpdateExpense)
 	api.DELETE("/expenses/:id", rh.authMiddleware.Handle, rh.DeleteExpense)
 	api.POST("/expenses/:id/receipts", rh.authMiddleware.Handle, rh.UploadExpenseReceipt)
 	api.DELETE("/receipts/:id", rh.authMiddleware.Handle, rh.DeleteReceipt)
 	api.POST("/treasury/wallets", rh.authMiddleware.Handle, rh.AddWallet)
+	api.PUT("/treasury/wallets/:address", rh.authMiddleware.Handle, rh.UpdateWallet)
+	api.POST("/treasury/wallets/:address/challenge", rh.authMiddleware.Handle, rh.CreateWalletChallenge)
+	api.POST("/treasury/wallets/:address/verify", rh.authMiddleware.Handle, rh.VerifyWallet)
 	api.DELETE("/treasury/wallets/:address", rh.authMiddleware.Handle, rh.DeleteWallet)
+	api.POST("/treasury/wallet-groups", rh.authMiddleware.Handle, rh.CreateWalletGroup)
+	api.PUT("/treasury/wallet-groups/:id", rh.authMiddleware.Handle, rh.UpdateWalletGroup)
+	api.DELETE("/treasury/wallet-groups/:id", rh.authMiddleware.Handle, rh.DeleteWalletGroup)
 	api.POST("/transfers", rh.authMiddleware.Handle, rh.CreateTransfer)
+	api.POST("/transfers/import", rh.authMiddleware.Handle, rh.ImportTransfers)
 	api.POST("/treasury/assets", rh.authMiddleware.Handle, rh.AddAsset)
+	api.PUT("/treasury/assets/:address/class", rh.authMiddleware.Handle, rh.SetAssetClass)
+	api.PUT("/treasury/asset-statuses/:address", rh.authMiddleware.Handle, rh.SetAssetStatus)
+	api.DELETE("/treasury/asset-statuses/:address", rh.authMiddleware.Handle, rh.DeleteAssetStatus)
+	api.PUT("/treasury/valuation-policies/:address", rh.authMiddleware.Handle, rh.SetValuationPolicy)
+	api.DELETE("/treasury/valuation-policies/:address", rh.authMiddleware.Handle, rh.DeleteValuationPolicy)
+	api.POST("/policy-rules", rh.authMiddleware.Handle, rh.CreatePolicyRule)
+	api.PUT("/policy-rules/:id", rh.authMiddleware.Handle, rh.UpdatePolicyRule)
+	api.DELETE("/policy-rules/:id", rh.authMiddleware.Handle, rh.DeletePolicyRule)
+	api.PUT("/reconciliation/starting-balances", rh.authMiddleware.Handle, rh.SetStartingBalance)
+	api.DELETE("/reconciliation/starting-balances/:wallet/:asset", rh.authMiddleware.Handle, rh.DeleteStartingBalance)
 	api.PUT("/transfer-parties/:address", rh.authMiddleware.Handle, rh.UpdateTransferPartyName)
 	api.POST("/transfer-parties", rh.authMiddleware.Handle, rh.UpsertTransferParty)
+	api.GET("/labels/sources", rh.authMiddleware.Handle, rh.GetLabelSources)
+	api.GET("/labels/sources/:source", rh.authMiddleware.Handle, rh.GetLabelSource)
+	api.PUT("/labels/sources/:source", rh.authMiddleware.Handle, rh.UpdateLabelSource)
+	api.DELETE("/labels/sources/:source", rh.authMiddleware.Handle, rh.DeleteLabelSource)
+	api.POST("/labels/sources/:source/diff", rh.authMiddleware.Handle, rh.DiffLabelImport)
+	api.POST("/labels/sources/:source/import", rh.authMiddleware.Handle, rh.ImportLabels)
 	api.PUT("/settings/organization-name", rh.authMiddleware.Handle, rh.UpdateOrganizationName)
 	api.POST("/settings/name", rh.authMiddleware.Handle, rh.UpdateOrganizationName)
 	api.POST("/settings/total-funds-raised", rh.authMiddleware.Handle, rh.UpdateTotalFundsRaised)
 	api.POST("/settings/total-funds-raised-unit", rh.authMiddleware.Handle, rh.UpdateTotalFundsRaisedUnit)
+	api.POST("/settings/dust-thresholds", rh.authMiddleware.Handle, rh.UpdateDustThresholds)
+	api.POST("/settings/milestone-sign-off", rh.authMiddleware.Handle, rh.UpdateMilestoneSignOffSettings)
 	api.POST("/grants/:id/disbursements", rh.authMiddleware.Handle, rh.CreateDisbursement)
 	api.PUT("/grants/:id/disbursements/:disbursementId", rh.authMiddleware.Handle, rh.UpdateDisbursement)
+	api.POST("/grants/:id/disbursements/:disbursementId/verify", rh.authMiddleware.Handle, rh.VerifyDisbursement)
+	api.POST("/grants/:id/addresses", rh.authMiddleware.Handle, rh.AddGrantAddress)
+	api.DELETE("/grants/:id/addresses/:address", rh.authMiddleware.Handle, rh.DeleteGrantAddress)
 	api.POST("/grants/:id/funds-usage", rh.authMiddleware.Handle, rh.CreateGrantFundsUsage)
 	api.PUT("/grants/:id/funds-usage/:usageId", rh.authMiddleware.Handle, rh.UpdateGrantFundsUsage)
+	api.POST("/grants/:id/documents", rh.authMiddleware.Handle, rh.UploadGrantDocument)
+	api.PUT("/grant-documents/:id/visibility", rh.authMiddleware.Handle, rh.UpdateGrantDocumentVisibility)
+	api.DELETE("/grant-documents/:id", rh.authMiddleware.Handle, rh.DeleteGrantDocument)
 	api.POST("/budgets/allocations", rh.authMiddleware.Handle, rh.CreateMonthlyBudgetAllocation)
 	api.PUT("/budgets/allocations/:id", rh.authMiddleware.Handle, rh.UpdateMonthlyBudgetAllocation)
 	api.DELETE("/budgets/allocations/:id", rh.authMiddleware.Handle, rh.DeleteMonthlyBudgetAllocation)
 	api.POST("/categories", rh.authMiddleware.Handle, rh.CreateCategory)
 	api.PUT("/categories/:name", rh.authMiddleware.Handle, rh.UpdateCategory)
 	api.DELETE("/categories/:name", rh.authMiddleware.Handle, rh.DeleteCategory)
+	api.POST("/offchain-accounts", rh.authMiddleware.Handle, rh.CreateOffchainAccount)
+	api.PUT("/offchain-accounts/:id", rh.authMiddleware.Handle, rh.UpdateOffchainAccount)
+	api.DELETE("/offchain-accounts/:id", rh.authMiddleware.Handle, rh.DeleteOffchainAccount)
+	api.POST("/offchain-accounts/:id/statements", rh.authMiddleware.Handle, rh.CreateOffchainStatement)
+	api.DELETE("/offchain-statements/:id", rh.authMiddleware.Handle, rh.DeleteOffchainStatement)
+	api.POST("/offchain-statements/:id/documents", rh.authMiddleware.Handle, rh.UploadOffchainDocument)
+	api.PUT("/offchain-documents/:id/visibility", rh.authMiddleware.Handle, rh.UpdateOffchainDocumentVisibility)
+	api.DELETE("/offchain-documents/:id", rh.authMiddleware.Handle, rh.DeleteOffchainDocument)
 }
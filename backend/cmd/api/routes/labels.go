@@ -0,0 +1,266 @@
+package routes
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ETHCF/ethutils"
+	"github.com/gin-gonic/gin"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/auth"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/labels"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+// Address label routes
+
+// GET /api/v1/transfer-parties/{address}/labels - Get every label for an address, in priority order
+func (rh *RouteHandler) GetTransferPartyLabels(c *gin.Context) {
+	address, err := ethutils.SanitizeEthAddr(c.Param("address"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid Ethereum address"})
+		return
+	}
+
+	partyLabels, err := rh.labelDB.GetLabelsByAddress(c, address)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to get labels by address")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve labels"})
+		return
+	}
+
+	c.JSON(http.StatusOK, partyLabels)
+}
+
+// GET /api/v1/labels/sources - Get all label sources in priority order
+func (rh *RouteHandler) GetLabelSources(c *gin.Context) {
+	sources, err := rh.labelDB.GetLabelSources(c)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to get label sources")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve label sources"})
+		return
+	}
+
+	c.JSON(http.StatusOK, sources)
+}
+
+// GET /api/v1/labels/sources/{source} - Get a label source and its labels
+func (rh *RouteHandler) GetLabelSource(c *gin.Context) {
+	sourceName := c.Param("source")
+	source, err := rh.labelDB.GetLabelSource(c, sourceName)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Label source not found"})
+			return
+		}
+		rh.log.WithError(err).Error("failed to get label source")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve label source"})
+		return
+	}
+
+	sourceLabels, err := rh.labelDB.GetLabelsBySource(c, sourceName)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to get labels by source")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve labels"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"source": source, "labels": sourceLabels})
+}
+
+// PUT /api/v1/labels/sources/{source} - Update the priority of a label source
+func (rh *RouteHandler) UpdateLabelSource(c *gin.Context) {
+	sourceName := c.Param("source")
+
+	var req types.UpdateLabelSourceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		rh.log.WithError(err).Warn("failed to bind update label source request")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := rh.labelDB.UpdateLabelSourcePriority(c, sourceName, *req.Priority)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Label source not found"})
+			return
+		}
+		rh.log.WithError(err).Error("failed to update label source priority")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to update label source"})
+		return
+	}
+
+	adminAction := types.AdminAction{
+		AdminAddress: auth.MustUserID(c),
+		Action:       "update_label_source",
+		ResourceType: "label_source",
+		ResourceID:   sourceName,
+		Details: types.AdminActionDetails{
+			"priority": *req.Priority,
+		},
+		CreatedAt: time.Now(),
+	}
+
+	err = rh.adminActionDB.RecordAdminAction(c, adminAction)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to record admin action")
+		// Don't fail the request for logging errors
+	}
+
+	source, err := rh.labelDB.GetLabelSource(c, sourceName)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to get updated label source")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve updated label source"})
+		return
+	}
+
+	c.JSON(http.StatusOK, source)
+}
+
+// DELETE /api/v1/labels/sources/{source} - Roll back an import by deleting its source and all of its labels
+func (rh *RouteHandler) DeleteLabelSource(c *gin.Context) {
+	sourceName := c.Param("source")
+	if !labels.IsImportSource(sourceName) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Only imported label sources can be deleted"})
+		return
+	}
+
+	// Keep the labels in the admin action so the import can be restored
+	sourceLabels, err := rh.labelDB.GetLabelsBySource(c, sourceName)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to get labels by source")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve labels"})
+		return
+	}
+
+	err = rh.labelDB.DeleteLabelSource(c, sourceName)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Label source not found"})
+			return
+		}
+		rh.log.WithError(err).Error("failed to delete label source")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete label source"})
+		return
+	}
+
+	adminAction := types.AdminAction{
+		AdminAddress: auth.MustUserID(c),
+		Action:       "delete_label_source",
+		ResourceType: "label_source",
+		ResourceID:   sourceName,
+		Details: types.AdminActionDetails{
+			"labels": sourceLabels,
+		},
+		CreatedAt: time.Now(),
+	}
+
+	err = rh.adminActionDB.RecordAdminAction(c, adminAction)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to record admin action")
+		// Don't fail the request for logging errors
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// POST /api/v1/labels/sources/{source}/diff - Preview the changes an import would make to a label source
+func (rh *RouteHandler) DiffLabelImport(c *gin.Context) {
+	diff, _, ok := rh.parseLabelImport(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, diff)
+}
+
+// POST /api/v1/labels/sources/{source}/import - Replace the labels of an import source with an uploaded CSV or JSON file
+func (rh *RouteHandler) ImportLabels(c *gin.Context) {
+	diff, incoming, ok := rh.parseLabelImport(c)
+	if !ok {
+		return
+	}
+
+	var priority null.Int
+	if priorityStr := c.PostForm("priority"); priorityStr != "" {
+		p, err := strconv.ParseInt(priorityStr, 10, 64)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid priority parameter"})
+			return
+		}
+		priority = null.IntFrom(p)
+	}
+
+	err := rh.labelDB.ReplaceSourceLabels(c, diff.Source, priority, incoming)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to import labels")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to import labels"})
+		return
+	}
+
+	adminAction := types.AdminAction{
+		AdminAddress: auth.MustUserID(c),
+		Action:       "import_labels",
+		ResourceType: "label_source",
+		ResourceID:   diff.Source,
+		Details: types.AdminActionDetails{
+			"added":     len(diff.Added),
+			"changed":   len(diff.Changed),
+			"removed":   len(diff.Removed),
+			"unchanged": diff.Unchanged,
+			"failed":    len(diff.Errors),
+		},
+		CreatedAt: time.Now(),
+	}
+
+	err = rh.adminActionDB.RecordAdminAction(c, adminAction)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to record admin action")
+		// Don't fail the request for logging errors
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+// parseLabelImport reads the uploaded label file and compares it with the labels currently in the source
+func (rh *RouteHandler) parseLabelImport(c *gin.Context) (*types.LabelDiff, []types.TransferPartyLabel, bool) {
+	source, err := labels.ImportSource(c.Param("source"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, nil, false
+	}
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "No file uploaded or file upload error"})
+		return nil, nil, false
+	}
+	defer file.Close()
+
+	format, err := labels.DetectFormat(c.PostForm("format"), header.Filename)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, nil, false
+	}
+
+	incoming, rowErrors, err := labels.Parse(file, format, source)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, nil, false
+	}
+
+	existing, err := rh.labelDB.GetLabelsBySource(c, source)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to get labels by source")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve labels"})
+		return nil, nil, false
+	}
+
+	diff := labels.Diff(source, existing, incoming)
+	diff.Errors = rowErrors
+	return &diff, incoming, true
+}
@@ -0,0 +1,399 @@
+package routes
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/auth"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+// Milestone sign-off routes. A milestone is marked completed with evidence by the grant recipient or an admin, then
+// signed off once enough distinct admins approve it.
+
+// milestoneIDs parses the grant and milestone IDs of a milestone route
+func milestoneIDs(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	grantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid grant ID format"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	milestoneID, err := uuid.Parse(c.Param("milestoneId"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid milestone ID format"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return grantID, milestoneID, true
+}
+
+// abortMilestoneError responds to an error of the sign-off workflow
+func (rh *RouteHandler) abortMilestoneError(c *gin.Context, err error, action string) {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Milestone not found"})
+	case strings.Contains(err.Error(), "already"), strings.Contains(err.Error(), "not completed"):
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		rh.log.WithError(err).Errorf("failed to %s milestone", action)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to " + action + " milestone"})
+	}
+}
+
+// POST /api/v1/grants/{id}/milestones/{milestoneId}/complete - Mark a milestone completed with evidence
+func (rh *RouteHandler) CompleteMilestone(c *gin.Context) {
+	grantID, milestoneID, ok := milestoneIDs(c)
+	if !ok {
+		return
+	}
+
+	var req types.CompleteMilestoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		rh.log.WithError(err).Warn("failed to bind complete milestone request")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	evidence := strings.TrimSpace(req.Evidence)
+	if evidence == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Evidence cannot be empty"})
+		return
+	}
+
+	currentAdminAddr := auth.MustUserID(c)
+	milestone, err := rh.grantDB.CompleteMilestone(c, grantID, milestoneID, evidence, currentAdminAddr)
+	if err != nil {
+		rh.abortMilestoneError(c, err, "complete")
+		return
+	}
+
+	adminAction := types.AdminAction{
+		AdminAddress: currentAdminAddr,
+		Action:       "complete_milestone",
+		ResourceType: "milestone",
+		ResourceID:   milestoneID.String(),
+		Details: types.AdminActionDetails{
+			"grant_id": grantID.String(),
+			"evidence": evidence,
+		},
+		CreatedAt: time.Now(),
+	}
+
+	err = rh.adminActionDB.RecordAdminAction(c, adminAction)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to record admin action")
+		// Don't fail the request for logging errors
+	}
+
+	c.JSON(http.StatusOK, milestone)
+}
+
+// POST /api/v1/grants/{id}/milestones/{milestoneId}/completion-challenge - Get the EIP-712 typed data the recipient signs to complete a milestone
+func (rh *RouteHandler) CreateMilestoneCompletionChallenge(c *gin.Context) {
+	grantID, milestoneID, ok := milestoneIDs(c)
+	if !ok {
+		return
+	}
+
+	var req types.CompleteMilestoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		rh.log.WithError(err).Warn("failed to bind completion challenge request")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	grant, ok := rh.milestoneGrant(c, grantID, milestoneID)
+	if !ok {
+		return
+	}
+
+	typedData, err := auth.GenEIP712MilestoneCompletion(rh.conf, grant.RecipientAddress, grantID, milestoneID, strings.TrimSpace(req.Evidence))
+	if err != nil {
+		rh.log.WithError(err).Error("failed to generate milestone completion challenge")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate challenge"})
+		return
+	}
+
+	c.JSON(http.StatusOK, typedData)
+}
+
+// POST /api/v1/grants/{id}/milestones/{milestoneId}/recipient-completion - Mark a milestone completed with the recipient's signature
+func (rh *RouteHandler) RecipientCompleteMilestone(c *gin.Context) {
+	grantID, milestoneID, ok := milestoneIDs(c)
+	if !ok {
+		return
+	}
+
+	var req types.RecipientCompleteMilestoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		rh.log.WithError(err).Warn("failed to bind recipient completion request")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	evidence := strings.TrimSpace(req.Evidence)
+	if evidence == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Evidence cannot be empty"})
+		return
+	}
+
+	grant, ok := rh.milestoneGrant(c, grantID, milestoneID)
+	if !ok {
+		return
+	}
+
+	typedData, err := auth.GenEIP712MilestoneCompletion(rh.conf, grant.RecipientAddress, grantID, milestoneID, evidence)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to rebuild milestone completion challenge")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify signature"})
+		return
+	}
+
+	_, valid, err := auth.VerifyWalletSignature(c, rh.ethClient, grant.RecipientAddress, common.HexToHash(typedData.Hash), req.Signature)
+	if err != nil {
+		rh.log.WithError(err).WithField("recipient", grant.RecipientAddress).Warn("failed to verify milestone completion signature")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Signature could not be verified"})
+		return
+	}
+	if !valid {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Signature is not from the grant recipient"})
+		return
+	}
+
+	milestone, err := rh.grantDB.CompleteMilestone(c, grantID, milestoneID, evidence, grant.RecipientAddress)
+	if err != nil {
+		rh.abortMilestoneError(c, err, "complete")
+		return
+	}
+
+	c.JSON(http.StatusOK, milestone)
+}
+
+// GET /api/v1/grants/{id}/milestones/{milestoneId}/approval-challenge - Get the EIP-712 typed data the admin signs to approve a milestone
+func (rh *RouteHandler) GetMilestoneApprovalChallenge(c *gin.Context) {
+	grantID, milestoneID, ok := milestoneIDs(c)
+	if !ok {
+		return
+	}
+
+	milestone, err := rh.grantDB.GetMilestone(c, grantID, milestoneID)
+	if err != nil {
+		rh.abortMilestoneError(c, err, "get")
+		return
+	}
+
+	typedData, err := auth.GenEIP712MilestoneApproval(rh.conf, auth.MustUserID(c), *milestone)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to generate milestone approval challenge")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate challenge"})
+		return
+	}
+
+	c.JSON(http.StatusOK, typedData)
+}
+
+// POST /api/v1/grants/{id}/milestones/{milestoneId}/approvals - Approve a completed milestone, optionally with an EIP-712 signature
+func (rh *RouteHandler) ApproveMilestone(c *gin.Context) {
+	grantID, milestoneID, ok := milestoneIDs(c)
+	if !ok {
+		return
+	}
+
+	// The body is optional, approving without a signature needs none
+	var req types.ApproveMilestoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		rh.log.WithError(err).Warn("failed to bind approve milestone request")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	currentAdminAddr := auth.MustUserID(c)
+	approval := types.MilestoneApproval{
+		AdminAddress: currentAdminAddr,
+		CreatedAt:    time.Now(),
+	}
+
+	if req.Signature.Valid {
+		milestone, err := rh.grantDB.GetMilestone(c, grantID, milestoneID)
+		if err != nil {
+			rh.abortMilestoneError(c, err, "approve")
+			return
+		}
+		typedData, err := auth.GenEIP712MilestoneApproval(rh.conf, currentAdminAddr, *milestone)
+		if err != nil {
+			rh.log.WithError(err).Error("failed to rebuild milestone approval challenge")
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify signature"})
+			return
+		}
+		_, valid, err := auth.VerifyWalletSignature(c, rh.ethClient, currentAdminAddr, common.HexToHash(typedData.Hash), req.Signature.String)
+		if err != nil {
+			rh.log.WithError(err).WithField("admin", currentAdminAddr).Warn("failed to verify milestone approval signature")
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Signature could not be verified"})
+			return
+		}
+		if !valid {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Signature is not valid for this admin"})
+			return
+		}
+		approval.Signature = req.Signature
+		approval.Signature.String = strings.ToLower(req.Signature.String)
+	}
+
+	settings, err := rh.settingsDB.GetMilestoneSignOffSettings(c)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to get milestone sign-off settings")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to approve milestone"})
+		return
+	}
+
+	milestone, err := rh.grantDB.ApproveMilestone(c, grantID, milestoneID, approval, settings)
+	if err != nil {
+		rh.abortMilestoneError(c, err, "approve")
+		return
+	}
+
+	adminAction := types.AdminAction{
+		AdminAddress: currentAdminAddr,
+		Action:       "approve_milestone",
+		ResourceType: "milestone",
+		ResourceID:   milestoneID.String(),
+		Details: types.AdminActionDetails{
+			"grant_id":   grantID.String(),
+			"signed":     approval.Signature.Valid,
+			"approvals":  len(milestone.Approvals),
+			"signed_off": milestone.SignedOff,
+		},
+		CreatedAt: time.Now(),
+	}
+
+	err = rh.adminActionDB.RecordAdminAction(c, adminAction)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to record admin action")
+		// Don't fail the request for logging errors
+	}
+
+	c.JSON(http.StatusOK, milestone)
+}
+
+// GET /api/v1/milestones/overdue - Get the milestones past their target date across all grants
+func (rh *RouteHandler) GetOverdueMilestones(c *gin.Context) {
+	milestones, err := rh.grantDB.GetOverdueMilestones(c)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to get overdue milestones")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve overdue milestones"})
+		return
+	}
+
+	c.JSON(http.StatusOK, milestones)
+}
+
+// GET /api/v1/grants/{id}/milestone-events - Get what happened to the milestones of a grant on their own, such as becoming overdue
+func (rh *RouteHandler) GetMilestoneEvents(c *gin.Context) {
+	grantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid grant ID format"})
+		return
+	}
+
+	events, err := rh.grantDB.GetMilestoneEvents(c, grantID)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to get milestone events")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve milestone events"})
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
+
+// GET /api/v1/grants/{id}/disbursement-proposals - Get the payments proposed for signed off milestones of a grant
+func (rh *RouteHandler) GetDisbursementProposals(c *gin.Context) {
+	grantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid grant ID format"})
+		return
+	}
+
+	proposals, err := rh.grantDB.GetDisbursementProposals(c, grantID)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to get disbursement proposals")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve disbursement proposals"})
+		return
+	}
+
+	c.JSON(http.StatusOK, proposals)
+}
+
+// PUT /api/v1/grants/{id}/disbursement-proposals/{proposalId} - Mark a proposed payment disbursed or dismiss it
+func (rh *RouteHandler) ReviewDisbursementProposal(c *gin.Context) {
+	grantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid grant ID format"})
+		return
+	}
+	proposalID, err := uuid.Parse(c.Param("proposalId"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid proposal ID format"})
+		return
+	}
+
+	var req types.ReviewDisbursementProposalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		rh.log.WithError(err).Warn("failed to bind review disbursement proposal request")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	currentAdminAddr := auth.MustUserID(c)
+	err = rh.grantDB.ReviewDisbursementProposal(c, grantID, proposalID, req.Status, currentAdminAddr)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Disbursement proposal not found"})
+		case strings.Contains(err.Error(), "already reviewed"):
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			rh.log.WithError(err).Error("failed to review disbursement proposal")
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to review disbursement proposal"})
+		}
+		return
+	}
+
+	adminAction := types.AdminAction{
+		AdminAddress: currentAdminAddr,
+		Action:       "review_disbursement_proposal",
+		ResourceType: "grant",
+		ResourceID:   grantID.String(),
+		Details: types.AdminActionDetails{
+			"proposal_id": proposalID.String(),
+			"status":      req.Status,
+		},
+		CreatedAt: time.Now(),
+	}
+
+	err = rh.adminActionDB.RecordAdminAction(c, adminAction)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to record admin action")
+		// Don't fail the request for logging errors
+	}
+
+	c.JSON(http.StatusOK, gin.H{"id": proposalID, "status": req.Status})
+}
+
+// milestoneGrant returns the grant of a milestone, responding when either doesn't exist
+func (rh *RouteHandler) milestoneGrant(c *gin.Context, grantID, milestoneID uuid.UUID) (*types.Grant, bool) {
+	if _, err := rh.grantDB.GetMilestone(c, grantID, milestoneID); err != nil {
+		rh.abortMilestoneError(c, err, "get")
+		return nil, false
+	}
+	grant, err := rh.grantDB.GetGrantByID(c, grantID)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to get grant")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve grant"})
+		return nil, false
+	}
+	return grant, true
+}
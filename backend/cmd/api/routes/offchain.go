@@ -0,0 +1,557 @@
+package routes
+
+import (
+	"database/sql"
+	"errors"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/auth"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+// Off-chain account routes, balances are self-reported by admins from bank and custodian statements
+
+// GET /api/v1/offchain-accounts - Get off-chain accounts with their latest statements
+func (rh *RouteHandler) GetOffchainAccounts(c *gin.Context) {
+	accounts, err := rh.offchainDB.GetAccounts(c)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to get off-chain accounts")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve off-chain accounts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, accounts)
+}
+
+// GET /api/v1/offchain-accounts/{id} - Get off-chain account by ID
+func (rh *RouteHandler) GetOffchainAccountByID(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid account ID format"})
+		return
+	}
+
+	account, err := rh.offchainDB.GetAccountByID(c, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Off-chain account not found"})
+			return
+		}
+		rh.log.WithError(err).Error("failed to get off-chain account")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve off-chain account"})
+		return
+	}
+
+	c.JSON(http.StatusOK, account)
+}
+
+// GET /api/v1/offchain-accounts/{id}/statements - Get the balance history of an off-chain account, newest first.
+// The private evidence documents are only listed for admins.
+func (rh *RouteHandler) GetOffchainStatements(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid account ID format"})
+		return
+	}
+
+	statements, err := rh.offchainDB.GetStatements(c, id, auth.IsAdmin(c))
+	if err != nil {
+		rh.log.WithError(err).Error("failed to get off-chain statements")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve off-chain statements"})
+		return
+	}
+
+	for i := range statements {
+		for j := range statements[i].Documents {
+			statements[i].Documents[j].DownloadURL = offchainDocumentDownloadURL(statements[i].Documents[j].ID)
+		}
+	}
+
+	c.JSON(http.StatusOK, statements)
+}
+
+// POST /api/v1/offchain-accounts - Create an off-chain account
+func (rh *RouteHandler) CreateOffchainAccount(c *gin.Context) {
+	var req types.OffchainAccountRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		rh.log.WithError(err).Warn("failed to bind create off-chain account request")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	account := types.OffchainAccount{
+		ID:          uuid.New(),
+		Name:        strings.TrimSpace(req.Name),
+		Institution: strings.TrimSpace(req.Institution),
+		Currency:    strings.ToUpper(strings.TrimSpace(req.Currency)),
+		Notes:       req.Notes,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+
+	if err := rh.offchainDB.CreateAccount(c, account); err != nil {
+		rh.log.WithError(err).Error("failed to create off-chain account")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to create off-chain account"})
+		return
+	}
+
+	adminAction := types.AdminAction{
+		AdminAddress: auth.MustUserID(c),
+		Action:       "create_offchain_account",
+		ResourceType: "offchain_account",
+		ResourceID:   account.ID.String(),
+		Details: types.AdminActionDetails{
+			"name":        account.Name,
+			"institution": account.Institution,
+			"currency":    account.Currency,
+		},
+		CreatedAt: time.Now(),
+	}
+
+	err = rh.adminActionDB.RecordAdminAction(c, adminAction)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to record admin action")
+		// Don't fail the request for logging errors
+	}
+
+	c.JSON(http.StatusCreated, account)
+}
+
+// PUT /api/v1/offchain-accounts/{id} - Update an off-chain account
+func (rh *RouteHandler) UpdateOffchainAccount(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid account ID format"})
+		return
+	}
+
+	var req types.OffchainAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		rh.log.WithError(err).Warn("failed to bind update off-chain account request")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	account := types.OffchainAccount{
+		ID:          id,
+		Name:        strings.TrimSpace(req.Name),
+		Institution: strings.TrimSpace(req.Institution),
+		Currency:    strings.ToUpper(strings.TrimSpace(req.Currency)),
+		Notes:       req.Notes,
+	}
+
+	if err := rh.offchainDB.UpdateAccount(c, account); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Off-chain account not found"})
+			return
+		}
+		rh.log.WithError(err).Error("failed to update off-chain account")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to update off-chain account"})
+		return
+	}
+
+	updatedAccount, err := rh.offchainDB.GetAccountByID(c, id)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to get updated off-chain account")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve updated off-chain account"})
+		return
+	}
+
+	adminAction := types.AdminAction{
+		AdminAddress: auth.MustUserID(c),
+		Action:       "update_offchain_account",
+		ResourceType: "offchain_account",
+		ResourceID:   id.String(),
+		Details: types.AdminActionDetails{
+			"name":        updatedAccount.Name,
+			"institution": updatedAccount.Institution,
+			"currency":    updatedAccount.Currency,
+		},
+		CreatedAt: time.Now(),
+	}
+
+	err = rh.adminActionDB.RecordAdminAction(c, adminAction)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to record admin action")
+		// Don't fail the request for logging errors
+	}
+
+	c.JSON(http.StatusOK, updatedAccount)
+}
+
+// DELETE /api/v1/offchain-accounts/{id} - Delete an off-chain account along with its statements
+func (rh *RouteHandler) DeleteOffchainAccount(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid account ID format"})
+		return
+	}
+
+	if err := rh.offchainDB.DeleteAccount(c, id); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Off-chain account not found"})
+			return
+		}
+		rh.log.WithError(err).Error("failed to delete off-chain account")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete off-chain account"})
+		return
+	}
+
+	adminAction := types.AdminAction{
+		AdminAddress: auth.MustUserID(c),
+		Action:       "delete_offchain_account",
+		ResourceType: "offchain_account",
+		ResourceID:   id.String(),
+		Details:      types.AdminActionDetails{},
+		CreatedAt:    time.Now(),
+	}
+
+	err = rh.adminActionDB.RecordAdminAction(c, adminAction)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to record admin action")
+		// Don't fail the request for logging errors
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// POST /api/v1/offchain-accounts/{id}/statements - Record the balance of an off-chain account on a date
+func (rh *RouteHandler) CreateOffchainStatement(c *gin.Context) {
+	accountID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid account ID format"})
+		return
+	}
+
+	account, err := rh.offchainDB.GetAccountByID(c, accountID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Off-chain account not found"})
+			return
+		}
+		rh.log.WithError(err).Error("failed to get off-chain account")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify off-chain account exists"})
+		return
+	}
+
+	var req types.CreateOffchainStatementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		rh.log.WithError(err).Warn("failed to bind create off-chain statement request")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	balance, ok := new(big.Float).SetPrec(256).SetString(strings.TrimSpace(req.Balance))
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid balance"})
+		return
+	}
+	// Without exchange rates the USD value has to be entered, unless the account is held in USD
+	if !req.UsdValue.Valid {
+		if account.Currency != "USD" {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "usdValue is required for accounts not held in USD"})
+			return
+		}
+		usdValue, _ := balance.Float64()
+		req.UsdValue = null.FloatFrom(usdValue)
+	}
+
+	statement := types.OffchainStatement{
+		ID:            uuid.New(),
+		AccountID:     accountID,
+		StatementDate: req.StatementDate.UTC().Truncate(24 * time.Hour),
+		Balance:       balance.Text('f', -1),
+		UsdValue:      req.UsdValue.Float64,
+		Notes:         req.Notes,
+		CreatedBy:     auth.MustUserID(c),
+		CreatedAt:     time.Now(),
+		SelfReported:  true,
+	}
+
+	if err := rh.offchainDB.CreateStatement(c, statement); err != nil {
+		if strings.Contains(err.Error(), "duplicate key value") {
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "A statement already exists for this date"})
+			return
+		}
+		rh.log.WithError(err).Error("failed to create off-chain statement")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to create off-chain statement"})
+		return
+	}
+
+	adminAction := types.AdminAction{
+		AdminAddress: statement.CreatedBy,
+		Action:       "create_offchain_statement",
+		ResourceType: "offchain_statement",
+		ResourceID:   statement.ID.String(),
+		Details: types.AdminActionDetails{
+			"account_id":     accountID.String(),
+			"statement_date": statement.StatementDate.Format("2006-01-02"),
+			"balance":        statement.Balance,
+			"usd_value":      statement.UsdValue,
+		},
+		CreatedAt: time.Now(),
+	}
+
+	err = rh.adminActionDB.RecordAdminAction(c, adminAction)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to record admin action")
+		// Don't fail the request for logging errors
+	}
+
+	c.JSON(http.StatusCreated, statement)
+}
+
+// DELETE /api/v1/offchain-statements/{id} - Delete an off-chain statement along with its documents
+func (rh *RouteHandler) DeleteOffchainStatement(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid statement ID format"})
+		return
+	}
+
+	if err := rh.offchainDB.DeleteStatement(c, id); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Off-chain statement not found"})
+			return
+		}
+		rh.log.WithError(err).Error("failed to delete off-chain statement")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete off-chain statement"})
+		return
+	}
+
+	adminAction := types.AdminAction{
+		AdminAddress: auth.MustUserID(c),
+		Action:       "delete_offchain_statement",
+		ResourceType: "offchain_statement",
+		ResourceID:   id.String(),
+		Details:      types.AdminActionDetails{},
+		CreatedAt:    time.Now(),
+	}
+
+	err = rh.adminActionDB.RecordAdminAction(c, adminAction)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to record admin action")
+		// Don't fail the request for logging errors
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// POST /api/v1/offchain-statements/{id}/documents - Attach an evidence document to an off-chain statement
+func (rh *RouteHandler) UploadOffchainDocument(c *gin.Context) {
+	statementID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid statement ID format"})
+		return
+	}
+
+	_, err = rh.offchainDB.GetStatementByID(c, statementID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Off-chain statement not found"})
+			return
+		}
+		rh.log.WithError(err).Error("failed to get off-chain statement")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify off-chain statement exists"})
+		return
+	}
+
+	var req types.UploadOffchainDocumentRequest
+	if err := c.ShouldBind(&req); err != nil {
+		rh.log.WithError(err).Warn("failed to bind upload off-chain document request")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "No file uploaded or file upload error"})
+		return
+	}
+	defer file.Close()
+
+	storageID, err := rh.storage.Put(c, file)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to store off-chain document")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload document"})
+		return
+	}
+
+	document := types.OffchainDocument{
+		ID:          uuid.New(),
+		StatementID: statementID,
+		Name:        strings.TrimSpace(req.Name),
+		FileName:    null.StringFrom(header.Filename),
+		FileSize:    header.Size,
+		MimeType:    null.StringFrom(header.Header.Get("Content-Type")),
+		StorageID:   storageID,
+		Visibility:  req.Visibility,
+		CreatedAt:   time.Now(),
+	}
+	// Statements show account numbers and the like, so their evidence is private unless asked otherwise
+	if document.Visibility == "" {
+		document.Visibility = types.DocumentPrivate
+	}
+
+	if err := rh.offchainDB.CreateDocument(c, document); err != nil {
+		rh.log.WithError(err).Error("failed to create off-chain document record")
+		if err := rh.storage.Delete(c, storageID); err != nil {
+			rh.log.WithError(err).Error("failed to delete stored off-chain document")
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload document"})
+		return
+	}
+	document.DownloadURL = offchainDocumentDownloadURL(document.ID)
+
+	adminAction := types.AdminAction{
+		AdminAddress: auth.MustUserID(c),
+		Action:       "upload_offchain_document",
+		ResourceType: "offchain_document",
+		ResourceID:   document.ID.String(),
+		Details: types.AdminActionDetails{
+			"statement_id":  statementID.String(),
+			"document_name": document.Name,
+			"storage_id":    document.StorageID,
+			"visibility":    document.Visibility,
+		},
+		CreatedAt: time.Now(),
+	}
+
+	err = rh.adminActionDB.RecordAdminAction(c, adminAction)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to record admin action")
+		// Don't fail the request for logging errors
+	}
+
+	c.JSON(http.StatusCreated, document)
+}
+
+// DELETE /api/v1/offchain-documents/{id} - Delete an off-chain statement document
+func (rh *RouteHandler) DeleteOffchainDocument(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid document ID format"})
+		return
+	}
+
+	document, err := rh.offchainDB.DeleteDocument(c, id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Off-chain document not found"})
+			return
+		}
+		rh.log.WithError(err).Error("failed to delete off-chain document")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete document"})
+		return
+	}
+
+	// Documents uploaded before files were stored have nothing to remove
+	if err := rh.storage.Delete(c, document.StorageID); err != nil {
+		rh.log.WithError(err).WithField("storageID", document.StorageID).Error("failed to delete stored off-chain document")
+	}
+
+	adminAction := types.AdminAction{
+		AdminAddress: auth.MustUserID(c),
+		Action:       "delete_offchain_document",
+		ResourceType: "offchain_document",
+		ResourceID:   id.String(),
+		Details: types.AdminActionDetails{
+			"statement_id":  document.StatementID.String(),
+			"document_name": document.Name,
+			"storage_id":    document.StorageID,
+		},
+		CreatedAt: time.Now(),
+	}
+
+	err = rh.adminActionDB.RecordAdminAction(c, adminAction)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to record admin action")
+		// Don't fail the request for logging errors
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// PUT /api/v1/offchain-documents/{id}/visibility - Make an off-chain statement document public or private
+func (rh *RouteHandler) UpdateOffchainDocumentVisibility(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid document ID format"})
+		return
+	}
+
+	var req types.UpdateDocumentVisibilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		rh.log.WithError(err).Warn("failed to bind update document visibility request")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := rh.offchainDB.SetDocumentVisibility(c, id, req.Visibility); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Off-chain document not found"})
+			return
+		}
+		rh.log.WithError(err).Error("failed to update off-chain document visibility")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to update document"})
+		return
+	}
+
+	adminAction := types.AdminAction{
+		AdminAddress: auth.MustUserID(c),
+		Action:       "update_offchain_document_visibility",
+		ResourceType: "offchain_document",
+		ResourceID:   id.String(),
+		Details: types.AdminActionDetails{
+			"visibility": req.Visibility,
+		},
+		CreatedAt: time.Now(),
+	}
+
+	err = rh.adminActionDB.RecordAdminAction(c, adminAction)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to record admin action")
+		// Don't fail the request for logging errors
+	}
+
+	c.JSON(http.StatusOK, gin.H{"id": id, "visibility": req.Visibility})
+}
+
+func offchainDocumentDownloadURL(id uuid.UUID) string {
+	return "/api/v1/offchain-documents/" + id.String() + "/download"
+}
+
+// GET /api/v1/offchain-documents/{id}/download - Download an off-chain statement document
+func (rh *RouteHandler) DownloadOffchainDocument(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid document ID format"})
+		return
+	}
+
+	document, err := rh.offchainDB.GetDocument(c, id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Off-chain document not found"})
+			return
+		}
+		rh.log.WithError(err).Error("failed to get off-chain document")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve document"})
+		return
+	}
+	// Private documents aren't acknowledged to exist
+	if document.Visibility != types.DocumentPublic && !auth.IsAdmin(c) {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Off-chain document not found"})
+		return
+	}
+
+	rh.serveStoredFile(c, document.StorageID, document.Name, document.FileName, document.MimeType, document.FileSize)
+}
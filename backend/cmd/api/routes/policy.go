@@ -0,0 +1,238 @@
+package routes
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ETHCF/ethutils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/auth"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/policy"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+// Treasury policy routes, the rules are evaluated by the tracker after every balance refresh
+
+// GET /api/v1/policy-rules - Get the treasury policy rules with their latest evaluation
+func (rh *RouteHandler) GetPolicyRules(c *gin.Context) {
+	rules, err := rh.policyDB.GetRules(c)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to get policy rules")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve policy rules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// GET /api/v1/policy-violations - Get policy violations, newest first. open=true only returns unresolved violations
+func (rh *RouteHandler) GetPolicyViolations(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 1000 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid limit parameter (1-1000)"})
+		return
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid offset parameter"})
+		return
+	}
+
+	violations, err := rh.policyDB.GetViolations(c, c.Query("open") == "true", limit, offset)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to get policy violations")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve policy violations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, violations)
+}
+
+// policyRuleFromRequest validates a policy rule request, sanitizing the asset address
+func policyRuleFromRequest(req types.PolicyRuleRequest) (types.PolicyRule, error) {
+	if err := policy.Validate(&req); err != nil {
+		return types.PolicyRule{}, err
+	}
+	rule := types.PolicyRule{
+		Name:        req.Name,
+		Description: req.Description,
+		Metric:      req.Metric,
+		Comparison:  req.Comparison,
+		Threshold:   req.Threshold,
+		AssetClass:  req.AssetClass,
+		WindowDays:  req.WindowDays,
+		Enabled:     !req.Enabled.Valid || req.Enabled.Bool,
+	}
+	if req.Asset.Valid {
+		address, err := ethutils.SanitizeEthAddr(req.Asset.String)
+		if err != nil {
+			return rule, errors.New("Invalid asset address")
+		}
+		rule.Asset = null.StringFrom(address)
+	}
+	return rule, nil
+}
+
+// POST /api/v1/policy-rules - Create a treasury policy rule
+func (rh *RouteHandler) CreatePolicyRule(c *gin.Context) {
+	var req types.PolicyRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		rh.log.WithError(err).Warn("failed to bind create policy rule request")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule, err := policyRuleFromRequest(req)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	rule.ID = uuid.New()
+	rule.CreatedBy = auth.MustUserID(c)
+	rule.CreatedAt = time.Now()
+	rule.UpdatedAt = rule.CreatedAt
+
+	if err := rh.policyDB.CreateRule(c, rule); err != nil {
+		rh.log.WithError(err).Error("failed to create policy rule")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to create policy rule"})
+		return
+	}
+
+	adminAction := types.AdminAction{
+		AdminAddress: auth.MustUserID(c),
+		Action:       "create_policy_rule",
+		ResourceType: "policy_rule",
+		ResourceID:   rule.ID.String(),
+		Details: types.AdminActionDetails{
+			"name":       rule.Name,
+			"metric":     rule.Metric,
+			"comparison": rule.Comparison,
+			"threshold":  rule.Threshold,
+		},
+		CreatedAt: time.Now(),
+	}
+
+	err = rh.adminActionDB.RecordAdminAction(c, adminAction)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to record admin action")
+		// Don't fail the request for logging errors
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// PUT /api/v1/policy-rules/:id - Update a treasury policy rule, disabling it resolves its open violation
+func (rh *RouteHandler) UpdatePolicyRule(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid rule ID format"})
+		return
+	}
+
+	var req types.PolicyRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		rh.log.WithError(err).Warn("failed to bind update policy rule request")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	existing, err := rh.policyDB.GetRuleByID(c, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Policy rule not found"})
+			return
+		}
+		rh.log.WithError(err).Error("failed to get policy rule")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve policy rule"})
+		return
+	}
+
+	rule, err := policyRuleFromRequest(req)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	rule.ID = id
+	// An omitted enabled flag keeps the rule as it was
+	if !req.Enabled.Valid {
+		rule.Enabled = existing.Enabled
+	}
+
+	if err := rh.policyDB.UpdateRule(c, rule); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Policy rule not found"})
+			return
+		}
+		rh.log.WithError(err).Error("failed to update policy rule")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to update policy rule"})
+		return
+	}
+
+	adminAction := types.AdminAction{
+		AdminAddress: auth.MustUserID(c),
+		Action:       "update_policy_rule",
+		ResourceType: "policy_rule",
+		ResourceID:   id.String(),
+		Details: types.AdminActionDetails{
+			"name":              rule.Name,
+			"metric":            rule.Metric,
+			"comparison":        rule.Comparison,
+			"threshold":         rule.Threshold,
+			"previousThreshold": existing.Threshold,
+			"enabled":           rule.Enabled,
+		},
+		CreatedAt: time.Now(),
+	}
+
+	err = rh.adminActionDB.RecordAdminAction(c, adminAction)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to record admin action")
+		// Don't fail the request for logging errors
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Policy rule updated successfully"})
+}
+
+// DELETE /api/v1/policy-rules/:id - Delete a treasury policy rule, its violations are kept
+func (rh *RouteHandler) DeletePolicyRule(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid rule ID format"})
+		return
+	}
+
+	if err := rh.policyDB.DeleteRule(c, id); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Policy rule not found"})
+			return
+		}
+		rh.log.WithError(err).Error("failed to delete policy rule")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete policy rule"})
+		return
+	}
+
+	adminAction := types.AdminAction{
+		AdminAddress: auth.MustUserID(c),
+		Action:       "delete_policy_rule",
+		ResourceType: "policy_rule",
+		ResourceID:   id.String(),
+		Details:      types.AdminActionDetails{},
+		CreatedAt:    time.Now(),
+	}
+
+	err = rh.adminActionDB.RecordAdminAction(c, adminAction)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to record admin action")
+		// Don't fail the request for logging errors
+	}
+
+	c.Status(http.StatusNoContent)
+}
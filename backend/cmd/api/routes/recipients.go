@@ -0,0 +1,359 @@
+package routes
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ETHCF/ethutils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/auth"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+// Recipient portal routes. A grant recipient logs in with the recipient address of their grants and submits updates
+// on them, which take effect once an admin approves them.
+
+// recipientGrant returns the grant of a recipient route, responding when it doesn't exist or isn't the user's
+func (rh *RouteHandler) recipientGrant(c *gin.Context) (*types.Grant, bool) {
+	grantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid grant ID format"})
+		return nil, false
+	}
+
+	exists, err := rh.grantDB.GrantExists(c, grantID)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to check if grant exists")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify grant exists"})
+		return nil, false
+	}
+	if !exists {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Grant not found"})
+		return nil, false
+	}
+
+	grant, err := rh.grantDB.GetGrantByID(c, grantID)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to get grant by ID")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve grant"})
+		return nil, false
+	}
+	if !strings.EqualFold(grant.RecipientAddress, auth.MustUserID(c)) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Not the recipient of this grant"})
+		return nil, false
+	}
+	return grant, true
+}
+
+// createSubmission stores a submission of the user and responds with it
+func (rh *RouteHandler) createSubmission(c *gin.Context, submission types.CreateGrantSubmission) {
+	submission.RecipientAddress = auth.MustUserID(c)
+	created, err := rh.submissionDB.CreateSubmission(c, submission)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to create grant submission")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to create submission"})
+		return
+	}
+	c.JSON(http.StatusCreated, created)
+}
+
+// GET /api/v1/recipient/grants - Get the grants of the logged in recipient
+func (rh *RouteHandler) GetRecipientGrants(c *gin.Context) {
+	listing, err := rh.grantDB.GetGrants(c, types.GrantFilter{
+		RecipientAddress: strings.ToLower(auth.MustUserID(c)),
+		Descending:       true,
+		Limit:            1000,
+	})
+	if err != nil {
+		rh.log.WithError(err).Error("failed to get recipient grants")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve grants"})
+		return
+	}
+
+	c.JSON(http.StatusOK, listing.Data)
+}
+
+// POST /api/v1/recipient/grants/{id}/milestones/{milestoneId}/progress - Submit evidence that a milestone is completed
+func (rh *RouteHandler) SubmitMilestoneProgress(c *gin.Context) {
+	grant, ok := rh.recipientGrant(c)
+	if !ok {
+		return
+	}
+	milestoneID, err := uuid.Parse(c.Param("milestoneId"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid milestone ID format"})
+		return
+	}
+
+	var req types.SubmitMilestoneProgressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		rh.log.WithError(err).Warn("failed to bind submit milestone progress request")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	evidence := strings.TrimSpace(req.Evidence)
+	if evidence == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Evidence cannot be empty"})
+		return
+	}
+
+	milestone, err := rh.grantDB.GetMilestone(c, grant.ID, milestoneID)
+	if err != nil {
+		rh.abortMilestoneError(c, err, "get")
+		return
+	}
+	if milestone.Status != types.MilestoneStatusPending {
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "milestone is already completed"})
+		return
+	}
+
+	rh.createSubmission(c, types.CreateGrantSubmission{
+		GrantID:     grant.ID,
+		MilestoneID: uuid.NullUUID{UUID: milestoneID, Valid: true},
+		Kind:        types.GrantSubmissionMilestoneProgress,
+		Payload:     types.GrantSubmissionPayload{Evidence: evidence, Note: strings.TrimSpace(req.Note)},
+	})
+}
+
+// POST /api/v1/recipient/grants/{id}/funds-usage - Submit how some of the grant was spent
+func (rh *RouteHandler) SubmitFundsUsage(c *gin.Context) {
+	grant, ok := rh.recipientGrant(c)
+	if !ok {
+		return
+	}
+
+	var req types.SubmitFundsUsageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		rh.log.WithError(err).Warn("failed to bind submit funds usage request")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if price, err := strconv.ParseFloat(req.Price, 64); err != nil || price < 0 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid price"})
+		return
+	}
+	if req.TxHash.Valid {
+		var err error
+		req.TxHash.String, err = ethutils.SanitizeEthHash(req.TxHash.String)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid transaction hash"})
+			return
+		}
+	}
+
+	rh.createSubmission(c, types.CreateGrantSubmission{
+		GrantID: grant.ID,
+		Kind:    types.GrantSubmissionFundsUsage,
+		Payload: types.GrantSubmissionPayload{
+			FundsUsage: &types.CreateFundsUsage{
+				GrantID:  grant.ID,
+				Item:     req.Item,
+				Quantity: req.Quantity,
+				Price:    req.Price,
+				Purpose:  req.Purpose,
+				Category: req.Category,
+				Date:     req.Date,
+				TxHash:   req.TxHash,
+			},
+			Note: strings.TrimSpace(req.Note),
+		},
+	})
+}
+
+// POST /api/v1/recipient/grants/{id}/documents - Submit a link to a document about the grant
+func (rh *RouteHandler) SubmitDocument(c *gin.Context) {
+	grant, ok := rh.recipientGrant(c)
+	if !ok {
+		return
+	}
+
+	var req types.SubmitDocumentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		rh.log.WithError(err).Warn("failed to bind submit document request")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rh.createSubmission(c, types.CreateGrantSubmission{
+		GrantID: grant.ID,
+		Kind:    types.GrantSubmissionDocument,
+		Payload: types.GrantSubmissionPayload{
+			Title: strings.TrimSpace(req.Title),
+			URL:   req.URL,
+			Note:  strings.TrimSpace(req.Note),
+		},
+	})
+}
+
+// GET /api/v1/recipient/submissions - Get the submissions of the logged in recipient
+func (rh *RouteHandler) GetRecipientSubmissions(c *gin.Context) {
+	filter, ok := submissionFilter(c)
+	if !ok {
+		return
+	}
+	filter.RecipientAddress = auth.MustUserID(c)
+
+	submissions, err := rh.submissionDB.GetSubmissions(c, filter)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to get recipient submissions")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve submissions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, submissions)
+}
+
+// GET /api/v1/grants/{id}/submissions - Get the approved submissions of a grant
+func (rh *RouteHandler) GetGrantSubmissions(c *gin.Context) {
+	grantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid grant ID format"})
+		return
+	}
+	filter, ok := submissionFilter(c)
+	if !ok {
+		return
+	}
+	filter.GrantID = uuid.NullUUID{UUID: grantID, Valid: true}
+	filter.Status = types.GrantSubmissionApproved // Pending and rejected ones aren't public
+
+	submissions, err := rh.submissionDB.GetSubmissions(c, filter)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to get grant submissions")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve submissions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, submissions)
+}
+
+// GET /api/v1/grant-submissions - Get the submissions of all grants, for review
+func (rh *RouteHandler) GetSubmissionsForReview(c *gin.Context) {
+	filter, ok := submissionFilter(c)
+	if !ok {
+		return
+	}
+	if grantID := c.Query("grantId"); grantID != "" {
+		id, err := uuid.Parse(grantID)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid grant ID format"})
+			return
+		}
+		filter.GrantID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+
+	submissions, err := rh.submissionDB.GetSubmissions(c, filter)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to get grant submissions")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve submissions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, submissions)
+}
+
+// GET /api/v1/grant-submissions/{id} - Get a submission by ID
+func (rh *RouteHandler) GetSubmissionByID(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid submission ID format"})
+		return
+	}
+
+	submission, err := rh.submissionDB.GetSubmission(c, id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Submission not found"})
+			return
+		}
+		rh.log.WithError(err).Error("failed to get grant submission")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve submission"})
+		return
+	}
+
+	c.JSON(http.StatusOK, submission)
+}
+
+// PUT /api/v1/grant-submissions/{id} - Approve or reject a pending submission
+func (rh *RouteHandler) ReviewGrantSubmission(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid submission ID format"})
+		return
+	}
+
+	var req types.ReviewGrantSubmissionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		rh.log.WithError(err).Warn("failed to bind review grant submission request")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	currentAdminAddr := auth.MustUserID(c)
+	submission, err := rh.submissionDB.ReviewSubmission(c, id, req.Status, req.Note, currentAdminAddr)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "submission not found"):
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Submission not found"})
+		case strings.Contains(err.Error(), "not found"):
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Milestone not found"})
+		case strings.Contains(err.Error(), "already"):
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			rh.log.WithError(err).Error("failed to review grant submission")
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to review submission"})
+		}
+		return
+	}
+
+	adminAction := types.AdminAction{
+		AdminAddress: currentAdminAddr,
+		Action:       "review_grant_submission",
+		ResourceType: "grant",
+		ResourceID:   submission.GrantID.String(),
+		Details: types.AdminActionDetails{
+			"submission_id": id.String(),
+			"kind":          submission.Kind,
+			"status":        req.Status,
+			"recipient":     submission.RecipientAddress,
+		},
+		CreatedAt: time.Now(),
+	}
+
+	err = rh.adminActionDB.RecordAdminAction(c, adminAction)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to record admin action")
+		// Don't fail the request for logging errors
+	}
+
+	c.JSON(http.StatusOK, submission)
+}
+
+// submissionFilter parses the status and pagination query parameters of the submission listings
+func submissionFilter(c *gin.Context) (types.GrantSubmissionFilter, bool) {
+	status := types.GrantSubmissionStatus(c.Query("status"))
+	switch status {
+	case "", types.GrantSubmissionPending, types.GrantSubmissionApproved, types.GrantSubmissionRejected:
+	default:
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid status parameter (pending, approved or rejected)"})
+		return types.GrantSubmissionFilter{}, false
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 1000 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid limit parameter (1-1000)"})
+		return types.GrantSubmissionFilter{}, false
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid offset parameter"})
+		return types.GrantSubmissionFilter{}, false
+	}
+
+	return types.GrantSubmissionFilter{Status: status, Limit: limit, Offset: offset}, true
+}
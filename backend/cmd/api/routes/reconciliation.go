@@ -0,0 +1,146 @@
+package routes
+
+import (
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ETHCF/ethutils"
+	"github.com/gin-gonic/gin"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/auth"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/reconcile"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+// Reconciliation routes, the tracker compares the ingested transfers with on-chain balances
+
+// GET /api/v1/reconciliation - Get the latest reconciliation report, showing which wallets and assets are fully reconciled
+func (rh *RouteHandler) GetReconciliation(c *gin.Context) {
+	results, err := rh.reconDB.GetResults(c)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to get reconciliation results")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve reconciliation report"})
+		return
+	}
+
+	c.JSON(http.StatusOK, reconcile.BuildReport(results))
+}
+
+// GET /api/v1/reconciliation/starting-balances - Get the starting balances transfers are reconciled against
+func (rh *RouteHandler) GetStartingBalances(c *gin.Context) {
+	balances, err := rh.reconDB.GetStartingBalances(c)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to get starting balances")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve starting balances"})
+		return
+	}
+
+	c.JSON(http.StatusOK, balances)
+}
+
+// PUT /api/v1/reconciliation/starting-balances - Set what a wallet held of an asset before its first ingested transfer
+func (rh *RouteHandler) SetStartingBalance(c *gin.Context) {
+	var req types.SetStartingBalanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		rh.log.WithError(err).Warn("failed to bind set starting balance request")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	wallet, err := ethutils.SanitizeEthAddr(req.Wallet)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid wallet address"})
+		return
+	}
+	asset, err := ethutils.SanitizeEthAddr(req.Asset)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid asset address"})
+		return
+	}
+	amount, ok := new(big.Int).SetString(req.Amount, 10)
+	if !ok || amount.Sign() < 0 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Amount must be a non-negative integer in raw units"})
+		return
+	}
+
+	balance := types.StartingBalance{
+		ChainID:     1,
+		Wallet:      wallet,
+		Asset:       asset,
+		Amount:      amount.String(),
+		BlockNumber: req.BlockNumber,
+		SetBy:       auth.MustUserID(c),
+	}
+	if err := rh.reconDB.SetStartingBalance(c, balance); err != nil {
+		if strings.Contains(err.Error(), "violates foreign key constraint") {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Wallet must be a treasury wallet"})
+			return
+		}
+		rh.log.WithError(err).Error("failed to set starting balance")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to set starting balance"})
+		return
+	}
+
+	adminAction := types.AdminAction{
+		AdminAddress: auth.MustUserID(c),
+		Action:       "set_starting_balance",
+		ResourceType: "starting_balance",
+		ResourceID:   wallet + ":" + asset,
+		Details: types.AdminActionDetails{
+			"amount":      balance.Amount,
+			"blockNumber": balance.BlockNumber,
+		},
+		CreatedAt: time.Now(),
+	}
+
+	err = rh.adminActionDB.RecordAdminAction(c, adminAction)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to record admin action")
+		// Don't fail the request for logging errors
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Starting balance set successfully"})
+}
+
+// DELETE /api/v1/reconciliation/starting-balances/:wallet/:asset - Remove a starting balance
+func (rh *RouteHandler) DeleteStartingBalance(c *gin.Context) {
+	wallet, err := ethutils.SanitizeEthAddr(c.Param("wallet"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid wallet address"})
+		return
+	}
+	asset, err := ethutils.SanitizeEthAddr(c.Param("asset"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid asset address"})
+		return
+	}
+
+	if err := rh.reconDB.DeleteStartingBalance(c, 1, wallet, asset); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Starting balance not found"})
+			return
+		}
+		rh.log.WithError(err).Error("failed to delete starting balance")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete starting balance"})
+		return
+	}
+
+	adminAction := types.AdminAction{
+		AdminAddress: auth.MustUserID(c),
+		Action:       "delete_starting_balance",
+		ResourceType: "starting_balance",
+		ResourceID:   wallet + ":" + asset,
+		Details:      types.AdminActionDetails{},
+		CreatedAt:    time.Now(),
+	}
+
+	err = rh.adminActionDB.RecordAdminAction(c, adminAction)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to record admin action")
+		// Don't fail the request for logging errors
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -201,3 +201,97 @@ func (rh *RouteHandler) UpdateTotalFundsRaisedUnit(c *gin.Context) {
 
 	c.JSON(http.StatusOK, TotalFundsRaisedUnitResponse{Unit: req.Unit})
 }
+
+// GET /api/v1/settings/dust-thresholds - Get the USD values below which balances and transfers are hidden
+func (rh *RouteHandler) GetDustThresholds(c *gin.Context) {
+	thresholds, err := rh.settingsDB.GetDustThresholds(c)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to get dust thresholds")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve dust thresholds"})
+		return
+	}
+
+	c.JSON(http.StatusOK, thresholds)
+}
+
+// POST /api/v1/settings/dust-thresholds - Update the dust thresholds
+func (rh *RouteHandler) UpdateDustThresholds(c *gin.Context) {
+	var req types.DustThresholds
+	if err := c.ShouldBindJSON(&req); err != nil {
+		rh.log.WithError(err).Warn("failed to bind dust thresholds update request")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := rh.settingsDB.SetDustThresholds(c, req); err != nil {
+		rh.log.WithError(err).Error("failed to update dust thresholds")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to update dust thresholds"})
+		return
+	}
+
+	adminAction := types.AdminAction{
+		AdminAddress: auth.MustUserID(c),
+		Action:       "update_dust_thresholds",
+		ResourceType: "setting",
+		ResourceID:   "dust_thresholds",
+		Details: types.AdminActionDetails{
+			"balance_usd":  req.BalanceUSD,
+			"transfer_usd": req.TransferUSD,
+		},
+		CreatedAt: time.Now(),
+	}
+
+	err := rh.adminActionDB.RecordAdminAction(c, adminAction)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to record admin action")
+	}
+
+	c.JSON(http.StatusOK, req)
+}
+
+// GET /api/v1/settings/milestone-sign-off - Get how many admin approvals sign off a milestone
+func (rh *RouteHandler) GetMilestoneSignOffSettings(c *gin.Context) {
+	settings, err := rh.settingsDB.GetMilestoneSignOffSettings(c)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to get milestone sign-off settings")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve milestone sign-off settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// POST /api/v1/settings/milestone-sign-off - Update the milestone sign-off settings
+func (rh *RouteHandler) UpdateMilestoneSignOffSettings(c *gin.Context) {
+	var req types.MilestoneSignOffSettings
+	if err := c.ShouldBindJSON(&req); err != nil {
+		rh.log.WithError(err).Warn("failed to bind milestone sign-off settings update request")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := rh.settingsDB.SetMilestoneSignOffSettings(c, req); err != nil {
+		rh.log.WithError(err).Error("failed to update milestone sign-off settings")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to update milestone sign-off settings"})
+		return
+	}
+
+	adminAction := types.AdminAction{
+		AdminAddress: auth.MustUserID(c),
+		Action:       "update_milestone_sign_off_settings",
+		ResourceType: "setting",
+		ResourceID:   "milestone_sign_off",
+		Details: types.AdminActionDetails{
+			"required_approvals":   req.RequiredApprovals,
+			"propose_disbursement": req.ProposeDisbursement,
+		},
+		CreatedAt: time.Now(),
+	}
+
+	err := rh.adminActionDB.RecordAdminAction(c, adminAction)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to record admin action")
+	}
+
+	c.JSON(http.StatusOK, req)
+}
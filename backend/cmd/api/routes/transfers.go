@@ -4,16 +4,20 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ETHCF/ethutils"
 	"github.com/gin-gonic/gin"
 
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/auth"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/explorer"
 	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
 )
 
 // Transfer management routes
 
-// GET /api/v1/transfers - Get transfers with pagination
+// GET /api/v1/transfers - Get transfers with pagination, spam and dust are left out unless includeFiltered=true.
+// Passing group=<id> only returns transfers to or from the wallets of that wallet group.
 func (rh *RouteHandler) GetTransfers(c *gin.Context) {
 	// Parse pagination parameters
 	limitStr := c.DefaultQuery("limit", "50")
@@ -31,7 +35,14 @@ func (rh *RouteHandler) GetTransfers(c *gin.Context) {
 		return
 	}
 
-	transfers, err := rh.treasuryDB.GetTransfers(c, limit, offset)
+	includeFiltered := c.Query("includeFiltered") == "true"
+
+	group, ok := parseGroupQuery(c)
+	if !ok {
+		return
+	}
+
+	transfers, err := rh.treasuryDB.GetTransfers(c, limit, offset, includeFiltered, group)
 	if err != nil {
 		rh.log.WithError(err).Error("failed to get transfers")
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve transfers"})
@@ -115,6 +126,63 @@ func (rh *RouteHandler) CreateTransfer(c *gin.Context) {
 	c.JSON(http.StatusCreated, req)
 }
 
+// POST /api/v1/transfers/import - Import transfer history from an Etherscan or Blockscout CSV export
+func (rh *RouteHandler) ImportTransfers(c *gin.Context) {
+	kind, err := explorer.ParseExportKind(c.PostForm("kind"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	chainID, err := strconv.ParseInt(c.DefaultPostForm("chainId", "1"), 10, 64)
+	if err != nil || chainID < 1 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid chainId parameter"})
+		return
+	}
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "No file uploaded or file upload error"})
+		return
+	}
+	defer file.Close()
+
+	report, err := rh.importer.ImportTransfers(c, file, explorer.ImportOptions{ChainID: chainID, Kind: kind})
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid export") {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		rh.log.WithError(err).Error("failed to import transfers")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to import transfers"})
+		return
+	}
+
+	adminAction := types.AdminAction{
+		AdminAddress: auth.MustUserID(c),
+		Action:       "import_transfers",
+		ResourceType: "transfer",
+		ResourceID:   header.Filename,
+		Details: types.AdminActionDetails{
+			"chain_id": chainID,
+			"kind":     string(kind),
+			"rows":     report.Rows,
+			"inserted": report.Inserted,
+			"skipped":  report.Skipped,
+			"failed":   report.Failed,
+		},
+		CreatedAt: time.Now(),
+	}
+
+	err = rh.adminActionDB.RecordAdminAction(c, adminAction)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to record admin action")
+		// Don't fail the request for logging errors
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
 // GET /api/v1/transfer-parties/{address} - Get transfer party by address
 func (rh *RouteHandler) GetTransferPartyByAddress(c *gin.Context) {
 	// Sanitize the address
@@ -1,21 +1,42 @@
 package routes
 
 import (
+	"database/sql"
+	"errors"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ETHCF/ethutils"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/auth"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/composition"
 	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/valuation"
 )
 
 // Treasury routes
 
-// GET /api/v1/treasury - Get treasury assets and information
+// GET /api/v1/treasury - Get treasury assets and information, spam and dust are left out unless includeFiltered=true.
+// Passing group=<id> scopes the response to the wallets of that wallet group.
 func (rh *RouteHandler) GetTreasury(c *gin.Context) {
-	treasuryResponse, err := rh.treasuryDB.GetTreasuryResponse(c)
+	includeFiltered := c.Query("includeFiltered") == "true"
+
+	group, ok := parseGroupQuery(c)
+	if !ok {
+		return
+	}
+
+	treasuryResponse, err := rh.treasuryDB.GetTreasuryResponse(c, includeFiltered, group)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Wallet group not found"})
+			return
+		}
 		rh.log.WithError(err).Error("failed to get treasury information")
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve treasury information"})
 		return
@@ -24,6 +45,57 @@ func (rh *RouteHandler) GetTreasury(c *gin.Context) {
 	c.JSON(http.StatusOK, treasuryResponse)
 }
 
+// parseGroupQuery reads the optional group query parameter, aborting the request when it is not a valid ID
+func parseGroupQuery(c *gin.Context) (uuid.NullUUID, bool) {
+	groupStr := c.Query("group")
+	if groupStr == "" {
+		return uuid.NullUUID{}, true
+	}
+	id, err := uuid.Parse(groupStr)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID format"})
+		return uuid.NullUUID{}, false
+	}
+	return uuid.NullUUID{UUID: id, Valid: true}, true
+}
+
+// GET /api/v1/treasury/composition - Get the treasury broken down by asset class, chain, wallet group and asset,
+// with the daily snapshots of the last `days` days (default 90)
+func (rh *RouteHandler) GetTreasuryComposition(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", "90"))
+	if err != nil || days < 0 || days > 3650 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid days parameter (0-3650)"})
+		return
+	}
+
+	treasury, err := rh.treasuryDB.GetTreasuryResponse(c, true, uuid.NullUUID{})
+	if err != nil {
+		rh.log.WithError(err).Error("failed to get treasury information")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve treasury composition"})
+		return
+	}
+
+	groups, err := rh.treasuryDB.GetWalletGroups(c)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to get wallet groups")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve treasury composition"})
+		return
+	}
+
+	now := time.Now().UTC()
+	history, err := rh.treasuryDB.GetCompositionSnapshots(c, now.Truncate(24*time.Hour).AddDate(0, 0, -days))
+	if err != nil {
+		rh.log.WithError(err).Error("failed to get composition snapshots")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve treasury composition"})
+		return
+	}
+
+	c.JSON(http.StatusOK, types.CompositionResponse{
+		Current: composition.Compute(treasury, groups, now),
+		History: history,
+	})
+}
+
 // GET /api/v1/treasury/assets - Get treasury assets
 func (rh *RouteHandler) GetTreasuryAssets(c *gin.Context) {
 	assets, err := rh.treasuryDB.GetAssets(c)
@@ -53,6 +125,14 @@ func (rh *RouteHandler) AddAsset(c *gin.Context) {
 		return
 	}
 
+	if asset.Class == "" {
+		asset.Class = types.AssetClassOther
+	}
+	if !asset.Class.Valid() {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Class must be native, stablecoin, governance, lp or other"})
+		return
+	}
+
 	if err := rh.treasuryDB.AddAsset(c, asset); err != nil {
 		if strings.Contains(err.Error(), "duplicate key value") {
 			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "Asset already exists"})
@@ -65,6 +145,55 @@ func (rh *RouteHandler) AddAsset(c *gin.Context) {
 	c.JSON(http.StatusCreated, asset)
 }
 
+// PUT /api/v1/treasury/assets/:address/class - Set the asset class used by the composition breakdown
+func (rh *RouteHandler) SetAssetClass(c *gin.Context) {
+	address, err := ethutils.SanitizeEthAddr(c.Param("address"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid Ethereum address"})
+		return
+	}
+
+	var req types.SetAssetClassRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		rh.log.WithError(err).Warn("failed to bind asset class request")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.ChainID == 0 {
+		req.ChainID = 1
+	}
+
+	if err := rh.treasuryDB.SetAssetClass(c, req.ChainID, address, req.Class); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Asset not found"})
+			return
+		}
+		rh.log.WithError(err).Error("failed to set asset class")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to set asset class"})
+		return
+	}
+
+	adminAction := types.AdminAction{
+		AdminAddress: auth.MustUserID(c),
+		Action:       "set_asset_class",
+		ResourceType: "asset",
+		ResourceID:   address,
+		Details: types.AdminActionDetails{
+			"chain_id": req.ChainID,
+			"class":    req.Class,
+		},
+		CreatedAt: time.Now(),
+	}
+
+	err = rh.adminActionDB.RecordAdminAction(c, adminAction)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to record admin action")
+		// Don't fail the request for logging errors
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Asset class updated successfully"})
+}
+
 // GET /api/v1/treasury/wallets - Get treasury wallets
 func (rh *RouteHandler) GetTreasuryWallets(c *gin.Context) {
 	wallets, err := rh.treasuryDB.GetWallets(c)
@@ -94,6 +223,16 @@ func (rh *RouteHandler) AddWallet(c *gin.Context) {
 		return
 	}
 
+	if wallet.Type.Valid && !types.WalletType(wallet.Type.String).Valid() {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Type must be eoa, safe or contract"})
+		return
+	}
+	if wallet.ChainID == 0 {
+		wallet.ChainID = 1
+	}
+	wallet.Name = strings.TrimSpace(wallet.Name)
+	wallet.GroupIDs = []uuid.UUID{}
+
 	if err := rh.treasuryDB.AddWallet(c, wallet); err != nil {
 		rh.log.WithError(err).Error("failed to add wallet")
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to add wallet"})
@@ -103,6 +242,200 @@ func (rh *RouteHandler) AddWallet(c *gin.Context) {
 	c.JSON(http.StatusCreated, wallet)
 }
 
+// PUT /api/v1/treasury/wallets/:address - Update a treasury wallet's chain, name, description or type
+func (rh *RouteHandler) UpdateWallet(c *gin.Context) {
+	address, err := ethutils.SanitizeEthAddr(c.Param("address"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid Ethereum address"})
+		return
+	}
+
+	var req types.UpdateWalletRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		rh.log.WithError(err).Warn("failed to bind update wallet request")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Type.Valid && !types.WalletType(req.Type.String).Valid() {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Type must be eoa, safe or contract"})
+		return
+	}
+	if req.ChainID.Valid && req.ChainID.Int64 < 1 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid chain ID"})
+		return
+	}
+	if req.Name.Valid {
+		req.Name.String = strings.TrimSpace(req.Name.String)
+	}
+
+	if err := rh.treasuryDB.UpdateWallet(c, address, req); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Wallet not found"})
+			return
+		}
+		rh.log.WithError(err).Error("failed to update wallet")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to update wallet"})
+		return
+	}
+
+	adminAction := types.AdminAction{
+		AdminAddress: auth.MustUserID(c),
+		Action:       "update_wallet",
+		ResourceType: "wallet",
+		ResourceID:   address,
+		Details: types.AdminActionDetails{
+			"updates": req,
+		},
+		CreatedAt: time.Now(),
+	}
+
+	err = rh.adminActionDB.RecordAdminAction(c, adminAction)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to record admin action")
+		// Don't fail the request for logging errors
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Wallet updated successfully"})
+}
+
+// POST /api/v1/treasury/wallets/:address/challenge - Issue an EIP-712 challenge for the wallet to sign, proving the organization controls it
+func (rh *RouteHandler) CreateWalletChallenge(c *gin.Context) {
+	address, err := ethutils.SanitizeEthAddr(c.Param("address"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid Ethereum address"})
+		return
+	}
+
+	wallet, err := rh.treasuryDB.GetWallet(c, address)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Wallet not found"})
+			return
+		}
+		rh.log.WithError(err).Error("failed to get wallet")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve wallet"})
+		return
+	}
+
+	challenge := types.WalletChallenge{
+		Wallet:   wallet.Address,
+		Nonce:    uuid.New(),
+		IssuedAt: time.Now().UTC().Truncate(time.Second),
+	}
+
+	typedData, err := auth.GenEIP712WalletChallenge(rh.conf, wallet.Address, wallet.ChainID, challenge.Nonce, challenge.IssuedAt)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to generate wallet challenge")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate challenge"})
+		return
+	}
+
+	if err := rh.treasuryDB.SetWalletChallenge(c, challenge); err != nil {
+		rh.log.WithError(err).Error("failed to store wallet challenge")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate challenge"})
+		return
+	}
+
+	c.JSON(http.StatusOK, typedData)
+}
+
+// POST /api/v1/treasury/wallets/:address/verify - Check the wallet's signature over its challenge and mark it verified
+func (rh *RouteHandler) VerifyWallet(c *gin.Context) {
+	address, err := ethutils.SanitizeEthAddr(c.Param("address"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid Ethereum address"})
+		return
+	}
+
+	var req types.VerifyWalletRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		rh.log.WithError(err).Warn("failed to bind verify wallet request")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	wallet, err := rh.treasuryDB.GetWallet(c, address)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Wallet not found"})
+			return
+		}
+		rh.log.WithError(err).Error("failed to get wallet")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve wallet"})
+		return
+	}
+
+	challenge, err := rh.treasuryDB.GetWalletChallenge(c, address)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "No open challenge for this wallet"})
+			return
+		}
+		rh.log.WithError(err).Error("failed to get wallet challenge")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve challenge"})
+		return
+	}
+	if time.Since(challenge.IssuedAt) > rh.conf.ChallengeExpire {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Challenge has expired, request a new one"})
+		return
+	}
+
+	typedData, err := auth.GenEIP712WalletChallenge(rh.conf, wallet.Address, wallet.ChainID, challenge.Nonce, challenge.IssuedAt)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to rebuild wallet challenge")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify signature"})
+		return
+	}
+
+	hash := common.HexToHash(typedData.Hash)
+	method, valid, err := auth.VerifyWalletSignature(c, rh.ethClient, wallet.Address, hash, req.Signature)
+	if err != nil {
+		rh.log.WithError(err).WithField("wallet", wallet.Address).Warn("failed to verify wallet signature")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Signature could not be verified"})
+		return
+	}
+	if !valid {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Signature is not valid for this wallet"})
+		return
+	}
+
+	verification := types.WalletVerification{
+		Wallet:        wallet.Address,
+		Method:        method,
+		ChallengeHash: strings.ToLower(typedData.Hash),
+		Signature:     strings.ToLower(req.Signature),
+		VerifiedBy:    auth.MustUserID(c),
+		VerifiedAt:    time.Now(),
+	}
+
+	if err := rh.treasuryDB.SaveWalletVerification(c, verification); err != nil {
+		rh.log.WithError(err).Error("failed to save wallet verification")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to save verification"})
+		return
+	}
+
+	adminAction := types.AdminAction{
+		AdminAddress: verification.VerifiedBy,
+		Action:       "verify_wallet",
+		ResourceType: "wallet",
+		ResourceID:   wallet.Address,
+		Details: types.AdminActionDetails{
+			"method":        verification.Method,
+			"challengeHash": verification.ChallengeHash,
+		},
+		CreatedAt: time.Now(),
+	}
+
+	err = rh.adminActionDB.RecordAdminAction(c, adminAction)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to record admin action")
+		// Don't fail the request for logging errors
+	}
+
+	c.JSON(http.StatusOK, verification)
+}
+
 // DELETE /api/v1/treasury/wallets/:address - Delete a treasury wallet
 func (rh *RouteHandler) DeleteWallet(c *gin.Context) {
 	// Sanitize the address
@@ -120,3 +453,229 @@ func (rh *RouteHandler) DeleteWallet(c *gin.Context) {
 
 	c.Status(http.StatusNoContent)
 }
+
+// GET /api/v1/treasury/asset-statuses - Get the assets marked as trusted, hidden or spam
+func (rh *RouteHandler) GetAssetStatuses(c *gin.Context) {
+	statuses, err := rh.treasuryDB.GetAssetStatuses(c)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to get asset statuses")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve asset statuses"})
+		return
+	}
+
+	c.JSON(http.StatusOK, statuses)
+}
+
+// PUT /api/v1/treasury/asset-statuses/:address - Mark an asset as trusted, hidden or spam
+func (rh *RouteHandler) SetAssetStatus(c *gin.Context) {
+	address, err := ethutils.SanitizeEthAddr(c.Param("address"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid Ethereum address"})
+		return
+	}
+
+	var status types.AssetStatusEntry
+	if err := c.ShouldBindJSON(&status); err != nil {
+		rh.log.WithError(err).Warn("failed to bind asset status request")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	status.Address = address
+	if status.ChainID == 0 {
+		status.ChainID = 1
+	}
+
+	if err := rh.treasuryDB.SetAssetStatus(c, status); err != nil {
+		rh.log.WithError(err).Error("failed to set asset status")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to set asset status"})
+		return
+	}
+
+	adminAction := types.AdminAction{
+		AdminAddress: auth.MustUserID(c),
+		Action:       "set_asset_status",
+		ResourceType: "asset",
+		ResourceID:   address,
+		Details: types.AdminActionDetails{
+			"chain_id": status.ChainID,
+			"status":   status.Status,
+			"reason":   status.Reason,
+		},
+		CreatedAt: time.Now(),
+	}
+
+	err = rh.adminActionDB.RecordAdminAction(c, adminAction)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to record admin action")
+		// Don't fail the request for logging errors
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// DELETE /api/v1/treasury/asset-statuses/:address - Clear the status of an asset
+func (rh *RouteHandler) DeleteAssetStatus(c *gin.Context) {
+	address, err := ethutils.SanitizeEthAddr(c.Param("address"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid Ethereum address"})
+		return
+	}
+
+	chainID, err := strconv.ParseInt(c.DefaultQuery("chainId", "1"), 10, 64)
+	if err != nil || chainID < 1 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid chainId parameter"})
+		return
+	}
+
+	if err := rh.treasuryDB.DeleteAssetStatus(c, chainID, address); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Asset status not found"})
+			return
+		}
+		rh.log.WithError(err).Error("failed to delete asset status")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete asset status"})
+		return
+	}
+
+	adminAction := types.AdminAction{
+		AdminAddress: auth.MustUserID(c),
+		Action:       "delete_asset_status",
+		ResourceType: "asset",
+		ResourceID:   address,
+		Details: types.AdminActionDetails{
+			"chain_id": chainID,
+		},
+		CreatedAt: time.Now(),
+	}
+
+	err = rh.adminActionDB.RecordAdminAction(c, adminAction)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to record admin action")
+		// Don't fail the request for logging errors
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GET /api/v1/treasury/positions - Get DeFi positions broken down into their underlying assets
+func (rh *RouteHandler) GetTreasuryPositions(c *gin.Context) {
+	positions, err := rh.treasuryDB.GetWalletPositions(c)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to get treasury positions")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve treasury positions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, positions)
+}
+
+// GET /api/v1/treasury/valuation-policies - Get the valuation policies that override market prices
+func (rh *RouteHandler) GetValuationPolicies(c *gin.Context) {
+	policies, err := rh.treasuryDB.GetValuationPolicies(c)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to get valuation policies")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve valuation policies"})
+		return
+	}
+
+	c.JSON(http.StatusOK, policies)
+}
+
+// PUT /api/v1/treasury/valuation-policies/:address - Set how an asset is valued
+func (rh *RouteHandler) SetValuationPolicy(c *gin.Context) {
+	address, err := ethutils.SanitizeEthAddr(c.Param("address"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid Ethereum address"})
+		return
+	}
+
+	var policy types.AssetValuationPolicy
+	if err := c.ShouldBindJSON(&policy); err != nil {
+		rh.log.WithError(err).Warn("failed to bind valuation policy request")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	policy.Address = address
+	if policy.ChainID == 0 {
+		policy.ChainID = 1
+	}
+
+	if err := valuation.Validate(&policy); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := rh.treasuryDB.SetValuationPolicy(c, policy); err != nil {
+		rh.log.WithError(err).Error("failed to set valuation policy")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to set valuation policy"})
+		return
+	}
+
+	adminAction := types.AdminAction{
+		AdminAddress: auth.MustUserID(c),
+		Action:       "set_valuation_policy",
+		ResourceType: "asset",
+		ResourceID:   address,
+		Details: types.AdminActionDetails{
+			"chain_id":         policy.ChainID,
+			"policy":           policy.Policy,
+			"manual_price":     policy.ManualPrice,
+			"peg_address":      policy.PegAddress,
+			"discount_percent": policy.DiscountPercent,
+			"notes":            policy.Notes,
+		},
+		CreatedAt: time.Now(),
+	}
+
+	err = rh.adminActionDB.RecordAdminAction(c, adminAction)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to record admin action")
+		// Don't fail the request for logging errors
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// DELETE /api/v1/treasury/valuation-policies/:address - Value an asset at the market price again
+func (rh *RouteHandler) DeleteValuationPolicy(c *gin.Context) {
+	address, err := ethutils.SanitizeEthAddr(c.Param("address"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid Ethereum address"})
+		return
+	}
+
+	chainID, err := strconv.ParseInt(c.DefaultQuery("chainId", "1"), 10, 64)
+	if err != nil || chainID < 1 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid chainId parameter"})
+		return
+	}
+
+	if err := rh.treasuryDB.DeleteValuationPolicy(c, chainID, address); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Valuation policy not found"})
+			return
+		}
+		rh.log.WithError(err).Error("failed to delete valuation policy")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete valuation policy"})
+		return
+	}
+
+	adminAction := types.AdminAction{
+		AdminAddress: auth.MustUserID(c),
+		Action:       "delete_valuation_policy",
+		ResourceType: "asset",
+		ResourceID:   address,
+		Details: types.AdminActionDetails{
+			"chain_id": chainID,
+		},
+		CreatedAt: time.Now(),
+	}
+
+	err = rh.adminActionDB.RecordAdminAction(c, adminAction)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to record admin action")
+		// Don't fail the request for logging errors
+	}
+
+	c.Status(http.StatusNoContent)
+}
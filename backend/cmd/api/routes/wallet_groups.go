@@ -0,0 +1,222 @@
+package routes
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ETHCF/ethutils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/auth"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+// Wallet group routes
+
+// GET /api/v1/treasury/wallet-groups - Get all wallet groups with their member wallets
+func (rh *RouteHandler) GetWalletGroups(c *gin.Context) {
+	groups, err := rh.treasuryDB.GetWalletGroups(c)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to get wallet groups")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve wallet groups"})
+		return
+	}
+
+	c.JSON(http.StatusOK, groups)
+}
+
+// GET /api/v1/treasury/wallet-groups/:id - Get a wallet group by ID
+func (rh *RouteHandler) GetWalletGroupByID(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID format"})
+		return
+	}
+
+	group, err := rh.treasuryDB.GetWalletGroupByID(c, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Wallet group not found"})
+			return
+		}
+		rh.log.WithError(err).Error("failed to get wallet group")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve wallet group"})
+		return
+	}
+
+	c.JSON(http.StatusOK, group)
+}
+
+// walletGroupFromRequest validates a wallet group request, sanitizing the member addresses
+func walletGroupFromRequest(req types.WalletGroupRequest) (types.WalletGroup, error) {
+	group := types.WalletGroup{
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		Wallets:     make([]string, 0, len(req.Wallets)),
+	}
+	if group.Name == "" {
+		return group, errors.New("Name is required")
+	}
+	for _, wallet := range req.Wallets {
+		address, err := ethutils.SanitizeEthAddr(wallet)
+		if err != nil {
+			return group, errors.New("Invalid wallet address: " + wallet)
+		}
+		group.Wallets = append(group.Wallets, address)
+	}
+	return group, nil
+}
+
+// POST /api/v1/treasury/wallet-groups - Create a wallet group
+func (rh *RouteHandler) CreateWalletGroup(c *gin.Context) {
+	var req types.WalletGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		rh.log.WithError(err).Warn("failed to bind create wallet group request")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	group, err := walletGroupFromRequest(req)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	group.ID = uuid.New()
+	group.CreatedAt = time.Now()
+	group.UpdatedAt = group.CreatedAt
+
+	if err := rh.treasuryDB.CreateWalletGroup(c, group); err != nil {
+		if strings.Contains(err.Error(), "duplicate key value") {
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "A wallet group with this name already exists"})
+			return
+		}
+		if strings.Contains(err.Error(), "violates foreign key constraint") {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Group members must be treasury wallets"})
+			return
+		}
+		rh.log.WithError(err).Error("failed to create wallet group")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to create wallet group"})
+		return
+	}
+
+	adminAction := types.AdminAction{
+		AdminAddress: auth.MustUserID(c),
+		Action:       "create_wallet_group",
+		ResourceType: "wallet_group",
+		ResourceID:   group.ID.String(),
+		Details: types.AdminActionDetails{
+			"name":    group.Name,
+			"wallets": group.Wallets,
+		},
+		CreatedAt: time.Now(),
+	}
+
+	err = rh.adminActionDB.RecordAdminAction(c, adminAction)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to record admin action")
+		// Don't fail the request for logging errors
+	}
+
+	c.JSON(http.StatusCreated, group)
+}
+
+// PUT /api/v1/treasury/wallet-groups/:id - Update a wallet group, replacing its member wallets
+func (rh *RouteHandler) UpdateWalletGroup(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID format"})
+		return
+	}
+
+	var req types.WalletGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		rh.log.WithError(err).Warn("failed to bind update wallet group request")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	group, err := walletGroupFromRequest(req)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	group.ID = id
+
+	if err := rh.treasuryDB.UpdateWalletGroup(c, group); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Wallet group not found"})
+			return
+		}
+		if strings.Contains(err.Error(), "duplicate key value") {
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "A wallet group with this name already exists"})
+			return
+		}
+		if strings.Contains(err.Error(), "violates foreign key constraint") {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Group members must be treasury wallets"})
+			return
+		}
+		rh.log.WithError(err).Error("failed to update wallet group")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to update wallet group"})
+		return
+	}
+
+	adminAction := types.AdminAction{
+		AdminAddress: auth.MustUserID(c),
+		Action:       "update_wallet_group",
+		ResourceType: "wallet_group",
+		ResourceID:   id.String(),
+		Details: types.AdminActionDetails{
+			"name":    group.Name,
+			"wallets": group.Wallets,
+		},
+		CreatedAt: time.Now(),
+	}
+
+	err = rh.adminActionDB.RecordAdminAction(c, adminAction)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to record admin action")
+		// Don't fail the request for logging errors
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Wallet group updated successfully"})
+}
+
+// DELETE /api/v1/treasury/wallet-groups/:id - Delete a wallet group, its wallets are kept
+func (rh *RouteHandler) DeleteWalletGroup(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID format"})
+		return
+	}
+
+	if err := rh.treasuryDB.DeleteWalletGroup(c, id); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Wallet group not found"})
+			return
+		}
+		rh.log.WithError(err).Error("failed to delete wallet group")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete wallet group"})
+		return
+	}
+
+	adminAction := types.AdminAction{
+		AdminAddress: auth.MustUserID(c),
+		Action:       "delete_wallet_group",
+		ResourceType: "wallet_group",
+		ResourceID:   id.String(),
+		Details:      types.AdminActionDetails{},
+		CreatedAt:    time.Now(),
+	}
+
+	err = rh.adminActionDB.RecordAdminAction(c, adminAction)
+	if err != nil {
+		rh.log.WithError(err).Error("failed to record admin action")
+		// Don't fail the request for logging errors
+	}
+
+	c.Status(http.StatusNoContent)
+}
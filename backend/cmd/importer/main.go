@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/numbergroup/errors"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/config"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/db"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/eth"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/explorer"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/labels"
+)
+
+const usage = `usage: importer <command> [flags] files...
+
+commands:
+  transfers   import transfer history from Etherscan or Blockscout CSV exports
+  labels      replace the labels of an import source with a CSV or JSON file
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var err error
+	switch os.Args[1] {
+	case "transfers":
+		err = importTransfers(ctx, os.Args[2:])
+	case "labels":
+		err = importLabels(ctx, os.Args[2:])
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func importTransfers(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("transfers", flag.ExitOnError)
+	chainID := flags.Int64("chain-id", 1, "chain the exports were taken from")
+	kindStr := flags.String("kind", "", "export type: normal, internal, erc20 or erc721 (detected from the header if empty)")
+	flags.Parse(args)
+	if flags.NArg() == 0 {
+		return errors.New("no files given")
+	}
+	kind, err := explorer.ParseExportKind(*kindStr)
+	if err != nil {
+		return err
+	}
+
+	conf, err := config.NewConfig(ctx)
+	if err != nil {
+		return err
+	}
+	dbConn, err := conf.ConnectPSQL(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to PSQL")
+	}
+	settingsDB, err := db.NewSettingsDB(conf, dbConn)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to settings PSQL")
+	}
+	treasuryDB, err := db.NewTreasuryDB(ctx, conf, dbConn, settingsDB)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to treasury PSQL")
+	}
+	var ethClient eth.Client
+	if conf.RPCURL != "" {
+		ethClient = eth.NewClient(conf)
+	}
+	imp := explorer.NewImporter(conf, treasuryDB, ethClient)
+
+	for _, name := range flags.Args() {
+		file, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		report, err := imp.ImportTransfers(ctx, file, explorer.ImportOptions{ChainID: *chainID, Kind: kind})
+		file.Close()
+		if err != nil {
+			return errors.Wrap(err, name)
+		}
+		fmt.Printf("%s: %d rows, %d inserted, %d skipped, %d failed\n", name, report.Rows, report.Inserted, report.Skipped, report.Failed)
+		for _, rowErr := range report.Errors {
+			fmt.Printf("  row %d: %s\n", rowErr.Row, rowErr.Error)
+		}
+	}
+	return nil
+}
+
+func importLabels(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("labels", flag.ExitOnError)
+	name := flags.String("source", "", "name of the import, the labels are stored under import:<name>")
+	priority := flags.Int("priority", -1, "priority of the source, lower wins (defaults to 100 for new sources, unchanged for existing ones)")
+	formatStr := flags.String("format", "", "file format: csv or json (detected from the extension if empty)")
+	dryRun := flags.Bool("dry-run", false, "only print the changes the import would make")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		return errors.New("expected exactly one file")
+	}
+	source, err := labels.ImportSource(*name)
+	if err != nil {
+		return err
+	}
+	fileName := flags.Arg(0)
+	format, err := labels.DetectFormat(*formatStr, fileName)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	incoming, rowErrors, err := labels.Parse(file, format, source)
+	if err != nil {
+		return errors.Wrap(err, fileName)
+	}
+
+	conf, err := config.NewConfig(ctx)
+	if err != nil {
+		return err
+	}
+	dbConn, err := conf.ConnectPSQL(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to PSQL")
+	}
+	labelDB, err := db.NewLabelDB(ctx, conf, dbConn)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to label PSQL")
+	}
+
+	existing, err := labelDB.GetLabelsBySource(ctx, source)
+	if err != nil {
+		return err
+	}
+	diff := labels.Diff(source, existing, incoming)
+	for _, label := range diff.Added {
+		fmt.Printf("+ %s %q\n", label.Address, label.Label)
+	}
+	for _, change := range diff.Changed {
+		fmt.Printf("~ %s %q -> %q\n", change.Address, change.OldLabel, change.NewLabel)
+	}
+	for _, label := range diff.Removed {
+		fmt.Printf("- %s %q\n", label.Address, label.Label)
+	}
+	for _, rowErr := range rowErrors {
+		fmt.Printf("! row %d: %s\n", rowErr.Row, rowErr.Error)
+	}
+	fmt.Printf("%s: %d added, %d changed, %d removed, %d unchanged, %d failed\n",
+		source, len(diff.Added), len(diff.Changed), len(diff.Removed), diff.Unchanged, len(rowErrors))
+	if *dryRun {
+		return nil
+	}
+
+	var sourcePriority null.Int
+	if *priority >= 0 {
+		sourcePriority = null.IntFrom(int64(*priority))
+	}
+	return labelDB.ReplaceSourceLabels(ctx, source, sourcePriority, incoming)
+}
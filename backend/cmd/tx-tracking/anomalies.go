@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/anomaly"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+// scoreOutflows scores each outflow ingested since the last run against the outflows sent before it
+func (t *Tracker) scoreOutflows(ctx context.Context) error {
+	outflows, err := t.anomalyDB.GetOutflows(ctx)
+	if err != nil {
+		return err
+	}
+
+	detector := anomaly.NewDetector(anomaly.Thresholds{
+		Percentile:    t.conf.AnomalyPercentile,
+		MinHistory:    t.conf.AnomalyMinHistory,
+		RareHourShare: t.conf.AnomalyRareHourShare,
+	})
+	var (
+		checked   []uuid.UUID
+		anomalies []types.TransferAnomaly
+	)
+	for _, outflow := range outflows {
+		if !outflow.Checked {
+			checked = append(checked, outflow.ID)
+			anomalies = append(anomalies, detector.Score(outflow)...)
+		}
+		detector.Add(outflow)
+	}
+	if len(checked) == 0 {
+		return nil
+	}
+
+	if err := t.anomalyDB.RecordAnomalies(ctx, checked, anomalies); err != nil {
+		return err
+	}
+	for _, found := range anomalies {
+		t.log.WithField("transfer", found.TransferID).
+			WithField("kind", found.Kind).
+			WithField("reason", found.Reason).
+			Warn("unusual outflow")
+	}
+	t.log.WithField("outflows", len(checked)).WithField("anomalies", len(anomalies)).Info("finished scoring outflows")
+	return nil
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/numbergroup/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/approvals"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/constants"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/eth"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+// processApprovals ingests the wallet's Approval events since the last run, then refreshes the allowances
+// which are still open, as spending them doesn't always emit an event
+func (t *Tracker) processApprovals(ctx context.Context, wallet types.Wallet) error {
+	fromBlock := t.conf.ApprovalStartBlock
+	lastBlock, err := t.metaDB.GetUint64(ctx, "last_approval_block_"+wallet.Address)
+	if err == nil {
+		fromBlock = lastBlock + 1
+	}
+
+	currentHead, err := t.ethClient.BlockNumber(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get current block number")
+	}
+	currentHead = currentHead - t.conf.BlockDelay
+
+	timestamps := map[int64]time.Time{}
+	for fromBlock <= currentHead {
+		toBlock := min(fromBlock+t.conf.ApprovalBlockRange-1, currentHead)
+		logs, err := t.ethClient.GetLogs(ctx, eth.LogFilter{
+			FromBlock: fromBlock,
+			ToBlock:   toBlock,
+			Topics:    [][]string{{constants.ApprovalEventTopic}, {approvals.OwnerTopic(wallet.Address)}},
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to get approval logs from block %d to %d", fromBlock, toBlock)
+		}
+
+		for _, log := range logs {
+			approval, err := approvals.ParseLog(log)
+			if err != nil {
+				// NFT approvals share the event signature
+				continue
+			}
+			timestamp, ok := timestamps[approval.BlockNumber]
+			if !ok {
+				timestamp, err = t.ethClient.GetBlockTimestamp(ctx, uint64(approval.BlockNumber))
+				if err != nil {
+					return errors.Wrapf(err, "failed to get timestamp of block %d", approval.BlockNumber)
+				}
+				timestamps[approval.BlockNumber] = timestamp
+			}
+			approval.BlockTimestamp = timestamp
+
+			if err := t.approvalDB.UpsertApproval(ctx, approval); err != nil {
+				return errors.Wrapf(err, "failed to store approval from tx %s", approval.TxHash)
+			}
+			t.log.WithFields(logrus.Fields{
+				"wallet":  wallet.Address,
+				"token":   approval.Token,
+				"spender": approval.Spender,
+			}).Info("processed approval")
+		}
+
+		if err := t.metaDB.Set(ctx, "last_approval_block_"+wallet.Address, toBlock); err != nil {
+			return errors.Wrap(err, "failed to set last approval block")
+		}
+		fromBlock = toBlock + 1
+	}
+
+	return t.refreshAllowances(ctx, wallet)
+}
+
+// refreshAllowances reads the current allowance of each open approval from the token
+func (t *Tracker) refreshAllowances(ctx context.Context, wallet types.Wallet) error {
+	open, err := t.approvalDB.GetApprovals(ctx, wallet.Address, false)
+	if err != nil {
+		return err
+	}
+
+	for _, approval := range open {
+		err := t.refreshAllowance(ctx, approval.TokenApproval)
+		if err != nil {
+			// Leave it to be retried on the next run
+			t.log.WithError(err).WithFields(logrus.Fields{
+				"wallet":  wallet.Address,
+				"token":   approval.Token,
+				"spender": approval.Spender,
+			}).Warn("failed to refresh allowance")
+		}
+	}
+	return nil
+}
+
+func (t *Tracker) refreshAllowance(ctx context.Context, approval types.TokenApproval) error {
+	result, err := t.ethClient.Call(ctx, approval.Token, approvals.AllowanceCallData(approval.Owner, approval.Spender), "latest")
+	if err != nil {
+		return errors.Wrap(err, "failed to call allowance")
+	}
+	allowance, err := approvals.DecodeAllowance(result)
+	if err != nil {
+		return errors.Wrap(err, "failed to decode allowance")
+	}
+	if allowance.String() == approval.Allowance {
+		return nil
+	}
+	return t.approvalDB.UpdateAllowance(ctx, approval.ChainID, approval.Owner, approval.Token, approval.Spender, allowance.String())
+}
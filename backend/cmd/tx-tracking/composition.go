@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/numbergroup/errors"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/composition"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+// recordComposition stores today's treasury composition, so the composition endpoint can show how it changed over time.
+// The composition is returned for the policy rules to be evaluated against.
+func (t *Tracker) recordComposition(ctx context.Context) (types.TreasuryComposition, error) {
+	treasury, err := t.treasuryDB.GetTreasuryResponse(ctx, true, uuid.NullUUID{})
+	if err != nil {
+		return types.TreasuryComposition{}, errors.Wrap(err, "failed to get treasury")
+	}
+
+	groups, err := t.treasuryDB.GetWalletGroups(ctx)
+	if err != nil {
+		return types.TreasuryComposition{}, errors.Wrap(err, "failed to get wallet groups")
+	}
+
+	now := time.Now().UTC()
+	current := composition.Compute(treasury, groups, now)
+	err = t.treasuryDB.SaveCompositionSnapshot(ctx, types.CompositionSnapshot{
+		SnapshotDate:  now.Truncate(24 * time.Hour),
+		TotalValueUsd: current.TotalValueUsd,
+		Composition:   current,
+	})
+	return current, err
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/disbursement"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+// matchDisbursements links the disbursements entered by hand to their transfers, then matches the remaining transfers
+// to grant addresses to the grants they may have paid
+func (t *Tracker) matchDisbursements(ctx context.Context) error {
+	linked, err := t.matchDB.LinkManualDisbursements(ctx)
+	if err != nil {
+		return err
+	}
+	if linked > 0 {
+		t.log.WithField("disbursements", linked).Info("linked disbursements to transfers")
+	}
+
+	candidates, err := t.matchDB.GetCandidates(ctx)
+	if err != nil {
+		return err
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	matches := disbursement.Plan(candidates, t.conf.AutoDisburse)
+	if err := t.matchDB.RecordMatches(ctx, matches); err != nil {
+		return err
+	}
+
+	var confirmed int
+	for _, match := range matches {
+		if match.Status == types.DisbursementMatchConfirmed {
+			confirmed++
+		}
+	}
+	t.log.WithField("matches", len(matches)).WithField("confirmed", confirmed).Info("matched transfers to grants")
+	return nil
+}
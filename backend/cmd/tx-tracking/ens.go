@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"gopkg.in/guregu/null.v4"
+)
+
+// ensUpdates looks up the ENS names of wallets and counterparties which have never been resolved
+// or were resolved longer ago than the refresh age, a batch at a time
+func (t *Tracker) ensUpdates(ctx context.Context) error {
+	addresses, err := t.treasuryDB.GetAddressesForENSResolution(ctx, time.Now().Add(-t.conf.ENSRefreshAge), t.conf.ENSBatchSize)
+	if err != nil {
+		return err
+	}
+
+	resolved := 0
+	for _, address := range addresses {
+		name, err := t.ens.LookupAddress(ctx, address)
+		if err != nil {
+			// Leave it to be retried on the next run
+			t.log.WithError(err).WithField("address", address).Warn("failed to look up ENS name")
+			continue
+		}
+
+		ensName := null.NewString(name, name != "")
+		if err := t.treasuryDB.SetTransferPartyENSName(ctx, address, ensName); err != nil {
+			return err
+		}
+		if ensName.Valid {
+			resolved++
+		}
+	}
+	t.log.WithField("checked", len(addresses)).WithField("resolved", resolved).Info("finished ENS updates")
+	return nil
+}
+
+func (t *Tracker) startENSResolver(ctx context.Context) {
+	for {
+		err := t.ensUpdates(ctx)
+		if err != nil {
+			t.log.WithError(err).Error("error during ENS updates")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(t.conf.ENSPollInterval):
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"time"
+)
+
+// exchangeMovementOverlap is how far before the last sync movements are read again, so deposits and
+// withdrawals which were pending get their final status and tx hash
+const exchangeMovementOverlap = 7 * 24 * time.Hour
+
+// exchangeUpdates reads the balances and recent deposits/withdrawals of each exchange account
+func (t *Tracker) exchangeUpdates(ctx context.Context) error {
+	for _, connector := range t.connectors {
+		log := t.log.WithField("exchange", connector.Name())
+
+		balances, err := connector.Balances(ctx)
+		if err != nil {
+			// Keep the last balances until the exchange can be reached again
+			log.WithError(err).Warn("failed to get exchange balances")
+			continue
+		}
+		if err := t.exchangeDB.ReplaceBalances(ctx, connector.Name(), balances); err != nil {
+			return err
+		}
+
+		since := time.Unix(0, 0)
+		lastSync, err := t.metaDB.GetUint64(ctx, "last_exchange_sync_"+connector.Name())
+		if err == nil {
+			since = time.Unix(int64(lastSync), 0).Add(-exchangeMovementOverlap)
+		}
+
+		syncedAt := time.Now()
+		movements, err := connector.Movements(ctx, since)
+		if err != nil {
+			log.WithError(err).Warn("failed to get exchange movements")
+			continue
+		}
+		if err := t.exchangeDB.UpsertMovements(ctx, movements); err != nil {
+			return err
+		}
+		if err := t.metaDB.Set(ctx, "last_exchange_sync_"+connector.Name(), uint64(syncedAt.Unix())); err != nil {
+			return err
+		}
+		log.WithField("balances", len(balances)).WithField("movements", len(movements)).Info("finished exchange updates")
+	}
+	return nil
+}
+
+func (t *Tracker) startExchangeSync(ctx context.Context) {
+	if len(t.connectors) == 0 {
+		return
+	}
+	for {
+		err := t.exchangeUpdates(ctx)
+		if err != nil {
+			t.log.WithError(err).Error("error during exchange updates")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(t.conf.ExchangePollInterval):
+		}
+	}
+}
@@ -9,7 +9,10 @@ import (
 	"github.com/ETHCF/transparency-dashboard/backend/pkg/alchemy"
 	"github.com/ETHCF/transparency-dashboard/backend/pkg/config"
 	"github.com/ETHCF/transparency-dashboard/backend/pkg/db"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/ens"
 	"github.com/ETHCF/transparency-dashboard/backend/pkg/eth"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/exchange"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/positions"
 	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
 )
 
@@ -55,10 +58,52 @@ func main() {
 		log.WithError(err).Fatal("failed to connect to treasury PSQL")
 	}
 
+	approvalDB, err := db.NewApprovalDB(ctx, conf, dbConn)
+	if err != nil {
+		log.WithError(err).Fatal("failed to connect to approval PSQL")
+	}
+
+	exchangeDB, err := db.NewExchangeDB(ctx, conf, dbConn)
+	if err != nil {
+		log.WithError(err).Fatal("failed to connect to exchange PSQL")
+	}
+
+	policyDB, err := db.NewPolicyDB(ctx, conf, dbConn)
+	if err != nil {
+		log.WithError(err).Fatal("failed to connect to policy PSQL")
+	}
+
+	anomalyDB, err := db.NewAnomalyDB(ctx, conf, dbConn)
+	if err != nil {
+		log.WithError(err).Fatal("failed to connect to anomaly PSQL")
+	}
+
+	reconDB, err := db.NewReconciliationDB(ctx, conf, dbConn)
+	if err != nil {
+		log.WithError(err).Fatal("failed to connect to reconciliation PSQL")
+	}
+
+	matchDB, err := db.NewDisbursementMatchDB(ctx, conf, dbConn)
+	if err != nil {
+		log.WithError(err).Fatal("failed to connect to disbursement match PSQL")
+	}
+
+	grantDB, err := db.NewGrantDB(ctx, conf, dbConn)
+	if err != nil {
+		log.WithError(err).Fatal("failed to connect to grant PSQL")
+	}
+
 	alchemyAPI := alchemy.NewAPI(conf)
 	ethRPC := eth.NewClient(conf)
 
-	tracker := NewTracker(conf, ethRPC, alchemyAPI, metaDB, treasuryDB)
+	ensResolver := ens.NewResolver(ethRPC)
+
+	adapters, err := positions.NewAdapters(conf.PositionAdapters, ethRPC)
+	if err != nil {
+		log.WithError(err).Fatal("failed to create position adapters")
+	}
+
+	tracker := NewTracker(conf, ethRPC, alchemyAPI, ensResolver, adapters, exchange.NewConnectors(conf), metaDB, treasuryDB, approvalDB, exchangeDB, policyDB, anomalyDB, reconDB, matchDB, grantDB)
 
 	tracker.Start(ctx)
 
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+// checkMilestoneDeadlines records the milestones which became overdue since the last check, each is only reported
+// once per target date
+func (t *Tracker) checkMilestoneDeadlines(ctx context.Context) error {
+	events, err := t.grantDB.RecordOverdueMilestones(ctx)
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		t.log.WithFields(logrus.Fields{
+			"grant":      event.GrantID,
+			"milestone":  event.MilestoneID,
+			"targetDate": event.TargetDate.Format("2006-01-02"),
+		}).Warn("milestone is overdue")
+	}
+	return nil
+}
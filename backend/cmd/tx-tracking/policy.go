@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/numbergroup/errors"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/policy"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+// evaluatePolicies checks every enabled policy rule against the current composition, recording violations as they open and resolve
+func (t *Tracker) evaluatePolicies(ctx context.Context, current types.TreasuryComposition) error {
+	rules, err := t.policyDB.GetRules(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	// Rules usually share a window, so the outflows are only summed once per window
+	outflows := map[int]types.OutflowSummary{}
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+
+		summary, ok := outflows[rule.WindowDays]
+		if !ok {
+			summary, err = t.policyDB.GetOutflowSummary(ctx, now.AddDate(0, 0, -rule.WindowDays))
+			if err != nil {
+				return errors.Wrapf(err, "failed to get outflows for the last %d days", rule.WindowDays)
+			}
+			outflows[rule.WindowDays] = summary
+		}
+
+		value, violated := policy.Evaluate(rule, current, summary)
+		if err := t.policyDB.RecordEvaluation(ctx, rule, value, violated, now); err != nil {
+			return errors.Wrapf(err, "failed to record evaluation of policy rule %s", rule.ID)
+		}
+
+		if violated && !rule.Violated {
+			t.log.WithField("rule", rule.Name).
+				WithField("metric", rule.Metric).
+				WithField("value", value.Float64).
+				WithField("threshold", rule.Threshold).
+				Warn("treasury policy violated")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/numbergroup/errors"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/constants"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/reconcile"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+// reconcileWallets checks that the transfers ingested for each wallet add up to its on-chain balances, at the last
+// block its transfers were ingested up to
+func (t *Tracker) reconcileWallets(ctx context.Context) error {
+	wallets, err := t.treasuryDB.GetWallets(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, wallet := range wallets {
+		if err := t.reconcileWallet(ctx, wallet); err != nil {
+			return errors.Wrapf(err, "failed to reconcile wallet %s", wallet.Address)
+		}
+	}
+	return nil
+}
+
+func (t *Tracker) reconcileWallet(ctx context.Context, wallet types.Wallet) error {
+	log := t.log.WithField("wallet", wallet.Address)
+	checkpoint, err := t.getLastProcessedBlockForWallet(ctx, wallet.Address)
+	if err != nil {
+		return err
+	}
+	if checkpoint == 0 {
+		log.Info("no transfers ingested yet, skipping reconciliation")
+		return nil
+	}
+
+	// The gas paid by the wallet only shows in its ether balance, so it is read from the receipts
+	hashes, err := t.reconDB.GetTxHashesWithoutFees(ctx, 1, wallet.Address)
+	if err != nil {
+		return err
+	}
+	for _, hash := range hashes {
+		receipt, err := t.ethClient.GetTransactionReceipt(ctx, hash)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get receipt of %s", hash)
+		}
+		if receipt.TransactionHash == "" {
+			log.WithField("txHash", hash).Warn("no receipt found for transaction")
+			continue
+		}
+		fee, err := reconcile.TransactionFee(receipt)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read fee of %s", hash)
+		}
+		if err := t.reconDB.SaveTransactionFee(ctx, fee); err != nil {
+			return err
+		}
+	}
+
+	expected, err := t.reconDB.GetExpectedBalances(ctx, 1, wallet.Address, checkpoint)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	blockTag := reconcile.BlockTag(checkpoint)
+	results := make([]types.ReconciliationResult, 0, len(expected))
+	for _, balance := range expected {
+		onchain, err := t.balanceAt(ctx, wallet.Address, balance.Asset, blockTag)
+		if err != nil {
+			// Not every asset implements balanceOf, those can't be reconciled
+			log.WithError(err).WithField("asset", balance.Asset).Warn("failed to get on-chain balance")
+			continue
+		}
+		result, err := reconcile.Compare(wallet.Address, checkpoint, balance, onchain, now)
+		if err != nil {
+			return errors.Wrapf(err, "failed to compare balance of %s", balance.Asset)
+		}
+		if !result.Reconciled {
+			log.WithField("asset", balance.Asset).WithField("difference", result.Difference).Warn("balance doesn't reconcile")
+		}
+		results = append(results, result)
+	}
+
+	return t.reconDB.ReplaceResults(ctx, 1, wallet.Address, results)
+}
+
+// balanceAt reads the balance a wallet held of an asset at a block
+func (t *Tracker) balanceAt(ctx context.Context, wallet, asset, blockTag string) (*big.Int, error) {
+	if asset == constants.EtherAddress {
+		return t.ethClient.GetBalance(ctx, wallet, blockTag)
+	}
+	result, err := t.ethClient.Call(ctx, asset, reconcile.BalanceOfCallData(wallet), blockTag)
+	if err != nil {
+		return nil, err
+	}
+	return reconcile.DecodeBalance(result)
+}
+
+func (t *Tracker) startReconciliation(ctx context.Context) {
+	for {
+		err := t.reconcileWallets(ctx)
+		if err != nil {
+			t.log.WithError(err).Error("error during reconciliation")
+		} else {
+			t.log.Info("Completed reconciliation")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(t.conf.ReconcileInterval):
+		}
+	}
+}
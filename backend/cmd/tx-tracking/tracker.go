@@ -14,55 +14,126 @@ import (
 	"github.com/ETHCF/transparency-dashboard/backend/pkg/config"
 	"github.com/ETHCF/transparency-dashboard/backend/pkg/constants"
 	"github.com/ETHCF/transparency-dashboard/backend/pkg/db"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/ens"
 	"github.com/ETHCF/transparency-dashboard/backend/pkg/eth"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/exchange"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/positions"
 	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/valuation"
 )
 
-// 0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef
-
 type Tracker struct {
 	conf       *config.Config
 	log        logrus.Ext1FieldLogger
 	ethClient  eth.Client
 	alchemy    alchemy.API
+	ens        ens.Resolver
+	adapters   []positions.Adapter
+	connectors []exchange.Connector
 	metaDB     db.MetaDB
 	treasuryDB db.TreasuryDB
+	approvalDB db.ApprovalDB
+	exchangeDB db.ExchangeDB
+	policyDB   db.PolicyDB
+	anomalyDB  db.AnomalyDB
+	reconDB    db.ReconciliationDB
+	matchDB    db.DisbursementMatchDB
+	grantDB    db.GrantDB
 }
 
-func NewTracker(conf *config.Config, ethClient eth.Client, alchemyAPI alchemy.API, metaDB db.MetaDB, treasuryDB db.TreasuryDB) *Tracker {
+func NewTracker(conf *config.Config, ethClient eth.Client, alchemyAPI alchemy.API, ensResolver ens.Resolver, adapters []positions.Adapter, connectors []exchange.Connector, metaDB db.MetaDB, treasuryDB db.TreasuryDB, approvalDB db.ApprovalDB, exchangeDB db.ExchangeDB, policyDB db.PolicyDB, anomalyDB db.AnomalyDB, reconDB db.ReconciliationDB, matchDB db.DisbursementMatchDB, grantDB db.GrantDB) *Tracker {
 	return &Tracker{
 		conf:       conf,
 		log:        conf.GetLogger(),
 		ethClient:  ethClient,
 		alchemy:    alchemyAPI,
+		ens:        ensResolver,
+		adapters:   adapters,
+		connectors: connectors,
 		metaDB:     metaDB,
 		treasuryDB: treasuryDB,
+		approvalDB: approvalDB,
+		exchangeDB: exchangeDB,
+		policyDB:   policyDB,
+		anomalyDB:  anomalyDB,
+		reconDB:    reconDB,
+		matchDB:    matchDB,
+		grantDB:    grantDB,
 	}
 }
 
-func (t *Tracker) GetAssets(ctx context.Context) (map[string]types.Asset, map[string]float64, error) {
+// GetAssets returns the known assets and the price of each, with their valuation policies applied
+func (t *Tracker) GetAssets(ctx context.Context) (map[string]types.Asset, map[string]types.Valuation, error) {
 	assets, err := t.treasuryDB.GetAssets(ctx)
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "failed to get assets")
 	}
 
+	policies, err := t.treasuryDB.GetValuationPolicies(ctx)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to get valuation policies")
+	}
+	policyMap := make(map[string]*types.AssetValuationPolicy, len(policies))
+	for i := range policies {
+		policyMap[policies[i].Address] = &policies[i]
+	}
+
 	assetMap := make(map[string]types.Asset)
 	assetList := []string{constants.WethAddress}
 	for _, asset := range assets {
 		assetMap[asset.Address] = asset
 		assetList = append(assetList, asset.Address)
 	}
+	assetList = append(assetList, valuation.PegAddresses(policies)...)
 
 	prices, err := t.alchemy.GetTokenPrices(ctx, assetList)
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "failed to get token prices")
 	}
+	fetchedAt := time.Now()
 
-	prices[constants.EtherAddress] = prices[constants.WethAddress]
-	return assetMap, prices, nil
+	if wethPrice, ok := prices[constants.WethAddress]; ok {
+		prices[constants.EtherAddress] = wethPrice
+	}
+
+	valuations := make(map[string]types.Valuation, len(assetMap)+1)
+	valuations[constants.EtherAddress] = valuation.Apply(policyMap[constants.EtherAddress], constants.EtherAddress, prices, fetchedAt)
+	for address := range assetMap {
+		valuations[address] = valuation.Apply(policyMap[address], address, prices, fetchedAt)
+	}
+
+	// Keep the prices so the API can value transfers when filtering dust
+	if err := t.treasuryDB.UpdateAssetPrices(ctx, 1, valuations); err != nil {
+		t.log.WithError(err).Error("failed to update asset prices")
+	}
+	return assetMap, valuations, nil
 }
 
-func (t *Tracker) processBalances(ctx context.Context, prices map[string]float64, assetMap map[string]types.Asset, wallet types.Wallet) error {
+// newWalletBalance values a balance using the asset's valuation. Assets without a price are still stored, at zero.
+func newWalletBalance(wallet, address string, balance *big.Float, assetValuation types.Valuation, ethPrice float64) types.WalletBalance {
+	var usdVal, ethVal float64
+	if assetValuation.UsdPrice.Valid {
+		usdVal, _ = big.NewFloat(0).Mul(balance, big.NewFloat(assetValuation.UsdPrice.Float64)).Float64()
+	}
+	if ethPrice != 0 {
+		ethVal = usdVal / ethPrice
+	}
+	return types.WalletBalance{
+		ChainID:         1,
+		Address:         address,
+		Wallet:          wallet,
+		Amount:          balance.String(),
+		EthWorth:        strconv.FormatFloat(ethVal, 'f', -1, 64),
+		UsdWorth:        usdVal,
+		LastUpdated:     time.Now(),
+		UsdPrice:        assetValuation.UsdPrice,
+		PriceSource:     assetValuation.Source,
+		PriceUpdatedAt:  assetValuation.UpdatedAt,
+		ValuationPolicy: assetValuation.Policy,
+	}
+}
+
+func (t *Tracker) processBalances(ctx context.Context, valuations map[string]types.Valuation, assetMap map[string]types.Asset, wallet types.Wallet) error {
 	balances, err := t.alchemy.TokenBalances(ctx, wallet.Address)
 	if err != nil {
 		return errors.Wrapf(err, "failed to get token balances for wallet %s", wallet.Address)
@@ -73,48 +144,121 @@ func (t *Tracker) processBalances(ctx context.Context, prices map[string]float64
 		return errors.Wrapf(err, "failed to get ether balance for wallet %s", wallet.Address)
 	}
 
+	tokens := make([]string, 0, len(balances))
+	for _, bal := range balances {
+		if bal.Balance.Sign() > 0 {
+			tokens = append(tokens, bal.Address)
+		}
+	}
+	walletPositions, positionTokens, err := t.processPositions(ctx, valuations, wallet, tokens)
+	if err != nil {
+		return errors.Wrapf(err, "failed to process positions for wallet %s", wallet.Address)
+	}
+
+	etherValuation := valuations[constants.EtherAddress]
+	ethPrice := etherValuation.UsdPrice.Float64
+
 	walletBalances := make([]types.WalletBalance, 0, len(balances)+1)
 	etherBalDec := ethutils.ToDecimal(etherBalance, 18)
-	etherUSDVal, _ := big.NewFloat(0).Mul(etherBalDec, big.NewFloat(prices[constants.EtherAddress])).Float64()
-	walletBalances = append(walletBalances, types.WalletBalance{
-		ChainID:     1,
-		Address:     constants.EtherAddress,
-		Wallet:      wallet.Address,
-		Amount:      etherBalDec.String(),
-		EthWorth:    etherBalDec.String(),
-		UsdWorth:    etherUSDVal,
-		LastUpdated: time.Now(),
-	})
+	etherWalletBalance := newWalletBalance(wallet.Address, constants.EtherAddress, etherBalDec, etherValuation, ethPrice)
+	etherWalletBalance.EthWorth = etherBalDec.String()
+	walletBalances = append(walletBalances, etherWalletBalance)
 
 	for _, bal := range balances {
+		if positionTokens[bal.Address] {
+			// Already counted through its underlying assets
+			continue
+		}
 		asset, ok := assetMap[bal.Address]
 		if !ok {
 			t.log.WithField("address", bal.Address).Info("unknown asset address, skipping")
 			// TODO: consider automatically adding new assets to the DB
 			continue
 		}
-		price, ok := prices[bal.Address]
-		if !ok {
-			t.log.WithField("address", bal.Address).Info("no price found for asset, skipping")
-			continue
+		assetValuation := valuations[bal.Address]
+		if !assetValuation.UsdPrice.Valid {
+			t.log.WithField("address", bal.Address).Warn("no price found for asset, storing it without a value")
 		}
 		balance := ethutils.ToDecimal(bal.Balance, asset.Decimals)
-		usdVal, _ := balance.Mul(balance, big.NewFloat(price)).Float64()
-		var ethVal float64 = 0
-		if prices[constants.EtherAddress] != 0 {
-			ethVal = usdVal / prices[constants.EtherAddress]
+		walletBalances = append(walletBalances, newWalletBalance(wallet.Address, bal.Address, balance, assetValuation, ethPrice))
+	}
+	err = t.treasuryDB.UpdateWalletBalances(ctx, wallet.Address, walletBalances)
+	if err != nil {
+		return err
+	}
+	return t.treasuryDB.UpdateWalletPositions(ctx, wallet.Address, walletPositions)
+}
+
+// processPositions reads the wallet's DeFi positions with each adapter and values them. It also returns the
+// tokens representing the positions, which are left out of the plain balances so they aren't counted twice.
+func (t *Tracker) processPositions(ctx context.Context, valuations map[string]types.Valuation, wallet types.Wallet, tokens []string) ([]types.Position, map[string]bool, error) {
+	holdings := []positions.Holding{}
+	for _, adapter := range t.adapters {
+		adapterHoldings, err := adapter.Positions(ctx, wallet.Address, tokens)
+		if err != nil {
+			// Don't fail the balance update when a protocol can't be read
+			t.log.WithError(err).WithFields(logrus.Fields{
+				"wallet":  wallet.Address,
+				"adapter": adapter.Name(),
+			}).Error("failed to get positions")
+			continue
 		}
-		walletBalances = append(walletBalances, types.WalletBalance{
-			ChainID:     1,
-			Address:     bal.Address,
-			Wallet:      wallet.Address,
-			Amount:      balance.String(),
-			EthWorth:    strconv.FormatFloat(ethVal, 'f', -1, 64),
-			UsdWorth:    usdVal,
-			LastUpdated: time.Now(),
-		})
+		holdings = append(holdings, adapterHoldings...)
 	}
-	return t.treasuryDB.UpdateWalletBalances(ctx, wallet.Address, walletBalances)
+
+	err := t.priceAssets(ctx, valuations, positions.UnderlyingAddresses(holdings))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	out := make([]types.Position, 0, len(holdings))
+	positionTokens := map[string]bool{}
+	for _, holding := range holdings {
+		out = append(out, positions.Value(wallet.Address, holding, valuations, now))
+		if holding.TokenAddress != "" {
+			positionTokens[holding.TokenAddress] = true
+		}
+	}
+	return out, positionTokens, nil
+}
+
+// priceAssets adds valuations for assets which don't have one yet, such as the underlying assets of positions
+func (t *Tracker) priceAssets(ctx context.Context, valuations map[string]types.Valuation, addresses []string) error {
+	missing := []string{}
+	for _, address := range addresses {
+		if _, ok := valuations[address]; !ok {
+			missing = append(missing, address)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	policies, err := t.treasuryDB.GetValuationPolicies(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get valuation policies")
+	}
+	policyMap := make(map[string]*types.AssetValuationPolicy, len(policies))
+	for i := range policies {
+		policyMap[policies[i].Address] = &policies[i]
+	}
+
+	prices, err := t.alchemy.GetTokenPrices(ctx, append(missing, valuation.PegAddresses(policies)...))
+	if err != nil {
+		return errors.Wrap(err, "failed to get token prices")
+	}
+	fetchedAt := time.Now()
+
+	added := make(map[string]types.Valuation, len(missing))
+	for _, address := range missing {
+		added[address] = valuation.Apply(policyMap[address], address, prices, fetchedAt)
+		valuations[address] = added[address]
+	}
+	if err := t.treasuryDB.UpdateAssetPrices(ctx, 1, added); err != nil {
+		t.log.WithError(err).Error("failed to update asset prices")
+	}
+	return nil
 }
 
 func (t *Tracker) fetchTransfers(ctx context.Context, wallet types.Wallet, fromBlock uint64, toBlock uint64) ([]types.CreateTransfer, error) {
@@ -153,6 +297,7 @@ func (t *Tracker) fetchTransfers(ctx context.Context, wallet types.Wallet, fromB
 	allTransfers := make([]types.CreateTransfer, 0, len(outgoing)+len(incoming))
 	for _, tr := range outgoing {
 		allTransfers = append(allTransfers, types.CreateTransfer{
+			ChainID:        1,
 			TxHash:         tr.TxHash,
 			Asset:          tr.AssetAddress,
 			FromAddress:    tr.FromAddress,
@@ -167,6 +312,7 @@ func (t *Tracker) fetchTransfers(ctx context.Context, wallet types.Wallet, fromB
 
 	for _, tr := range incoming {
 		allTransfers = append(allTransfers, types.CreateTransfer{
+			ChainID:        1,
 			TxHash:         tr.TxHash,
 			Asset:          tr.AssetAddress,
 			FromAddress:    tr.FromAddress,
@@ -215,7 +361,7 @@ func (t *Tracker) processTransfers(ctx context.Context, wallet types.Wallet) err
 }
 func (t *Tracker) walletUpdates(ctx context.Context) error {
 
-	assetMap, prices, err := t.GetAssets(ctx)
+	assetMap, valuations, err := t.GetAssets(ctx)
 	if err != nil {
 		return err
 	}
@@ -228,7 +374,7 @@ func (t *Tracker) walletUpdates(ctx context.Context) error {
 
 	for _, wallet := range wallets {
 		t.log.WithField("wallet", wallet.Address).Info("processing wallet")
-		err = t.processBalances(ctx, prices, assetMap, wallet)
+		err = t.processBalances(ctx, valuations, assetMap, wallet)
 		if err != nil {
 			return errors.Wrapf(err, "failed to process balances for wallet %s", wallet.Address)
 		}
@@ -237,13 +383,42 @@ func (t *Tracker) walletUpdates(ctx context.Context) error {
 		if err != nil {
 			return errors.Wrapf(err, "failed to process transfers for wallet %s", wallet.Address)
 		}
+
+		err = t.processApprovals(ctx, wallet)
+		if err != nil {
+			return errors.Wrapf(err, "failed to process approvals for wallet %s", wallet.Address)
+		}
 		t.log.WithField("wallet", wallet.Address).Info("finished processing wallet")
 	}
+
+	if err := t.scoreOutflows(ctx); err != nil {
+		t.log.WithError(err).Warn("failed to score outflows")
+	}
+
+	if err := t.matchDisbursements(ctx); err != nil {
+		t.log.WithError(err).Warn("failed to match disbursements")
+	}
+
+	if err := t.checkMilestoneDeadlines(ctx); err != nil {
+		t.log.WithError(err).Warn("failed to check milestone deadlines")
+	}
+
+	current, err := t.recordComposition(ctx)
+	if err != nil {
+		t.log.WithError(err).Warn("failed to record treasury composition")
+		return nil
+	}
+	if err := t.evaluatePolicies(ctx, current); err != nil {
+		t.log.WithError(err).Warn("failed to evaluate treasury policies")
+	}
 	return nil
 }
 
 func (t *Tracker) Start(ctx context.Context) {
 	t.log.Info("Starting transaction tracker...")
+	go t.startENSResolver(ctx)
+	go t.startExchangeSync(ctx)
+	go t.startReconciliation(ctx)
 	err := t.walletUpdates(ctx)
 	if err != nil {
 		t.log.WithError(err).Error("error during wallet updates")
@@ -0,0 +1,22 @@
+-- Add ENS names to transfer parties, kept separate from the admin set name
+
+BEGIN;
+
+ALTER TABLE "transfer_parties" ADD COLUMN "ens_name" VARCHAR(255);
+ALTER TABLE "transfer_parties" ADD COLUMN "ens_resolved_at" TIMESTAMPTZ;
+ALTER TABLE "transfer_parties" ALTER COLUMN "name" SET DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_transfer_parties_ens_resolved_at ON "transfer_parties" ("ens_resolved_at");
+
+COMMIT;
+---- create above / drop below ----
+
+BEGIN;
+
+DROP INDEX IF EXISTS idx_transfer_parties_ens_resolved_at;
+DELETE FROM "transfer_parties" WHERE "name" = '';
+ALTER TABLE "transfer_parties" ALTER COLUMN "name" DROP DEFAULT;
+ALTER TABLE "transfer_parties" DROP COLUMN IF EXISTS "ens_resolved_at";
+ALTER TABLE "transfer_parties" DROP COLUMN IF EXISTS "ens_name";
+
+COMMIT;
@@ -0,0 +1,50 @@
+-- Address labels from multiple sources, the source with the lowest priority value decides the label shown
+
+BEGIN;
+
+CREATE TABLE "label_sources" (
+    "name" VARCHAR(255) PRIMARY KEY, -- manual, ens or import:<name>
+    "priority" INTEGER NOT NULL DEFAULT 100,
+    "created_at" TIMESTAMPTZ DEFAULT NOW(),
+    "updated_at" TIMESTAMPTZ DEFAULT NOW()
+);
+
+INSERT INTO "label_sources" ("name", "priority") VALUES ('manual', 0), ('ens', 200);
+
+CREATE TABLE "transfer_party_labels" (
+    "address" ETH_ADDR_T NOT NULL,
+    "source" VARCHAR(255) NOT NULL REFERENCES "label_sources" ("name") ON DELETE CASCADE,
+    "label" VARCHAR(255) NOT NULL,
+    "created_at" TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY ("address", "source")
+);
+
+CREATE INDEX IF NOT EXISTS idx_transfer_party_labels_source ON "transfer_party_labels" ("source");
+
+-- Admin set names and ENS names stay on transfer_parties, this combines them with the imported labels
+CREATE VIEW "party_labels" AS
+    SELECT "address", 'manual' AS "source", "name" AS "label", "updated_at" AS "created_at"
+        FROM "transfer_parties" WHERE "name" <> ''
+    UNION ALL
+    SELECT "address", 'ens' AS "source", "ens_name" AS "label", "ens_resolved_at" AS "created_at"
+        FROM "transfer_parties" WHERE "ens_name" IS NOT NULL
+    UNION ALL
+    SELECT "address", "source", "label", "created_at" FROM "transfer_party_labels";
+
+CREATE VIEW "party_display_labels" AS
+    SELECT DISTINCT ON (l."address") l."address", l."label", l."source"
+    FROM "party_labels" l
+        JOIN "label_sources" s ON (l."source" = s."name")
+    ORDER BY l."address", s."priority", l."source";
+
+COMMIT;
+---- create above / drop below ----
+
+BEGIN;
+
+DROP VIEW IF EXISTS "party_display_labels";
+DROP VIEW IF EXISTS "party_labels";
+DROP TABLE IF EXISTS "transfer_party_labels";
+DROP TABLE IF EXISTS "label_sources";
+
+COMMIT;
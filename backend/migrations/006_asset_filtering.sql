@@ -0,0 +1,41 @@
+-- Asset statuses and cached prices, used to hide spam and dust from balances and transfers
+
+BEGIN;
+
+CREATE TYPE ASSET_STATUS_T AS ENUM ('trusted', 'hidden', 'spam');
+
+-- Set by admins, assets without a status are checked by the spam heuristics
+CREATE TABLE "asset_statuses" (
+    "chain_id" BIGINT NOT NULL DEFAULT 1,
+    "address" ETH_ADDR_T NOT NULL,
+    "status" ASSET_STATUS_T NOT NULL,
+    "reason" TEXT,
+    "updated_at" TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY ("chain_id", "address")
+);
+
+INSERT INTO "asset_statuses" ("chain_id", "address", "status", "reason") VALUES
+(1, '0xeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee', 'trusted', 'Native asset');
+
+-- Latest USD price for each asset, updated by the tracker
+CREATE TABLE "asset_prices" (
+    "chain_id" BIGINT NOT NULL DEFAULT 1,
+    "address" ETH_ADDR_T NOT NULL,
+    "usd_price" DOUBLE PRECISION NOT NULL,
+    "updated_at" TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY ("chain_id", "address")
+);
+
+CREATE INDEX IF NOT EXISTS idx_transfers_asset_direction ON "transfers" ("chain_id", "asset", "direction");
+
+COMMIT;
+---- create above / drop below ----
+
+BEGIN;
+
+DROP INDEX IF EXISTS idx_transfers_asset_direction;
+DROP TABLE IF EXISTS "asset_prices";
+DROP TABLE IF EXISTS "asset_statuses";
+DROP TYPE IF EXISTS ASSET_STATUS_T;
+
+COMMIT;
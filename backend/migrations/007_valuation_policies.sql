@@ -0,0 +1,46 @@
+-- Per asset valuation policies, and the price used for each wallet balance
+
+BEGIN;
+
+CREATE TYPE VALUATION_POLICY_T AS ENUM ('market', 'manual', 'pegged', 'discount', 'excluded');
+
+-- Set by admins, assets without a policy are valued at the market price
+CREATE TABLE "valuation_policies" (
+    "chain_id" BIGINT NOT NULL DEFAULT 1,
+    "address" ETH_ADDR_T NOT NULL,
+    "policy" VALUATION_POLICY_T NOT NULL,
+    "manual_price" DOUBLE PRECISION CHECK ("manual_price" >= 0),
+    "peg_address" ETH_ADDR_T, -- Pegged to $1 when not set
+    "discount_percent" DOUBLE PRECISION CHECK ("discount_percent" > 0 AND "discount_percent" < 100),
+    "notes" TEXT,
+    "updated_at" TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY ("chain_id", "address"),
+    CHECK ("policy" <> 'manual' OR "manual_price" IS NOT NULL),
+    CHECK ("policy" <> 'discount' OR "discount_percent" IS NOT NULL)
+);
+
+ALTER TABLE "wallet_balances"
+    ADD COLUMN "usd_price" DOUBLE PRECISION,
+    ADD COLUMN "price_source" TEXT,
+    ADD COLUMN "price_updated_at" TIMESTAMPTZ,
+    ADD COLUMN "valuation_policy" VALUATION_POLICY_T NOT NULL DEFAULT 'market';
+
+ALTER TABLE "asset_prices" ADD COLUMN "source" TEXT NOT NULL DEFAULT 'market';
+
+COMMIT;
+---- create above / drop below ----
+
+BEGIN;
+
+ALTER TABLE "asset_prices" DROP COLUMN IF EXISTS "source";
+
+ALTER TABLE "wallet_balances"
+    DROP COLUMN IF EXISTS "usd_price",
+    DROP COLUMN IF EXISTS "price_source",
+    DROP COLUMN IF EXISTS "price_updated_at",
+    DROP COLUMN IF EXISTS "valuation_policy";
+
+DROP TABLE IF EXISTS "valuation_policies";
+DROP TYPE IF EXISTS VALUATION_POLICY_T;
+
+COMMIT;
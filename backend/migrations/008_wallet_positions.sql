@@ -0,0 +1,25 @@
+-- DeFi positions held by treasury wallets, broken down into their underlying assets
+
+BEGIN;
+
+CREATE TABLE "wallet_positions" (
+    "chain_id" BIGINT NOT NULL DEFAULT 1,
+    "wallet" ETH_ADDR_T NOT NULL REFERENCES "wallets" ("address") ON DELETE CASCADE,
+    "protocol" VARCHAR(64) NOT NULL,
+    "position_id" VARCHAR(128) NOT NULL,
+    "name" VARCHAR(255) NOT NULL,
+    "token_address" ETH_ADDR_T,
+    "underlying" JSONB NOT NULL DEFAULT '[]',
+    "usd_worth" FIAT_T NOT NULL,
+    "last_updated" TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY ("chain_id", "wallet", "protocol", "position_id")
+);
+
+COMMIT;
+---- create above / drop below ----
+
+BEGIN;
+
+DROP TABLE IF EXISTS "wallet_positions";
+
+COMMIT;
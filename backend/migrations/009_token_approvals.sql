@@ -0,0 +1,30 @@
+-- ERC-20 allowances granted by treasury wallets, from Approval events
+
+BEGIN;
+
+CREATE TABLE "token_approvals" (
+    "chain_id" BIGINT NOT NULL DEFAULT 1,
+    "owner" ETH_ADDR_T NOT NULL,
+    "token" ETH_ADDR_T NOT NULL,
+    "spender" ETH_ADDR_T NOT NULL,
+    "approved_amount" NUMERIC(78, 0) NOT NULL, -- Amount in the latest Approval event, up to the max uint256
+    "allowance" NUMERIC(78, 0) NOT NULL, -- Current allowance, lower than the approved amount once spent
+    "block_number" BIGINT NOT NULL,
+    "block_timestamp" TIMESTAMPTZ NOT NULL,
+    "tx_hash" ETH_HASH_T NOT NULL,
+    "log_index" INT NOT NULL,
+    "updated_at" TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY ("chain_id", "owner", "token", "spender")
+);
+
+CREATE INDEX IF NOT EXISTS idx_token_approvals_active ON "token_approvals" ("owner") WHERE "allowance" > 0;
+
+COMMIT;
+---- create above / drop below ----
+
+BEGIN;
+
+DROP INDEX IF EXISTS idx_token_approvals_active;
+DROP TABLE IF EXISTS "token_approvals";
+
+COMMIT;
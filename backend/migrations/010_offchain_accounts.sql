@@ -0,0 +1,49 @@
+-- Bank and custodial accounts, with balances entered by admins from their statements
+
+BEGIN;
+
+CREATE TABLE "offchain_accounts" (
+    "id" UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    "name" VARCHAR(255) NOT NULL,
+    "institution" VARCHAR(255) NOT NULL,
+    "currency" VARCHAR(16) NOT NULL, -- ISO 4217 code or asset symbol
+    "notes" TEXT DEFAULT NULL,
+    "created_at" TIMESTAMPTZ DEFAULT NOW(),
+    "updated_at" TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE TABLE "offchain_statements" (
+    "id" UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    "account_id" UUID NOT NULL REFERENCES "offchain_accounts" ("id") ON DELETE CASCADE,
+    "statement_date" DATE NOT NULL,
+    "balance" DECIMAL(36,18) NOT NULL, -- In the account's currency
+    "usd_value" FIAT_T NOT NULL,
+    "notes" TEXT DEFAULT NULL,
+    "created_by" ETH_ADDR_T NOT NULL,
+    "created_at" TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE ("account_id", "statement_date")
+);
+
+CREATE TABLE "offchain_documents" (
+    "id" UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    "statement_id" UUID NOT NULL REFERENCES "offchain_statements" ("id") ON DELETE CASCADE,
+    "name" VARCHAR(255) NOT NULL,
+    "file_name" VARCHAR(255) DEFAULT NULL,
+    "file_size" BIGINT NOT NULL,
+    "mime_type" VARCHAR(100) DEFAULT NULL,
+    "storage_id" VARCHAR(255) NOT NULL,
+    "created_at" TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_offchain_documents_statement_id ON "offchain_documents" ("statement_id");
+
+COMMIT;
+---- create above / drop below ----
+
+BEGIN;
+
+DROP TABLE IF EXISTS "offchain_documents";
+DROP TABLE IF EXISTS "offchain_statements";
+DROP TABLE IF EXISTS "offchain_accounts";
+
+COMMIT;
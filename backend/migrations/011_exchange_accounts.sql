@@ -0,0 +1,45 @@
+-- Balances and deposits/withdrawals read from custodial exchange accounts
+
+BEGIN;
+
+CREATE TYPE EXCHANGE_MOVEMENT_DIRECTION_T AS ENUM ('deposit', 'withdrawal');
+
+CREATE TABLE "exchange_balances" (
+    "exchange" VARCHAR(64) NOT NULL,
+    "currency" VARCHAR(16) NOT NULL,
+    "amount" DECIMAL(36,18) NOT NULL,
+    "usd_value" FIAT_T DEFAULT NULL, -- Null when the exchange doesn't report a USD value
+    "last_updated" TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY ("exchange", "currency")
+);
+
+CREATE TABLE "exchange_movements" (
+    "exchange" VARCHAR(64) NOT NULL,
+    "external_id" VARCHAR(255) NOT NULL,
+    "direction" EXCHANGE_MOVEMENT_DIRECTION_T NOT NULL,
+    "currency" VARCHAR(16) NOT NULL,
+    "amount" DECIMAL(36,18) NOT NULL,
+    "status" VARCHAR(32) NOT NULL,
+    "tx_hash" ETH_HASH_T DEFAULT NULL,
+    "address" VARCHAR(255) DEFAULT NULL, -- Counterparty address, not always an Ethereum address
+    "occurred_at" TIMESTAMPTZ NOT NULL,
+    "updated_at" TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY ("exchange", "external_id")
+);
+
+CREATE INDEX idx_exchange_movements_occurred_at ON "exchange_movements" ("occurred_at" DESC);
+
+-- Movements are matched to transfers by tx hash
+CREATE INDEX IF NOT EXISTS idx_transfers_tx_hash ON "transfers" ("tx_hash");
+
+COMMIT;
+---- create above / drop below ----
+
+BEGIN;
+
+DROP INDEX IF EXISTS idx_transfers_tx_hash;
+DROP TABLE IF EXISTS "exchange_movements";
+DROP TABLE IF EXISTS "exchange_balances";
+DROP TYPE IF EXISTS EXCHANGE_MOVEMENT_DIRECTION_T;
+
+COMMIT;
@@ -0,0 +1,44 @@
+-- Wallet metadata and groups, so the treasury can be viewed per purpose
+
+BEGIN;
+
+CREATE TYPE WALLET_TYPE_T AS ENUM ('eoa', 'safe', 'contract');
+
+ALTER TABLE "wallets"
+    ADD COLUMN "chain_id" BIGINT NOT NULL DEFAULT 1,
+    ADD COLUMN "name" VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN "description" TEXT DEFAULT NULL,
+    ADD COLUMN "wallet_type" WALLET_TYPE_T DEFAULT NULL; -- Null when unknown
+
+CREATE TABLE "wallet_groups" (
+    "id" UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    "name" VARCHAR(255) NOT NULL UNIQUE,
+    "description" TEXT DEFAULT NULL,
+    "created_at" TIMESTAMPTZ DEFAULT NOW(),
+    "updated_at" TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- A wallet can belong to any number of groups
+CREATE TABLE "wallet_group_members" (
+    "group_id" UUID NOT NULL REFERENCES "wallet_groups" ("id") ON DELETE CASCADE,
+    "wallet" ETH_ADDR_T NOT NULL REFERENCES "wallets" ("address") ON DELETE CASCADE,
+    PRIMARY KEY ("group_id", "wallet")
+);
+
+CREATE INDEX idx_wallet_group_members_wallet ON "wallet_group_members" ("wallet");
+
+COMMIT;
+---- create above / drop below ----
+
+BEGIN;
+
+DROP TABLE IF EXISTS "wallet_group_members";
+DROP TABLE IF EXISTS "wallet_groups";
+ALTER TABLE "wallets"
+    DROP COLUMN IF EXISTS "wallet_type",
+    DROP COLUMN IF EXISTS "description",
+    DROP COLUMN IF EXISTS "name",
+    DROP COLUMN IF EXISTS "chain_id";
+DROP TYPE IF EXISTS WALLET_TYPE_T;
+
+COMMIT;
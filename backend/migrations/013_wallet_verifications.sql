@@ -0,0 +1,32 @@
+-- Proof that treasury wallets are controlled by the organization, signed over an EIP-712 challenge
+
+BEGIN;
+
+CREATE TYPE WALLET_PROOF_METHOD_T AS ENUM ('ecdsa', 'eip1271');
+
+-- At most one open challenge per wallet, issuing a new one replaces it
+CREATE TABLE "wallet_challenges" (
+    "wallet" ETH_ADDR_T PRIMARY KEY REFERENCES "wallets" ("address") ON DELETE CASCADE,
+    "nonce" UUID NOT NULL,
+    "issued_at" TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE "wallet_verifications" (
+    "wallet" ETH_ADDR_T PRIMARY KEY REFERENCES "wallets" ("address") ON DELETE CASCADE,
+    "method" WALLET_PROOF_METHOD_T NOT NULL,
+    "challenge_hash" ETH_HASH_T NOT NULL,
+    "signature" TEXT NOT NULL,
+    "verified_by" ETH_ADDR_T NOT NULL,
+    "verified_at" TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMIT;
+---- create above / drop below ----
+
+BEGIN;
+
+DROP TABLE IF EXISTS "wallet_verifications";
+DROP TABLE IF EXISTS "wallet_challenges";
+DROP TYPE IF EXISTS WALLET_PROOF_METHOD_T;
+
+COMMIT;
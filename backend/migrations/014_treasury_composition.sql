@@ -0,0 +1,36 @@
+-- Asset classes and daily composition snapshots for the portfolio view
+
+BEGIN;
+
+CREATE TYPE ASSET_CLASS_T AS ENUM ('native', 'stablecoin', 'governance', 'lp', 'other');
+
+ALTER TABLE "assets" ADD COLUMN "asset_class" ASSET_CLASS_T NOT NULL DEFAULT 'other';
+
+UPDATE "assets" SET "asset_class" = 'native' WHERE "chain_id" = 1 AND "address" IN (
+    '0xeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee', -- ETH
+    '0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2'  -- WETH
+);
+
+UPDATE "assets" SET "asset_class" = 'stablecoin' WHERE "chain_id" = 1 AND "address" IN (
+    '0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48', -- USDC
+    '0xdac17f958d2ee523a2206206994597c13d831ec7', -- USDT
+    '0x6b175474e89094c44da98b954eedeac495271d0f'  -- DAI
+);
+
+CREATE TABLE "composition_snapshots" (
+    "snapshot_date" DATE PRIMARY KEY,
+    "total_value_usd" FIAT_T NOT NULL,
+    "composition" JSONB NOT NULL,
+    "created_at" TIMESTAMPTZ DEFAULT NOW()
+);
+
+COMMIT;
+---- create above / drop below ----
+
+BEGIN;
+
+DROP TABLE IF EXISTS "composition_snapshots";
+ALTER TABLE "assets" DROP COLUMN IF EXISTS "asset_class";
+DROP TYPE IF EXISTS ASSET_CLASS_T;
+
+COMMIT;
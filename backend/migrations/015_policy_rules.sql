@@ -0,0 +1,56 @@
+-- Treasury policy rules set by governance, evaluated by the tracker after every balance refresh
+
+BEGIN;
+
+CREATE TYPE POLICY_METRIC_T AS ENUM ('runway_months', 'class_percent', 'asset_percent', 'total_value_usd', 'single_outflow_usd');
+CREATE TYPE POLICY_COMPARISON_T AS ENUM ('min', 'max');
+
+CREATE TABLE "policy_rules" (
+    "id" UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    "name" VARCHAR(255) NOT NULL,
+    "description" TEXT DEFAULT NULL,
+    "metric" POLICY_METRIC_T NOT NULL,
+    "comparison" POLICY_COMPARISON_T NOT NULL,
+    "threshold" DOUBLE PRECISION NOT NULL,
+    "asset_class" ASSET_CLASS_T DEFAULT NULL, -- The class a class or runway metric is about
+    "asset" ETH_ADDR_T DEFAULT NULL, -- The asset an asset metric is about, the largest non-stable asset when null
+    "window_days" INTEGER NOT NULL DEFAULT 90 CHECK ("window_days" > 0), -- Lookback for outflow based metrics
+    "enabled" BOOLEAN NOT NULL DEFAULT TRUE,
+    "violated" BOOLEAN NOT NULL DEFAULT FALSE,
+    "last_value" DOUBLE PRECISION DEFAULT NULL,
+    "last_evaluated_at" TIMESTAMPTZ DEFAULT NULL,
+    "created_by" ETH_ADDR_T NOT NULL,
+    "created_at" TIMESTAMPTZ DEFAULT NOW(),
+    "updated_at" TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- Violations outlive their rule, so the rule is copied into them
+CREATE TABLE "policy_violations" (
+    "id" UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    "rule_id" UUID REFERENCES "policy_rules" ("id") ON DELETE SET NULL,
+    "rule_name" VARCHAR(255) NOT NULL,
+    "metric" POLICY_METRIC_T NOT NULL,
+    "comparison" POLICY_COMPARISON_T NOT NULL,
+    "threshold" DOUBLE PRECISION NOT NULL,
+    "first_value" DOUBLE PRECISION NOT NULL,
+    "worst_value" DOUBLE PRECISION NOT NULL,
+    "last_value" DOUBLE PRECISION NOT NULL,
+    "started_at" TIMESTAMPTZ NOT NULL,
+    "last_seen_at" TIMESTAMPTZ NOT NULL,
+    "resolved_at" TIMESTAMPTZ DEFAULT NULL
+);
+
+CREATE UNIQUE INDEX idx_policy_violations_open ON "policy_violations" ("rule_id") WHERE "resolved_at" IS NULL;
+CREATE INDEX idx_policy_violations_started_at ON "policy_violations" ("started_at" DESC);
+
+COMMIT;
+---- create above / drop below ----
+
+BEGIN;
+
+DROP TABLE IF EXISTS "policy_violations";
+DROP TABLE IF EXISTS "policy_rules";
+DROP TYPE IF EXISTS POLICY_COMPARISON_T;
+DROP TYPE IF EXISTS POLICY_METRIC_T;
+
+COMMIT;
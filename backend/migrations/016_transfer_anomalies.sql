@@ -0,0 +1,40 @@
+-- Unusual outgoing transfers flagged by the tracker for admins to review
+
+BEGIN;
+
+-- Transfers already ingested are history, only the ones added from now on are scored
+ALTER TABLE "transfers" ADD COLUMN "anomaly_checked" BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE "transfers" ALTER COLUMN "anomaly_checked" SET DEFAULT FALSE;
+CREATE INDEX IF NOT EXISTS idx_transfers_anomaly_unchecked ON "transfers" ("block_timestamp") WHERE NOT "anomaly_checked" AND "direction" = 'outgoing';
+
+CREATE TYPE ANOMALY_KIND_T AS ENUM ('large_outflow', 'new_counterparty', 'unusual_hour');
+CREATE TYPE ANOMALY_STATUS_T AS ENUM ('open', 'acknowledged', 'dismissed');
+
+CREATE TABLE "transfer_anomalies" (
+    "id" UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    "transfer_id" UUID NOT NULL REFERENCES "transfers" ("id") ON DELETE CASCADE,
+    "kind" ANOMALY_KIND_T NOT NULL,
+    "score" DOUBLE PRECISION NOT NULL, -- From 0 to 1, how unusual the transfer is
+    "reason" TEXT NOT NULL,
+    "status" ANOMALY_STATUS_T NOT NULL DEFAULT 'open',
+    "note" TEXT DEFAULT NULL,
+    "reviewed_by" ETH_ADDR_T DEFAULT NULL,
+    "reviewed_at" TIMESTAMPTZ DEFAULT NULL,
+    "created_at" TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE ("transfer_id", "kind")
+);
+
+CREATE INDEX IF NOT EXISTS idx_transfer_anomalies_status ON "transfer_anomalies" ("status", "created_at" DESC);
+
+COMMIT;
+---- create above / drop below ----
+
+BEGIN;
+
+DROP TABLE IF EXISTS "transfer_anomalies";
+DROP TYPE IF EXISTS ANOMALY_STATUS_T;
+DROP TYPE IF EXISTS ANOMALY_KIND_T;
+DROP INDEX IF EXISTS idx_transfers_anomaly_unchecked;
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "anomaly_checked";
+
+COMMIT;
@@ -0,0 +1,57 @@
+-- Reconciliation of the ingested transfers against on-chain balances
+
+BEGIN;
+
+-- Gas paid by treasury wallets, from the receipts of the transactions behind their transfers
+CREATE TABLE "transaction_fees" (
+    "chain_id" BIGINT NOT NULL DEFAULT 1,
+    "tx_hash" ETH_HASH_T NOT NULL,
+    "payer" ETH_ADDR_T NOT NULL, -- The account which sent the transaction and paid for its gas
+    "fee" NUMERIC(78, 0) NOT NULL, -- In wei
+    "block_number" BIGINT NOT NULL,
+    PRIMARY KEY ("chain_id", "tx_hash")
+);
+
+CREATE INDEX IF NOT EXISTS idx_transaction_fees_payer ON "transaction_fees" ("payer", "block_number");
+
+-- The balance a wallet held before its first ingested transfer, for wallets whose history isn't ingested from genesis
+CREATE TABLE "reconciliation_starting_balances" (
+    "chain_id" BIGINT NOT NULL DEFAULT 1,
+    "wallet" ETH_ADDR_T NOT NULL REFERENCES "wallets" ("address") ON DELETE CASCADE,
+    "asset" ETH_ADDR_T NOT NULL,
+    "amount" NUMERIC(78, 0) NOT NULL, -- In raw units
+    "block_number" BIGINT NOT NULL, -- Transfers up to this block are included in the amount
+    "set_by" ETH_ADDR_T NOT NULL,
+    "updated_at" TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY ("chain_id", "wallet", "asset")
+);
+
+-- The latest reconciliation of each wallet and asset, amounts are in raw units
+CREATE TABLE "reconciliation_results" (
+    "chain_id" BIGINT NOT NULL DEFAULT 1,
+    "wallet" ETH_ADDR_T NOT NULL REFERENCES "wallets" ("address") ON DELETE CASCADE,
+    "asset" ETH_ADDR_T NOT NULL,
+    "checkpoint_block" BIGINT NOT NULL,
+    "starting_balance" NUMERIC(78, 0) NOT NULL,
+    "transfers_in" NUMERIC(78, 0) NOT NULL,
+    "transfers_out" NUMERIC(78, 0) NOT NULL,
+    "fees" NUMERIC(78, 0) NOT NULL,
+    "expected_balance" NUMERIC(78, 0) NOT NULL,
+    "onchain_balance" NUMERIC(78, 0) NOT NULL,
+    "difference" NUMERIC(78, 0) NOT NULL, -- On-chain balance minus the expected balance
+    "reconciled" BOOLEAN NOT NULL,
+    "checked_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY ("chain_id", "wallet", "asset")
+);
+
+COMMIT;
+---- create above / drop below ----
+
+BEGIN;
+
+DROP TABLE IF EXISTS "reconciliation_results";
+DROP TABLE IF EXISTS "reconciliation_starting_balances";
+DROP INDEX IF EXISTS idx_transaction_fees_payer;
+DROP TABLE IF EXISTS "transaction_fees";
+
+COMMIT;
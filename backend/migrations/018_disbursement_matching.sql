@@ -0,0 +1,46 @@
+-- Outgoing transfers matched to grant disbursements by the tracker
+
+BEGIN;
+
+-- Other addresses grant recipients are paid at, besides their recipient address
+CREATE TABLE "grant_addresses" (
+    "grant_id" UUID NOT NULL REFERENCES "grants" ("id") ON DELETE CASCADE,
+    "address" ETH_ADDR_T NOT NULL,
+    "label" TEXT DEFAULT NULL,
+    "created_at" TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY ("grant_id", "address")
+);
+
+CREATE INDEX IF NOT EXISTS idx_grant_addresses_address ON "grant_addresses" ("address");
+
+-- The transfer a disbursement was paid with, a split transfer backs several disbursements
+ALTER TABLE "disbursements" ADD COLUMN "transfer_id" UUID DEFAULT NULL REFERENCES "transfers" ("id") ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_disbursements_transfer_id ON "disbursements" ("transfer_id");
+
+CREATE TYPE DISBURSEMENT_MATCH_STATUS_T AS ENUM ('proposed', 'confirmed', 'rejected');
+
+CREATE TABLE "disbursement_matches" (
+    "id" UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    "transfer_id" UUID NOT NULL REFERENCES "transfers" ("id") ON DELETE CASCADE,
+    "grant_id" UUID NOT NULL REFERENCES "grants" ("id") ON DELETE CASCADE,
+    "status" DISBURSEMENT_MATCH_STATUS_T NOT NULL DEFAULT 'proposed',
+    "reviewed_by" ETH_ADDR_T DEFAULT NULL, -- Null when confirmed by the tracker
+    "reviewed_at" TIMESTAMPTZ DEFAULT NULL,
+    "created_at" TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE ("transfer_id", "grant_id")
+);
+
+CREATE INDEX IF NOT EXISTS idx_disbursement_matches_status ON "disbursement_matches" ("status", "created_at" DESC);
+
+COMMIT;
+---- create above / drop below ----
+
+BEGIN;
+
+DROP TABLE IF EXISTS "disbursement_matches";
+DROP TYPE IF EXISTS DISBURSEMENT_MATCH_STATUS_T;
+DROP INDEX IF EXISTS idx_disbursements_transfer_id;
+ALTER TABLE "disbursements" DROP COLUMN IF EXISTS "transfer_id";
+DROP TABLE IF EXISTS "grant_addresses";
+
+COMMIT;
@@ -0,0 +1,29 @@
+-- Disbursements checked against the transaction they were paid with
+
+BEGIN;
+
+CREATE TYPE DISBURSEMENT_VERIFICATION_T AS ENUM ('unverified', 'verified', 'mismatch');
+
+-- Grants have only been paid in ether so far
+ALTER TABLE "disbursements" ADD COLUMN "asset" ETH_ADDR_T NOT NULL DEFAULT '0xeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee';
+ALTER TABLE "disbursements" ADD COLUMN "verification_status" DISBURSEMENT_VERIFICATION_T NOT NULL DEFAULT 'unverified';
+ALTER TABLE "disbursements" ADD COLUMN "verification_reason" TEXT DEFAULT NULL; -- Why the transaction doesn't match, or couldn't be checked
+ALTER TABLE "disbursements" ADD COLUMN "verified_at" TIMESTAMPTZ DEFAULT NULL;
+
+-- Disbursements created from a matched transfer were read from the chain
+UPDATE "disbursements" d SET "verification_status" = 'verified', "verified_at" = NOW()
+WHERE EXISTS (SELECT 1 FROM "disbursement_matches" m
+    WHERE m."transfer_id" = d."transfer_id" AND m."grant_id" = d."grant_id" AND m."status" = 'confirmed');
+
+COMMIT;
+---- create above / drop below ----
+
+BEGIN;
+
+ALTER TABLE "disbursements" DROP COLUMN IF EXISTS "verified_at";
+ALTER TABLE "disbursements" DROP COLUMN IF EXISTS "verification_reason";
+ALTER TABLE "disbursements" DROP COLUMN IF EXISTS "verification_status";
+ALTER TABLE "disbursements" DROP COLUMN IF EXISTS "asset";
+DROP TYPE IF EXISTS DISBURSEMENT_VERIFICATION_T;
+
+COMMIT;
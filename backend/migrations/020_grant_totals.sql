@@ -0,0 +1,12 @@
+-- The amount given to a grant is kept in sync with its disbursements, resync what drifted
+
+BEGIN;
+
+UPDATE "grants" g SET "amount_given_so_far" = COALESCE((
+    SELECT SUM(d."amount") FROM "disbursements" d
+    WHERE d."grant_id" = g."id" AND d."asset" = '0xeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee'), 0);
+
+COMMIT;
+---- create above / drop below ----
+
+-- The previous amounts can't be recovered
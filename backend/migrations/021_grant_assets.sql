@@ -0,0 +1,45 @@
+-- Grants, milestones and disbursements in any asset, disbursements valued in USD when they are recorded
+
+BEGIN;
+
+-- Grants are paid on mainnet, the chain only completes the reference to the asset. Amounts stay ETHER_T, which holds
+-- any asset with up to 18 decimals.
+ALTER TABLE "grants" ADD COLUMN "chain_id" BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE "grants" ADD COLUMN "asset" ETH_ADDR_T NOT NULL DEFAULT '0xeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee';
+ALTER TABLE "grants" ADD CONSTRAINT "grants_asset_fkey"
+    FOREIGN KEY ("chain_id", "asset") REFERENCES "assets" ("chain_id", "address");
+
+ALTER TABLE "milestones" ADD COLUMN "chain_id" BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE "milestones" ADD COLUMN "asset" ETH_ADDR_T NOT NULL DEFAULT '0xeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee';
+ALTER TABLE "milestones" ADD CONSTRAINT "milestones_asset_fkey"
+    FOREIGN KEY ("chain_id", "asset") REFERENCES "assets" ("chain_id", "address");
+
+ALTER TABLE "disbursements" ADD COLUMN "chain_id" BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE "disbursements" ADD CONSTRAINT "disbursements_asset_fkey"
+    FOREIGN KEY ("chain_id", "asset") REFERENCES "assets" ("chain_id", "address");
+
+-- USD price of the asset when the disbursement was recorded, null for disbursements recorded before prices were kept
+ALTER TABLE "disbursements" ADD COLUMN "usd_price" DOUBLE PRECISION CHECK ("usd_price" >= 0);
+
+CREATE INDEX IF NOT EXISTS idx_disbursements_grant_asset ON "disbursements" ("grant_id", "asset");
+
+COMMIT;
+---- create above / drop below ----
+
+BEGIN;
+
+DROP INDEX IF EXISTS idx_disbursements_grant_asset;
+
+ALTER TABLE "disbursements" DROP COLUMN IF EXISTS "usd_price";
+ALTER TABLE "disbursements" DROP CONSTRAINT IF EXISTS "disbursements_asset_fkey";
+ALTER TABLE "disbursements" DROP COLUMN IF EXISTS "chain_id";
+
+ALTER TABLE "milestones" DROP CONSTRAINT IF EXISTS "milestones_asset_fkey";
+ALTER TABLE "milestones" DROP COLUMN IF EXISTS "asset";
+ALTER TABLE "milestones" DROP COLUMN IF EXISTS "chain_id";
+
+ALTER TABLE "grants" DROP CONSTRAINT IF EXISTS "grants_asset_fkey";
+ALTER TABLE "grants" DROP COLUMN IF EXISTS "asset";
+ALTER TABLE "grants" DROP COLUMN IF EXISTS "chain_id";
+
+COMMIT;
@@ -0,0 +1,32 @@
+-- Indexes for searching, filtering and sorting grants
+
+BEGIN;
+
+-- Trigram indexes serve the case insensitive substring search on names and descriptions
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_grants_name_trgm ON "grants" USING GIN ("name" gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_grants_description_trgm ON "grants" USING GIN ("description" gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS idx_grants_status ON "grants" ("status");
+CREATE INDEX IF NOT EXISTS idx_grants_recipient_address ON "grants" ("recipient_address");
+CREATE INDEX IF NOT EXISTS idx_grants_start_date ON "grants" ("start_date", "id");
+CREATE INDEX IF NOT EXISTS idx_grants_expected_completion_date ON "grants" ("expected_completion_date", "id");
+CREATE INDEX IF NOT EXISTS idx_grants_total_grant_amount ON "grants" ("total_grant_amount", "id");
+CREATE INDEX IF NOT EXISTS idx_grants_created_at ON "grants" ("created_at", "id");
+
+COMMIT;
+---- create above / drop below ----
+
+BEGIN;
+
+DROP INDEX IF EXISTS idx_grants_created_at;
+DROP INDEX IF EXISTS idx_grants_total_grant_amount;
+DROP INDEX IF EXISTS idx_grants_expected_completion_date;
+DROP INDEX IF EXISTS idx_grants_start_date;
+DROP INDEX IF EXISTS idx_grants_recipient_address;
+DROP INDEX IF EXISTS idx_grants_status;
+DROP INDEX IF EXISTS idx_grants_description_trgm;
+DROP INDEX IF EXISTS idx_grants_name_trgm;
+
+COMMIT;
@@ -0,0 +1,35 @@
+-- Grant statuses follow a lifecycle, transitions are recorded in the admin actions
+
+BEGIN;
+
+CREATE TYPE GRANT_STATUS_T AS ENUM ('proposed', 'approved', 'active', 'paused', 'completed', 'cancelled', 'clawed_back');
+
+-- Previous grants were over, anything else was running
+ALTER TABLE "grants" ALTER COLUMN "status" TYPE GRANT_STATUS_T USING (
+    CASE
+        WHEN "status" = 'previous' THEN 'completed'
+        WHEN "status" IN ('proposed', 'approved', 'active', 'paused', 'completed', 'cancelled', 'clawed_back') THEN "status"
+        ELSE 'active'
+    END
+)::GRANT_STATUS_T;
+ALTER TABLE "grants" ALTER COLUMN "status" SET DEFAULT 'proposed';
+ALTER TABLE "grants" ALTER COLUMN "status" SET NOT NULL;
+
+-- Timelines of grants and other resources
+CREATE INDEX IF NOT EXISTS idx_admin_actions_resource ON "admin_actions" ("resource_type", "resource_id", "created_at");
+
+COMMIT;
+---- create above / drop below ----
+
+BEGIN;
+
+DROP INDEX IF EXISTS idx_admin_actions_resource;
+
+ALTER TABLE "grants" ALTER COLUMN "status" DROP NOT NULL;
+ALTER TABLE "grants" ALTER COLUMN "status" DROP DEFAULT;
+ALTER TABLE "grants" ALTER COLUMN "status" TYPE TEXT USING (
+    CASE WHEN "status" IN ('completed', 'cancelled', 'clawed_back') THEN 'previous' ELSE 'active' END
+);
+DROP TYPE IF EXISTS GRANT_STATUS_T;
+
+COMMIT;
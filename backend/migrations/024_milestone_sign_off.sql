@@ -0,0 +1,67 @@
+-- Milestones are completed with evidence and signed off once enough admins approve them
+
+BEGIN;
+
+CREATE TYPE MILESTONE_STATUS_T AS ENUM ('pending', 'completed', 'signed_off');
+
+-- The booleans were set independently of the status, the furthest one wins
+ALTER TABLE "milestones" ALTER COLUMN "status" TYPE MILESTONE_STATUS_T USING (
+    CASE
+        WHEN "signed_off" THEN 'signed_off'
+        WHEN "completed" OR "status" = 'completed' THEN 'completed'
+        ELSE 'pending'
+    END
+)::MILESTONE_STATUS_T;
+ALTER TABLE "milestones" ALTER COLUMN "status" SET DEFAULT 'pending';
+UPDATE "milestones" SET "completed" = "status" IN ('completed', 'signed_off'), "signed_off" = "status" = 'signed_off';
+
+ALTER TABLE "milestones" ADD COLUMN "evidence" TEXT DEFAULT NULL;
+ALTER TABLE "milestones" ADD COLUMN "completed_by" ETH_ADDR_T DEFAULT NULL;
+ALTER TABLE "milestones" ADD COLUMN "completed_at" TIMESTAMPTZ DEFAULT NULL;
+ALTER TABLE "milestones" ADD COLUMN "signed_off_at" TIMESTAMPTZ DEFAULT NULL;
+
+CREATE TABLE "milestone_approvals" (
+    "milestone_id" UUID NOT NULL REFERENCES "milestones" ("id") ON DELETE CASCADE,
+    "admin_address" ETH_ADDR_T NOT NULL,
+    "signature" TEXT DEFAULT NULL, -- EIP-712 signature over the approval, when the admin signed it
+    "created_at" TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY ("milestone_id", "admin_address")
+);
+
+CREATE TYPE DISBURSEMENT_PROPOSAL_STATUS_T AS ENUM ('proposed', 'disbursed', 'dismissed');
+
+-- Payments proposed when a milestone is signed off, for an admin to make
+CREATE TABLE "disbursement_proposals" (
+    "id" UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    "grant_id" UUID NOT NULL REFERENCES "grants" ("id") ON DELETE CASCADE,
+    "milestone_id" UUID NOT NULL UNIQUE REFERENCES "milestones" ("id") ON DELETE CASCADE,
+    "asset" ETH_ADDR_T NOT NULL,
+    "amount" ETHER_T NOT NULL,
+    "recipient_address" ETH_ADDR_T NOT NULL,
+    "status" DISBURSEMENT_PROPOSAL_STATUS_T NOT NULL DEFAULT 'proposed',
+    "reviewed_by" ETH_ADDR_T DEFAULT NULL,
+    "reviewed_at" TIMESTAMPTZ DEFAULT NULL,
+    "created_at" TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_disbursement_proposals_grant_id ON "disbursement_proposals" ("grant_id", "created_at");
+
+COMMIT;
+---- create above / drop below ----
+
+BEGIN;
+
+DROP TABLE IF EXISTS "disbursement_proposals";
+DROP TYPE IF EXISTS DISBURSEMENT_PROPOSAL_STATUS_T;
+DROP TABLE IF EXISTS "milestone_approvals";
+
+ALTER TABLE "milestones" DROP COLUMN IF EXISTS "signed_off_at";
+ALTER TABLE "milestones" DROP COLUMN IF EXISTS "completed_at";
+ALTER TABLE "milestones" DROP COLUMN IF EXISTS "completed_by";
+ALTER TABLE "milestones" DROP COLUMN IF EXISTS "evidence";
+
+ALTER TABLE "milestones" ALTER COLUMN "status" DROP DEFAULT;
+ALTER TABLE "milestones" ALTER COLUMN "status" TYPE VARCHAR(50) USING "status"::TEXT;
+DROP TYPE IF EXISTS MILESTONE_STATUS_T;
+
+COMMIT;
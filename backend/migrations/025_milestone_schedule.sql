@@ -0,0 +1,33 @@
+-- Milestones are due by a target date, the tracker records when they become overdue
+
+BEGIN;
+
+ALTER TABLE "milestones" ADD COLUMN "target_date" DATE DEFAULT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_milestones_pending_target_date ON "milestones" ("target_date") WHERE "status" = 'pending';
+
+CREATE TYPE MILESTONE_EVENT_T AS ENUM ('overdue');
+
+CREATE TABLE "milestone_events" (
+    "id" UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    "milestone_id" UUID NOT NULL REFERENCES "milestones" ("id") ON DELETE CASCADE,
+    "grant_id" UUID NOT NULL REFERENCES "grants" ("id") ON DELETE CASCADE,
+    "event" MILESTONE_EVENT_T NOT NULL,
+    "target_date" DATE NOT NULL, -- Moving the target date lets the milestone become overdue again
+    "created_at" TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE ("milestone_id", "event", "target_date")
+);
+
+CREATE INDEX IF NOT EXISTS idx_milestone_events_grant_id ON "milestone_events" ("grant_id", "created_at");
+
+COMMIT;
+---- create above / drop below ----
+
+BEGIN;
+
+DROP TABLE IF EXISTS "milestone_events";
+DROP TYPE IF EXISTS MILESTONE_EVENT_T;
+DROP INDEX IF EXISTS idx_milestones_pending_target_date;
+ALTER TABLE "milestones" DROP COLUMN IF EXISTS "target_date";
+
+COMMIT;
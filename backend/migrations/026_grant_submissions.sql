@@ -0,0 +1,34 @@
+-- Grant recipients log in to submit updates on their grants, which take effect once an admin approves them
+
+BEGIN;
+
+CREATE TYPE GRANT_SUBMISSION_KIND_T AS ENUM ('milestone_progress', 'funds_usage', 'document');
+CREATE TYPE GRANT_SUBMISSION_STATUS_T AS ENUM ('pending', 'approved', 'rejected');
+
+CREATE TABLE "grant_submissions" (
+    "id" UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    "grant_id" UUID NOT NULL REFERENCES "grants" ("id") ON DELETE CASCADE,
+    "milestone_id" UUID DEFAULT NULL REFERENCES "milestones" ("id") ON DELETE CASCADE, -- Set for milestone progress
+    "recipient_address" ETH_ADDR_T NOT NULL, -- Who submitted it
+    "kind" GRANT_SUBMISSION_KIND_T NOT NULL,
+    "payload" JSONB NOT NULL,
+    "status" GRANT_SUBMISSION_STATUS_T NOT NULL DEFAULT 'pending',
+    "reviewed_by" ETH_ADDR_T DEFAULT NULL,
+    "reviewed_at" TIMESTAMPTZ DEFAULT NULL,
+    "review_note" TEXT DEFAULT NULL,
+    "created_at" TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_grant_submissions_grant_id ON "grant_submissions" ("grant_id", "created_at");
+CREATE INDEX IF NOT EXISTS idx_grant_submissions_pending ON "grant_submissions" ("created_at") WHERE "status" = 'pending';
+
+COMMIT;
+---- create above / drop below ----
+
+BEGIN;
+
+DROP TABLE IF EXISTS "grant_submissions";
+DROP TYPE IF EXISTS GRANT_SUBMISSION_STATUS_T;
+DROP TYPE IF EXISTS GRANT_SUBMISSION_KIND_T;
+
+COMMIT;
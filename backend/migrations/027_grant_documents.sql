@@ -0,0 +1,34 @@
+-- Documents attached to grants and their milestones, such as agreements, progress reports and deliverables
+
+BEGIN;
+
+CREATE TYPE GRANT_DOCUMENT_CATEGORY_T AS ENUM ('agreement', 'report', 'milestone_evidence', 'other');
+CREATE TYPE DOCUMENT_VISIBILITY_T AS ENUM ('public', 'private');
+
+CREATE TABLE "grant_documents" (
+    "id" UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    "grant_id" UUID NOT NULL REFERENCES "grants" ("id") ON DELETE CASCADE,
+    "milestone_id" UUID DEFAULT NULL REFERENCES "milestones" ("id") ON DELETE SET NULL,
+    "category" GRANT_DOCUMENT_CATEGORY_T NOT NULL DEFAULT 'other',
+    "name" VARCHAR(255) NOT NULL,
+    "file_name" VARCHAR(255) DEFAULT NULL,
+    "file_size" BIGINT NOT NULL,
+    "mime_type" VARCHAR(100) DEFAULT NULL,
+    "storage_id" VARCHAR(255) NOT NULL,
+    "visibility" DOCUMENT_VISIBILITY_T NOT NULL DEFAULT 'private',
+    "uploaded_by" ETH_ADDR_T NOT NULL,
+    "created_at" TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_grant_documents_grant_id ON "grant_documents" ("grant_id", "created_at");
+
+COMMIT;
+---- create above / drop below ----
+
+BEGIN;
+
+DROP TABLE IF EXISTS "grant_documents";
+DROP TYPE IF EXISTS DOCUMENT_VISIBILITY_T;
+DROP TYPE IF EXISTS GRANT_DOCUMENT_CATEGORY_T;
+
+COMMIT;
@@ -0,0 +1,14 @@
+-- Evidence attached to off-chain statements is private unless an admin makes it public, like grant documents
+
+BEGIN;
+
+ALTER TABLE "offchain_documents" ADD COLUMN "visibility" DOCUMENT_VISIBILITY_T NOT NULL DEFAULT 'private';
+
+COMMIT;
+---- create above / drop below ----
+
+BEGIN;
+
+ALTER TABLE "offchain_documents" DROP COLUMN IF EXISTS "visibility";
+
+COMMIT;
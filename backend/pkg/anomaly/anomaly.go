@@ -0,0 +1,92 @@
+package anomaly
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+// Thresholds set how unusual an outflow has to be before it is flagged
+type Thresholds struct {
+	Percentile    float64 // Outflows worth more than this percentile of past outflows are large
+	MinHistory    int     // Past outflows needed before anything is flagged, until then nothing is usual yet
+	RareHourShare float64 // Hours of the day (UTC) with less than this share of past outflows are unusual
+}
+
+// Detector scores outflows against the ones added to it before. Outflows should be added in the order they were sent.
+type Detector struct {
+	thresholds Thresholds
+	values     []float64 // Priced outflows, sorted
+	payees     map[string]bool
+	hours      [24]int
+	count      int
+}
+
+func NewDetector(thresholds Thresholds) *Detector {
+	return &Detector{thresholds: thresholds, payees: map[string]bool{}}
+}
+
+// Add adds an outflow to the history
+func (d *Detector) Add(outflow types.Outflow) {
+	d.count++
+	d.payees[outflow.PayeeAddress] = true
+	d.hours[hourOf(outflow)]++
+	if outflow.UsdValue.Valid {
+		i := sort.SearchFloat64s(d.values, outflow.UsdValue.Float64)
+		d.values = append(d.values, 0)
+		copy(d.values[i+1:], d.values[i:])
+		d.values[i] = outflow.UsdValue.Float64
+	}
+}
+
+// Score returns the ways in which an outflow is unusual compared to the history, without adding it
+func (d *Detector) Score(outflow types.Outflow) []types.TransferAnomaly {
+	if d.count < d.thresholds.MinHistory || d.count == 0 {
+		return nil
+	}
+	var anomalies []types.TransferAnomaly
+	add := func(kind types.AnomalyKind, score float64, reason string) {
+		anomalies = append(anomalies, types.TransferAnomaly{
+			TransferID: outflow.ID,
+			Kind:       kind,
+			Score:      score,
+			Reason:     reason,
+			Status:     types.AnomalyStatusOpen,
+		})
+	}
+
+	if outflow.UsdValue.Valid && len(d.values) >= d.thresholds.MinHistory && len(d.values) > 0 {
+		threshold := d.percentile(d.thresholds.Percentile)
+		if outflow.UsdValue.Float64 > threshold {
+			below := sort.SearchFloat64s(d.values, outflow.UsdValue.Float64)
+			add(types.AnomalyLargeOutflow, float64(below)/float64(len(d.values)),
+				fmt.Sprintf("$%.2f is above the %gth percentile of past outflows ($%.2f)", outflow.UsdValue.Float64, d.thresholds.Percentile, threshold))
+		}
+	}
+
+	if !outflow.KnownParty && !d.payees[outflow.PayeeAddress] {
+		add(types.AnomalyNewCounterparty, 1, "First payment to an address which hasn't been named")
+	}
+
+	hour := hourOf(outflow)
+	share := float64(d.hours[hour]) / float64(d.count)
+	if d.thresholds.RareHourShare > 0 && share < d.thresholds.RareHourShare {
+		add(types.AnomalyUnusualHour, 1-share/d.thresholds.RareHourShare,
+			fmt.Sprintf("Sent at %02d:00 UTC, when %.1f%% of past outflows were sent", hour, share*100))
+	}
+	return anomalies
+}
+
+// percentile returns the nearest-rank percentile of the priced outflows
+func (d *Detector) percentile(p float64) float64 {
+	rank := int(math.Ceil(p/100*float64(len(d.values)))) - 1
+	rank = max(0, min(rank, len(d.values)-1))
+	return d.values[rank]
+}
+
+func hourOf(outflow types.Outflow) int {
+	return time.Unix(outflow.BlockTimestamp, 0).UTC().Hour()
+}
@@ -0,0 +1,86 @@
+package anomaly
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ETHCF/ethutils"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+var start = time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+
+func outflow(payee string, usd float64, at time.Time) types.Outflow {
+	return types.Outflow{ID: uuid.New(), PayeeAddress: payee, BlockTimestamp: at.Unix(), UsdValue: null.FloatFrom(usd)}
+}
+
+// history is 20 payments of 100 to 2000 USD to the same payees, always sent during office hours
+func history(t *testing.T) (*Detector, []string) {
+	t.Helper()
+	payees := []string{ethutils.GenRandEVMAddr(), ethutils.GenRandEVMAddr()}
+	d := NewDetector(Thresholds{Percentile: 95, MinHistory: 20, RareHourShare: 0.02})
+	for i := range 20 {
+		d.Add(outflow(payees[i%2], float64(i+1)*100, start.AddDate(0, 0, i).Add(time.Duration(9+i%8)*time.Hour)))
+	}
+	return d, payees
+}
+
+func kinds(anomalies []types.TransferAnomaly) []types.AnomalyKind {
+	out := make([]types.AnomalyKind, 0, len(anomalies))
+	for _, found := range anomalies {
+		out = append(out, found.Kind)
+	}
+	return out
+}
+
+func TestDetector(t *testing.T) {
+	d, payees := history(t)
+	at := start.AddDate(0, 1, 0).Add(10 * time.Hour)
+
+	usual := outflow(payees[0], 500, at)
+	require.Empty(t, d.Score(usual))
+
+	// The 95th percentile of the history is 1900
+	large := outflow(payees[1], 5000, at)
+	anomalies := d.Score(large)
+	require.Equal(t, []types.AnomalyKind{types.AnomalyLargeOutflow}, kinds(anomalies))
+	require.Equal(t, large.ID, anomalies[0].TransferID)
+	require.Equal(t, types.AnomalyStatusOpen, anomalies[0].Status)
+	require.Equal(t, 1.0, anomalies[0].Score)
+	require.Contains(t, anomalies[0].Reason, "$1900.00")
+	require.Empty(t, d.Score(outflow(payees[1], 1900, at)))
+
+	stranger := outflow(ethutils.GenRandEVMAddr(), 500, at)
+	require.Equal(t, []types.AnomalyKind{types.AnomalyNewCounterparty}, kinds(d.Score(stranger)))
+	stranger.KnownParty = true
+	require.Empty(t, d.Score(stranger))
+
+	night := outflow(payees[0], 500, at.Add(-7*time.Hour))
+	anomalies = d.Score(night)
+	require.Equal(t, []types.AnomalyKind{types.AnomalyUnusualHour}, kinds(anomalies))
+	require.Equal(t, 1.0, anomalies[0].Score)
+	require.Contains(t, anomalies[0].Reason, "03:00 UTC")
+
+	unpriced := outflow(ethutils.GenRandEVMAddr(), 0, at.Add(-7*time.Hour))
+	unpriced.UsdValue = null.Float{}
+	require.Equal(t, []types.AnomalyKind{types.AnomalyNewCounterparty, types.AnomalyUnusualHour}, kinds(d.Score(unpriced)))
+
+	// Once paid, a payee is no longer new
+	d.Add(stranger)
+	stranger.KnownParty = false
+	require.Empty(t, d.Score(stranger))
+}
+
+func TestDetectorMinHistory(t *testing.T) {
+	d := NewDetector(Thresholds{Percentile: 95, MinHistory: 20, RareHourShare: 0.02})
+	require.Empty(t, d.Score(outflow(ethutils.GenRandEVMAddr(), 1e9, start.Add(3*time.Hour))))
+
+	for i := range 19 {
+		d.Add(outflow(ethutils.GenRandEVMAddr(), 100, start.Add(time.Duration(i)*time.Hour)))
+	}
+	require.Empty(t, d.Score(outflow(ethutils.GenRandEVMAddr(), 1e9, start.Add(23*time.Hour))))
+}
@@ -0,0 +1,92 @@
+package approvals
+
+import (
+	"encoding/hex"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/numbergroup/errors"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/constants"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/eth"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+// AllowanceSelector is the selector of allowance(address,address)
+const AllowanceSelector = "0xdd62ed3e"
+
+// UnlimitedThreshold is the allowance above which an approval is treated as unlimited. Wallets usually
+// approve the max uint256, but some protocols use smaller sentinels such as the max uint160.
+var UnlimitedThreshold = new(big.Int).Lsh(big.NewInt(1), 128)
+
+// OwnerTopic encodes an address as an indexed event topic
+func OwnerTopic(address string) string {
+	return "0x" + strings.Repeat("0", 24) + strings.TrimPrefix(strings.ToLower(address), "0x")
+}
+
+// ParseLog decodes an ERC-20 Approval event. ERC-721 approvals share the signature but index the
+// token id, and are rejected along with any other log which isn't an ERC-20 approval.
+func ParseLog(log eth.Log) (types.TokenApproval, error) {
+	if len(log.Topics) != 3 || log.Topics[0] != constants.ApprovalEventTopic {
+		return types.TokenApproval{}, errors.New("not an ERC-20 approval")
+	}
+	data, err := hex.DecodeString(strings.TrimPrefix(log.Data, "0x"))
+	if err != nil || len(data) != 32 {
+		return types.TokenApproval{}, errors.New("invalid approval data")
+	}
+	blockNumber, err := strconv.ParseInt(strings.TrimPrefix(log.BlockNumber, "0x"), 16, 64)
+	if err != nil {
+		return types.TokenApproval{}, errors.Wrap(err, "invalid block number")
+	}
+	logIndex, err := strconv.ParseInt(strings.TrimPrefix(log.LogIndex, "0x"), 16, 64)
+	if err != nil {
+		return types.TokenApproval{}, errors.Wrap(err, "invalid log index")
+	}
+
+	amount := new(big.Int).SetBytes(data).String()
+	return types.TokenApproval{
+		ChainID:        1,
+		Owner:          topicAddress(log.Topics[1]),
+		Token:          strings.ToLower(log.Address),
+		Spender:        topicAddress(log.Topics[2]),
+		ApprovedAmount: amount,
+		Allowance:      amount,
+		BlockNumber:    blockNumber,
+		TxHash:         strings.ToLower(log.TransactionHash),
+		LogIndex:       int(logIndex),
+	}, nil
+}
+
+func topicAddress(topic string) string {
+	topic = strings.ToLower(strings.TrimPrefix(topic, "0x"))
+	if len(topic) < 40 {
+		return "0x" + topic
+	}
+	return "0x" + topic[len(topic)-40:]
+}
+
+// AllowanceCallData encodes a call to allowance(owner, spender)
+func AllowanceCallData(owner, spender string) string {
+	return AllowanceSelector + strings.TrimPrefix(OwnerTopic(owner), "0x") + strings.TrimPrefix(OwnerTopic(spender), "0x")
+}
+
+// DecodeAllowance decodes the result of an allowance call
+func DecodeAllowance(result string) (*big.Int, error) {
+	data, err := hex.DecodeString(strings.TrimPrefix(result, "0x"))
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid hex")
+	}
+	if len(data) < 32 {
+		return nil, errors.Errorf("result too short for an allowance: %d bytes", len(data))
+	}
+	return new(big.Int).SetBytes(data[:32]), nil
+}
+
+// Flag marks approvals which are unlimited, or were granted longer than staleAge ago and are still open
+func Flag(listing *types.ApprovalListing, staleAge time.Duration, now time.Time) {
+	allowance, ok := new(big.Int).SetString(listing.Allowance, 10)
+	listing.Unlimited = ok && allowance.Cmp(UnlimitedThreshold) >= 0
+	listing.Stale = staleAge > 0 && now.Sub(listing.BlockTimestamp) > staleAge
+}
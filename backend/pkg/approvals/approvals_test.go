@@ -0,0 +1,89 @@
+package approvals
+
+import (
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ETHCF/ethutils"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/constants"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/eth"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+func TestSignatures(t *testing.T) {
+	require.Equal(t, constants.ApprovalEventTopic, "0x"+hex.EncodeToString(crypto.Keccak256([]byte("Approval(address,address,uint256)"))))
+	require.Equal(t, AllowanceSelector, "0x"+hex.EncodeToString(crypto.Keccak256([]byte("allowance(address,address)"))[:4]))
+}
+
+func TestParseLog(t *testing.T) {
+	var (
+		owner   = ethutils.GenRandEVMAddr()
+		spender = ethutils.GenRandEVMAddr()
+		token   = ethutils.GenRandEVMAddr()
+		txHash  = ethutils.GenRandEVMHash()
+		amount  = hex.EncodeToString(big.NewInt(1000).FillBytes(make([]byte, 32)))
+	)
+	log := eth.Log{
+		Address:         strings.ToUpper(token[:2]) + token[2:],
+		Topics:          []string{constants.ApprovalEventTopic, OwnerTopic(owner), OwnerTopic(spender)},
+		Data:            "0x" + amount,
+		BlockNumber:     "0x10",
+		TransactionHash: txHash,
+		LogIndex:        "0x2",
+	}
+
+	approval, err := ParseLog(log)
+	require.NoError(t, err)
+	require.Equal(t, owner, approval.Owner)
+	require.Equal(t, spender, approval.Spender)
+	require.Equal(t, token, approval.Token)
+	require.Equal(t, "1000", approval.ApprovedAmount)
+	require.Equal(t, "1000", approval.Allowance)
+	require.Equal(t, int64(16), approval.BlockNumber)
+	require.Equal(t, 2, approval.LogIndex)
+
+	// ERC-721 approvals index the token id
+	log.Topics = append(log.Topics, OwnerTopic(token))
+	log.Data = "0x"
+	_, err = ParseLog(log)
+	require.Error(t, err)
+
+	_, err = ParseLog(eth.Log{Topics: []string{constants.TransferEventTopic, OwnerTopic(owner), OwnerTopic(spender)}, Data: "0x" + amount})
+	require.Error(t, err)
+}
+
+func TestAllowanceCall(t *testing.T) {
+	owner := ethutils.GenRandEVMAddr()
+	spender := ethutils.GenRandEVMAddr()
+	data := AllowanceCallData(owner, spender)
+	require.Len(t, data, 2+8+64+64)
+	require.True(t, strings.HasSuffix(data, strings.TrimPrefix(spender, "0x")))
+
+	allowance, err := DecodeAllowance("0x" + hex.EncodeToString(big.NewInt(42).FillBytes(make([]byte, 32))))
+	require.NoError(t, err)
+	require.Equal(t, int64(42), allowance.Int64())
+
+	_, err = DecodeAllowance("0x")
+	require.Error(t, err)
+}
+
+func TestFlag(t *testing.T) {
+	now := time.Now()
+	maxUint256 := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+	listing := types.ApprovalListing{TokenApproval: types.TokenApproval{Allowance: maxUint256.String(), BlockTimestamp: now.Add(-time.Hour)}}
+	Flag(&listing, 24*time.Hour, now)
+	require.True(t, listing.Unlimited)
+	require.False(t, listing.Stale)
+
+	listing = types.ApprovalListing{TokenApproval: types.TokenApproval{Allowance: "1000000", BlockTimestamp: now.Add(-48 * time.Hour)}}
+	Flag(&listing, 24*time.Hour, now)
+	require.False(t, listing.Unlimited)
+	require.True(t, listing.Stale)
+}
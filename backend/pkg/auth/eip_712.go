@@ -51,6 +51,10 @@ func GenEIP712DelegateChallenge(conf *config.Config, signerAddr, delegateTo stri
 		},
 	}
 
+	return newEIP712Challenge(signerData)
+}
+
+func newEIP712Challenge(signerData apitypes.TypedData) (EIP712Challenge, error) {
 	typedDataHash, err := signerData.HashStruct(signerData.PrimaryType, signerData.Message)
 	if err != nil {
 		return EIP712Challenge{}, err
@@ -38,6 +38,7 @@ func (m *jwtManager) IssueToken(ctx context.Context, authn Authentication, meta
 	m.log.WithField("user", authn.UserAddress).Debug("Issuing token")
 	token := jwt.NewWithClaims(jwt.SigningMethodES384, &jwt.MapClaims{
 		"user": authn.UserAddress,
+		"role": authn.Role,
 		"meta": meta,
 	})
 	return token.SignedString(m.privateKey)
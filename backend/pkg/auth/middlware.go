@@ -13,10 +13,16 @@ import (
 
 const (
 	UserIDKey = "userID"
+	RoleKey   = "role"
 )
 
 type Middleware interface {
+	// Handle lets only admins through
 	Handle(c *gin.Context)
+	// HandleRecipient lets only grant recipients through
+	HandleRecipient(c *gin.Context)
+	// HandleOptional lets everyone through, identifying admins with a valid token
+	HandleOptional(c *gin.Context)
 }
 type middleware struct {
 	verifier JWTManager
@@ -35,14 +41,49 @@ func NewMiddleware(conf *config.Config, verifier JWTManager) (Middleware, error)
 }
 
 func (m middleware) Handle(c *gin.Context) {
+	m.handle(c, RoleAdmin)
+}
+
+func (m middleware) HandleRecipient(c *gin.Context) {
+	m.handle(c, RoleRecipient)
+}
+
+func (m middleware) HandleOptional(c *gin.Context) {
+	if os.Getenv("DEV_MODE") == "true" {
+		m.handle(c, RoleAdmin)
+		return
+	}
+
+	token, err := m.verifier.ValidateToken(c)
+	if err == nil {
+		tokenRole, _ := token["role"].(string)
+		if tokenRole == "" || tokenRole == RoleAdmin {
+			c.Set(UserIDKey, token["user"])
+			c.Set(RoleKey, RoleAdmin)
+		}
+	}
+	c.Next()
+}
+
+// IsAdmin tells whether the request was made by an admin
+func IsAdmin(c *gin.Context) bool {
+	_, ok := UserID(c)
+	return ok && c.GetString(RoleKey) == RoleAdmin
+}
+
+func (m middleware) handle(c *gin.Context, role string) {
 	// Dev mode bypass - skip authentication
 	if os.Getenv("DEV_MODE") == "true" {
 		// Set a default dev user
 		devUser := os.Getenv("DEV_USER")
+		if role == RoleRecipient {
+			devUser = os.Getenv("DEV_RECIPIENT")
+		}
 		if devUser == "" {
 			devUser = "0x554c5aF96E9e3c05AEC01ce18221d0DD25975aB4" // Default to zak.eth
 		}
 		c.Set(UserIDKey, devUser)
+		c.Set(RoleKey, role)
 		c.Next()
 		return
 	}
@@ -58,7 +99,18 @@ func (m middleware) Handle(c *gin.Context) {
 		return
 	}
 
+	tokenRole, _ := token["role"].(string)
+	if tokenRole == "" {
+		tokenRole = RoleAdmin // Only admins could log in before tokens had a role
+	}
+	if tokenRole != role {
+		m.log.WithFields(logrus.Fields{"user": token["user"], "role": tokenRole}).Warn("token does not have the required role")
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "insufficient permissions"})
+		return
+	}
+
 	c.Set(UserIDKey, token["user"])
+	c.Set(RoleKey, tokenRole)
 	c.Next()
 }
 
@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/config"
+)
+
+func Test_Middleware_Roles(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("DEV_MODE", "")
+
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	conf := &config.Config{}
+	manager := &jwtManager{conf: conf, log: logrus.New(), privateKey: key, publicKey: &key.PublicKey}
+	m := middleware{verifier: manager, log: logrus.New(), conf: conf}
+
+	r := gin.New()
+	r.GET("/admin", m.Handle, func(c *gin.Context) { c.String(http.StatusOK, c.GetString(RoleKey)) })
+	r.GET("/recipient", m.HandleRecipient, func(c *gin.Context) { c.String(http.StatusOK, c.GetString(RoleKey)) })
+	r.GET("/optional", m.HandleOptional, func(c *gin.Context) {
+		if !IsAdmin(c) {
+			c.Status(http.StatusNoContent)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	request := func(path, role string) int {
+		token, err := manager.IssueToken(t.Context(), Authentication{UserAddress: "0x0d2a8b91b97e26dd08eede6a755c4bbf36b8f311", Role: role}, map[string]any{})
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	require.Equal(t, http.StatusOK, request("/admin", RoleAdmin))
+	require.Equal(t, http.StatusForbidden, request("/admin", RoleRecipient))
+	require.Equal(t, http.StatusOK, request("/recipient", RoleRecipient))
+	require.Equal(t, http.StatusForbidden, request("/recipient", RoleAdmin))
+
+	// Tokens issued before roles were added are admin tokens
+	require.Equal(t, http.StatusOK, request("/admin", ""))
+	require.Equal(t, http.StatusForbidden, request("/recipient", ""))
+
+	// Only admins are identified on routes open to everyone
+	require.Equal(t, http.StatusOK, request("/optional", RoleAdmin))
+	require.Equal(t, http.StatusNoContent, request("/optional", RoleRecipient))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/optional", nil))
+	require.Equal(t, http.StatusNoContent, w.Code)
+}
@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/google/uuid"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/config"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+var milestoneDomainTypes = []apitypes.Type{
+	{Name: "name", Type: "string"},
+	{Name: "version", Type: "string"},
+	{Name: "chainId", Type: "uint256"},
+}
+
+// GenEIP712MilestoneApproval builds the typed data an admin signs to approve a milestone. It covers the amount and asset
+// of the milestone, so a signature doesn't carry over to a milestone whose terms were changed after it was approved.
+func GenEIP712MilestoneApproval(conf *config.Config, admin string, milestone types.Milestone) (EIP712Challenge, error) {
+	signerData := apitypes.TypedData{
+		Types: apitypes.Types{
+			"MilestoneApproval": []apitypes.Type{
+				{Name: "admin", Type: "address"},
+				{Name: "grantId", Type: "string"},
+				{Name: "milestoneId", Type: "string"},
+				{Name: "asset", Type: "address"},
+				{Name: "amount", Type: "string"},
+				{Name: "statement", Type: "string"},
+			},
+			"EIP712Domain": milestoneDomainTypes,
+		},
+		PrimaryType: "MilestoneApproval",
+		Domain:      milestoneDomain(conf),
+		Message: apitypes.TypedDataMessage{
+			"admin":       strings.ToLower(admin),
+			"grantId":     milestone.GrantID.String(),
+			"milestoneId": milestone.ID.String(),
+			"asset":       strings.ToLower(milestone.Asset),
+			"amount":      milestone.GrantAmount,
+			"statement":   "I approve that this milestone was completed",
+		},
+	}
+
+	return newEIP712Challenge(signerData)
+}
+
+// GenEIP712MilestoneCompletion builds the typed data a grant recipient signs to mark a milestone completed with the
+// evidence
+func GenEIP712MilestoneCompletion(conf *config.Config, recipient string, grantID, milestoneID uuid.UUID, evidence string) (EIP712Challenge, error) {
+	signerData := apitypes.TypedData{
+		Types: apitypes.Types{
+			"MilestoneCompletion": []apitypes.Type{
+				{Name: "recipient", Type: "address"},
+				{Name: "grantId", Type: "string"},
+				{Name: "milestoneId", Type: "string"},
+				{Name: "evidence", Type: "string"},
+			},
+			"EIP712Domain": milestoneDomainTypes,
+		},
+		PrimaryType: "MilestoneCompletion",
+		Domain:      milestoneDomain(conf),
+		Message: apitypes.TypedDataMessage{
+			"recipient":   strings.ToLower(recipient),
+			"grantId":     grantID.String(),
+			"milestoneId": milestoneID.String(),
+			"evidence":    evidence,
+		},
+	}
+
+	return newEIP712Challenge(signerData)
+}
+
+func milestoneDomain(conf *config.Config) apitypes.TypedDataDomain {
+	return apitypes.TypedDataDomain{
+		Name:    conf.Domain,
+		Version: "1",
+		ChainId: math.NewHexOrDecimal256(1), // Grants are paid on mainnet
+	}
+}
@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/config"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+func Test_GenEIP712MilestoneApproval(t *testing.T) {
+	conf := &config.Config{Auth: config.Auth{Domain: "example.com"}}
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	admin := crypto.PubkeyToAddress(key.PublicKey).Hex()
+	milestone := types.Milestone{
+		ID:          uuid.New(),
+		GrantID:     uuid.New(),
+		Asset:       "0xeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee",
+		GrantAmount: "2.5",
+	}
+
+	challenge, err := GenEIP712MilestoneApproval(conf, admin, milestone)
+	require.NoError(t, err)
+	require.Equal(t, "MilestoneApproval", challenge.Data["primaryType"])
+
+	sig, err := crypto.Sign(common.HexToHash(challenge.Hash).Bytes(), key)
+	require.NoError(t, err)
+	_, valid, err := VerifyWalletSignature(t.Context(), nil, admin, common.HexToHash(challenge.Hash), hexutil.Encode(sig))
+	require.NoError(t, err)
+	require.True(t, valid)
+
+	// Changing the terms of the milestone changes what has to be signed
+	milestone.GrantAmount = "3"
+	changed, err := GenEIP712MilestoneApproval(conf, admin, milestone)
+	require.NoError(t, err)
+	require.NotEqual(t, challenge.Hash, changed.Hash)
+}
+
+func Test_GenEIP712MilestoneCompletion(t *testing.T) {
+	conf := &config.Config{Auth: config.Auth{Domain: "example.com"}}
+	recipient := "0x0d2a8b91b97e26dd08eede6a755c4bbf36b8f311"
+	grantID, milestoneID := uuid.New(), uuid.New()
+
+	challenge, err := GenEIP712MilestoneCompletion(conf, recipient, grantID, milestoneID, "https://example.com/report")
+	require.NoError(t, err)
+	require.Equal(t, "MilestoneCompletion", challenge.Data["primaryType"])
+
+	again, err := GenEIP712MilestoneCompletion(conf, recipient, grantID, milestoneID, "https://example.com/report")
+	require.NoError(t, err)
+	require.Equal(t, challenge.Hash, again.Hash)
+
+	otherEvidence, err := GenEIP712MilestoneCompletion(conf, recipient, grantID, milestoneID, "https://example.com/other")
+	require.NoError(t, err)
+	require.NotEqual(t, challenge.Hash, otherEvidence.Hash)
+}
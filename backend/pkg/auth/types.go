@@ -12,6 +12,12 @@ const (
 	SIWEAuthn = "siwe"
 )
 
+// Roles of the principals who can log in
+const (
+	RoleAdmin     = "admin"
+	RoleRecipient = "recipient" // A grant recipient, logging in with the recipient address of their grants
+)
+
 type GenerateChallengeRequest struct {
 	Address string `json:"address"`
 	Website string `json:"website"`
@@ -45,6 +51,7 @@ type Authentication struct {
 	IPAddr      string
 	UserAddress string
 	Website     string
+	Role        string
 	Msg         *siwe.Message
 }
 
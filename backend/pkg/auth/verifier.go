@@ -16,28 +16,42 @@ import (
 type TokenVerifier interface {
 	VerifyMessage(ctx context.Context, message *siwe.Message, ip string) (bool, error)
 	Verify(ctx context.Context, req Authentication) (bool, error)
+	// VerifyRecipient verifies the login of a grant recipient, whose address has to be the recipient of a grant
+	VerifyRecipient(ctx context.Context, req Authentication) (bool, error)
 }
 
 type tokenVerifier struct {
-	conf    *config.Config
-	log     logrus.Ext1FieldLogger
-	authDB  db.AuthDB
-	adminDB db.AdminDB
+	conf         *config.Config
+	log          logrus.Ext1FieldLogger
+	authDB       db.AuthDB
+	adminDB      db.AdminDB
+	submissionDB db.GrantSubmissionDB
 }
 
-func NewTokenVerifier(conf *config.Config, authDB db.AuthDB, adminDB db.AdminDB) (TokenVerifier, error) {
+func NewTokenVerifier(conf *config.Config, authDB db.AuthDB, adminDB db.AdminDB, submissionDB db.GrantSubmissionDB) (TokenVerifier, error) {
 
 	out := &tokenVerifier{
-		conf:    conf,
-		log:     conf.GetLogger(),
-		authDB:  authDB,
-		adminDB: adminDB,
+		conf:         conf,
+		log:          conf.GetLogger(),
+		authDB:       authDB,
+		adminDB:      adminDB,
+		submissionDB: submissionDB,
 	}
 
 	return out, nil
 }
 
 func (tv tokenVerifier) Verify(ctx context.Context, req Authentication) (bool, error) {
+	return tv.verify(ctx, req, tv.VerifyMessage)
+}
+
+func (tv tokenVerifier) VerifyRecipient(ctx context.Context, req Authentication) (bool, error) {
+	return tv.verify(ctx, req, func(ctx context.Context, message *siwe.Message, ip string) (bool, error) {
+		return tv.verifyMessage(ctx, message, ip, RoleRecipient, tv.submissionDB.IsGrantRecipient)
+	})
+}
+
+func (tv tokenVerifier) verify(ctx context.Context, req Authentication, verifyMessage func(context.Context, *siwe.Message, string) (bool, error)) (bool, error) {
 
 	var valid bool
 	var err error
@@ -58,7 +72,7 @@ func (tv tokenVerifier) Verify(ctx context.Context, req Authentication) (bool, e
 		return false, errors.Wrap(err, "invalid signature")
 	}
 
-	validMsg, err := tv.VerifyMessage(ctx, req.Msg, req.IPAddr)
+	validMsg, err := verifyMessage(ctx, req.Msg, req.IPAddr)
 	if err != nil {
 		return false, errors.Wrap(err, "failed to verify message")
 	}
@@ -70,6 +84,11 @@ func (tv tokenVerifier) Verify(ctx context.Context, req Authentication) (bool, e
 }
 
 func (tv tokenVerifier) VerifyMessage(ctx context.Context, message *siwe.Message, ip string) (bool, error) {
+	return tv.verifyMessage(ctx, message, ip, RoleAdmin, tv.adminDB.AdminExists)
+}
+
+// verifyMessage checks the message is valid now and consumes its nonce, if the address has the role
+func (tv tokenVerifier) verifyMessage(ctx context.Context, message *siwe.Message, ip, role string, hasRole func(context.Context, string) (bool, error)) (bool, error) {
 	ok, err := message.ValidNow()
 	if err != nil {
 		tv.log.WithError(err).Error("failed to check if the message is valid now")
@@ -85,13 +104,13 @@ func (tv tokenVerifier) VerifyMessage(ctx context.Context, message *siwe.Message
 		return false, err
 	}
 
-	exists, err := tv.adminDB.AdminExists(ctx, strings.ToLower(message.GetAddress().Hex()))
+	exists, err := hasRole(ctx, strings.ToLower(message.GetAddress().Hex()))
 	if err != nil {
-		tv.log.WithError(err).Error("failed to check if admin exists")
+		tv.log.WithError(err).WithField("role", role).Error("failed to check the role of the address")
 		return false, err
 	}
 	if !exists {
-		tv.log.WithField("address", strings.ToLower(message.GetAddress().Hex())).Error("admin does not exist")
+		tv.log.WithField("address", strings.ToLower(message.GetAddress().Hex())).Errorf("%s does not exist", role)
 		return false, nil
 	}
 
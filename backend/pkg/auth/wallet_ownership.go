@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/google/uuid"
+	"github.com/numbergroup/errors"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/config"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/eth"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+const (
+	// isValidSignature(bytes32,bytes) from EIP-1271, contracts return the selector itself when the signature is valid
+	isValidSignatureSelector = "0x1626ba7e"
+)
+
+// GenEIP712WalletChallenge builds the typed data a treasury wallet signs to prove the organization controls it.
+// The challenge is deterministic so it can be rebuilt from the stored nonce and issue time when the signature comes back.
+func GenEIP712WalletChallenge(conf *config.Config, wallet string, chainID int64, nonce uuid.UUID, issuedAt time.Time) (EIP712Challenge, error) {
+	signerData := apitypes.TypedData{
+		Types: apitypes.Types{
+			"WalletOwnership": []apitypes.Type{
+				{Name: "wallet", Type: "address"},
+				{Name: "nonce", Type: "string"},
+				{Name: "issuedAt", Type: "uint256"},
+				{Name: "statement", Type: "string"},
+			},
+			"EIP712Domain": []apitypes.Type{
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+			},
+		},
+		PrimaryType: "WalletOwnership",
+		Domain: apitypes.TypedDataDomain{
+			Name:    conf.Domain,
+			Version: "1",
+			ChainId: math.NewHexOrDecimal256(chainID),
+		},
+		Message: apitypes.TypedDataMessage{
+			"wallet":    strings.ToLower(wallet),
+			"nonce":     nonce.String(),
+			"issuedAt":  math.NewHexOrDecimal256(issuedAt.Unix()),
+			"statement": fmt.Sprintf("This wallet is controlled by %s and may be listed on its transparency dashboard", conf.Domain),
+		},
+	}
+
+	return newEIP712Challenge(signerData)
+}
+
+// VerifyWalletSignature checks that wallet signed the challenge hash. Signatures recovering to the wallet are accepted
+// directly, otherwise the wallet is asked through EIP-1271, which is how Safes and other contract wallets sign.
+func VerifyWalletSignature(ctx context.Context, client eth.Client, wallet string, hash common.Hash, sig string) (types.WalletProofMethod, bool, error) {
+	decodedSig, err := hexutil.Decode(sig)
+	if err != nil {
+		return "", false, errors.Wrap(err, "failed to decode signature")
+	}
+
+	if len(decodedSig) == crypto.SignatureLength {
+		ecdsaSig := make([]byte, len(decodedSig))
+		copy(ecdsaSig, decodedSig)
+		if ecdsaSig[crypto.RecoveryIDOffset] == 27 || ecdsaSig[crypto.RecoveryIDOffset] == 28 {
+			ecdsaSig[crypto.RecoveryIDOffset] -= 27 // Transform yellow paper V from 27/28 to 0/1
+		}
+		recovered, err := crypto.SigToPub(hash.Bytes(), ecdsaSig)
+		if err == nil && strings.EqualFold(crypto.PubkeyToAddress(*recovered).Hex(), wallet) {
+			return types.WalletProofECDSA, true, nil
+		}
+	}
+
+	if client == nil {
+		return "", false, errors.New("no RPC configured to check contract signatures")
+	}
+
+	result, err := client.Call(ctx, wallet, IsValidSignatureCallData(hash, decodedSig), "latest")
+	if err != nil {
+		if strings.Contains(err.Error(), "execution reverted") {
+			return "", false, nil
+		}
+		return "", false, errors.Wrap(err, "failed to call isValidSignature")
+	}
+	if !strings.HasPrefix(strings.ToLower(result), isValidSignatureSelector) {
+		return "", false, nil
+	}
+	return types.WalletProofEIP1271, true, nil
+}
+
+// IsValidSignatureCallData encodes a call to isValidSignature(bytes32 hash, bytes signature)
+func IsValidSignatureCallData(hash common.Hash, sig []byte) string {
+	padded := make([]byte, (len(sig)+31)/32*32)
+	copy(padded, sig)
+
+	var sb strings.Builder
+	sb.WriteString(isValidSignatureSelector)
+	sb.WriteString(hex.EncodeToString(hash.Bytes()))
+	sb.WriteString(hex.EncodeToString(common.LeftPadBytes(big.NewInt(64).Bytes(), 32)))
+	sb.WriteString(hex.EncodeToString(common.LeftPadBytes(big.NewInt(int64(len(sig))).Bytes(), 32)))
+	sb.WriteString(hex.EncodeToString(padded))
+	return sb.String()
+}
@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+	"github.com/numbergroup/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/config"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/eth"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+// mockClient answers isValidSignature calls for a single contract wallet
+type mockClient struct {
+	eth.Client
+	wallet string
+	result string
+}
+
+func (m *mockClient) Call(ctx context.Context, to string, data string, blockTag string) (string, error) {
+	if to != m.wallet || !strings.HasPrefix(data, isValidSignatureSelector) {
+		return "", errors.New("eth_call failed: execution reverted")
+	}
+	return m.result, nil
+}
+
+func Test_GenEIP712WalletChallenge(t *testing.T) {
+	conf := &config.Config{Auth: config.Auth{Domain: "example.com"}}
+	wallet := "0x0d2a8b91b97e26dd08eede6a755c4bbf36b8f311"
+	nonce := uuid.New()
+	issuedAt := time.Unix(1700000000, 0)
+
+	challenge, err := GenEIP712WalletChallenge(conf, wallet, 1, nonce, issuedAt)
+	require.NoError(t, err)
+	require.Len(t, challenge.Hash, 66)
+	require.Equal(t, "WalletOwnership", challenge.Data["primaryType"])
+
+	// Rebuilding from the same inputs gives the same hash, anything else changes it
+	again, err := GenEIP712WalletChallenge(conf, wallet, 1, nonce, issuedAt)
+	require.NoError(t, err)
+	require.Equal(t, challenge.Hash, again.Hash)
+
+	otherChain, err := GenEIP712WalletChallenge(conf, wallet, 10, nonce, issuedAt)
+	require.NoError(t, err)
+	require.NotEqual(t, challenge.Hash, otherChain.Hash)
+}
+
+func Test_VerifyWalletSignature(t *testing.T) {
+	conf := &config.Config{Auth: config.Auth{Domain: "example.com"}}
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	wallet := strings.ToLower(crypto.PubkeyToAddress(key.PublicKey).Hex())
+
+	challenge, err := GenEIP712WalletChallenge(conf, wallet, 1, uuid.New(), time.Now())
+	require.NoError(t, err)
+	hash := common.HexToHash(challenge.Hash)
+
+	sig, err := crypto.Sign(hash.Bytes(), key)
+	require.NoError(t, err)
+	sig[crypto.RecoveryIDOffset] += 27 // Wallets return 27/28
+
+	method, valid, err := VerifyWalletSignature(t.Context(), nil, wallet, hash, hexutil.Encode(sig))
+	require.NoError(t, err)
+	require.True(t, valid)
+	require.Equal(t, types.WalletProofECDSA, method)
+
+	// A signature from another key is passed on to EIP-1271, which an EOA cannot answer
+	otherKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	otherSig, err := crypto.Sign(hash.Bytes(), otherKey)
+	require.NoError(t, err)
+	_, valid, err = VerifyWalletSignature(t.Context(), &mockClient{result: "0x"}, wallet, hash, hexutil.Encode(otherSig))
+	require.NoError(t, err)
+	require.False(t, valid)
+
+	// Contract wallets accept the signature through isValidSignature
+	safe := "0x31cb7f492f860e34bb627a1152efd58fcc9da4a9"
+	client := &mockClient{wallet: safe, result: isValidSignatureSelector + strings.Repeat("0", 56)}
+	method, valid, err = VerifyWalletSignature(t.Context(), client, safe, hash, hexutil.Encode(otherSig))
+	require.NoError(t, err)
+	require.True(t, valid)
+	require.Equal(t, types.WalletProofEIP1271, method)
+
+	client.result = "0xffffffff" + strings.Repeat("0", 56)
+	_, valid, err = VerifyWalletSignature(t.Context(), client, safe, hash, "0x")
+	require.NoError(t, err)
+	require.False(t, valid)
+
+	_, _, err = VerifyWalletSignature(t.Context(), client, safe, hash, "not hex")
+	require.Error(t, err)
+}
+
+func Test_IsValidSignatureCallData(t *testing.T) {
+	hash := common.HexToHash("0x01")
+	data := IsValidSignatureCallData(hash, []byte{0xab, 0xcd})
+	require.Equal(t, isValidSignatureSelector+
+		strings.Repeat("0", 63)+"1"+
+		strings.Repeat("0", 62)+"40"+
+		strings.Repeat("0", 63)+"2"+
+		"abcd"+strings.Repeat("0", 60), data)
+
+	// Safes that approved the message on-chain send an empty signature
+	empty := IsValidSignatureCallData(hash, nil)
+	require.Len(t, empty, len(isValidSignatureSelector)+3*64)
+}
@@ -0,0 +1,216 @@
+package composition
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/positions"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+const (
+	KeyOffchain  = "offchain"
+	KeyExchange  = "exchange"
+	KeyUngrouped = "ungrouped"
+)
+
+var chainNames = map[int64]string{
+	1: "Ethereum",
+}
+
+// bucket sums values by key, keeping the order in which the keys were first seen for stable output
+type bucket struct {
+	names  map[string]string
+	values map[string]float64
+	keys   []string
+}
+
+func newBucket() *bucket {
+	return &bucket{names: map[string]string{}, values: map[string]float64{}}
+}
+
+func (b *bucket) add(key, name string, value float64) {
+	if _, ok := b.values[key]; !ok {
+		b.keys = append(b.keys, key)
+		b.names[key] = name
+	}
+	b.values[key] += value
+}
+
+// entries returns the buckets largest first, with their share of total
+func (b *bucket) entries(total float64) []types.CompositionEntry {
+	out := make([]types.CompositionEntry, 0, len(b.keys))
+	for _, key := range b.keys {
+		entry := types.CompositionEntry{Key: key, Name: b.names[key], ValueUsd: b.values[key]}
+		if total > 0 {
+			entry.Percent = b.values[key] / total * 100
+		}
+		out = append(out, entry)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].ValueUsd > out[j].ValueUsd })
+	return out
+}
+
+func assetKey(chainID int64, address string) string {
+	return strconv.FormatInt(chainID, 10) + ":" + address
+}
+
+func chainName(chainID int64) string {
+	if name, ok := chainNames[chainID]; ok {
+		return name
+	}
+	return "Chain " + strconv.FormatInt(chainID, 10)
+}
+
+func isLP(protocol string) bool {
+	return protocol == positions.AdapterUniswapV2 || protocol == positions.AdapterUniswapV3
+}
+
+// Compute breaks the treasury down the same way its total is counted: spam and excluded balances are left out,
+// everything else, including positions, off-chain statements and exchange balances, is included.
+// The treasury should be the unscoped response with filtered balances included.
+func Compute(treasury *types.TreasuryResponse, groups []types.WalletGroup, now time.Time) types.TreasuryComposition {
+	assets := make(map[string]types.Asset, len(treasury.Assets))
+	classBySymbol := make(map[string]types.AssetClass, len(treasury.Assets))
+	for _, asset := range treasury.Assets {
+		assets[assetKey(asset.ChainID, asset.Address)] = asset
+		if asset.Class != "" {
+			classBySymbol[strings.ToUpper(asset.Symbol)] = asset.Class
+		}
+	}
+	classOf := func(key string) types.AssetClass {
+		if asset, ok := assets[key]; ok && asset.Class != "" {
+			return asset.Class
+		}
+		return types.AssetClassOther
+	}
+	assetName := func(key, fallback string) string {
+		if asset, ok := assets[key]; ok {
+			return asset.Symbol
+		}
+		return fallback
+	}
+
+	walletGroups := map[string][]types.WalletGroup{}
+	for _, group := range groups {
+		for _, wallet := range group.Wallets {
+			walletGroups[wallet] = append(walletGroups[wallet], group)
+		}
+	}
+
+	var (
+		total      float64
+		byClass    = newBucket()
+		byChain    = newBucket()
+		byGroup    = newBucket()
+		byAsset    = newBucket()
+		assetClass = map[string]types.AssetClass{}
+	)
+	for _, group := range groups {
+		byGroup.add(group.ID.String(), group.Name, 0)
+	}
+
+	addOnchain := func(wallet string, chainID int64, value float64) {
+		total += value
+		byChain.add(strconv.FormatInt(chainID, 10), chainName(chainID), value)
+		if len(groups) == 0 {
+			return
+		}
+		memberOf := walletGroups[wallet]
+		if len(memberOf) == 0 {
+			byGroup.add(KeyUngrouped, "Ungrouped", value)
+		}
+		for _, group := range memberOf {
+			byGroup.add(group.ID.String(), group.Name, value)
+		}
+	}
+	addAsset := func(key, name string, class types.AssetClass, value float64) {
+		byClass.add(string(class), string(class), value)
+		byAsset.add(key, name, value)
+		assetClass[key] = class
+	}
+
+	for _, balance := range treasury.WalletBalances {
+		if balance.FilterReason.String == types.FilterReasonSpam || balance.ValuationPolicy == types.ValuationPolicyExcluded {
+			continue
+		}
+		key := assetKey(balance.ChainID, balance.Address)
+		addOnchain(balance.Wallet, balance.ChainID, balance.UsdWorth)
+		addAsset(key, assetName(key, balance.Address), classOf(key), balance.UsdWorth)
+	}
+
+	for _, position := range treasury.Positions {
+		addOnchain(position.Wallet, position.ChainID, position.UsdWorth)
+		if isLP(position.Protocol) || len(position.Underlying) == 0 {
+			class := types.AssetClassOther
+			if isLP(position.Protocol) {
+				class = types.AssetClassLP
+			}
+			addAsset(position.Protocol+":"+position.PositionID, position.Name, class, position.UsdWorth)
+			continue
+		}
+		// Deposits and staked assets are exposure to what they hold, borrowed assets count against it
+		for _, underlying := range position.Underlying {
+			key := assetKey(position.ChainID, underlying.Address)
+			addAsset(key, assetName(key, underlying.Address), classOf(key), underlying.UsdWorth)
+		}
+	}
+
+	for _, balance := range treasury.OffchainBalances {
+		total += balance.UsdValue
+		byChain.add(KeyOffchain, "Off-chain accounts", balance.UsdValue)
+		addAsset(KeyOffchain+":"+balance.AccountID.String(), balance.Name, types.AssetClassOther, balance.UsdValue)
+	}
+
+	for _, balance := range treasury.ExchangeBalances {
+		total += balance.UsdValue.Float64
+		byChain.add(KeyExchange, "Exchanges", balance.UsdValue.Float64)
+		class, ok := classBySymbol[strings.ToUpper(balance.Currency)]
+		if !ok {
+			class = types.AssetClassOther
+		}
+		addAsset(KeyExchange+":"+balance.Exchange+":"+balance.Currency, balance.Currency, class, balance.UsdValue.Float64)
+	}
+
+	out := types.TreasuryComposition{
+		TotalValueUsd: total,
+		ByClass:       byClass.entries(total),
+		ByChain:       byChain.entries(total),
+		ByGroup:       byGroup.entries(total),
+		ByAsset:       byAsset.entries(total),
+		ComputedAt:    now,
+	}
+	out.Concentration = concentration(out.ByAsset, assetClass)
+	return out
+}
+
+// concentration computes the HHI over the assets held, ignoring net borrowed assets, and finds the largest holdings
+func concentration(byAsset []types.CompositionEntry, assetClass map[string]types.AssetClass) types.Concentration {
+	var out types.Concentration
+	var held float64
+	for _, entry := range byAsset {
+		if entry.ValueUsd > 0 {
+			held += entry.ValueUsd
+		}
+	}
+	if held == 0 {
+		return out
+	}
+	for i, entry := range byAsset {
+		if entry.ValueUsd <= 0 {
+			continue
+		}
+		share := entry.ValueUsd / held
+		out.HHI += share * share
+		// byAsset is sorted largest first
+		if out.LargestAsset == nil {
+			out.LargestAsset = &byAsset[i]
+		}
+		if out.LargestNonStableAsset == nil && assetClass[entry.Key] != types.AssetClassStablecoin {
+			out.LargestNonStableAsset = &byAsset[i]
+		}
+	}
+	return out
+}
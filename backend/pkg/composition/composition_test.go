@@ -0,0 +1,124 @@
+package composition
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/constants"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/positions"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+const (
+	usdc     = "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+	gov      = "0x1f9840a85d5af5bf1d1762f925bdaddc4201f984"
+	spam     = "0x000000000000000000000000000000000000dead"
+	opsSafe  = "0x1111111111111111111111111111111111111111"
+	coldSafe = "0x2222222222222222222222222222222222222222"
+)
+
+func testTreasury() *types.TreasuryResponse {
+	return &types.TreasuryResponse{
+		Assets: []types.Asset{
+			{ChainID: 1, Address: constants.EtherAddress, Symbol: "ETH", Class: types.AssetClassNative},
+			{ChainID: 1, Address: usdc, Symbol: "USDC", Class: types.AssetClassStablecoin},
+			{ChainID: 1, Address: gov, Symbol: "UNI", Class: types.AssetClassGovernance},
+			{ChainID: 1, Address: spam, Symbol: "SPAM", Class: types.AssetClassOther},
+		},
+		WalletBalances: []types.WalletBalance{
+			{ChainID: 1, Address: constants.EtherAddress, Wallet: coldSafe, UsdWorth: 3000},
+			{ChainID: 1, Address: usdc, Wallet: opsSafe, UsdWorth: 2000},
+			{ChainID: 1, Address: gov, Wallet: opsSafe, UsdWorth: 1000, ValuationPolicy: types.ValuationPolicyExcluded},
+			{ChainID: 1, Address: spam, Wallet: opsSafe, UsdWorth: 99999, FilterReason: null.StringFrom(types.FilterReasonSpam)},
+		},
+		Positions: []types.Position{
+			{
+				ChainID: 1, Wallet: coldSafe, Protocol: positions.AdapterStakedETH, PositionID: "steth", Name: "stETH", UsdWorth: 1000,
+				Underlying: types.PositionAssets{{Address: constants.EtherAddress, UsdWorth: 1000}},
+			},
+			{ChainID: 1, Wallet: opsSafe, Protocol: positions.AdapterUniswapV3, PositionID: "42", Name: "ETH/USDC", UsdWorth: 500},
+		},
+		OffchainBalances: []types.OffchainBalance{
+			{AccountID: uuid.New(), Name: "Bank", Currency: "USD", UsdValue: 2500},
+		},
+		ExchangeBalances: []types.ExchangeBalance{
+			{Exchange: "coinbase", Currency: "USDC", UsdValue: null.FloatFrom(1000)},
+		},
+	}
+}
+
+func findEntry(t *testing.T, entries []types.CompositionEntry, key string) types.CompositionEntry {
+	t.Helper()
+	for _, entry := range entries {
+		if entry.Key == key {
+			return entry
+		}
+	}
+	t.Fatalf("entry %s not found", key)
+	return types.CompositionEntry{}
+}
+
+func TestCompute(t *testing.T) {
+	ops := types.WalletGroup{ID: uuid.New(), Name: "Operations", Wallets: []string{opsSafe}}
+	now := time.Now()
+
+	comp := Compute(testTreasury(), []types.WalletGroup{ops}, now)
+	// Spam and excluded balances are left out, as they are from the treasury total
+	require.Equal(t, 10000.0, comp.TotalValueUsd)
+	require.Equal(t, now, comp.ComputedAt)
+
+	native := findEntry(t, comp.ByClass, string(types.AssetClassNative))
+	require.Equal(t, 4000.0, native.ValueUsd)
+	require.Equal(t, 40.0, native.Percent)
+	require.Equal(t, 3000.0, findEntry(t, comp.ByClass, string(types.AssetClassStablecoin)).ValueUsd)
+	require.Equal(t, 500.0, findEntry(t, comp.ByClass, string(types.AssetClassLP)).ValueUsd)
+	require.Equal(t, 2500.0, findEntry(t, comp.ByClass, string(types.AssetClassOther)).ValueUsd)
+	require.Equal(t, string(types.AssetClassNative), comp.ByClass[0].Key, "largest first")
+
+	require.Equal(t, 6500.0, findEntry(t, comp.ByChain, "1").ValueUsd)
+	require.Equal(t, "Ethereum", findEntry(t, comp.ByChain, "1").Name)
+	require.Equal(t, 2500.0, findEntry(t, comp.ByChain, KeyOffchain).ValueUsd)
+	require.Equal(t, 1000.0, findEntry(t, comp.ByChain, KeyExchange).ValueUsd)
+
+	require.Equal(t, 2500.0, findEntry(t, comp.ByGroup, ops.ID.String()).ValueUsd)
+	require.Equal(t, 4000.0, findEntry(t, comp.ByGroup, KeyUngrouped).ValueUsd)
+
+	// ETH held directly and staked count as one asset
+	require.Equal(t, 4000.0, findEntry(t, comp.ByAsset, "1:"+constants.EtherAddress).ValueUsd)
+	require.NotNil(t, comp.Concentration.LargestAsset)
+	require.Equal(t, "ETH", comp.Concentration.LargestAsset.Name)
+	require.Equal(t, "ETH", comp.Concentration.LargestNonStableAsset.Name)
+	// ETH 0.4, bank 0.25, USDC 0.2, exchange USDC 0.1, LP 0.05
+	require.InDelta(t, 0.16+0.0625+0.04+0.01+0.0025, comp.Concentration.HHI, 1e-9)
+}
+
+func TestComputeEmpty(t *testing.T) {
+	comp := Compute(&types.TreasuryResponse{}, nil, time.Now())
+	require.Zero(t, comp.TotalValueUsd)
+	require.Empty(t, comp.ByClass)
+	require.Empty(t, comp.ByGroup)
+	require.Nil(t, comp.Concentration.LargestAsset)
+	require.Zero(t, comp.Concentration.HHI)
+}
+
+func TestConcentrationSkipsStablecoins(t *testing.T) {
+	treasury := &types.TreasuryResponse{
+		Assets: []types.Asset{
+			{ChainID: 1, Address: usdc, Symbol: "USDC", Class: types.AssetClassStablecoin},
+			{ChainID: 1, Address: gov, Symbol: "UNI", Class: types.AssetClassGovernance},
+		},
+		WalletBalances: []types.WalletBalance{
+			{ChainID: 1, Address: usdc, Wallet: opsSafe, UsdWorth: 9000},
+			{ChainID: 1, Address: gov, Wallet: opsSafe, UsdWorth: 1000},
+		},
+	}
+	comp := Compute(treasury, nil, time.Now())
+	require.Equal(t, "USDC", comp.Concentration.LargestAsset.Name)
+	require.Equal(t, "UNI", comp.Concentration.LargestNonStableAsset.Name)
+	require.Equal(t, 10.0, comp.Concentration.LargestNonStableAsset.Percent)
+	require.Empty(t, comp.ByGroup, "no groups means no group breakdown")
+}
@@ -13,13 +13,32 @@ import (
 )
 
 type Config struct {
-	InitialAdminAddress string        `env:"INITIAL_ADMIN_ADDRESS" env-default:""`
-	AlchemyAPIKey       string        `env:"ALCHEMY_API_KEY" env-default:""`
-	AlchemyAPITimeout   time.Duration `env:"ALCHEMY_API_TIMEOUT" env-default:"10s"`
-	RPCURL              string        `env:"RPC_URL" env-default:""`
-	RPCAPITimeout       time.Duration `env:"RPC_API_TIMEOUT" env-default:"10s"`
-	BlockDelay          uint64        `env:"BLOCK_DELAY" env-default:"8"`
-	TrackerPollInterval time.Duration `env:"TRACKER_POLL_INTERVAL" env-default:"1m"`
+	InitialAdminAddress  string        `env:"INITIAL_ADMIN_ADDRESS" env-default:""`
+	AlchemyAPIKey        string        `env:"ALCHEMY_API_KEY" env-default:""`
+	AlchemyAPITimeout    time.Duration `env:"ALCHEMY_API_TIMEOUT" env-default:"10s"`
+	RPCURL               string        `env:"RPC_URL" env-default:""`
+	RPCAPITimeout        time.Duration `env:"RPC_API_TIMEOUT" env-default:"10s"`
+	BlockDelay           uint64        `env:"BLOCK_DELAY" env-default:"8"`
+	TrackerPollInterval  time.Duration `env:"TRACKER_POLL_INTERVAL" env-default:"1m"`
+	ENSPollInterval      time.Duration `env:"ENS_POLL_INTERVAL" env-default:"10m"`
+	ENSRefreshAge        time.Duration `env:"ENS_REFRESH_AGE" env-default:"168h"` // How old a resolved name can get before it is looked up again
+	ENSBatchSize         int           `env:"ENS_BATCH_SIZE" env-default:"100"`
+	PositionAdapters     []string      `env:"POSITION_ADAPTERS" env-separator:"," env-default:"staked-eth,aave-v3,uniswap-v2,uniswap-v3"`
+	ApprovalStartBlock   uint64        `env:"APPROVAL_START_BLOCK" env-default:"0"`
+	ApprovalBlockRange   uint64        `env:"APPROVAL_BLOCK_RANGE" env-default:"100000"` // Blocks per eth_getLogs request
+	ApprovalStaleAge     time.Duration `env:"APPROVAL_STALE_AGE" env-default:"2160h"`    // How long an open approval can go unchanged before it is flagged
+	ExchangePollInterval time.Duration `env:"EXCHANGE_POLL_INTERVAL" env-default:"15m"`
+	ExchangeAPITimeout   time.Duration `env:"EXCHANGE_API_TIMEOUT" env-default:"10s"`
+	CoinbaseAPIURL       string        `env:"COINBASE_API_URL" env-default:"https://api.coinbase.com"`
+	CoinbaseAPIKey       string        `env:"COINBASE_API_KEY" env-default:""` // Read-only key, the connector is disabled when unset
+	CoinbaseAPISecret    string        `env:"COINBASE_API_SECRET" env-default:""`
+	AnomalyPercentile    float64       `env:"ANOMALY_PERCENTILE" env-default:"95"`        // Outflows above this percentile of past outflows are flagged
+	AnomalyMinHistory    int           `env:"ANOMALY_MIN_HISTORY" env-default:"20"`       // Past outflows needed before any are flagged
+	AnomalyRareHourShare float64       `env:"ANOMALY_RARE_HOUR_SHARE" env-default:"0.02"` // Hours with less than this share of past outflows are unusual
+	ReconcileInterval    time.Duration `env:"RECONCILE_INTERVAL" env-default:"6h"`        // Needs an archive node, balances are read at past blocks
+	AutoDisburse         bool          `env:"AUTO_DISBURSE" env-default:"false"`          // Disburse transfers which can only have paid one grant without review
+	StorageDir           string        `env:"STORAGE_DIR" env-default:"./uploads"`        // Where uploaded files are kept
+	MaxUploadSize        int64         `env:"MAX_UPLOAD_SIZE" env-default:"26214400"`     // In bytes
 	config.BaseConfig
 	ServerConfig server.Config
 	Auth
@@ -3,4 +3,6 @@ package constants
 const (
 	EtherAddress = "0xeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee"
 	WethAddress  = "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2"
+
+	ENSRegistryAddress = "0x00000000000c2e074ec69a0dfb2997ba6c7d2e1e"
 )
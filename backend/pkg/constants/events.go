@@ -0,0 +1,8 @@
+package constants
+
+const (
+	// keccak256("Transfer(address,address,uint256)"), shared by ERC-20 and ERC-721
+	TransferEventTopic = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+	// keccak256("Approval(address,address,uint256)"), shared by ERC-20 and ERC-721
+	ApprovalEventTopic = "0x8c5be1e5ebec7d5bd14f71427d1e84f3dd0314c0f7b2291e5b200ac8c7c3b925"
+)
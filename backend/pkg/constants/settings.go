@@ -4,4 +4,10 @@ const (
 	SettingOrgName              = "org_name"
 	SettingTotalFundsRaised     = "total_funds_raised"
 	SettingTotalFundsRaisedUnit = "total_funds_raised_unit"
+
+	SettingDustThresholdBalanceUSD  = "dust_threshold_balance_usd"
+	SettingDustThresholdTransferUSD = "dust_threshold_transfer_usd"
+
+	SettingMilestoneRequiredApprovals   = "milestone_required_approvals"
+	SettingMilestoneProposeDisbursement = "milestone_propose_disbursement"
 )
@@ -0,0 +1,202 @@
+package db
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/numbergroup/errors"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/config"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+type AnomalyDB interface {
+	// GetOutflows returns every outflow in the order they were sent, or none when they have all been scored
+	GetOutflows(ctx context.Context) ([]types.Outflow, error)
+	// RecordAnomalies stores the anomalies found and marks the transfers scored
+	RecordAnomalies(ctx context.Context, checked []uuid.UUID, anomalies []types.TransferAnomaly) error
+
+	// GetAnomalies returns anomalies newest first, only the ones with the given status unless it is empty
+	GetAnomalies(ctx context.Context, status types.AnomalyStatus, limit, offset int) ([]types.TransferAnomalyListing, error)
+	GetAnomalyByID(ctx context.Context, id uuid.UUID) (*types.TransferAnomalyListing, error)
+	// ReviewAnomaly sets the status and note of an anomaly, either is left as it was when null
+	ReviewAnomaly(ctx context.Context, id uuid.UUID, status null.String, note null.String, reviewer string) error
+}
+
+type anomaly struct {
+	log            logrus.Ext1FieldLogger
+	dbConn         *sqlx.DB
+	getOutflows    *sqlx.Stmt
+	getAnomalies   *sqlx.Stmt
+	getAnomalyByID *sqlx.Stmt
+	reviewAnomaly  *sqlx.Stmt
+}
+
+func NewAnomalyDB(ctx context.Context, conf *config.Config, dbConn *sqlx.DB) (AnomalyDB, error) {
+	// Outflows are valued at the current price, as prices aren't kept over time. Transfers between treasury wallets
+	// aren't outflows and spam isn't worth scoring. A payee is known once an admin names it, or when it receives a grant.
+	getOutflows, err := dbConn.PreparexContext(ctx, `
+		WITH outflows AS (
+			SELECT t.id,
+				t.payee_address,
+				t.block_timestamp,
+				t.log_index,
+				t.anomaly_checked,
+				(t.amount / POWER(10::NUMERIC, a.decimals) * ap.usd_price::NUMERIC)::DOUBLE PRECISION AS usd_value,
+				(EXISTS (SELECT 1 FROM transfer_parties tp WHERE tp.address = t.payee_address AND tp.name <> '')
+					OR EXISTS (SELECT 1 FROM grants g WHERE g.recipient_address = t.payee_address)) AS known_party
+			FROM transfers t
+				LEFT JOIN assets a ON (t.chain_id = a.chain_id AND t.asset = a.address)
+				LEFT JOIN asset_prices ap ON (t.chain_id = ap.chain_id AND t.asset = ap.address)
+				LEFT JOIN asset_statuses st ON (t.chain_id = st.chain_id AND t.asset = st.address)
+			WHERE t.direction = 'outgoing'
+				AND t.payee_address NOT IN (SELECT address FROM wallets)
+				AND (st.status IS NULL OR st.status <> 'spam')
+		)
+		SELECT id, payee_address, block_timestamp, usd_value, known_party, anomaly_checked FROM outflows
+		WHERE EXISTS (SELECT 1 FROM outflows WHERE NOT anomaly_checked)
+		ORDER BY block_timestamp, log_index`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetOutflows statement")
+	}
+
+	getAnomaliesQuery := `
+		SELECT an.id,
+			an.transfer_id,
+			an.kind,
+			an.score,
+			an.reason,
+			an.status,
+			an.note,
+			an.reviewed_by,
+			an.reviewed_at,
+			an.created_at,
+			t.tx_hash,
+			t.block_timestamp,
+			t.payer_address,
+			t.payee_address,
+			pl.label AS payee_name,
+			t.asset,
+			a.symbol AS asset_symbol,
+			t.amount,
+			(t.amount / POWER(10::NUMERIC, a.decimals) * ap.usd_price::NUMERIC)::DOUBLE PRECISION AS usd_value
+		FROM transfer_anomalies an
+			JOIN transfers t ON (an.transfer_id = t.id)
+			LEFT JOIN party_display_labels pl ON (t.payee_address = pl.address)
+			LEFT JOIN assets a ON (t.chain_id = a.chain_id AND t.asset = a.address)
+			LEFT JOIN asset_prices ap ON (t.chain_id = ap.chain_id AND t.asset = ap.address)`
+
+	getAnomalies, err := dbConn.PreparexContext(ctx, getAnomaliesQuery+`
+		WHERE ($1 = '' OR an.status::TEXT = $1)
+		ORDER BY an.created_at DESC, t.block_timestamp DESC LIMIT $2 OFFSET $3`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetAnomalies statement")
+	}
+
+	getAnomalyByID, err := dbConn.PreparexContext(ctx, getAnomaliesQuery+` WHERE an.id = $1`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetAnomalyByID statement")
+	}
+
+	reviewAnomaly, err := dbConn.PreparexContext(ctx, `
+		UPDATE transfer_anomalies SET status = COALESCE($2::ANOMALY_STATUS_T, status), note = COALESCE($3, note),
+			reviewed_by = $4, reviewed_at = NOW()
+		WHERE id = $1`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare ReviewAnomaly statement")
+	}
+
+	return &anomaly{
+		log:            conf.GetLogger(),
+		dbConn:         dbConn,
+		getOutflows:    getOutflows,
+		getAnomalies:   getAnomalies,
+		getAnomalyByID: getAnomalyByID,
+		reviewAnomaly:  reviewAnomaly,
+	}, nil
+}
+
+func (an *anomaly) GetOutflows(ctx context.Context) ([]types.Outflow, error) {
+	var outflows []types.Outflow
+	err := an.getOutflows.SelectContext(ctx, &outflows)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get outflows")
+	}
+	if len(outflows) == 0 {
+		return []types.Outflow{}, nil
+	}
+	return outflows, nil
+}
+
+func (an *anomaly) RecordAnomalies(ctx context.Context, checked []uuid.UUID, anomalies []types.TransferAnomaly) error {
+	tx, err := an.dbConn.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	for _, found := range anomalies {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO transfer_anomalies (transfer_id, kind, score, reason) VALUES ($1, $2, $3, $4)
+			ON CONFLICT (transfer_id, kind) DO NOTHING`,
+			found.TransferID, found.Kind, found.Score, found.Reason)
+		if err != nil {
+			return errors.Wrapf(err, "failed to record %s anomaly for transfer %s", found.Kind, found.TransferID)
+		}
+	}
+
+	for _, id := range checked {
+		_, err = tx.ExecContext(ctx, `UPDATE transfers SET anomaly_checked = TRUE WHERE id = $1`, id)
+		if err != nil {
+			return errors.Wrapf(err, "failed to mark transfer %s scored", id)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, "failed to commit transaction")
+	}
+	return nil
+}
+
+func (an *anomaly) GetAnomalies(ctx context.Context, status types.AnomalyStatus, limit, offset int) ([]types.TransferAnomalyListing, error) {
+	var anomalies []types.TransferAnomalyListing
+	err := an.getAnomalies.SelectContext(ctx, &anomalies, string(status), limit, offset)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get anomalies")
+	}
+	if len(anomalies) == 0 {
+		return []types.TransferAnomalyListing{}, nil
+	}
+	return anomalies, nil
+}
+
+func (an *anomaly) GetAnomalyByID(ctx context.Context, id uuid.UUID) (*types.TransferAnomalyListing, error) {
+	var listing types.TransferAnomalyListing
+	err := an.getAnomalyByID.GetContext(ctx, &listing, id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get anomaly (%s)", id)
+	}
+	return &listing, nil
+}
+
+func (an *anomaly) ReviewAnomaly(ctx context.Context, id uuid.UUID, status null.String, note null.String, reviewer string) error {
+	result, err := an.reviewAnomaly.ExecContext(ctx, id, status, note, reviewer)
+	if err != nil {
+		return errors.Wrapf(err, "failed to review anomaly (%s)", id)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to get rows affected")
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("anomaly not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+//go:build integration
+// +build integration
+
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ETHCF/ethutils"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+func GetTestAnomalyDB(t *testing.T) AnomalyDB {
+	adb, err := NewAnomalyDB(t.Context(), conf, dbConn)
+	require.NoError(t, err)
+	return adb
+}
+
+func Test_AnomalyDB(t *testing.T) {
+	var (
+		db       = GetTestAnomalyDB(t)
+		treasury = GetTestTreasuryDB(t)
+		wallet   = ethutils.GenRandEVMAddr()
+		named    = ethutils.GenRandEVMAddr()
+		asset    = ethutils.GenRandEVMAddr()
+		reviewer = ethutils.GenRandEVMAddr()
+	)
+
+	_, err := dbConn.ExecContext(t.Context(), "INSERT INTO wallets (address) VALUES ($1)", wallet)
+	require.NoError(t, err)
+	_, err = dbConn.ExecContext(t.Context(),
+		"INSERT INTO assets (chain_id, address, name, symbol, decimals) VALUES (1, $1, 'Test', 'TST', 2)", asset)
+	require.NoError(t, err)
+	_, err = dbConn.ExecContext(t.Context(), "INSERT INTO asset_prices (chain_id, address, usd_price) VALUES (1, $1, 2)", asset)
+	require.NoError(t, err)
+	_, err = dbConn.ExecContext(t.Context(), "INSERT INTO transfer_parties (address, name) VALUES ($1, 'Auditor')", named)
+	require.NoError(t, err)
+
+	transfers := []types.CreateTransfer{
+		{ToAddress: named, Amount: "1000", Direction: types.TransferTypeOutgoing},
+		{ToAddress: ethutils.GenRandEVMAddr(), Amount: "5000", Direction: types.TransferTypeOutgoing},
+		// Incoming transfers aren't outflows
+		{FromAddress: ethutils.GenRandEVMAddr(), ToAddress: wallet, Amount: "5000", Direction: types.TransferTypeIncoming},
+	}
+	for i := range transfers {
+		transfers[i].ChainID = 1
+		transfers[i].TxHash = ethutils.GenRandEVMHash()
+		transfers[i].BlockNumber = 1
+		transfers[i].BlockTimestamp = time.Now().Add(time.Duration(i) * time.Minute).Unix()
+		transfers[i].Asset = asset
+		if transfers[i].FromAddress == "" {
+			transfers[i].FromAddress = wallet
+		}
+		err = treasury.CreateTransfer(t.Context(), transfers[i])
+		require.NoError(t, err)
+	}
+
+	// Newly ingested transfers are unchecked, so the outflows are returned
+	outflows, err := db.GetOutflows(t.Context())
+	require.NoError(t, err)
+	var ours []types.Outflow
+	for _, outflow := range outflows {
+		if outflow.PayeeAddress == named || outflow.PayeeAddress == transfers[1].ToAddress {
+			ours = append(ours, outflow)
+		}
+	}
+	require.Len(t, ours, 2)
+	require.Equal(t, named, ours[0].PayeeAddress, "in the order they were sent")
+	require.True(t, ours[0].KnownParty)
+	require.False(t, ours[0].Checked)
+	require.InDelta(t, 20.0, ours[0].UsdValue.Float64, 0.0001)
+	require.False(t, ours[1].KnownParty)
+
+	found := types.TransferAnomaly{TransferID: ours[1].ID, Kind: types.AnomalyNewCounterparty, Score: 1, Reason: "First payment"}
+	err = db.RecordAnomalies(t.Context(), []uuid.UUID{ours[0].ID, ours[1].ID}, []types.TransferAnomaly{found})
+	require.NoError(t, err)
+	// Scoring again doesn't duplicate anomalies
+	err = db.RecordAnomalies(t.Context(), nil, []types.TransferAnomaly{found})
+	require.NoError(t, err)
+
+	var checked bool
+	err = dbConn.GetContext(t.Context(), &checked, "SELECT anomaly_checked FROM transfers WHERE id = $1", ours[1].ID)
+	require.NoError(t, err)
+	require.True(t, checked)
+
+	anomalies, err := db.GetAnomalies(t.Context(), types.AnomalyStatusOpen, 1000, 0)
+	require.NoError(t, err)
+	var listing *types.TransferAnomalyListing
+	for i := range anomalies {
+		if anomalies[i].TransferID == ours[1].ID {
+			require.Nil(t, listing, "anomalies are unique per transfer and kind")
+			listing = &anomalies[i]
+		}
+	}
+	require.NotNil(t, listing)
+	require.Equal(t, transfers[1].TxHash, listing.TxHash)
+	require.Equal(t, "TST", listing.AssetSymbol.String)
+	require.InDelta(t, 100.0, listing.UsdValue.Float64, 0.0001)
+
+	// Annotating keeps the status, acknowledging keeps the note
+	err = db.ReviewAnomaly(t.Context(), listing.ID, null.String{}, null.StringFrom("New auditor"), reviewer)
+	require.NoError(t, err)
+	err = db.ReviewAnomaly(t.Context(), listing.ID, null.StringFrom(string(types.AnomalyStatusAcknowledged)), null.String{}, reviewer)
+	require.NoError(t, err)
+
+	reviewed, err := db.GetAnomalyByID(t.Context(), listing.ID)
+	require.NoError(t, err)
+	require.Equal(t, types.AnomalyStatusAcknowledged, reviewed.Status)
+	require.Equal(t, "New auditor", reviewed.Note.String)
+	require.Equal(t, reviewer, reviewed.ReviewedBy.String)
+	require.True(t, reviewed.ReviewedAt.Valid)
+
+	err = db.ReviewAnomaly(t.Context(), uuid.New(), null.StringFrom(string(types.AnomalyStatusDismissed)), null.String{}, reviewer)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "not found")
+
+	// Cleanup
+	_, err = dbConn.ExecContext(t.Context(), "DELETE FROM transfers WHERE asset = $1", asset)
+	require.NoError(t, err)
+	_, err = dbConn.ExecContext(t.Context(), "DELETE FROM transfer_parties WHERE address = $1", named)
+	require.NoError(t, err)
+	_, err = dbConn.ExecContext(t.Context(), "DELETE FROM asset_prices WHERE address = $1", asset)
+	require.NoError(t, err)
+	_, err = dbConn.ExecContext(t.Context(), "DELETE FROM assets WHERE address = $1", asset)
+	require.NoError(t, err)
+	_, err = dbConn.ExecContext(t.Context(), "DELETE FROM wallets WHERE address = $1", wallet)
+	require.NoError(t, err)
+}
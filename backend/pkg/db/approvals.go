@@ -0,0 +1,126 @@
+package db
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/numbergroup/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/config"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+type ApprovalDB interface {
+	// UpsertApproval stores an Approval event, unless a later event for the same token and spender is already stored
+	UpsertApproval(ctx context.Context, approval types.TokenApproval) error
+	// GetApprovals returns the approvals of an owner, or of every owner when it is empty. Approvals which
+	// have been revoked or fully spent are only returned when includeRevoked is set.
+	GetApprovals(ctx context.Context, owner string, includeRevoked bool) ([]types.ApprovalListing, error)
+	UpdateAllowance(ctx context.Context, chainID int64, owner, token, spender, allowance string) error
+}
+
+type approval struct {
+	log             logrus.Ext1FieldLogger
+	dbConn          *sqlx.DB
+	upsertApproval  *sqlx.NamedStmt
+	getApprovals    *sqlx.Stmt
+	updateAllowance *sqlx.Stmt
+}
+
+func NewApprovalDB(ctx context.Context, conf *config.Config, dbConn *sqlx.DB) (ApprovalDB, error) {
+	upsertApproval, err := dbConn.PrepareNamedContext(ctx, `
+		INSERT INTO token_approvals (chain_id, owner, token, spender, approved_amount, allowance, block_number, block_timestamp, tx_hash, log_index)
+		VALUES (:chain_id, :owner, :token, :spender, :approved_amount, :allowance, :block_number, :block_timestamp, :tx_hash, :log_index)
+		ON CONFLICT (chain_id, owner, token, spender) DO UPDATE SET
+			approved_amount = EXCLUDED.approved_amount,
+			allowance = EXCLUDED.allowance,
+			block_number = EXCLUDED.block_number,
+			block_timestamp = EXCLUDED.block_timestamp,
+			tx_hash = EXCLUDED.tx_hash,
+			log_index = EXCLUDED.log_index,
+			updated_at = NOW()
+		WHERE (token_approvals.block_number, token_approvals.log_index) < (EXCLUDED.block_number, EXCLUDED.log_index)`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare UpsertApproval statement")
+	}
+
+	getApprovals, err := dbConn.PreparexContext(ctx, `
+		SELECT ta.chain_id,
+			ta.owner,
+			ta.token,
+			ta.spender,
+			ta.approved_amount,
+			ta.allowance,
+			ta.block_number,
+			ta.block_timestamp,
+			ta.tx_hash,
+			ta.log_index,
+			ta.updated_at,
+			sl.label AS spender_name,
+			a.symbol AS token_symbol
+		FROM token_approvals ta
+			LEFT JOIN party_display_labels sl ON (ta.spender = sl.address)
+			LEFT JOIN assets a ON (ta.chain_id = a.chain_id AND ta.token = a.address)
+		WHERE ($1 = '' OR ta.owner = $1) AND ($2 OR ta.allowance > 0)
+		ORDER BY ta.block_timestamp DESC, ta.owner, ta.token, ta.spender`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetApprovals statement")
+	}
+
+	updateAllowance, err := dbConn.PreparexContext(ctx, `
+		UPDATE token_approvals SET allowance = $5, updated_at = NOW()
+		WHERE chain_id = $1 AND owner = $2 AND token = $3 AND spender = $4`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare UpdateAllowance statement")
+	}
+
+	return &approval{
+		log:             conf.GetLogger(),
+		dbConn:          dbConn,
+		upsertApproval:  upsertApproval,
+		getApprovals:    getApprovals,
+		updateAllowance: updateAllowance,
+	}, nil
+}
+
+func (a *approval) UpsertApproval(ctx context.Context, approval types.TokenApproval) error {
+	if approval.ChainID == 0 {
+		approval.ChainID = 1
+	}
+	_, err := a.upsertApproval.ExecContext(ctx, approval)
+	if err != nil {
+		return errors.Wrap(err, "failed to upsert approval")
+	}
+	return nil
+}
+
+func (a *approval) GetApprovals(ctx context.Context, owner string, includeRevoked bool) ([]types.ApprovalListing, error) {
+	var approvals []types.ApprovalListing
+	err := a.getApprovals.SelectContext(ctx, &approvals, owner, includeRevoked)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get approvals")
+	}
+	if len(approvals) == 0 {
+		return []types.ApprovalListing{}, nil
+	}
+	return approvals, nil
+}
+
+func (a *approval) UpdateAllowance(ctx context.Context, chainID int64, owner, token, spender, allowance string) error {
+	result, err := a.updateAllowance.ExecContext(ctx, chainID, owner, token, spender, allowance)
+	if err != nil {
+		return errors.Wrap(err, "failed to update allowance")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to get rows affected")
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("approval not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+//go:build integration
+// +build integration
+
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ETHCF/ethutils"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+func GetTestApprovalDB(t *testing.T) ApprovalDB {
+	adb, err := NewApprovalDB(t.Context(), conf, dbConn)
+	require.NoError(t, err)
+	return adb
+}
+
+func Test_ApprovalDB_Approvals(t *testing.T) {
+	var (
+		db       = GetTestApprovalDB(t)
+		treasury = GetTestTreasuryDB(t)
+		owner    = ethutils.GenRandEVMAddr()
+		token    = ethutils.GenRandEVMAddr()
+		spender  = ethutils.GenRandEVMAddr()
+		approval = types.TokenApproval{
+			ChainID:        1,
+			Owner:          owner,
+			Token:          token,
+			Spender:        spender,
+			ApprovedAmount: "1000",
+			Allowance:      "1000",
+			BlockNumber:    100,
+			BlockTimestamp: time.Now().Add(-time.Hour).Truncate(time.Second),
+			TxHash:         ethutils.GenRandEVMHash(),
+			LogIndex:       5,
+		}
+	)
+
+	err := treasury.UpsertTransferParty(t.Context(), types.TransferParty{Address: spender, Name: "Test Router"})
+	require.NoError(t, err)
+
+	err = db.UpsertApproval(t.Context(), approval)
+	require.NoError(t, err)
+
+	listings, err := db.GetApprovals(t.Context(), owner, false)
+	require.NoError(t, err)
+	require.Len(t, listings, 1)
+	require.Equal(t, spender, listings[0].Spender)
+	require.Equal(t, "1000", listings[0].Allowance)
+	require.Equal(t, "Test Router", listings[0].SpenderName.String)
+
+	// An earlier event doesn't overwrite a later one
+	older := approval
+	older.ApprovedAmount = "5"
+	older.Allowance = "5"
+	older.LogIndex = 4
+	err = db.UpsertApproval(t.Context(), older)
+	require.NoError(t, err)
+
+	listings, err = db.GetApprovals(t.Context(), owner, false)
+	require.NoError(t, err)
+	require.Len(t, listings, 1)
+	require.Equal(t, "1000", listings[0].ApprovedAmount)
+
+	// A fully spent approval is only listed with includeRevoked
+	err = db.UpdateAllowance(t.Context(), 1, owner, token, spender, "0")
+	require.NoError(t, err)
+
+	listings, err = db.GetApprovals(t.Context(), owner, false)
+	require.NoError(t, err)
+	require.Empty(t, listings)
+
+	listings, err = db.GetApprovals(t.Context(), owner, true)
+	require.NoError(t, err)
+	require.Len(t, listings, 1)
+	require.Equal(t, "0", listings[0].Allowance)
+	require.Equal(t, "1000", listings[0].ApprovedAmount)
+
+	err = db.UpdateAllowance(t.Context(), 1, owner, token, ethutils.GenRandEVMAddr(), "0")
+	require.Error(t, err)
+
+	// Cleanup
+	_, err = dbConn.ExecContext(t.Context(), "DELETE FROM token_approvals WHERE owner = $1", owner)
+	require.NoError(t, err)
+	_, err = dbConn.ExecContext(t.Context(), "DELETE FROM transfer_parties WHERE address = $1", spender)
+	require.NoError(t, err)
+}
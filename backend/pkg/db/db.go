@@ -20,11 +20,21 @@ func TrimZeros(s string) string {
 type DatabasePacket struct {
 	AdminActionDB AdminActionDB
 	AdminDB       AdminDB
+	AnomalyDB     AnomalyDB
+	ApprovalDB    ApprovalDB
 	AuthDB        AuthDB
 	BudgetDB      BudgetDB
 	CategoryDB    CategoryDB
+	ExchangeDB    ExchangeDB
 	ExpenseDB     ExpenseDB
 	GrantDB       GrantDB
+	DocumentDB    GrantDocumentDB
+	SubmissionDB  GrantSubmissionDB
+	LabelDB       LabelDB
+	MatchDB       DisbursementMatchDB
+	OffchainDB    OffchainDB
+	PolicyDB      PolicyDB
+	ReconDB       ReconciliationDB
 	SettingsDB    SettingsDB
 	TreasuryDB    TreasuryDB
 }
@@ -38,10 +48,22 @@ func NewDatabasePacket(ctx context.Context, conf *config.Config, dbConn *sqlx.DB
 	if err != nil {
 		return DatabasePacket{}, err
 	}
+	anomalyDB, err := NewAnomalyDB(ctx, conf, dbConn)
+	if err != nil {
+		return DatabasePacket{}, err
+	}
+	approvalDB, err := NewApprovalDB(ctx, conf, dbConn)
+	if err != nil {
+		return DatabasePacket{}, err
+	}
 	authDB, err := NewAuthDB(ctx, conf, dbConn)
 	if err != nil {
 		return DatabasePacket{}, err
 	}
+	exchangeDB, err := NewExchangeDB(ctx, conf, dbConn)
+	if err != nil {
+		return DatabasePacket{}, err
+	}
 	expenseDB, err := NewExpenseDB(ctx, conf, dbConn)
 	if err != nil {
 		return DatabasePacket{}, err
@@ -50,6 +72,14 @@ func NewDatabasePacket(ctx context.Context, conf *config.Config, dbConn *sqlx.DB
 	if err != nil {
 		return DatabasePacket{}, err
 	}
+	documentDB, err := NewGrantDocumentDB(ctx, conf, dbConn)
+	if err != nil {
+		return DatabasePacket{}, err
+	}
+	submissionDB, err := NewGrantSubmissionDB(ctx, conf, dbConn)
+	if err != nil {
+		return DatabasePacket{}, err
+	}
 	settingsDB, err := NewSettingsDB(conf, dbConn)
 	if err != nil {
 		return DatabasePacket{}, err
@@ -66,14 +96,44 @@ func NewDatabasePacket(ctx context.Context, conf *config.Config, dbConn *sqlx.DB
 	if err != nil {
 		return DatabasePacket{}, err
 	}
+	labelDB, err := NewLabelDB(ctx, conf, dbConn)
+	if err != nil {
+		return DatabasePacket{}, err
+	}
+	matchDB, err := NewDisbursementMatchDB(ctx, conf, dbConn)
+	if err != nil {
+		return DatabasePacket{}, err
+	}
+	offchainDB, err := NewOffchainDB(ctx, conf, dbConn)
+	if err != nil {
+		return DatabasePacket{}, err
+	}
+	policyDB, err := NewPolicyDB(ctx, conf, dbConn)
+	if err != nil {
+		return DatabasePacket{}, err
+	}
+	reconDB, err := NewReconciliationDB(ctx, conf, dbConn)
+	if err != nil {
+		return DatabasePacket{}, err
+	}
 	return DatabasePacket{
 		AdminActionDB: adminActionDB,
 		AdminDB:       adminDB,
+		AnomalyDB:     anomalyDB,
+		ApprovalDB:    approvalDB,
 		AuthDB:        authDB,
 		BudgetDB:      budgetDB,
 		CategoryDB:    categoryDB,
+		ExchangeDB:    exchangeDB,
 		ExpenseDB:     expenseDB,
 		GrantDB:       grantDB,
+		DocumentDB:    documentDB,
+		SubmissionDB:  submissionDB,
+		LabelDB:       labelDB,
+		MatchDB:       matchDB,
+		OffchainDB:    offchainDB,
+		PolicyDB:      policyDB,
+		ReconDB:       reconDB,
 		SettingsDB:    settingsDB,
 		TreasuryDB:    treasuryDB,
 	}, nil
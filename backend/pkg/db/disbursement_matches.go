@@ -0,0 +1,425 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/innodv/psql"
+	"github.com/jmoiron/sqlx"
+	"github.com/numbergroup/errors"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/config"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+type DisbursementMatchDB interface {
+	GetGrantAddresses(ctx context.Context, grantID uuid.UUID) ([]types.GrantAddress, error)
+	AddGrantAddress(ctx context.Context, address types.GrantAddress) error
+	DeleteGrantAddress(ctx context.Context, grantID uuid.UUID, address string) error
+
+	// LinkManualDisbursements links disbursements entered by hand to the transfer they were paid with, returning how many were linked
+	LinkManualDisbursements(ctx context.Context) (int64, error)
+	// GetCandidates returns the outgoing transfers to the addresses of a grant, in one of its assets, which haven't been
+	// matched yet, by transfer
+	GetCandidates(ctx context.Context) ([]types.DisbursementCandidate, error)
+	// RecordMatches stores new matches, a disbursement is created for each confirmed one
+	RecordMatches(ctx context.Context, matches []types.DisbursementMatch) error
+
+	// GetMatches returns matches newest first, only the ones with the given status unless it is empty
+	GetMatches(ctx context.Context, status types.DisbursementMatchStatus, limit, offset int) ([]types.DisbursementMatchListing, error)
+	GetMatchByID(ctx context.Context, id uuid.UUID) (*types.DisbursementMatchListing, error)
+	// ConfirmMatch disburses the whole transfer of a proposed match to its grant, the other proposals for the transfer are rejected
+	ConfirmMatch(ctx context.Context, id uuid.UUID, reviewer string) error
+	RejectMatch(ctx context.Context, id uuid.UUID, reviewer string) error
+	// SplitMatch disburses the transfer of a proposed match between grants, the allocations should already be validated
+	SplitMatch(ctx context.Context, id uuid.UUID, allocations []types.SplitAllocation, reviewer string) error
+}
+
+type disbursementMatch struct {
+	log                logrus.Ext1FieldLogger
+	dbConn             *sqlx.DB
+	getGrantAddresses  *sqlx.Stmt
+	addGrantAddress    *sqlx.NamedStmt
+	deleteGrantAddress *sqlx.Stmt
+	linkManual         *sqlx.Stmt
+	getCandidates      *sqlx.Stmt
+	getMatches         *sqlx.Stmt
+	getMatchByID       *sqlx.Stmt
+}
+
+// grantPaidAt is true when a transfer went to the recipient address of the grant, or one of its other addresses
+const grantPaidAt = `(t.payee_address = g.recipient_address
+	OR EXISTS (SELECT 1 FROM grant_addresses ga WHERE ga.grant_id = g.id AND ga.address = t.payee_address))`
+
+// grantPaidIn is true when a transfer was in the asset of the grant, or the asset of one of its milestones
+const grantPaidIn = `((t.chain_id = g.chain_id AND t.asset = g.asset)
+	OR EXISTS (SELECT 1 FROM milestones ms WHERE ms.grant_id = g.id AND ms.chain_id = t.chain_id AND ms.asset = t.asset))`
+
+// transferAmount is the amount of a transfer in whole units of its asset, with the asset joined as a
+const transferAmount = `(t.amount / POWER(10::NUMERIC, a.decimals))::ETHER_T`
+
+func NewDisbursementMatchDB(ctx context.Context, conf *config.Config, dbConn *sqlx.DB) (DisbursementMatchDB, error) {
+	getGrantAddresses, err := dbConn.PreparexContext(ctx, fmt.Sprintf(`
+		SELECT %s FROM grant_addresses WHERE grant_id = $1 ORDER BY created_at`,
+		strings.Join(psql.GetSQLColumnsQuoted[types.GrantAddress](), ", ")))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetGrantAddresses statement")
+	}
+
+	addGrantAddress, err := dbConn.PrepareNamedContext(ctx, `
+		INSERT INTO grant_addresses (grant_id, address, label) VALUES (:grant_id, :address, :label)`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare AddGrantAddress statement")
+	}
+
+	deleteGrantAddress, err := dbConn.PreparexContext(ctx, `DELETE FROM grant_addresses WHERE grant_id = $1 AND address = $2`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare DeleteGrantAddress statement")
+	}
+
+	// A disbursement entered by hand is linked to the first transfer of its transaction which paid the grant in the
+	// asset of the disbursement
+	linkManual, err := dbConn.PreparexContext(ctx, `
+		UPDATE disbursements d SET transfer_id = (
+			SELECT t.id FROM transfers t JOIN grants g ON (g.id = d.grant_id)
+			WHERE t.tx_hash = d.tx_hash AND t.asset = d.asset AND t.direction = 'outgoing' AND `+grantPaidAt+`
+			ORDER BY t.log_index LIMIT 1)
+		WHERE d.transfer_id IS NULL
+			AND EXISTS (SELECT 1 FROM transfers t JOIN grants g ON (g.id = d.grant_id)
+				WHERE t.tx_hash = d.tx_hash AND t.asset = d.asset AND t.direction = 'outgoing' AND `+grantPaidAt+`)`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare LinkManualDisbursements statement")
+	}
+
+	// Only transfers in an asset the grant is paid in are matched. Transfers sent before a grant started can't have paid
+	// it, and a transfer already disbursed to a grant by hand isn't proposed again.
+	getCandidates, err := dbConn.PreparexContext(ctx, `
+		SELECT t.id AS transfer_id, g.id AS grant_id, t.tx_hash, t.block_timestamp, t.asset, `+transferAmount+` AS amount
+		FROM transfers t
+			JOIN assets a ON (t.chain_id = a.chain_id AND t.asset = a.address)
+			JOIN grants g ON `+grantPaidAt+` AND `+grantPaidIn+`
+		WHERE t.direction = 'outgoing'
+			AND t.block_timestamp >= EXTRACT(EPOCH FROM g.start_date)
+			AND NOT EXISTS (SELECT 1 FROM disbursement_matches m WHERE m.transfer_id = t.id)
+			AND NOT EXISTS (SELECT 1 FROM disbursements d WHERE d.transfer_id = t.id)
+			AND NOT EXISTS (SELECT 1 FROM disbursements d WHERE d.grant_id = g.id AND d.tx_hash = t.tx_hash)
+		ORDER BY t.block_number, t.log_index, g.id`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetCandidates statement")
+	}
+
+	getMatchesQuery := `
+		SELECT m.id,
+			m.transfer_id,
+			m.grant_id,
+			m.status,
+			m.reviewed_by,
+			m.reviewed_at,
+			m.created_at,
+			g.name AS grant_name,
+			t.tx_hash,
+			t.block_number,
+			t.block_timestamp,
+			t.payer_address,
+			t.payee_address,
+			t.asset,
+			` + transferAmount + ` AS amount
+		FROM disbursement_matches m
+			JOIN transfers t ON (m.transfer_id = t.id)
+			JOIN assets a ON (t.chain_id = a.chain_id AND t.asset = a.address)
+			JOIN grants g ON (m.grant_id = g.id)`
+
+	getMatches, err := dbConn.PreparexContext(ctx, getMatchesQuery+`
+		WHERE ($1 = '' OR m.status::TEXT = $1)
+		ORDER BY m.created_at DESC, t.block_number DESC, g.name LIMIT $2 OFFSET $3`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetMatches statement")
+	}
+
+	getMatchByID, err := dbConn.PreparexContext(ctx, getMatchesQuery+` WHERE m.id = $1`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetMatchByID statement")
+	}
+
+	return &disbursementMatch{
+		log:                conf.GetLogger(),
+		dbConn:             dbConn,
+		getGrantAddresses:  getGrantAddresses,
+		addGrantAddress:    addGrantAddress,
+		deleteGrantAddress: deleteGrantAddress,
+		linkManual:         linkManual,
+		getCandidates:      getCandidates,
+		getMatches:         getMatches,
+		getMatchByID:       getMatchByID,
+	}, nil
+}
+
+func (dm *disbursementMatch) GetGrantAddresses(ctx context.Context, grantID uuid.UUID) ([]types.GrantAddress, error) {
+	var addresses []types.GrantAddress
+	err := dm.getGrantAddresses.SelectContext(ctx, &addresses, grantID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get addresses of grant %s", grantID)
+	}
+	if len(addresses) == 0 {
+		return []types.GrantAddress{}, nil
+	}
+	return addresses, nil
+}
+
+func (dm *disbursementMatch) AddGrantAddress(ctx context.Context, address types.GrantAddress) error {
+	_, err := dm.addGrantAddress.ExecContext(ctx, address)
+	if err != nil {
+		return errors.Wrapf(err, "failed to add address %s to grant %s", address.Address, address.GrantID)
+	}
+	return nil
+}
+
+func (dm *disbursementMatch) DeleteGrantAddress(ctx context.Context, grantID uuid.UUID, address string) error {
+	result, err := dm.deleteGrantAddress.ExecContext(ctx, grantID, address)
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete address %s from grant %s", address, grantID)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to get rows affected")
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("grant address not found")
+	}
+
+	return nil
+}
+
+func (dm *disbursementMatch) LinkManualDisbursements(ctx context.Context) (int64, error) {
+	result, err := dm.linkManual.ExecContext(ctx)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to link disbursements to transfers")
+	}
+	linked, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get rows affected")
+	}
+	return linked, nil
+}
+
+func (dm *disbursementMatch) GetCandidates(ctx context.Context) ([]types.DisbursementCandidate, error) {
+	var candidates []types.DisbursementCandidate
+	err := dm.getCandidates.SelectContext(ctx, &candidates)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get disbursement candidates")
+	}
+	if len(candidates) == 0 {
+		return []types.DisbursementCandidate{}, nil
+	}
+	return candidates, nil
+}
+
+func (dm *disbursementMatch) RecordMatches(ctx context.Context, matches []types.DisbursementMatch) error {
+	tx, err := dm.dbConn.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	for _, match := range matches {
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO disbursement_matches (transfer_id, grant_id, status) VALUES ($1, $2, $3)
+			ON CONFLICT (transfer_id, grant_id) DO NOTHING`,
+			match.TransferID, match.GrantID, match.Status)
+		if err != nil {
+			return errors.Wrapf(err, "failed to record match of transfer %s to grant %s", match.TransferID, match.GrantID)
+		}
+		inserted, err := result.RowsAffected()
+		if err != nil {
+			return errors.Wrap(err, "failed to get rows affected")
+		}
+		if inserted == 1 && match.Status == types.DisbursementMatchConfirmed {
+			if err := disburseTransfer(ctx, tx, match.TransferID, match.GrantID, null.String{}); err != nil {
+				return err
+			}
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, "failed to commit transaction")
+	}
+	return nil
+}
+
+func (dm *disbursementMatch) GetMatches(ctx context.Context, status types.DisbursementMatchStatus, limit, offset int) ([]types.DisbursementMatchListing, error) {
+	var matches []types.DisbursementMatchListing
+	err := dm.getMatches.SelectContext(ctx, &matches, string(status), limit, offset)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get disbursement matches")
+	}
+	if len(matches) == 0 {
+		return []types.DisbursementMatchListing{}, nil
+	}
+	for i := range matches {
+		matches[i].Amount = TrimZeros(matches[i].Amount)
+	}
+	return matches, nil
+}
+
+func (dm *disbursementMatch) GetMatchByID(ctx context.Context, id uuid.UUID) (*types.DisbursementMatchListing, error) {
+	var match types.DisbursementMatchListing
+	err := dm.getMatchByID.GetContext(ctx, &match, id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get disbursement match (%s)", id)
+	}
+	match.Amount = TrimZeros(match.Amount)
+	return &match, nil
+}
+
+func (dm *disbursementMatch) ConfirmMatch(ctx context.Context, id uuid.UUID, reviewer string) error {
+	tx, err := dm.dbConn.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	match, err := lockProposedMatch(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE disbursement_matches SET status = 'confirmed', reviewed_by = $2, reviewed_at = NOW() WHERE id = $1`, id, reviewer)
+	if err != nil {
+		return errors.Wrapf(err, "failed to confirm disbursement match (%s)", id)
+	}
+	if err := rejectOtherProposals(ctx, tx, match.TransferID, reviewer); err != nil {
+		return err
+	}
+	if err := disburseTransfer(ctx, tx, match.TransferID, match.GrantID, null.String{}); err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, "failed to commit transaction")
+	}
+	return nil
+}
+
+func (dm *disbursementMatch) RejectMatch(ctx context.Context, id uuid.UUID, reviewer string) error {
+	tx, err := dm.dbConn.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	if _, err := lockProposedMatch(ctx, tx, id); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE disbursement_matches SET status = 'rejected', reviewed_by = $2, reviewed_at = NOW() WHERE id = $1`, id, reviewer)
+	if err != nil {
+		return errors.Wrapf(err, "failed to reject disbursement match (%s)", id)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, "failed to commit transaction")
+	}
+	return nil
+}
+
+func (dm *disbursementMatch) SplitMatch(ctx context.Context, id uuid.UUID, allocations []types.SplitAllocation, reviewer string) error {
+	tx, err := dm.dbConn.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	match, err := lockProposedMatch(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	for _, allocation := range allocations {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO disbursement_matches (transfer_id, grant_id, status, reviewed_by, reviewed_at) VALUES ($1, $2, 'confirmed', $3, NOW())
+			ON CONFLICT (transfer_id, grant_id) DO UPDATE SET status = 'confirmed', reviewed_by = EXCLUDED.reviewed_by, reviewed_at = NOW()`,
+			match.TransferID, allocation.GrantID, reviewer)
+		if err != nil {
+			return errors.Wrapf(err, "failed to confirm match of transfer %s to grant %s", match.TransferID, allocation.GrantID)
+		}
+		if err := disburseTransfer(ctx, tx, match.TransferID, allocation.GrantID, null.StringFrom(allocation.Amount)); err != nil {
+			return err
+		}
+	}
+	if err := rejectOtherProposals(ctx, tx, match.TransferID, reviewer); err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, "failed to commit transaction")
+	}
+	return nil
+}
+
+// lockProposedMatch locks a match until the transaction ends. Only proposed matches of transfers which haven't been
+// disbursed can be reviewed.
+func lockProposedMatch(ctx context.Context, tx *sqlx.Tx, id uuid.UUID) (*types.DisbursementMatch, error) {
+	var match types.DisbursementMatch
+	err := tx.GetContext(ctx, &match, fmt.Sprintf(`SELECT %s FROM disbursement_matches WHERE id = $1 FOR UPDATE`,
+		strings.Join(psql.GetSQLColumnsQuoted[types.DisbursementMatch](), ", ")), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("disbursement match not found")
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to lock disbursement match (%s)", id)
+	}
+	if match.Status != types.DisbursementMatchProposed {
+		return nil, errors.Errorf("disbursement match already %s", match.Status)
+	}
+
+	var disbursed bool
+	err = tx.GetContext(ctx, &disbursed, `SELECT EXISTS(SELECT 1 FROM disbursements WHERE transfer_id = $1)`, match.TransferID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to check disbursements of transfer %s", match.TransferID)
+	}
+	if disbursed {
+		return nil, errors.New("transfer already disbursed")
+	}
+	return &match, nil
+}
+
+func rejectOtherProposals(ctx context.Context, tx *sqlx.Tx, transferID uuid.UUID, reviewer string) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE disbursement_matches SET status = 'rejected', reviewed_by = $2, reviewed_at = NOW()
+		WHERE transfer_id = $1 AND status = 'proposed'`, transferID, reviewer)
+	if err != nil {
+		return errors.Wrapf(err, "failed to reject other matches of transfer %s", transferID)
+	}
+	return nil
+}
+
+// disburseTransfer creates a disbursement of a transfer to a grant, of the whole transfer unless an amount is given,
+// and updates how much the grant has been given so far. The transfer was read from the chain, so it is verified.
+func disburseTransfer(ctx context.Context, tx *sqlx.Tx, transferID, grantID uuid.UUID, amount null.String) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO disbursements (grant_id, chain_id, asset, amount, tx_hash, block_number, block_timestamp, transfer_id, verification_status, verified_at, usd_price)
+		SELECT $2, t.chain_id, t.asset, COALESCE($3::ETHER_T, `+transferAmount+`), t.tx_hash, t.block_number, t.block_timestamp, t.id, 'verified', NOW(), ap.usd_price
+		FROM transfers t
+			JOIN assets a ON (t.chain_id = a.chain_id AND t.asset = a.address)
+			LEFT JOIN asset_prices ap ON (t.chain_id = ap.chain_id AND t.asset = ap.address
+				AND ABS(EXTRACT(EPOCH FROM ap.updated_at) - t.block_timestamp) <= $4)
+		WHERE t.id = $1`, transferID, grantID, amount, priceWindow.Seconds())
+	if err != nil {
+		return errors.Wrapf(err, "failed to disburse transfer %s to grant %s", transferID, grantID)
+	}
+
+	return syncAmountGiven(ctx, tx, grantID)
+}
@@ -0,0 +1,299 @@
+//go:build integration
+// +build integration
+
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ETHCF/ethutils"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/constants"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+func GetTestDisbursementMatchDB(t *testing.T) DisbursementMatchDB {
+	mdb, err := NewDisbursementMatchDB(t.Context(), conf, dbConn)
+	require.NoError(t, err)
+	return mdb
+}
+
+func Test_DisbursementMatchDB(t *testing.T) {
+	var (
+		db        = GetTestDisbursementMatchDB(t)
+		grants    = GetTestGrantDB(t)
+		treasury  = GetTestTreasuryDB(t)
+		wallet    = ethutils.GenRandEVMAddr()
+		recipient = ethutils.GenRandEVMAddr()
+		alternate = ethutils.GenRandEVMAddr()
+		reviewer  = ethutils.GenRandEVMAddr()
+		newGrant  = func(name string) uuid.UUID {
+			id, err := grants.CreateGrant(t.Context(), types.CreateGrant{
+				Name:                   name,
+				RecipientName:          "Matched Recipient",
+				RecipientAddress:       recipient,
+				Description:            "Testing disbursement matching",
+				TotalGrantAmount:       "10",
+				InitialGrantAmount:     "0",
+				StartDate:              time.Now().AddDate(0, 0, -7),
+				ExpectedCompletionDate: time.Now().AddDate(1, 0, 0),
+				AmountGivenSoFar:       "0",
+				Status:                 types.GrantStatusActive,
+			})
+			require.NoError(t, err)
+			return id
+		}
+		grantA = newGrant("Matched Grant A")
+		grantB = newGrant("Matched Grant B")
+	)
+
+	err := db.AddGrantAddress(t.Context(), types.GrantAddress{GrantID: grantA, Address: alternate, Label: null.StringFrom("Multisig")})
+	require.NoError(t, err)
+	err = db.AddGrantAddress(t.Context(), types.GrantAddress{GrantID: grantA, Address: alternate})
+	require.ErrorContains(t, err, "duplicate key value")
+	addresses, err := db.GetGrantAddresses(t.Context(), grantA)
+	require.NoError(t, err)
+	require.Len(t, addresses, 1)
+	require.Equal(t, alternate, addresses[0].Address)
+
+	transfers := []types.CreateTransfer{
+		{ToAddress: recipient, Amount: "1500000000000000000"},                                                       // Either grant
+		{ToAddress: alternate, Amount: "2000000000000000000"},                                                       // Only grant A
+		{ToAddress: recipient, Amount: "1000000000000000000", BlockTimestamp: time.Now().AddDate(0, 0, -30).Unix()}, // Before both grants started
+		{ToAddress: recipient, Amount: "1000000", Asset: ethutils.GenRandEVMAddr()},                                 // Not ether
+	}
+	for i := range transfers {
+		transfers[i].ChainID = 1
+		transfers[i].TxHash = ethutils.GenRandEVMHash()
+		transfers[i].BlockNumber = int64(i + 1)
+		transfers[i].FromAddress = wallet
+		transfers[i].Direction = types.TransferTypeOutgoing
+		if transfers[i].BlockTimestamp == 0 {
+			transfers[i].BlockTimestamp = time.Now().Unix()
+		}
+		if transfers[i].Asset == "" {
+			transfers[i].Asset = constants.EtherAddress
+		}
+		err = treasury.CreateTransfer(t.Context(), transfers[i])
+		require.NoError(t, err)
+	}
+
+	ours := func() []types.DisbursementCandidate {
+		candidates, err := db.GetCandidates(t.Context())
+		require.NoError(t, err)
+		var found []types.DisbursementCandidate
+		for _, candidate := range candidates {
+			if candidate.GrantID == grantA || candidate.GrantID == grantB {
+				found = append(found, candidate)
+			}
+		}
+		return found
+	}
+	candidates := ours()
+	require.Len(t, candidates, 3)
+	require.Equal(t, transfers[0].TxHash, candidates[0].TxHash)
+	require.Equal(t, transfers[0].TxHash, candidates[1].TxHash)
+	require.Equal(t, transfers[1].TxHash, candidates[2].TxHash)
+	require.Equal(t, grantA, candidates[2].GrantID)
+	require.Equal(t, "2.000000000000000000", candidates[2].Amount)
+
+	matches := []types.DisbursementMatch{
+		{TransferID: candidates[0].TransferID, GrantID: candidates[0].GrantID, Status: types.DisbursementMatchProposed},
+		{TransferID: candidates[1].TransferID, GrantID: candidates[1].GrantID, Status: types.DisbursementMatchProposed},
+		{TransferID: candidates[2].TransferID, GrantID: candidates[2].GrantID, Status: types.DisbursementMatchConfirmed},
+	}
+	err = db.RecordMatches(t.Context(), matches)
+	require.NoError(t, err)
+	require.Empty(t, ours(), "matched transfers aren't candidates anymore")
+
+	// The confirmed match was disbursed straight away
+	disbursements, err := grants.GetDisbursementsByGrantID(t.Context(), grantA)
+	require.NoError(t, err)
+	require.Len(t, disbursements, 1)
+	require.Equal(t, "2", disbursements[0].Amount)
+	require.Equal(t, transfers[1].TxHash, disbursements[0].TxHash)
+	require.Equal(t, candidates[2].TransferID, disbursements[0].TransferID.UUID)
+
+	proposed, err := db.GetMatches(t.Context(), types.DisbursementMatchProposed, 1000, 0)
+	require.NoError(t, err)
+	var proposals []types.DisbursementMatchListing
+	for _, match := range proposed {
+		if match.TransferID == candidates[0].TransferID {
+			proposals = append(proposals, match)
+		}
+	}
+	require.Len(t, proposals, 2)
+	require.Equal(t, "1.5", proposals[0].Amount)
+	require.Equal(t, recipient, proposals[0].PayeeAddress)
+
+	// Split the shared transfer between both grants
+	err = db.SplitMatch(t.Context(), proposals[0].ID, []types.SplitAllocation{
+		{GrantID: grantA, Amount: "0.5"},
+		{GrantID: grantB, Amount: "1"},
+	}, reviewer)
+	require.NoError(t, err)
+
+	err = db.RejectMatch(t.Context(), proposals[1].ID, reviewer)
+	require.ErrorContains(t, err, "already confirmed")
+	err = db.ConfirmMatch(t.Context(), uuid.New(), reviewer)
+	require.ErrorContains(t, err, "not found")
+
+	split, err := db.GetMatchByID(t.Context(), proposals[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, types.DisbursementMatchConfirmed, split.Status)
+	require.Equal(t, reviewer, split.ReviewedBy.String)
+
+	grant, err := grants.GetGrantByID(t.Context(), grantA)
+	require.NoError(t, err)
+	require.Equal(t, "2.5", grant.AmountGivenSoFar)
+	grant, err = grants.GetGrantByID(t.Context(), grantB)
+	require.NoError(t, err)
+	require.Equal(t, "1", grant.AmountGivenSoFar)
+
+	// A disbursement entered by hand is linked to its transfer rather than proposed
+	manual := types.CreateTransfer{
+		ChainID:        1,
+		TxHash:         ethutils.GenRandEVMHash(),
+		BlockNumber:    10,
+		BlockTimestamp: time.Now().Unix(),
+		FromAddress:    wallet,
+		ToAddress:      recipient,
+		Asset:          constants.EtherAddress,
+		Amount:         "3000000000000000000",
+		Direction:      types.TransferTypeOutgoing,
+	}
+	err = treasury.CreateTransfer(t.Context(), manual)
+	require.NoError(t, err)
+	err = grants.InsertDisbursement(t.Context(), grantB, types.CreateDisbursement{
+		Amount:         "3",
+		TxHash:         manual.TxHash,
+		BlockNumber:    manual.BlockNumber,
+		BlockTimestamp: manual.BlockTimestamp,
+	})
+	require.NoError(t, err)
+
+	linked, err := db.LinkManualDisbursements(t.Context())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, linked, int64(1))
+	require.Empty(t, ours())
+
+	disbursements, err = grants.GetDisbursementsByGrantID(t.Context(), grantB)
+	require.NoError(t, err)
+	for _, d := range disbursements {
+		require.True(t, d.TransferID.Valid)
+	}
+
+	err = db.DeleteGrantAddress(t.Context(), grantA, alternate)
+	require.NoError(t, err)
+	err = db.DeleteGrantAddress(t.Context(), grantA, alternate)
+	require.ErrorContains(t, err, "not found")
+
+	// Clean up
+	for _, id := range []uuid.UUID{grantA, grantB} {
+		_, err = dbConn.ExecContext(t.Context(), "DELETE FROM grants WHERE id = $1", id)
+		require.NoError(t, err)
+	}
+	_, err = dbConn.ExecContext(t.Context(), "DELETE FROM transfers WHERE payer_address = $1", wallet)
+	require.NoError(t, err)
+}
+
+func Test_DisbursementMatchDB_GrantAsset(t *testing.T) {
+	var (
+		db        = GetTestDisbursementMatchDB(t)
+		grants    = GetTestGrantDB(t)
+		treasury  = GetTestTreasuryDB(t)
+		wallet    = ethutils.GenRandEVMAddr()
+		recipient = ethutils.GenRandEVMAddr()
+		stable    = ethutils.GenRandEVMAddr()
+	)
+
+	_, err := dbConn.ExecContext(t.Context(),
+		"INSERT INTO assets (chain_id, address, name, symbol, decimals) VALUES (1, $1, 'Test Dollar', 'TUSD', 6)", stable)
+	require.NoError(t, err)
+
+	grantID, err := grants.CreateGrant(t.Context(), types.CreateGrant{
+		Name:                   "Stablecoin Grant",
+		RecipientName:          "Stablecoin Recipient",
+		RecipientAddress:       recipient,
+		Description:            "Testing disbursement matching in a 6 decimal asset",
+		Asset:                  stable,
+		TotalGrantAmount:       "1000",
+		InitialGrantAmount:     "0",
+		StartDate:              time.Now().AddDate(0, 0, -7),
+		ExpectedCompletionDate: time.Now().AddDate(1, 0, 0),
+		AmountGivenSoFar:       "0",
+		Status:                 types.GrantStatusActive,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		for _, query := range []string{
+			"DELETE FROM grants WHERE id = $1",
+			"DELETE FROM transfers WHERE payee_address = $2",
+			"DELETE FROM assets WHERE address = $3",
+		} {
+			_, err := dbConn.ExecContext(context.Background(), query, grantID, recipient, stable)
+			require.NoError(t, err)
+		}
+	})
+
+	transfers := []types.CreateTransfer{
+		{Asset: stable, Amount: "2500000"},                             // 2.5 in the grant's asset
+		{Asset: constants.EtherAddress, Amount: "1000000000000000000"}, // Only once a milestone is paid in ether
+	}
+	for i := range transfers {
+		transfers[i].ChainID = 1
+		transfers[i].TxHash = ethutils.GenRandEVMHash()
+		transfers[i].BlockNumber = int64(i + 1)
+		transfers[i].BlockTimestamp = time.Now().Unix()
+		transfers[i].FromAddress = wallet
+		transfers[i].ToAddress = recipient
+		transfers[i].Direction = types.TransferTypeOutgoing
+		require.NoError(t, treasury.CreateTransfer(t.Context(), transfers[i]))
+	}
+
+	ours := func() []types.DisbursementCandidate {
+		candidates, err := db.GetCandidates(t.Context())
+		require.NoError(t, err)
+		var found []types.DisbursementCandidate
+		for _, candidate := range candidates {
+			if candidate.GrantID == grantID {
+				found = append(found, candidate)
+			}
+		}
+		return found
+	}
+	candidates := ours()
+	require.Len(t, candidates, 1)
+	require.Equal(t, transfers[0].TxHash, candidates[0].TxHash)
+	require.Equal(t, stable, candidates[0].Asset)
+	require.Equal(t, "2.500000000000000000", candidates[0].Amount)
+
+	_, err = grants.UpdateGrantMilestones(t.Context(), grantID, []types.Milestone{
+		{ID: uuid.New(), Name: "Ether Milestone", Description: "Paid in ether", Asset: constants.EtherAddress, GrantAmount: "1"},
+	})
+	require.NoError(t, err)
+	candidates = ours()
+	require.Len(t, candidates, 2)
+	require.Equal(t, constants.EtherAddress, candidates[1].Asset)
+	require.Equal(t, "1.000000000000000000", candidates[1].Amount)
+
+	err = db.RecordMatches(t.Context(), []types.DisbursementMatch{
+		{TransferID: candidates[0].TransferID, GrantID: grantID, Status: types.DisbursementMatchConfirmed},
+	})
+	require.NoError(t, err)
+
+	disbursements, err := grants.GetDisbursementsByGrantID(t.Context(), grantID)
+	require.NoError(t, err)
+	require.Len(t, disbursements, 1)
+	require.Equal(t, stable, disbursements[0].Asset)
+	require.Equal(t, "2.5", disbursements[0].Amount)
+
+	grant, err := grants.GetGrantByID(t.Context(), grantID)
+	require.NoError(t, err)
+	require.Equal(t, "2.5", grant.AmountGivenSoFar)
+}
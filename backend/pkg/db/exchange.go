@@ -0,0 +1,128 @@
+package db
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/numbergroup/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/config"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+type ExchangeDB interface {
+	// ReplaceBalances replaces all of the balances of an exchange account
+	ReplaceBalances(ctx context.Context, exchange string, balances []types.ExchangeBalance) error
+	// UpsertMovements stores deposits and withdrawals, updating the status and hash of ones already stored
+	UpsertMovements(ctx context.Context, movements []types.ExchangeMovement) error
+	// GetMovements returns deposits and withdrawals newest first, matched to transfers by tx hash
+	GetMovements(ctx context.Context, limit, offset int) ([]types.ExchangeMovement, error)
+}
+
+type exchange struct {
+	log          logrus.Ext1FieldLogger
+	dbConn       *sqlx.DB
+	getMovements *sqlx.Stmt
+}
+
+func NewExchangeDB(ctx context.Context, conf *config.Config, dbConn *sqlx.DB) (ExchangeDB, error) {
+	getMovements, err := dbConn.PreparexContext(ctx, `
+		SELECT m.exchange,
+			m.external_id,
+			m.direction,
+			m.currency,
+			m.amount,
+			m.status,
+			m.tx_hash,
+			m.address,
+			m.occurred_at,
+			m.updated_at,
+			t.id AS transfer_id
+		FROM exchange_movements m
+			LEFT JOIN LATERAL (
+				SELECT id FROM transfers WHERE tx_hash = m.tx_hash ORDER BY log_index LIMIT 1
+			) t ON TRUE
+		ORDER BY m.occurred_at DESC, m.exchange, m.external_id
+		LIMIT $1 OFFSET $2`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetMovements statement")
+	}
+
+	return &exchange{
+		log:          conf.GetLogger(),
+		dbConn:       dbConn,
+		getMovements: getMovements,
+	}, nil
+}
+
+func (e *exchange) ReplaceBalances(ctx context.Context, exchange string, balances []types.ExchangeBalance) error {
+	tx, err := e.dbConn.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM exchange_balances WHERE exchange = $1", exchange)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete existing exchange balances")
+	}
+
+	for _, balance := range balances {
+		_, err = tx.NamedExecContext(ctx, `
+			INSERT INTO exchange_balances (exchange, currency, amount, usd_value, last_updated)
+			VALUES (:exchange, :currency, :amount, :usd_value, :last_updated)`, balance)
+		if err != nil {
+			return errors.Wrapf(err, "failed to insert %s balance of %s", exchange, balance.Currency)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, "failed to commit transaction")
+	}
+	return nil
+}
+
+func (e *exchange) UpsertMovements(ctx context.Context, movements []types.ExchangeMovement) error {
+	tx, err := e.dbConn.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	for _, movement := range movements {
+		_, err = tx.NamedExecContext(ctx, `
+			INSERT INTO exchange_movements (exchange, external_id, direction, currency, amount, status, tx_hash, address, occurred_at)
+			VALUES (:exchange, :external_id, :direction, :currency, :amount, :status, :tx_hash, :address, :occurred_at)
+			ON CONFLICT (exchange, external_id) DO UPDATE SET
+				status = EXCLUDED.status,
+				tx_hash = EXCLUDED.tx_hash,
+				address = EXCLUDED.address,
+				updated_at = NOW()`, movement)
+		if err != nil {
+			return errors.Wrapf(err, "failed to upsert %s movement %s", movement.Exchange, movement.ExternalID)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, "failed to commit transaction")
+	}
+	return nil
+}
+
+func (e *exchange) GetMovements(ctx context.Context, limit, offset int) ([]types.ExchangeMovement, error) {
+	var movements []types.ExchangeMovement
+	err := e.getMovements.SelectContext(ctx, &movements, limit, offset)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get exchange movements")
+	}
+	if len(movements) == 0 {
+		return []types.ExchangeMovement{}, nil
+	}
+	for i := range movements {
+		movements[i].Amount = TrimZeros(movements[i].Amount)
+	}
+	return movements, nil
+}
@@ -0,0 +1,117 @@
+//go:build integration
+// +build integration
+
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ETHCF/ethutils"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+func GetTestExchangeDB(t *testing.T) ExchangeDB {
+	edb, err := NewExchangeDB(t.Context(), conf, dbConn)
+	require.NoError(t, err)
+	return edb
+}
+
+func Test_ExchangeDB_MovementMatching(t *testing.T) {
+	var (
+		db       = GetTestExchangeDB(t)
+		treasury = GetTestTreasuryDB(t)
+		exchange = "test-" + ethutils.GenRandEVMAddr()[2:10]
+		txHash   = ethutils.GenRandEVMHash()
+		transfer = types.CreateTransfer{
+			TxHash:         txHash,
+			BlockNumber:    12545678,
+			BlockTimestamp: time.Now().Unix(),
+			FromAddress:    ethutils.GenRandEVMAddr(),
+			ToAddress:      ethutils.GenRandEVMAddr(),
+			Asset:          ethutils.GenRandEVMAddr(),
+			Amount:         "100",
+			Direction:      types.TransferTypeOutgoing,
+		}
+		matched = types.ExchangeMovement{
+			Exchange:   exchange,
+			ExternalID: "matched",
+			Direction:  types.ExchangeDeposit,
+			Currency:   "ETH",
+			Amount:     "1.5",
+			Status:     "completed",
+			TxHash:     null.StringFrom(txHash),
+			OccurredAt: time.Now().Add(-time.Hour),
+		}
+		pending = types.ExchangeMovement{
+			Exchange:   exchange,
+			ExternalID: "pending",
+			Direction:  types.ExchangeWithdrawal,
+			Currency:   "ETH",
+			Amount:     "2",
+			Status:     "pending",
+			OccurredAt: time.Now().Add(-2 * time.Hour),
+		}
+	)
+
+	err := treasury.CreateTransfer(t.Context(), transfer)
+	require.NoError(t, err)
+
+	err = db.UpsertMovements(t.Context(), []types.ExchangeMovement{matched, pending})
+	require.NoError(t, err)
+
+	// Once the withdrawal completes it gets a hash, but no transfer has it
+	pending.Status = "completed"
+	pending.TxHash = null.StringFrom(ethutils.GenRandEVMHash())
+	err = db.UpsertMovements(t.Context(), []types.ExchangeMovement{pending})
+	require.NoError(t, err)
+
+	movements, err := db.GetMovements(t.Context(), 1000, 0)
+	require.NoError(t, err)
+	byID := map[string]types.ExchangeMovement{}
+	for _, movement := range movements {
+		if movement.Exchange == exchange {
+			byID[movement.ExternalID] = movement
+		}
+	}
+	require.Len(t, byID, 2)
+	require.True(t, byID["matched"].TransferID.Valid)
+	require.Equal(t, "1.5", byID["matched"].Amount)
+	require.False(t, byID["pending"].TransferID.Valid)
+	require.Equal(t, "completed", byID["pending"].Status)
+	require.Equal(t, pending.TxHash, byID["pending"].TxHash)
+
+	// Balances are replaced as a whole and counted in the treasury
+	err = db.ReplaceBalances(t.Context(), exchange, []types.ExchangeBalance{
+		{Exchange: exchange, Currency: "ETH", Amount: "3.5", UsdValue: null.FloatFrom(10500), LastUpdated: time.Now()},
+		{Exchange: exchange, Currency: "USDC", Amount: "100", LastUpdated: time.Now()},
+	})
+	require.NoError(t, err)
+	err = db.ReplaceBalances(t.Context(), exchange, []types.ExchangeBalance{
+		{Exchange: exchange, Currency: "ETH", Amount: "3", UsdValue: null.FloatFrom(9000), LastUpdated: time.Now()},
+	})
+	require.NoError(t, err)
+
+	balances, err := treasury.GetExchangeBalances(t.Context())
+	require.NoError(t, err)
+	var found []types.ExchangeBalance
+	for _, balance := range balances {
+		if balance.Exchange == exchange {
+			found = append(found, balance)
+		}
+	}
+	require.Len(t, found, 1)
+	require.Equal(t, "3", found[0].Amount)
+	require.Equal(t, 9000.0, found[0].UsdValue.Float64)
+
+	// Cleanup
+	_, err = dbConn.ExecContext(t.Context(), "DELETE FROM exchange_movements WHERE exchange = $1", exchange)
+	require.NoError(t, err)
+	_, err = dbConn.ExecContext(t.Context(), "DELETE FROM exchange_balances WHERE exchange = $1", exchange)
+	require.NoError(t, err)
+	_, err = dbConn.ExecContext(t.Context(), "DELETE FROM transfers WHERE tx_hash = $1", txHash)
+	require.NoError(t, err)
+}
@@ -0,0 +1,137 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/innodv/psql"
+	"github.com/jmoiron/sqlx"
+	"github.com/numbergroup/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/config"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+type GrantDocumentDB interface {
+	CreateDocument(ctx context.Context, document types.GrantDocument) error
+	// GetDocumentsByGrantID returns the documents of a grant oldest first, the private ones only when asked for
+	GetDocumentsByGrantID(ctx context.Context, grantID uuid.UUID, includePrivate bool) ([]types.GrantDocument, error)
+	GetDocument(ctx context.Context, id uuid.UUID) (*types.GrantDocument, error)
+	SetDocumentVisibility(ctx context.Context, id uuid.UUID, visibility types.DocumentVisibility) error
+	// DeleteDocument deletes the record of a document and returns it, so its file can be removed from storage
+	DeleteDocument(ctx context.Context, id uuid.UUID) (*types.GrantDocument, error)
+}
+
+type grantDocument struct {
+	log                   logrus.Ext1FieldLogger
+	createDocument        *sqlx.NamedStmt
+	getDocumentsByGrantID *sqlx.Stmt
+	getDocument           *sqlx.Stmt
+	setVisibility         *sqlx.Stmt
+	deleteDocument        *sqlx.Stmt
+}
+
+func NewGrantDocumentDB(ctx context.Context, conf *config.Config, dbConn *sqlx.DB) (GrantDocumentDB, error) {
+	documentCols := strings.Join(psql.GetSQLColumnsQuoted[types.GrantDocument](), ", ")
+
+	createDocument, err := dbConn.PrepareNamedContext(ctx, fmt.Sprintf(`
+		INSERT INTO grant_documents (%s) VALUES (%s)`,
+		documentCols, ":"+strings.Join(psql.GetSQLColumns[types.GrantDocument](), ", :")))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare CreateDocument statement")
+	}
+
+	getDocumentsByGrantID, err := dbConn.PreparexContext(ctx, fmt.Sprintf(`
+		SELECT %s FROM grant_documents WHERE grant_id = $1 AND ($2 OR visibility = 'public') ORDER BY created_at, id`, documentCols))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetDocumentsByGrantID statement")
+	}
+
+	getDocument, err := dbConn.PreparexContext(ctx, fmt.Sprintf(`SELECT %s FROM grant_documents WHERE id = $1`, documentCols))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetDocument statement")
+	}
+
+	setVisibility, err := dbConn.PreparexContext(ctx, `UPDATE grant_documents SET visibility = $2 WHERE id = $1`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare SetDocumentVisibility statement")
+	}
+
+	deleteDocument, err := dbConn.PreparexContext(ctx, fmt.Sprintf(`DELETE FROM grant_documents WHERE id = $1 RETURNING %s`, documentCols))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare DeleteDocument statement")
+	}
+
+	return &grantDocument{
+		log:                   conf.GetLogger(),
+		createDocument:        createDocument,
+		getDocumentsByGrantID: getDocumentsByGrantID,
+		getDocument:           getDocument,
+		setVisibility:         setVisibility,
+		deleteDocument:        deleteDocument,
+	}, nil
+}
+
+func (gd *grantDocument) CreateDocument(ctx context.Context, document types.GrantDocument) error {
+	document.UploadedBy = strings.ToLower(document.UploadedBy)
+	_, err := gd.createDocument.ExecContext(ctx, document)
+	if err != nil {
+		return errors.Wrap(err, "failed to create grant document")
+	}
+	return nil
+}
+
+func (gd *grantDocument) GetDocumentsByGrantID(ctx context.Context, grantID uuid.UUID, includePrivate bool) ([]types.GrantDocument, error) {
+	var documents []types.GrantDocument
+	err := gd.getDocumentsByGrantID.SelectContext(ctx, &documents, grantID, includePrivate)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get documents by grant ID (%s)", grantID)
+	}
+	if len(documents) == 0 {
+		return []types.GrantDocument{}, nil
+	}
+	return documents, nil
+}
+
+func (gd *grantDocument) GetDocument(ctx context.Context, id uuid.UUID) (*types.GrantDocument, error) {
+	var document types.GrantDocument
+	err := gd.getDocument.GetContext(ctx, &document, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("grant document not found")
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get grant document (%s)", id)
+	}
+	return &document, nil
+}
+
+func (gd *grantDocument) SetDocumentVisibility(ctx context.Context, id uuid.UUID, visibility types.DocumentVisibility) error {
+	result, err := gd.setVisibility.ExecContext(ctx, id, visibility)
+	if err != nil {
+		return errors.Wrapf(err, "failed to set grant document visibility (%s)", id)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to get rows affected")
+	}
+	if rowsAffected == 0 {
+		return errors.New("grant document not found")
+	}
+	return nil
+}
+
+func (gd *grantDocument) DeleteDocument(ctx context.Context, id uuid.UUID) (*types.GrantDocument, error) {
+	var document types.GrantDocument
+	err := gd.deleteDocument.GetContext(ctx, &document, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("grant document not found")
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to delete grant document (%s)", id)
+	}
+	return &document, nil
+}
@@ -0,0 +1,108 @@
+//go:build integration
+// +build integration
+
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ETHCF/ethutils"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+func GetTestGrantDocumentDB(t *testing.T) GrantDocumentDB {
+	ddb, err := NewGrantDocumentDB(context.Background(), conf, dbConn)
+	require.NoError(t, err)
+	return ddb
+}
+
+func Test_GrantDocumentDB(t *testing.T) {
+	var (
+		grantDB    = GetTestGrantDB(t)
+		documentDB = GetTestGrantDocumentDB(t)
+		admin      = ethutils.GenRandEVMAddr()
+	)
+
+	grantID, err := grantDB.CreateGrant(t.Context(), types.CreateGrant{
+		Name:               "Test Grant Documents",
+		RecipientName:      "Test Recipient Documents",
+		RecipientAddress:   ethutils.GenRandEVMAddr(),
+		Description:        "Testing grant documents",
+		TotalGrantAmount:   "1",
+		InitialGrantAmount: "0",
+		AmountGivenSoFar:   "0",
+		Status:             types.GrantStatusActive,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, err := dbConn.ExecContext(context.Background(), "DELETE FROM grants WHERE id = $1", grantID)
+		require.NoError(t, err)
+	})
+
+	milestoneID := uuid.New()
+	_, err = grantDB.UpdateGrantMilestones(t.Context(), grantID, []types.Milestone{
+		{ID: milestoneID, Name: "Only Milestone", Description: "Do it", GrantAmount: "1"},
+	})
+	require.NoError(t, err)
+
+	agreement := types.GrantDocument{
+		ID:         uuid.New(),
+		GrantID:    grantID,
+		Category:   types.GrantDocumentAgreement,
+		Name:       "Grant agreement",
+		FileName:   null.StringFrom("agreement.pdf"),
+		FileSize:   1024,
+		MimeType:   null.StringFrom("application/pdf"),
+		StorageID:  uuid.New().String(),
+		Visibility: types.DocumentPrivate,
+		UploadedBy: admin,
+		CreatedAt:  time.Now(),
+	}
+	evidence := types.GrantDocument{
+		ID:          uuid.New(),
+		GrantID:     grantID,
+		MilestoneID: uuid.NullUUID{UUID: milestoneID, Valid: true},
+		Category:    types.GrantDocumentMilestoneEvidence,
+		Name:        "Deliverable",
+		FileSize:    2048,
+		StorageID:   uuid.New().String(),
+		Visibility:  types.DocumentPublic,
+		UploadedBy:  admin,
+		CreatedAt:   time.Now().Add(time.Second),
+	}
+	require.NoError(t, documentDB.CreateDocument(t.Context(), agreement))
+	require.NoError(t, documentDB.CreateDocument(t.Context(), evidence))
+
+	documents, err := documentDB.GetDocumentsByGrantID(t.Context(), grantID, true)
+	require.NoError(t, err)
+	require.Len(t, documents, 2)
+	require.Equal(t, agreement.ID, documents[0].ID)
+	require.Equal(t, agreement.StorageID, documents[0].StorageID)
+	require.Equal(t, milestoneID, documents[1].MilestoneID.UUID)
+
+	// Private documents aren't listed publicly
+	documents, err = documentDB.GetDocumentsByGrantID(t.Context(), grantID, false)
+	require.NoError(t, err)
+	require.Len(t, documents, 1)
+	require.Equal(t, evidence.ID, documents[0].ID)
+
+	require.NoError(t, documentDB.SetDocumentVisibility(t.Context(), agreement.ID, types.DocumentPublic))
+	document, err := documentDB.GetDocument(t.Context(), agreement.ID)
+	require.NoError(t, err)
+	require.Equal(t, types.DocumentPublic, document.Visibility)
+	require.ErrorContains(t, documentDB.SetDocumentVisibility(t.Context(), uuid.New(), types.DocumentPublic), "not found")
+
+	deleted, err := documentDB.DeleteDocument(t.Context(), agreement.ID)
+	require.NoError(t, err)
+	require.Equal(t, agreement.StorageID, deleted.StorageID)
+	_, err = documentDB.GetDocument(t.Context(), agreement.ID)
+	require.ErrorContains(t, err, "not found")
+	_, err = documentDB.DeleteDocument(t.Context(), agreement.ID)
+	require.ErrorContains(t, err, "not found")
+}
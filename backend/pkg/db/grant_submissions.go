@@ -0,0 +1,193 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/innodv/psql"
+	"github.com/jmoiron/sqlx"
+	"github.com/numbergroup/errors"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/config"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+type GrantSubmissionDB interface {
+	// IsGrantRecipient tells whether the address is the recipient address of any grant
+	IsGrantRecipient(ctx context.Context, address string) (bool, error)
+
+	CreateSubmission(ctx context.Context, submission types.CreateGrantSubmission) (*types.GrantSubmission, error)
+	// GetSubmissions returns the submissions matching the filter, newest first
+	GetSubmissions(ctx context.Context, filter types.GrantSubmissionFilter) ([]types.GrantSubmission, error)
+	GetSubmission(ctx context.Context, id uuid.UUID) (*types.GrantSubmission, error)
+	// ReviewSubmission approves or rejects a pending submission. Approving applies it: milestone progress completes the
+	// milestone and a funds usage entry is added to the grant, in the same transaction.
+	ReviewSubmission(ctx context.Context, id uuid.UUID, status types.GrantSubmissionStatus, note null.String, reviewer string) (*types.GrantSubmission, error)
+}
+
+type grantSubmission struct {
+	log              logrus.Ext1FieldLogger
+	dbConn           *sqlx.DB
+	isGrantRecipient *sqlx.Stmt
+	createSubmission *sqlx.NamedStmt
+	getSubmissions   *sqlx.Stmt
+	getSubmission    *sqlx.Stmt
+}
+
+func NewGrantSubmissionDB(ctx context.Context, conf *config.Config, dbConn *sqlx.DB) (GrantSubmissionDB, error) {
+	submissionCols := strings.Join(psql.GetSQLColumnsQuoted[types.GrantSubmission](), ", ")
+
+	isGrantRecipient, err := dbConn.PreparexContext(ctx, `SELECT EXISTS(SELECT 1 FROM grants WHERE recipient_address = $1)`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare IsGrantRecipient statement")
+	}
+
+	createSubmission, err := dbConn.PrepareNamedContext(ctx, fmt.Sprintf(`
+		INSERT INTO grant_submissions (%s) VALUES (%s) RETURNING %s`,
+		strings.Join(psql.GetSQLColumnsQuoted[types.CreateGrantSubmission](), ", "),
+		":"+strings.Join(psql.GetSQLColumns[types.CreateGrantSubmission](), ", :"),
+		submissionCols))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare CreateSubmission statement")
+	}
+
+	getSubmissions, err := dbConn.PreparexContext(ctx, fmt.Sprintf(`
+		SELECT %s FROM grant_submissions
+		WHERE ($1::UUID IS NULL OR grant_id = $1)
+			AND ($2 = '' OR recipient_address = $2)
+			AND ($3 = '' OR status::TEXT = $3)
+		ORDER BY created_at DESC, id LIMIT $4 OFFSET $5`, submissionCols))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetSubmissions statement")
+	}
+
+	getSubmission, err := dbConn.PreparexContext(ctx, fmt.Sprintf(`SELECT %s FROM grant_submissions WHERE id = $1`, submissionCols))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetSubmission statement")
+	}
+
+	return &grantSubmission{
+		log:              conf.GetLogger(),
+		dbConn:           dbConn,
+		isGrantRecipient: isGrantRecipient,
+		createSubmission: createSubmission,
+		getSubmissions:   getSubmissions,
+		getSubmission:    getSubmission,
+	}, nil
+}
+
+func (gs *grantSubmission) IsGrantRecipient(ctx context.Context, address string) (bool, error) {
+	var exists bool
+	err := gs.isGrantRecipient.GetContext(ctx, &exists, strings.ToLower(address))
+	if err != nil {
+		return false, errors.Wrap(err, "failed to check if address is a grant recipient")
+	}
+	return exists, nil
+}
+
+func (gs *grantSubmission) CreateSubmission(ctx context.Context, submission types.CreateGrantSubmission) (*types.GrantSubmission, error) {
+	submission.RecipientAddress = strings.ToLower(submission.RecipientAddress)
+
+	var out types.GrantSubmission
+	err := gs.createSubmission.GetContext(ctx, &out, submission)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create grant submission")
+	}
+	return &out, nil
+}
+
+func (gs *grantSubmission) GetSubmissions(ctx context.Context, filter types.GrantSubmissionFilter) ([]types.GrantSubmission, error) {
+	var submissions []types.GrantSubmission
+	err := gs.getSubmissions.SelectContext(ctx, &submissions, filter.GrantID, strings.ToLower(filter.RecipientAddress),
+		string(filter.Status), filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get grant submissions")
+	}
+	if len(submissions) == 0 {
+		return []types.GrantSubmission{}, nil
+	}
+	return submissions, nil
+}
+
+func (gs *grantSubmission) GetSubmission(ctx context.Context, id uuid.UUID) (*types.GrantSubmission, error) {
+	var submission types.GrantSubmission
+	err := gs.getSubmission.GetContext(ctx, &submission, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("grant submission not found")
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get grant submission")
+	}
+	return &submission, nil
+}
+
+func (gs *grantSubmission) ReviewSubmission(ctx context.Context, id uuid.UUID, status types.GrantSubmissionStatus, note null.String, reviewer string) (*types.GrantSubmission, error) {
+	tx, err := gs.dbConn.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	var submission types.GrantSubmission
+	err = tx.GetContext(ctx, &submission, fmt.Sprintf(`SELECT %s FROM grant_submissions WHERE id = $1 FOR UPDATE`,
+		strings.Join(psql.GetSQLColumnsQuoted[types.GrantSubmission](), ", ")), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("grant submission not found")
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to lock grant submission")
+	}
+	if submission.Status != types.GrantSubmissionPending {
+		return nil, errors.New("grant submission is already reviewed")
+	}
+
+	if status == types.GrantSubmissionApproved {
+		err = applySubmission(ctx, tx, submission)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE grant_submissions SET status = $2, reviewed_by = $3, reviewed_at = NOW(), review_note = $4 WHERE id = $1`,
+		id, status, strings.ToLower(reviewer), note)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to review grant submission")
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to commit transaction")
+	}
+	return gs.GetSubmission(ctx, id)
+}
+
+// applySubmission makes an approved submission take effect, documents have nothing to apply as they are listed once
+// approved
+func applySubmission(ctx context.Context, tx *sqlx.Tx, submission types.GrantSubmission) error {
+	switch submission.Kind {
+	case types.GrantSubmissionMilestoneProgress:
+		if !submission.MilestoneID.Valid {
+			return errors.New("milestone progress submission has no milestone")
+		}
+		return completeMilestone(ctx, tx, submission.GrantID, submission.MilestoneID.UUID, submission.Payload.Evidence, submission.RecipientAddress)
+	case types.GrantSubmissionFundsUsage:
+		if submission.Payload.FundsUsage == nil {
+			return errors.New("funds usage submission has no funds usage")
+		}
+		usage := *submission.Payload.FundsUsage
+		usage.GrantID = submission.GrantID
+		_, err := tx.NamedExecContext(ctx, fmt.Sprintf(`INSERT INTO grant_funds_usage (%s) VALUES (%s)`,
+			strings.Join(psql.GetSQLColumnsQuoted[types.CreateFundsUsage](), ", "),
+			":"+strings.Join(psql.GetSQLColumns[types.CreateFundsUsage](), ", :")), usage)
+		if err != nil {
+			return errors.Wrap(err, "failed to create funds usage")
+		}
+	}
+	return nil
+}
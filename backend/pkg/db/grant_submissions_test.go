@@ -0,0 +1,164 @@
+//go:build integration
+// +build integration
+
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ETHCF/ethutils"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+func GetTestGrantSubmissionDB(t *testing.T) GrantSubmissionDB {
+	sdb, err := NewGrantSubmissionDB(context.Background(), conf, dbConn)
+	require.NoError(t, err)
+	return sdb
+}
+
+func Test_GrantSubmissionDB(t *testing.T) {
+	var (
+		grantDB      = GetTestGrantDB(t)
+		submissionDB = GetTestGrantSubmissionDB(t)
+		recipient    = ethutils.GenRandEVMAddr()
+		admin        = ethutils.GenRandEVMAddr()
+	)
+
+	isRecipient, err := submissionDB.IsGrantRecipient(t.Context(), recipient)
+	require.NoError(t, err)
+	require.False(t, isRecipient)
+
+	grantID, err := grantDB.CreateGrant(t.Context(), types.CreateGrant{
+		Name:               "Test Grant Submissions",
+		RecipientName:      "Test Recipient Submissions",
+		RecipientAddress:   recipient,
+		Description:        "Testing recipient submissions",
+		TotalGrantAmount:   "1",
+		InitialGrantAmount: "0",
+		AmountGivenSoFar:   "0",
+		Status:             types.GrantStatusActive,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, err := dbConn.ExecContext(context.Background(), "DELETE FROM grants WHERE id = $1", grantID)
+		require.NoError(t, err)
+	})
+
+	isRecipient, err = submissionDB.IsGrantRecipient(t.Context(), recipient)
+	require.NoError(t, err)
+	require.True(t, isRecipient)
+
+	milestoneID := uuid.New()
+	_, err = grantDB.UpdateGrantMilestones(t.Context(), grantID, []types.Milestone{
+		{ID: milestoneID, Name: "Only Milestone", Description: "Do it", GrantAmount: "1"},
+	})
+	require.NoError(t, err)
+
+	progress, err := submissionDB.CreateSubmission(t.Context(), types.CreateGrantSubmission{
+		GrantID:          grantID,
+		MilestoneID:      uuid.NullUUID{UUID: milestoneID, Valid: true},
+		RecipientAddress: recipient,
+		Kind:             types.GrantSubmissionMilestoneProgress,
+		Payload:          types.GrantSubmissionPayload{Evidence: "https://example.com/report"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, types.GrantSubmissionPending, progress.Status)
+	require.Equal(t, "https://example.com/report", progress.Payload.Evidence)
+
+	usage, err := submissionDB.CreateSubmission(t.Context(), types.CreateGrantSubmission{
+		GrantID:          grantID,
+		RecipientAddress: recipient,
+		Kind:             types.GrantSubmissionFundsUsage,
+		Payload: types.GrantSubmissionPayload{FundsUsage: &types.CreateFundsUsage{
+			GrantID:  grantID,
+			Item:     "Server",
+			Quantity: 1,
+			Price:    "100",
+			Purpose:  "Hosting",
+			Category: "test",
+			Date:     time.Now().UTC().Truncate(24 * time.Hour),
+		}},
+	})
+	require.NoError(t, err)
+
+	document, err := submissionDB.CreateSubmission(t.Context(), types.CreateGrantSubmission{
+		GrantID:          grantID,
+		RecipientAddress: recipient,
+		Kind:             types.GrantSubmissionDocument,
+		Payload:          types.GrantSubmissionPayload{Title: "Report", URL: "https://example.com/report.pdf"},
+	})
+	require.NoError(t, err)
+
+	pending, err := submissionDB.GetSubmissions(t.Context(), types.GrantSubmissionFilter{
+		RecipientAddress: recipient,
+		Status:           types.GrantSubmissionPending,
+		Limit:            10,
+	})
+	require.NoError(t, err)
+	require.Len(t, pending, 3)
+
+	// Nothing takes effect before an admin approves it
+	milestone, err := grantDB.GetMilestone(t.Context(), grantID, milestoneID)
+	require.NoError(t, err)
+	require.Equal(t, types.MilestoneStatusPending, milestone.Status)
+	fundsUsage, err := grantDB.GetFundsUsageByGrantID(t.Context(), grantID)
+	require.NoError(t, err)
+	require.Empty(t, fundsUsage)
+
+	reviewed, err := submissionDB.ReviewSubmission(t.Context(), progress.ID, types.GrantSubmissionApproved, null.String{}, admin)
+	require.NoError(t, err)
+	require.Equal(t, types.GrantSubmissionApproved, reviewed.Status)
+	require.Equal(t, admin, reviewed.ReviewedBy.String)
+	milestone, err = grantDB.GetMilestone(t.Context(), grantID, milestoneID)
+	require.NoError(t, err)
+	require.Equal(t, types.MilestoneStatusCompleted, milestone.Status)
+	require.Equal(t, "https://example.com/report", milestone.Evidence.String)
+	require.Equal(t, recipient, milestone.CompletedBy.String)
+
+	_, err = submissionDB.ReviewSubmission(t.Context(), progress.ID, types.GrantSubmissionRejected, null.String{}, admin)
+	require.ErrorContains(t, err, "already reviewed")
+
+	_, err = submissionDB.ReviewSubmission(t.Context(), usage.ID, types.GrantSubmissionApproved, null.String{}, admin)
+	require.NoError(t, err)
+	fundsUsage, err = grantDB.GetFundsUsageByGrantID(t.Context(), grantID)
+	require.NoError(t, err)
+	require.Len(t, fundsUsage, 1)
+	require.Equal(t, "Server", fundsUsage[0].Item)
+
+	reviewed, err = submissionDB.ReviewSubmission(t.Context(), document.ID, types.GrantSubmissionRejected, null.StringFrom("Broken link"), admin)
+	require.NoError(t, err)
+	require.Equal(t, types.GrantSubmissionRejected, reviewed.Status)
+	require.Equal(t, "Broken link", reviewed.ReviewNote.String)
+
+	approved, err := submissionDB.GetSubmissions(t.Context(), types.GrantSubmissionFilter{
+		GrantID: uuid.NullUUID{UUID: grantID, Valid: true},
+		Status:  types.GrantSubmissionApproved,
+		Limit:   10,
+	})
+	require.NoError(t, err)
+	require.Len(t, approved, 2)
+
+	// A second progress submission for the completed milestone can't be approved
+	again, err := submissionDB.CreateSubmission(t.Context(), types.CreateGrantSubmission{
+		GrantID:          grantID,
+		MilestoneID:      uuid.NullUUID{UUID: milestoneID, Valid: true},
+		RecipientAddress: recipient,
+		Kind:             types.GrantSubmissionMilestoneProgress,
+		Payload:          types.GrantSubmissionPayload{Evidence: "again"},
+	})
+	require.NoError(t, err)
+	_, err = submissionDB.ReviewSubmission(t.Context(), again.ID, types.GrantSubmissionApproved, null.String{}, admin)
+	require.ErrorContains(t, err, "already completed")
+	again, err = submissionDB.GetSubmission(t.Context(), again.ID)
+	require.NoError(t, err)
+	require.Equal(t, types.GrantSubmissionPending, again.Status)
+
+	_, err = submissionDB.GetSubmission(t.Context(), uuid.New())
+	require.ErrorContains(t, err, "not found")
+}
@@ -0,0 +1,170 @@
+package db
+
+import (
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+
+	"github.com/google/uuid"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+// disbursedAsset is what was disbursed to a grant in one asset
+type disbursedAsset struct {
+	GrantID  uuid.UUID `db:"grant_id"`
+	Asset    string    `db:"asset"`
+	Amount   string    `db:"amount"`
+	UsdValue float64   `db:"usd_value"` // At the price of each disbursement
+	Unpriced int       `db:"unpriced"`  // Disbursements without a price, not in UsdValue
+}
+
+// grantTotals derives the disbursed figures of a grant, per asset and in USD, from what was disbursed to it. Prices
+// are the current USD prices by asset. Disbursements of an asset pay the initial grant amount first when it is the
+// grant's asset, then the milestones in that asset in order.
+func grantTotals(grant types.Grant, milestones []types.Milestone, disbursed []disbursedAsset, prices map[string]float64) types.GrantTotals {
+	var (
+		zero      = new(big.Rat)
+		committed = map[string]*big.Rat{grant.Asset: parseAmount(grant.TotalGrantAmount)}
+		given     = map[string]*big.Rat{}
+		givenUsd  = map[string]float64{}
+		unpriced  = map[string]int{}
+		assets    = []string{}
+	)
+	for _, milestone := range milestones {
+		if milestone.Asset == grant.Asset {
+			continue
+		}
+		if committed[milestone.Asset] == nil {
+			committed[milestone.Asset] = new(big.Rat)
+		}
+		committed[milestone.Asset].Add(committed[milestone.Asset], parseAmount(milestone.GrantAmount))
+	}
+	for _, d := range disbursed {
+		given[d.Asset] = parseAmount(d.Amount)
+		givenUsd[d.Asset] = d.UsdValue
+		unpriced[d.Asset] = d.Unpriced
+		if committed[d.Asset] == nil {
+			committed[d.Asset] = new(big.Rat)
+		}
+	}
+	for asset := range committed {
+		if asset != grant.Asset {
+			assets = append(assets, asset)
+		}
+	}
+	sort.Strings(assets)
+	assets = append([]string{grant.Asset}, assets...)
+
+	totals := types.GrantTotals{
+		Milestones: make([]types.MilestonePayment, 0, len(milestones)),
+		Assets:     make([]types.AssetTotals, 0, len(assets)),
+	}
+	for _, asset := range assets {
+		disbursedAmount := given[asset]
+		if disbursedAmount == nil {
+			disbursedAmount = new(big.Rat)
+		}
+		remaining := new(big.Rat).Sub(committed[asset], disbursedAmount)
+		if remaining.Cmp(zero) < 0 {
+			remaining.Set(zero)
+		}
+
+		assetTotals := types.AssetTotals{
+			Asset:        asset,
+			Committed:    formatAmount(committed[asset]),
+			Disbursed:    formatAmount(disbursedAmount),
+			Remaining:    formatAmount(remaining),
+			DisbursedUsd: roundUsd(givenUsd[asset]),
+			Unpriced:     unpriced[asset],
+		}
+		if price, ok := prices[asset]; ok {
+			remainingFloat, _ := remaining.Float64()
+			assetTotals.RemainingUsd = null.FloatFrom(roundUsd(remainingFloat * price))
+			totals.RemainingUsd += assetTotals.RemainingUsd.Float64
+		}
+		totals.DisbursedUsd += assetTotals.DisbursedUsd
+		totals.Unpriced += assetTotals.Unpriced
+		totals.Assets = append(totals.Assets, assetTotals)
+
+		if asset == grant.Asset {
+			totals.Disbursed = assetTotals.Disbursed
+			totals.Remaining = assetTotals.Remaining
+			if committed[asset].Sign() > 0 {
+				percent, _ := new(big.Rat).Mul(new(big.Rat).Quo(disbursedAmount, committed[asset]), big.NewRat(100, 1)).Float64()
+				totals.PercentDisbursed = math.Round(percent*100) / 100
+			}
+		}
+	}
+	totals.DisbursedUsd = roundUsd(totals.DisbursedUsd)
+	totals.RemainingUsd = roundUsd(totals.RemainingUsd)
+
+	// What was disbursed of each asset beyond the amounts due before the milestone
+	paidBefore := map[string]*big.Rat{grant.Asset: parseAmount(grant.InitialGrantAmount)}
+	for _, milestone := range milestones {
+		if paidBefore[milestone.Asset] == nil {
+			paidBefore[milestone.Asset] = new(big.Rat)
+		}
+		disbursedAmount := given[milestone.Asset]
+		if disbursedAmount == nil {
+			disbursedAmount = new(big.Rat)
+		}
+
+		amount := parseAmount(milestone.GrantAmount)
+		paid := new(big.Rat).Sub(disbursedAmount, paidBefore[milestone.Asset])
+		if paid.Cmp(zero) < 0 {
+			paid.Set(zero)
+		}
+		if paid.Cmp(amount) > 0 {
+			paid.Set(amount)
+		}
+
+		status := types.MilestoneUnpaid
+		switch {
+		case paid.Cmp(amount) == 0 && disbursedAmount.Cmp(paidBefore[milestone.Asset]) >= 0 && disbursedAmount.Sign() > 0:
+			status = types.MilestonePaid
+		case paid.Sign() > 0:
+			status = types.MilestonePartiallyPaid
+		}
+
+		totals.Milestones = append(totals.Milestones, types.MilestonePayment{
+			MilestoneID: milestone.ID,
+			Paid:        formatAmount(paid),
+			Status:      status,
+		})
+		paidBefore[milestone.Asset].Add(paidBefore[milestone.Asset], amount)
+	}
+	return totals
+}
+
+// disbursementUsdValue values a disbursement at the price of its asset when it was recorded
+func disbursementUsdValue(disbursement types.Disbursement) null.Float {
+	if !disbursement.UsdPrice.Valid {
+		return null.Float{}
+	}
+	amount, err := strconv.ParseFloat(disbursement.Amount, 64)
+	if err != nil {
+		return null.Float{}
+	}
+	return null.FloatFrom(roundUsd(amount * disbursement.UsdPrice.Float64))
+}
+
+// parseAmount parses a decimal amount, anything unparsable counts as zero
+func parseAmount(amount string) *big.Rat {
+	value, ok := new(big.Rat).SetString(amount)
+	if !ok {
+		return new(big.Rat)
+	}
+	return value
+}
+
+// formatAmount formats an amount with the precision of ETHER_T, without trailing zeros
+func formatAmount(amount *big.Rat) string {
+	return TrimZeros(amount.FloatString(18))
+}
+
+func roundUsd(value float64) float64 {
+	return math.Round(value*100) / 100
+}
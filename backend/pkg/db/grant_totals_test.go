@@ -0,0 +1,112 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/constants"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+func TestGrantTotals(t *testing.T) {
+	var (
+		grant = types.Grant{CreateGrant: types.CreateGrant{
+			Asset: constants.EtherAddress, TotalGrantAmount: "10", InitialGrantAmount: "2", AmountGivenSoFar: "0",
+		}}
+		milestones = []types.Milestone{
+			{ID: uuid.New(), Asset: constants.EtherAddress, GrantAmount: "3.000000000000000000"},
+			{ID: uuid.New(), Asset: constants.EtherAddress, GrantAmount: "4.000000000000000000"},
+			{ID: uuid.New(), Asset: constants.EtherAddress, GrantAmount: "1.000000000000000000"},
+		}
+		prices   = map[string]float64{constants.EtherAddress: 2000}
+		disburse = func(amount string) []disbursedAsset {
+			return []disbursedAsset{{Asset: constants.EtherAddress, Amount: amount, UsdValue: 1000}}
+		}
+		statuses = func(totals types.GrantTotals) []types.MilestonePaymentStatus {
+			var out []types.MilestonePaymentStatus
+			for _, milestone := range totals.Milestones {
+				out = append(out, milestone.Status)
+			}
+			return out
+		}
+	)
+
+	totals := grantTotals(grant, milestones, nil, prices)
+	require.Equal(t, "0", totals.Disbursed)
+	require.Equal(t, "10", totals.Remaining)
+	require.Equal(t, float64(0), totals.PercentDisbursed)
+	require.Equal(t, []types.MilestonePaymentStatus{types.MilestoneUnpaid, types.MilestoneUnpaid, types.MilestoneUnpaid}, statuses(totals))
+
+	totals = grantTotals(grant, milestones, disburse("6.5"), prices)
+	require.Equal(t, "6.5", totals.Disbursed)
+	require.Equal(t, "3.5", totals.Remaining)
+	require.Equal(t, 65.0, totals.PercentDisbursed)
+	require.Equal(t, []types.MilestonePaymentStatus{types.MilestonePaid, types.MilestonePartiallyPaid, types.MilestoneUnpaid}, statuses(totals))
+	require.Equal(t, milestones[0].ID, totals.Milestones[0].MilestoneID)
+	require.Equal(t, "3", totals.Milestones[0].Paid)
+	require.Equal(t, "1.5", totals.Milestones[1].Paid)
+	require.Equal(t, "0", totals.Milestones[2].Paid)
+	require.Equal(t, 1000.0, totals.DisbursedUsd)
+	require.Equal(t, 7000.0, totals.RemainingUsd)
+
+	// Overpaying a grant doesn't make the remaining amount negative
+	totals = grantTotals(grant, milestones, disburse("12"), prices)
+	require.Equal(t, "0", totals.Remaining)
+	require.Equal(t, 120.0, totals.PercentDisbursed)
+	require.Equal(t, []types.MilestonePaymentStatus{types.MilestonePaid, types.MilestonePaid, types.MilestonePaid}, statuses(totals))
+
+	totals = grantTotals(types.Grant{CreateGrant: types.CreateGrant{Asset: constants.EtherAddress, TotalGrantAmount: "0"}}, nil, nil, prices)
+	require.Equal(t, float64(0), totals.PercentDisbursed)
+	require.Empty(t, totals.Milestones)
+}
+
+func TestGrantTotalsMultipleAssets(t *testing.T) {
+	var (
+		usdc  = "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+		token = "0x0000000000000000000000000000000000000001"
+		grant = types.Grant{CreateGrant: types.CreateGrant{
+			Asset: constants.EtherAddress, TotalGrantAmount: "5", InitialGrantAmount: "1",
+		}}
+		milestones = []types.Milestone{
+			{ID: uuid.New(), Asset: constants.EtherAddress, GrantAmount: "4"},
+			{ID: uuid.New(), Asset: usdc, GrantAmount: "1000"},
+			{ID: uuid.New(), Asset: usdc, GrantAmount: "500"},
+		}
+		disbursed = []disbursedAsset{
+			{Asset: constants.EtherAddress, Amount: "1", UsdValue: 1800},
+			{Asset: usdc, Amount: "1200", UsdValue: 1200},
+			{Asset: token, Amount: "3", UsdValue: 0, Unpriced: 2}, // Never priced
+		}
+		prices = map[string]float64{constants.EtherAddress: 2000, usdc: 1}
+	)
+
+	totals := grantTotals(grant, milestones, disbursed, prices)
+	require.Equal(t, "1", totals.Disbursed)
+	require.Equal(t, "4", totals.Remaining)
+	require.Equal(t, 20.0, totals.PercentDisbursed)
+
+	require.Len(t, totals.Assets, 3)
+	require.Equal(t, types.AssetTotals{
+		Asset: constants.EtherAddress, Committed: "5", Disbursed: "1", Remaining: "4",
+		DisbursedUsd: 1800, RemainingUsd: null.FloatFrom(8000),
+	}, totals.Assets[0], "the grant's asset comes first")
+	require.Equal(t, types.AssetTotals{
+		Asset: token, Committed: "0", Disbursed: "3", Remaining: "0", Unpriced: 2,
+	}, totals.Assets[1])
+	require.Equal(t, types.AssetTotals{
+		Asset: usdc, Committed: "1500", Disbursed: "1200", Remaining: "300",
+		DisbursedUsd: 1200, RemainingUsd: null.FloatFrom(300),
+	}, totals.Assets[2])
+	require.Equal(t, 3000.0, totals.DisbursedUsd)
+	require.Equal(t, 2, totals.Unpriced, "the USD figure leaves out what couldn't be priced")
+	require.Equal(t, 8300.0, totals.RemainingUsd)
+
+	// Each asset pays its own milestones in order, the initial amount is in the grant's asset
+	require.Equal(t, types.MilestoneUnpaid, totals.Milestones[0].Status)
+	require.Equal(t, types.MilestonePaid, totals.Milestones[1].Status)
+	require.Equal(t, types.MilestonePartiallyPaid, totals.Milestones[2].Status)
+	require.Equal(t, "200", totals.Milestones[2].Paid)
+}
@@ -2,30 +2,58 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/numbergroup/errors"
 	"github.com/google/uuid"
 	"github.com/innodv/psql"
 	"github.com/jmoiron/sqlx"
 	"github.com/sirupsen/logrus"
+	"gopkg.in/guregu/null.v4"
 
 	"github.com/ETHCF/transparency-dashboard/backend/pkg/config"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/constants"
 	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
 )
 
 type GrantDB interface {
 	// Grant management methods
-	GetGrants(ctx context.Context) ([]types.Grant, error)
+	// GetGrants returns a page of the grants matching the filter, with how many match in total
+	GetGrants(ctx context.Context, filter types.GrantFilter) (*types.GrantListing, error)
 	CreateGrant(ctx context.Context, grant types.CreateGrant) (uuid.UUID, error)
 	GetGrantByID(ctx context.Context, id uuid.UUID) (*types.Grant, error)
-	UpdateGrant(ctx context.Context, id uuid.UUID, updates types.UpdateGrantRequest) error
+	// UpdateGrant updates a grant, a status change has to be an allowed transition and is recorded as done by the admin
+	UpdateGrant(ctx context.Context, id uuid.UUID, updates types.UpdateGrantRequest, admin string) error
 	GrantExists(ctx context.Context, id uuid.UUID) (bool, error)
+	// GetStatusEvents returns the status transitions of a grant, oldest first
+	GetStatusEvents(ctx context.Context, id uuid.UUID) ([]types.GrantStatusEvent, error)
 
 	// Milestone management methods
 	GetMilestonesByGrantID(ctx context.Context, grantID uuid.UUID) ([]types.Milestone, error)
-	UpdateGrantMilestones(ctx context.Context, grantID uuid.UUID, milestones []types.Milestone) error
+	GetMilestone(ctx context.Context, grantID, milestoneID uuid.UUID) (*types.Milestone, error)
+	// UpdateGrantMilestones replaces the milestones of a grant, the ones kept keep their completion and approvals. A
+	// completed milestone can't be removed, and changing its asset or amount takes it back to completed without
+	// approvals. It returns the milestones taken back.
+	UpdateGrantMilestones(ctx context.Context, grantID uuid.UUID, milestones []types.Milestone) ([]uuid.UUID, error)
+	// CompleteMilestone marks a pending milestone completed with the evidence
+	CompleteMilestone(ctx context.Context, grantID, milestoneID uuid.UUID, evidence, completedBy string) (*types.Milestone, error)
+	// ApproveMilestone records an admin's approval of a completed milestone and signs it off once it has enough
+	// approvals, proposing its payment when the settings say so
+	ApproveMilestone(ctx context.Context, grantID, milestoneID uuid.UUID, approval types.MilestoneApproval, settings types.MilestoneSignOffSettings) (*types.Milestone, error)
+	// GetOverdueMilestones returns the pending milestones past their target date of the grants still expecting work,
+	// the most overdue first
+	GetOverdueMilestones(ctx context.Context) ([]types.OverdueMilestone, error)
+	// RecordOverdueMilestones records an overdue event for each milestone which became overdue since it was last
+	// called, and returns the new events
+	RecordOverdueMilestones(ctx context.Context) ([]types.MilestoneEvent, error)
+	GetMilestoneEvents(ctx context.Context, grantID uuid.UUID) ([]types.MilestoneEvent, error)
+
+	// Disbursement proposal methods
+	GetDisbursementProposals(ctx context.Context, grantID uuid.UUID) ([]types.DisbursementProposal, error)
+	ReviewDisbursementProposal(ctx context.Context, grantID, proposalID uuid.UUID, status types.DisbursementProposalStatus, admin string) error
 
 	// Disbursement management methods
 	GetDisbursementsByGrantID(ctx context.Context, grantID uuid.UUID) ([]types.Disbursement, error)
@@ -42,11 +70,20 @@ type GrantDB interface {
 type grant struct {
 	log                       logrus.Ext1FieldLogger
 	dbConn                    *sqlx.DB
-	getGrants                 *sqlx.Stmt
 	createGrant               *sqlx.NamedStmt
 	getGrantByID              *sqlx.Stmt
 	grantExists               *sqlx.Stmt
+	getStatusEvents           *sqlx.Stmt
 	getMilestonesByGrantID    *sqlx.Stmt
+	getMilestones             *sqlx.Stmt
+	getMilestone              *sqlx.Stmt
+	getApprovalsByGrantID     *sqlx.Stmt
+	getDisbursementProposals  *sqlx.Stmt
+	getOverdueMilestones      *sqlx.Stmt
+	recordOverdueMilestones   *sqlx.Stmt
+	getMilestoneEvents        *sqlx.Stmt
+	getDisbursedAssets        *sqlx.Stmt
+	getAssetPrices            *sqlx.Stmt
 	getDisbursementsByGrantID *sqlx.Stmt
 	insertDisbursement        *sqlx.NamedStmt
 	updateDisbursement        *sqlx.NamedStmt
@@ -64,12 +101,6 @@ func NewGrantDB(ctx context.Context, conf *config.Config, dbConn *sqlx.DB) (Gran
 	fundsUsageCols := psql.GetSQLColumnsQuoted[types.FundsUsage]()
 
 	// Grant queries
-	getGrants, err := dbConn.PreparexContext(ctx, fmt.Sprintf(`
-		SELECT %s FROM grants ORDER BY created_at DESC`, strings.Join(grantCols, ", ")))
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to prepare GetGrants statement")
-	}
-
 	createGrant, err := dbConn.PrepareNamedContext(ctx, fmt.Sprintf(`
 		INSERT INTO grants (%s) VALUES (%s) RETURNING id`,
 		strings.Join(psql.GetSQLColumnsQuoted[types.CreateGrant](), ", "), ":"+strings.Join(psql.GetSQLColumns[types.CreateGrant](), ", :")))
@@ -88,6 +119,19 @@ func NewGrantDB(ctx context.Context, conf *config.Config, dbConn *sqlx.DB) (Gran
 		return nil, errors.Wrap(err, "failed to prepare GrantExists statement")
 	}
 
+	getStatusEvents, err := dbConn.PreparexContext(ctx, `
+		SELECT details->>'from' AS from_status,
+			details->>'to' AS to_status,
+			COALESCE(details->>'reason', '') AS reason,
+			admin_address,
+			created_at
+		FROM admin_actions
+		WHERE resource_type = 'grant' AND resource_id = $1 AND action = 'grant_status_changed'
+		ORDER BY created_at ASC`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetStatusEvents statement")
+	}
+
 	// Milestone queries
 	getMilestonesByGrantID, err := dbConn.PreparexContext(ctx, fmt.Sprintf(`
 		SELECT %s FROM milestones WHERE grant_id = $1 ORDER BY order_index ASC`, strings.Join(milestoneCols, ", ")))
@@ -95,6 +139,82 @@ func NewGrantDB(ctx context.Context, conf *config.Config, dbConn *sqlx.DB) (Gran
 		return nil, errors.Wrap(err, "failed to prepare GetMilestonesByGrantID statement")
 	}
 
+	getMilestones, err := dbConn.PreparexContext(ctx, fmt.Sprintf(`
+		SELECT %s FROM milestones ORDER BY grant_id, order_index ASC`, strings.Join(milestoneCols, ", ")))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetMilestones statement")
+	}
+
+	getMilestone, err := dbConn.PreparexContext(ctx, fmt.Sprintf(`
+		SELECT %s FROM milestones WHERE id = $1 AND grant_id = $2`, strings.Join(milestoneCols, ", ")))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetMilestone statement")
+	}
+
+	getApprovalsByGrantID, err := dbConn.PreparexContext(ctx, fmt.Sprintf(`
+		SELECT %s FROM milestone_approvals
+		WHERE milestone_id IN (SELECT id FROM milestones WHERE grant_id = $1)
+		ORDER BY created_at ASC`, strings.Join(psql.GetSQLColumnsQuoted[types.MilestoneApproval](), ", ")))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetApprovalsByGrantID statement")
+	}
+
+	getDisbursementProposals, err := dbConn.PreparexContext(ctx, fmt.Sprintf(`
+		SELECT %s FROM disbursement_proposals WHERE grant_id = $1 ORDER BY created_at DESC`,
+		strings.Join(psql.GetSQLColumnsQuoted[types.DisbursementProposal](), ", ")))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetDisbursementProposals statement")
+	}
+
+	// Milestones are only overdue while the grant expects work on them
+	prefixedMilestoneCols := make([]string, len(milestoneCols))
+	for i, col := range milestoneCols {
+		prefixedMilestoneCols[i] = "m." + col
+	}
+	getOverdueMilestones, err := dbConn.PreparexContext(ctx, fmt.Sprintf(`
+		SELECT %s, g.name AS grant_name, g.recipient_name, CURRENT_DATE - m.target_date AS days_overdue
+		FROM milestones m JOIN grants g ON g.id = m.grant_id
+		WHERE m.status = 'pending' AND m.target_date < CURRENT_DATE AND g.status IN ('approved', 'active', 'paused')
+		ORDER BY m.target_date ASC, g.name ASC, m.order_index ASC`, strings.Join(prefixedMilestoneCols, ", ")))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetOverdueMilestones statement")
+	}
+
+	milestoneEventCols := strings.Join(psql.GetSQLColumnsQuoted[types.MilestoneEvent](), ", ")
+	recordOverdueMilestones, err := dbConn.PreparexContext(ctx, fmt.Sprintf(`
+		INSERT INTO milestone_events (milestone_id, grant_id, event, target_date)
+		SELECT m.id, m.grant_id, 'overdue', m.target_date
+		FROM milestones m JOIN grants g ON g.id = m.grant_id
+		WHERE m.status = 'pending' AND m.target_date < CURRENT_DATE AND g.status IN ('approved', 'active', 'paused')
+		ON CONFLICT (milestone_id, event, target_date) DO NOTHING
+		RETURNING %s`, milestoneEventCols))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare RecordOverdueMilestones statement")
+	}
+
+	getMilestoneEvents, err := dbConn.PreparexContext(ctx, fmt.Sprintf(`
+		SELECT %s FROM milestone_events WHERE grant_id = $1 ORDER BY created_at ASC`, milestoneEventCols))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetMilestoneEvents statement")
+	}
+
+	// What was disbursed to each grant in each asset, of every grant when no grant is given
+	getDisbursedAssets, err := dbConn.PreparexContext(ctx, `
+		SELECT grant_id, asset, SUM(amount) AS amount,
+			COALESCE(SUM(amount * usd_price::NUMERIC), 0)::DOUBLE PRECISION AS usd_value,
+			COUNT(*) FILTER (WHERE usd_price IS NULL) AS unpriced
+		FROM disbursements
+		WHERE $1::UUID IS NULL OR grant_id = $1
+		GROUP BY grant_id, asset`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetDisbursedAssets statement")
+	}
+
+	getAssetPrices, err := dbConn.PreparexContext(ctx, `SELECT address, usd_price FROM asset_prices WHERE chain_id = 1`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetAssetPrices statement")
+	}
+
 	// Disbursement queries
 	getDisbursementsByGrantID, err := dbConn.PreparexContext(ctx, fmt.Sprintf(`
 		SELECT %s FROM disbursements WHERE grant_id = $1`, strings.Join(disbursementCols, ", ")))
@@ -112,8 +232,10 @@ func NewGrantDB(ctx context.Context, conf *config.Config, dbConn *sqlx.DB) (Gran
 
 	updateDisbursement, err := dbConn.PrepareNamedContext(ctx, `
 		UPDATE disbursements
-		SET grant_id = :grant_id, amount = :amount, tx_hash = :tx_hash,
-			block_number = :block_number, block_timestamp = :block_timestamp
+		SET grant_id = :grant_id, asset = :asset, amount = :amount, tx_hash = :tx_hash,
+			block_number = :block_number, block_timestamp = :block_timestamp,
+			verification_status = :verification_status, verification_reason = :verification_reason, verified_at = :verified_at,
+			usd_price = :usd_price
 		WHERE id = :id`)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to prepare UpdateDisbursement statement")
@@ -152,11 +274,20 @@ func NewGrantDB(ctx context.Context, conf *config.Config, dbConn *sqlx.DB) (Gran
 	return &grant{
 		log:                       conf.GetLogger(),
 		dbConn:                    dbConn,
-		getGrants:                 getGrants,
 		createGrant:               createGrant,
 		getGrantByID:              getGrantByID,
 		grantExists:               grantExists,
+		getStatusEvents:           getStatusEvents,
 		getMilestonesByGrantID:    getMilestonesByGrantID,
+		getMilestones:             getMilestones,
+		getMilestone:              getMilestone,
+		getApprovalsByGrantID:     getApprovalsByGrantID,
+		getDisbursementProposals:  getDisbursementProposals,
+		getOverdueMilestones:      getOverdueMilestones,
+		recordOverdueMilestones:   recordOverdueMilestones,
+		getMilestoneEvents:        getMilestoneEvents,
+		getDisbursedAssets:        getDisbursedAssets,
+		getAssetPrices:            getAssetPrices,
 		getDisbursementsByGrantID: getDisbursementsByGrantID,
 		insertDisbursement:        insertDisbursement,
 		updateDisbursement:        updateDisbursement,
@@ -167,25 +298,89 @@ func NewGrantDB(ctx context.Context, conf *config.Config, dbConn *sqlx.DB) (Gran
 	}, nil
 }
 
+// grantSortColumns are the columns grants can be sorted by
+var grantSortColumns = map[types.GrantSort]string{
+	types.GrantSortCreatedAt:        "created_at",
+	types.GrantSortName:             "name",
+	types.GrantSortStartDate:        "start_date",
+	types.GrantSortCompletionDate:   "expected_completion_date",
+	types.GrantSortTotalGrantAmount: "total_grant_amount",
+	types.GrantSortAmountGivenSoFar: "amount_given_so_far",
+}
+
 // Grant methods
-func (g *grant) GetGrants(ctx context.Context) ([]types.Grant, error) {
+func (g *grant) GetGrants(ctx context.Context, filter types.GrantFilter) (*types.GrantListing, error) {
+	if filter.Sort == "" {
+		filter.Sort = types.GrantSortCreatedAt
+	}
+	sortColumn, ok := grantSortColumns[filter.Sort]
+	if !ok {
+		return nil, errors.Errorf("invalid grant sort %q", filter.Sort)
+	}
+	direction := "ASC"
+	if filter.Descending {
+		direction = "DESC"
+	}
+
+	where := ""
+	conditions, args := filter.GetSQLConditions(1)
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	listing := &types.GrantListing{Data: []types.Grant{}, Limit: filter.Limit, Offset: filter.Offset}
+	err := g.dbConn.GetContext(ctx, &listing.Total, "SELECT COUNT(*) FROM grants "+where, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to count grants")
+	}
+
+	// The ID breaks ties so that pages don't overlap
+	query := fmt.Sprintf("SELECT %s FROM grants %s ORDER BY %s %s, id %s LIMIT $%d OFFSET $%d",
+		strings.Join(psql.GetSQLColumnsQuoted[types.Grant](), ", "), where, sortColumn, direction, direction, len(args)+1, len(args)+2)
 	var grants []types.Grant
-	err := g.getGrants.SelectContext(ctx, &grants)
+	err = g.dbConn.SelectContext(ctx, &grants, query, append(args, filter.Limit, filter.Offset)...)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get grants")
 	}
 	if len(grants) == 0 {
-		return []types.Grant{}, nil
+		return listing, nil
 	}
+
+	var milestones []types.Milestone
+	err = g.getMilestones.SelectContext(ctx, &milestones)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get milestones")
+	}
+	milestonesByGrant := map[uuid.UUID][]types.Milestone{}
+	for _, milestone := range milestones {
+		milestonesByGrant[milestone.GrantID] = append(milestonesByGrant[milestone.GrantID], milestone)
+	}
+
+	disbursed, prices, err := g.getDisbursedAssetsAndPrices(ctx, uuid.NullUUID{})
+	if err != nil {
+		return nil, err
+	}
+	disbursedByGrant := map[uuid.UUID][]disbursedAsset{}
+	for _, d := range disbursed {
+		disbursedByGrant[d.GrantID] = append(disbursedByGrant[d.GrantID], d)
+	}
+
+	now := time.Now()
 	for i := range grants {
 		grants[i].AmountGivenSoFar = TrimZeros(grants[i].AmountGivenSoFar)
 		grants[i].InitialGrantAmount = TrimZeros(grants[i].InitialGrantAmount)
 		grants[i].TotalGrantAmount = TrimZeros(grants[i].TotalGrantAmount)
+		grants[i].Overdue = grants[i].IsOverdue(now)
+		grants[i].Totals = grantTotals(grants[i], milestonesByGrant[grants[i].ID], disbursedByGrant[grants[i].ID], prices)
 	}
-	return grants, nil
+	listing.Data = grants
+	return listing, nil
 }
 
 func (g *grant) CreateGrant(ctx context.Context, grant types.CreateGrant) (uuid.UUID, error) {
+	if grant.Asset == "" {
+		grant.Asset = constants.EtherAddress
+	}
 	var id uuid.UUID
 	err := g.createGrant.QueryRowxContext(ctx, grant).Scan(&id)
 	if err != nil {
@@ -200,18 +395,66 @@ func (g *grant) GetGrantByID(ctx context.Context, id uuid.UUID) (*types.Grant, e
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get grant by ID")
 	}
+	milestones, err := g.GetMilestonesByGrantID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	disbursed, prices, err := g.getDisbursedAssetsAndPrices(ctx, uuid.NullUUID{UUID: id, Valid: true})
+	if err != nil {
+		return nil, err
+	}
 	grant.AmountGivenSoFar = TrimZeros(grant.AmountGivenSoFar)
 	grant.InitialGrantAmount = TrimZeros(grant.InitialGrantAmount)
 	grant.TotalGrantAmount = TrimZeros(grant.TotalGrantAmount)
+	grant.Overdue = grant.IsOverdue(time.Now())
+	grant.Totals = grantTotals(grant, milestones, disbursed, prices)
 	return &grant, nil
 }
 
-func (g *grant) UpdateGrant(ctx context.Context, id uuid.UUID, updates types.UpdateGrantRequest) error {
+func (g *grant) getDisbursedAssetsAndPrices(ctx context.Context, grantID uuid.NullUUID) ([]disbursedAsset, map[string]float64, error) {
+	var disbursed []disbursedAsset
+	err := g.getDisbursedAssets.SelectContext(ctx, &disbursed, grantID)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to get disbursed assets")
+	}
+
+	var assetPrices []struct {
+		Address  string  `db:"address"`
+		UsdPrice float64 `db:"usd_price"`
+	}
+	err = g.getAssetPrices.SelectContext(ctx, &assetPrices)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to get asset prices")
+	}
+	prices := make(map[string]float64, len(assetPrices))
+	for _, price := range assetPrices {
+		prices[price.Address] = price.UsdPrice
+	}
+	return disbursed, prices, nil
+}
+
+func (g *grant) UpdateGrant(ctx context.Context, id uuid.UUID, updates types.UpdateGrantRequest, admin string) error {
+	tx, err := g.dbConn.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	if updates.Status.Valid {
+		changed, err := transitionGrant(ctx, tx, id, types.GrantStatus(updates.Status.String), updates.StatusReason.String, admin)
+		if err != nil {
+			return err
+		}
+		if !changed {
+			updates.Status = null.String{}
+		}
+	}
+
 	setParts, args := updates.GetSQLUpdates(2)
 
 	// If no fields to update, return early
 	if len(setParts) == 0 {
-		return nil
+		return tx.Commit()
 	}
 
 	// Always update updated_at
@@ -221,7 +464,7 @@ func (g *grant) UpdateGrant(ctx context.Context, id uuid.UUID, updates types.Upd
 	args = append([]any{id}, args...)
 	query := fmt.Sprintf("UPDATE grants SET %s WHERE id = $1", strings.Join(setParts, ", "))
 
-	result, err := g.dbConn.ExecContext(ctx, query, args...)
+	result, err := tx.ExecContext(ctx, query, args...)
 	if err != nil {
 		return errors.Wrap(err, "failed to update grant")
 	}
@@ -235,9 +478,75 @@ func (g *grant) UpdateGrant(ctx context.Context, id uuid.UUID, updates types.Upd
 		return errors.New("grant not found")
 	}
 
+	// The amount given so far is in the grant's asset
+	if updates.Asset.Valid {
+		err = syncAmountGiven(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, "failed to commit transaction")
+	}
 	return nil
 }
 
+// transitionGrant checks that a grant can be moved to the status and records the transition on its timeline, it returns
+// whether the status changes. The grant is locked until the transaction ends.
+func transitionGrant(ctx context.Context, tx *sqlx.Tx, id uuid.UUID, next types.GrantStatus, reason, admin string) (bool, error) {
+	var current types.GrantStatus
+	err := tx.GetContext(ctx, &current, `SELECT status FROM grants WHERE id = $1 FOR UPDATE`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, errors.New("grant not found")
+	}
+	if err != nil {
+		return false, errors.Wrap(err, "failed to lock grant")
+	}
+	if current == next {
+		return false, nil
+	}
+	if !current.CanTransitionTo(next) {
+		return false, errors.Errorf("invalid grant status transition from %s to %s", current, next)
+	}
+	if strings.TrimSpace(reason) == "" {
+		return false, errors.New("a reason is required to change the grant status")
+	}
+
+	event := types.AdminAction{
+		AdminAddress: admin,
+		Action:       "grant_status_changed",
+		ResourceType: "grant",
+		ResourceID:   id.String(),
+		Details: types.AdminActionDetails{
+			"from":   current,
+			"to":     next,
+			"reason": strings.TrimSpace(reason),
+		},
+		CreatedAt: time.Now(),
+	}
+	_, err = tx.NamedExecContext(ctx, fmt.Sprintf(`INSERT INTO admin_actions (%s) VALUES (%s)`,
+		strings.Join(psql.GetSQLColumnsQuoted[types.AdminAction](), ", "),
+		":"+strings.Join(psql.GetSQLColumns[types.AdminAction](), ", :")), event)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to record grant status change")
+	}
+	return true, nil
+}
+
+func (g *grant) GetStatusEvents(ctx context.Context, id uuid.UUID) ([]types.GrantStatusEvent, error) {
+	var events []types.GrantStatusEvent
+	err := g.getStatusEvents.SelectContext(ctx, &events, id.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get grant status events")
+	}
+	if len(events) == 0 {
+		return []types.GrantStatusEvent{}, nil
+	}
+	return events, nil
+}
+
 func (g *grant) GrantExists(ctx context.Context, id uuid.UUID) (bool, error) {
 	var exists bool
 	err := g.grantExists.GetContext(ctx, &exists, id)
@@ -257,47 +566,390 @@ func (g *grant) GetMilestonesByGrantID(ctx context.Context, grantID uuid.UUID) (
 	if len(milestones) == 0 {
 		return []types.Milestone{}, nil
 	}
+
+	var approvals []types.MilestoneApproval
+	err = g.getApprovalsByGrantID.SelectContext(ctx, &approvals, grantID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get milestone approvals")
+	}
+	approvalsByMilestone := map[uuid.UUID][]types.MilestoneApproval{}
+	for _, approval := range approvals {
+		approvalsByMilestone[approval.MilestoneID] = append(approvalsByMilestone[approval.MilestoneID], approval)
+	}
+	now := time.Now()
+	for i := range milestones {
+		milestones[i].GrantAmount = TrimZeros(milestones[i].GrantAmount)
+		milestones[i].Schedule = milestones[i].ScheduleStatus(now)
+		milestones[i].Approvals = approvalsByMilestone[milestones[i].ID]
+		if milestones[i].Approvals == nil {
+			milestones[i].Approvals = []types.MilestoneApproval{}
+		}
+	}
 	return milestones, nil
 }
 
-func (g *grant) UpdateGrantMilestones(ctx context.Context, grantID uuid.UUID, milestones []types.Milestone) error {
+func (g *grant) GetMilestone(ctx context.Context, grantID, milestoneID uuid.UUID) (*types.Milestone, error) {
+	var milestone types.Milestone
+	err := g.getMilestone.GetContext(ctx, &milestone, milestoneID, grantID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("milestone not found")
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get milestone")
+	}
+
+	err = g.dbConn.SelectContext(ctx, &milestone.Approvals, fmt.Sprintf(`
+		SELECT %s FROM milestone_approvals WHERE milestone_id = $1 ORDER BY created_at ASC`,
+		strings.Join(psql.GetSQLColumnsQuoted[types.MilestoneApproval](), ", ")), milestoneID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get milestone approvals")
+	}
+	if milestone.Approvals == nil {
+		milestone.Approvals = []types.MilestoneApproval{}
+	}
+	milestone.GrantAmount = TrimZeros(milestone.GrantAmount)
+	milestone.Schedule = milestone.ScheduleStatus(time.Now())
+	return &milestone, nil
+}
+
+func (g *grant) UpdateGrantMilestones(ctx context.Context, grantID uuid.UUID, milestones []types.Milestone) ([]uuid.UUID, error) {
 	// Start transaction
 	tx, err := g.dbConn.BeginTxx(ctx, nil)
 	if err != nil {
-		return errors.Wrap(err, "failed to begin transaction")
+		return nil, errors.Wrap(err, "failed to begin transaction")
 	}
 	defer tx.Rollback()
 
-	// Delete existing milestones
-	_, err = tx.ExecContext(ctx, "DELETE FROM milestones WHERE grant_id = $1", grantID)
+	// Milestones are in the grant's asset unless they have their own
+	var grantAsset string
+	err = tx.GetContext(ctx, &grantAsset, "SELECT asset FROM grants WHERE id = $1 FOR UPDATE", grantID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("grant not found")
+	}
 	if err != nil {
-		return errors.Wrap(err, "failed to delete existing milestones")
+		return nil, errors.Wrap(err, "failed to get grant asset")
 	}
 
-	// Insert new milestones
+	// Move the existing milestones out of the way of the new order, the ones left there afterwards were removed
+	_, err = tx.ExecContext(ctx, "UPDATE milestones SET order_index = -1 - order_index WHERE grant_id = $1", grantID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to reorder existing milestones")
+	}
+
+	// Insert new milestones, and update the terms of the existing ones without touching their sign-off unless the
+	// payment they were signed off for changes
+	reset := []uuid.UUID{}
 	milestoneCols := psql.GetSQLColumnsQuoted[types.Milestone]()
 	milestoneColsNoQuote := psql.GetSQLColumns[types.Milestone]()
 	for i, milestone := range milestones {
 		milestone.GrantID = grantID
 		milestone.OrderIndex = i
-		_, err = tx.NamedExecContext(ctx, fmt.Sprintf(`
-			INSERT INTO milestones (%s) VALUES (%s)`,
+		milestone.Status = types.MilestoneStatusPending
+		milestone.Completed = false
+		milestone.SignedOff = false
+		if milestone.Asset == "" {
+			milestone.Asset = grantAsset
+		}
+		wasReset, err := resetRepricedMilestone(ctx, tx, grantID, milestone)
+		if err != nil {
+			return nil, err
+		}
+		if wasReset {
+			reset = append(reset, milestone.ID)
+		}
+		result, err := tx.NamedExecContext(ctx, fmt.Sprintf(`
+			INSERT INTO milestones (%s) VALUES (%s)
+			ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, description = EXCLUDED.description,
+				asset = EXCLUDED.asset, grant_amount = EXCLUDED.grant_amount, order_index = EXCLUDED.order_index,
+				target_date = EXCLUDED.target_date, updated_at = NOW()
+			WHERE milestones.grant_id = EXCLUDED.grant_id`,
 			strings.Join(milestoneCols, ", "),
 			":"+strings.Join(milestoneColsNoQuote, ", :")), milestone)
 		if err != nil {
-			return errors.Wrap(err, "failed to insert milestone")
+			return nil, errors.Wrap(err, "failed to insert milestone")
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get rows affected")
+		}
+		if rowsAffected == 0 {
+			return nil, errors.Errorf("milestone %s belongs to another grant", milestone.ID)
 		}
 	}
 
+	// Deleting a completed milestone would take its evidence, approvals and proposed payment with it
+	var removed types.Milestone
+	err = tx.GetContext(ctx, &removed, `
+		SELECT id, status FROM milestones WHERE grant_id = $1 AND order_index < 0 AND status <> 'pending' LIMIT 1`, grantID)
+	if err == nil {
+		return nil, errors.Errorf("milestone %s is already %s and can't be removed", removed.ID, removed.Status)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.Wrap(err, "failed to check removed milestones")
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM milestones WHERE grant_id = $1 AND order_index < 0", grantID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to delete removed milestones")
+	}
+
 	// Commit transaction
 	err = tx.Commit()
 	if err != nil {
-		return errors.Wrap(err, "failed to commit transaction")
+		return nil, errors.Wrap(err, "failed to commit transaction")
+	}
+
+	return reset, nil
+}
+
+func (g *grant) CompleteMilestone(ctx context.Context, grantID, milestoneID uuid.UUID, evidence, completedBy string) (*types.Milestone, error) {
+	tx, err := g.dbConn.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	err = completeMilestone(ctx, tx, grantID, milestoneID, evidence, completedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to commit transaction")
+	}
+	return g.GetMilestone(ctx, grantID, milestoneID)
+}
+
+func (g *grant) ApproveMilestone(ctx context.Context, grantID, milestoneID uuid.UUID, approval types.MilestoneApproval, settings types.MilestoneSignOffSettings) (*types.Milestone, error) {
+	approval.MilestoneID = milestoneID
+	approval.AdminAddress = strings.ToLower(approval.AdminAddress)
+	if approval.CreatedAt.IsZero() {
+		approval.CreatedAt = time.Now()
+	}
+
+	tx, err := g.dbConn.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	// Locking the milestone keeps concurrent approvals from both seeing one approval short of the sign-off
+	status, err := lockMilestone(ctx, tx, grantID, milestoneID)
+	if err != nil {
+		return nil, err
+	}
+	switch status {
+	case types.MilestoneStatusPending:
+		return nil, errors.New("milestone is not completed")
+	case types.MilestoneStatusSignedOff:
+		return nil, errors.New("milestone is already signed off")
 	}
 
+	result, err := tx.NamedExecContext(ctx, fmt.Sprintf(`
+		INSERT INTO milestone_approvals (%s) VALUES (%s) ON CONFLICT DO NOTHING`,
+		strings.Join(psql.GetSQLColumnsQuoted[types.MilestoneApproval](), ", "),
+		":"+strings.Join(psql.GetSQLColumns[types.MilestoneApproval](), ", :")), approval)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to insert milestone approval")
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get rows affected")
+	}
+	if rowsAffected == 0 {
+		return nil, errors.New("milestone is already approved by this admin")
+	}
+
+	var approvals int
+	err = tx.GetContext(ctx, &approvals, `SELECT COUNT(*) FROM milestone_approvals WHERE milestone_id = $1`, milestoneID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to count milestone approvals")
+	}
+
+	if approvals >= settings.RequiredApprovals {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE milestones SET status = 'signed_off', signed_off = TRUE, signed_off_at = NOW(), updated_at = NOW()
+			WHERE id = $1`, milestoneID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to sign off milestone")
+		}
+
+		if settings.ProposeDisbursement {
+			_, err = tx.ExecContext(ctx, `
+				INSERT INTO disbursement_proposals (grant_id, milestone_id, asset, amount, recipient_address)
+				SELECT m.grant_id, m.id, m.asset, m.grant_amount, g.recipient_address
+				FROM milestones m JOIN grants g ON g.id = m.grant_id
+				WHERE m.id = $1
+				ON CONFLICT (milestone_id) DO NOTHING`, milestoneID)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to propose milestone disbursement")
+			}
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to commit transaction")
+	}
+	return g.GetMilestone(ctx, grantID, milestoneID)
+}
+
+func (g *grant) GetOverdueMilestones(ctx context.Context) ([]types.OverdueMilestone, error) {
+	var overdue []types.OverdueMilestone
+	err := g.getOverdueMilestones.SelectContext(ctx, &overdue)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get overdue milestones")
+	}
+	for i := range overdue {
+		overdue[i].GrantAmount = TrimZeros(overdue[i].GrantAmount)
+		overdue[i].Schedule = types.MilestoneOverdue
+		overdue[i].Approvals = []types.MilestoneApproval{} // Pending milestones have no approvals
+	}
+	if len(overdue) == 0 {
+		return []types.OverdueMilestone{}, nil
+	}
+	return overdue, nil
+}
+
+func (g *grant) RecordOverdueMilestones(ctx context.Context) ([]types.MilestoneEvent, error) {
+	var events []types.MilestoneEvent
+	err := g.recordOverdueMilestones.SelectContext(ctx, &events)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to record overdue milestones")
+	}
+	return events, nil
+}
+
+func (g *grant) GetMilestoneEvents(ctx context.Context, grantID uuid.UUID) ([]types.MilestoneEvent, error) {
+	var events []types.MilestoneEvent
+	err := g.getMilestoneEvents.SelectContext(ctx, &events, grantID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get milestone events")
+	}
+	if len(events) == 0 {
+		return []types.MilestoneEvent{}, nil
+	}
+	return events, nil
+}
+
+// completeMilestone marks a pending milestone of a grant completed with the evidence
+func completeMilestone(ctx context.Context, tx *sqlx.Tx, grantID, milestoneID uuid.UUID, evidence, completedBy string) error {
+	status, err := lockMilestone(ctx, tx, grantID, milestoneID)
+	if err != nil {
+		return err
+	}
+	if status != types.MilestoneStatusPending {
+		return errors.New("milestone is already completed")
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE milestones
+		SET status = 'completed', completed = TRUE, evidence = $2, completed_by = $3, completed_at = NOW(), updated_at = NOW()
+		WHERE id = $1`, milestoneID, evidence, strings.ToLower(completedBy))
+	if err != nil {
+		return errors.Wrap(err, "failed to complete milestone")
+	}
 	return nil
 }
 
+// resetRepricedMilestone takes a completed or signed off milestone whose asset or amount changes back to completed, so
+// it is approved again for what it pays. Its approvals and proposed payment go, a milestone already paid can't be
+// repriced. It returns whether the milestone was reset.
+func resetRepricedMilestone(ctx context.Context, tx *sqlx.Tx, grantID uuid.UUID, milestone types.Milestone) (bool, error) {
+	var existing struct {
+		Status  types.MilestoneStatus `db:"status"`
+		Changed bool                  `db:"changed"`
+		Paid    bool                  `db:"paid"`
+	}
+	err := tx.GetContext(ctx, &existing, `
+		SELECT m.status, (m.asset <> $3 OR m.grant_amount <> $4::ETHER_T) AS changed,
+			EXISTS (SELECT 1 FROM disbursement_proposals p WHERE p.milestone_id = m.id AND p.status = 'disbursed') AS paid
+		FROM milestones m WHERE m.id = $1 AND m.grant_id = $2 FOR UPDATE`,
+		milestone.ID, grantID, milestone.Asset, milestone.GrantAmount)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to get milestone %s", milestone.ID)
+	}
+	if existing.Status == types.MilestoneStatusPending || !existing.Changed {
+		return false, nil
+	}
+	if existing.Paid {
+		return false, errors.Errorf("milestone %s has been paid, its asset and amount can't be changed", milestone.ID)
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM milestone_approvals WHERE milestone_id = $1`, milestone.ID)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to delete approvals of milestone %s", milestone.ID)
+	}
+	_, err = tx.ExecContext(ctx, `DELETE FROM disbursement_proposals WHERE milestone_id = $1`, milestone.ID)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to delete disbursement proposal of milestone %s", milestone.ID)
+	}
+	_, err = tx.ExecContext(ctx, `
+		UPDATE milestones SET status = 'completed', signed_off = FALSE, signed_off_at = NULL, updated_at = NOW()
+		WHERE id = $1`, milestone.ID)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to reset milestone %s", milestone.ID)
+	}
+	return true, nil
+}
+
+// lockMilestone locks a milestone of a grant until the transaction ends and returns its status
+func lockMilestone(ctx context.Context, tx *sqlx.Tx, grantID, milestoneID uuid.UUID) (types.MilestoneStatus, error) {
+	var status types.MilestoneStatus
+	err := tx.GetContext(ctx, &status, `SELECT status FROM milestones WHERE id = $1 AND grant_id = $2 FOR UPDATE`, milestoneID, grantID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", errors.New("milestone not found")
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "failed to lock milestone")
+	}
+	return status, nil
+}
+
+// Disbursement proposal methods
+func (g *grant) GetDisbursementProposals(ctx context.Context, grantID uuid.UUID) ([]types.DisbursementProposal, error) {
+	var proposals []types.DisbursementProposal
+	err := g.getDisbursementProposals.SelectContext(ctx, &proposals, grantID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get disbursement proposals")
+	}
+	for i := range proposals {
+		proposals[i].Amount = TrimZeros(proposals[i].Amount)
+	}
+	if len(proposals) == 0 {
+		return []types.DisbursementProposal{}, nil
+	}
+	return proposals, nil
+}
+
+func (g *grant) ReviewDisbursementProposal(ctx context.Context, grantID, proposalID uuid.UUID, status types.DisbursementProposalStatus, admin string) error {
+	var current types.DisbursementProposalStatus
+	err := g.dbConn.GetContext(ctx, &current, `
+		UPDATE disbursement_proposals SET status = $3, reviewed_by = $4, reviewed_at = NOW()
+		WHERE id = $1 AND grant_id = $2 AND status = 'proposed'
+		RETURNING status`, proposalID, grantID, status, strings.ToLower(admin))
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return errors.Wrap(err, "failed to review disbursement proposal")
+	}
+
+	var exists bool
+	err = g.dbConn.GetContext(ctx, &exists, `SELECT EXISTS(SELECT 1 FROM disbursement_proposals WHERE id = $1 AND grant_id = $2)`, proposalID, grantID)
+	if err != nil {
+		return errors.Wrap(err, "failed to check if disbursement proposal exists")
+	}
+	if !exists {
+		return errors.New("disbursement proposal not found")
+	}
+	return errors.New("disbursement proposal is already reviewed")
+}
+
 // Disbursement methods
 func (g *grant) GetDisbursementsByGrantID(ctx context.Context, grantID uuid.UUID) ([]types.Disbursement, error) {
 	var disbursements []types.Disbursement
@@ -307,6 +959,7 @@ func (g *grant) GetDisbursementsByGrantID(ctx context.Context, grantID uuid.UUID
 	}
 	for i := range disbursements {
 		disbursements[i].Amount = TrimZeros(disbursements[i].Amount)
+		disbursements[i].UsdValue = disbursementUsdValue(disbursements[i])
 	}
 	if len(disbursements) == 0 {
 		return []types.Disbursement{}, nil
@@ -316,56 +969,145 @@ func (g *grant) GetDisbursementsByGrantID(ctx context.Context, grantID uuid.UUID
 
 func (g *grant) InsertDisbursement(ctx context.Context, grantID uuid.UUID, disbursement types.CreateDisbursement) error {
 	disbursement.GrantID = grantID
+	setDisbursementDefaults(&disbursement)
+
+	tx, err := g.dbConn.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	if !disbursement.UsdPrice.Valid {
+		disbursement.UsdPrice, err = assetPriceAt(ctx, tx, disbursement.Asset, disbursement.BlockTimestamp)
+		if err != nil {
+			return err
+		}
+	}
+
 	var id uuid.UUID
-	err := g.insertDisbursement.QueryRowxContext(ctx, disbursement).Scan(&id)
+	err = tx.NamedStmtContext(ctx, g.insertDisbursement).QueryRowxContext(ctx, disbursement).Scan(&id)
 	if err != nil {
 		return errors.Wrap(err, "failed to insert disbursement")
 	}
-	result, err := g.dbConn.ExecContext(ctx, "UPDATE grants SET amount_given_so_far = (SELECT SUM(amount) FROM disbursements WHERE grant_id = $1) WHERE id = $1", grantID)
+	err = syncAmountGiven(ctx, tx, grantID)
 	if err != nil {
-		return errors.Wrap(err, "failed to update grant amount")
+		return err
 	}
-	rowsAffected, err := result.RowsAffected()
+
+	err = tx.Commit()
 	if err != nil {
-		return errors.Wrap(err, "failed to get rows affected")
-	}
-	if rowsAffected == 0 {
-		return errors.New("grant not found when updating amount")
+		return errors.Wrap(err, "failed to commit transaction")
 	}
 	return nil
 }
 
 func (g *grant) UpdateDisbursement(ctx context.Context, disbursementID uuid.UUID, updates types.Disbursement) error {
 	updates.ID = disbursementID
-	result, err := g.updateDisbursement.ExecContext(ctx, updates)
+	setDisbursementDefaults(&updates.CreateDisbursement)
+
+	tx, err := g.dbConn.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	// The disbursement may be moved to another grant, whose amount has to be recomputed as well
+	var previous struct {
+		GrantID        uuid.UUID  `db:"grant_id"`
+		Asset          string     `db:"asset"`
+		BlockTimestamp int64      `db:"block_timestamp"`
+		UsdPrice       null.Float `db:"usd_price"`
+	}
+	err = tx.GetContext(ctx, &previous, `
+		SELECT grant_id, asset, block_timestamp, usd_price FROM disbursements WHERE id = $1 FOR UPDATE`, disbursementID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errors.New("disbursement not found")
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to get disbursement")
+	}
+
+	// The price is kept unless the asset or the time it was paid at changed, then it is priced again at that time
+	if !updates.UsdPrice.Valid && updates.Asset == previous.Asset && updates.BlockTimestamp == previous.BlockTimestamp {
+		updates.UsdPrice = previous.UsdPrice
+	}
+	if !updates.UsdPrice.Valid {
+		updates.UsdPrice, err = assetPriceAt(ctx, tx, updates.Asset, updates.BlockTimestamp)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.NamedStmtContext(ctx, g.updateDisbursement).ExecContext(ctx, updates)
 	if err != nil {
 		return errors.Wrap(err, "failed to update disbursement")
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	err = syncAmountGiven(ctx, tx, updates.GrantID)
 	if err != nil {
-		return errors.Wrap(err, "failed to get rows affected")
+		return err
+	}
+	if previous.GrantID != updates.GrantID {
+		err = syncAmountGiven(ctx, tx, previous.GrantID)
+		if err != nil {
+			return err
+		}
 	}
 
-	if rowsAffected == 0 {
-		return errors.New("disbursement not found")
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, "failed to commit transaction")
 	}
+	return nil
+}
 
-	result, err = g.dbConn.ExecContext(ctx, "UPDATE grants SET amount_given_so_far = (SELECT SUM(amount) FROM disbursements WHERE grant_id = $1) WHERE id = $1", updates.GrantID)
+// syncAmountGiven recomputes the amount given to a grant from its disbursements, within the transaction that changed
+// them. It is in the grant's asset, disbursements of other assets aren't counted.
+func syncAmountGiven(ctx context.Context, tx *sqlx.Tx, grantID uuid.UUID) error {
+	result, err := tx.ExecContext(ctx, `
+		UPDATE grants g SET amount_given_so_far = COALESCE((
+			SELECT SUM(d.amount) FROM disbursements d WHERE d.grant_id = g.id AND d.asset = g.asset), 0)
+		WHERE g.id = $1`, grantID)
 	if err != nil {
 		return errors.Wrap(err, "failed to update grant amount")
 	}
-	rowsAffected, err = result.RowsAffected()
+	rowsAffected, err := result.RowsAffected()
 	if err != nil {
 		return errors.Wrap(err, "failed to get rows affected")
 	}
 	if rowsAffected == 0 {
 		return errors.New("grant not found when updating amount")
 	}
-
 	return nil
 }
 
+// priceWindow is how far from a disbursement the price of its asset may have been read to value it. Only the latest
+// price of each asset is kept, so older disbursements are left without a price rather than valued at today's.
+const priceWindow = time.Hour
+
+// assetPriceAt returns the USD price of an asset at a block timestamp, null when there is no price from then
+func assetPriceAt(ctx context.Context, tx *sqlx.Tx, asset string, timestamp int64) (null.Float, error) {
+	var price null.Float
+	err := tx.GetContext(ctx, &price, `
+		SELECT usd_price FROM asset_prices
+		WHERE chain_id = 1 AND address = $1 AND ABS(EXTRACT(EPOCH FROM updated_at) - $2) <= $3`,
+		asset, timestamp, priceWindow.Seconds())
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return null.Float{}, errors.Wrapf(err, "failed to get price of %s", asset)
+	}
+	return price, nil
+}
+
+// setDisbursementDefaults fills in what disbursements recorded before assets and verification were added lack
+func setDisbursementDefaults(disbursement *types.CreateDisbursement) {
+	if disbursement.Asset == "" {
+		disbursement.Asset = constants.EtherAddress
+	}
+	if disbursement.VerificationStatus == "" {
+		disbursement.VerificationStatus = types.DisbursementUnverified
+	}
+}
+
 // Funds usage methods
 func (g *grant) GetFundsUsageByGrantID(ctx context.Context, grantID uuid.UUID) ([]types.FundsUsage, error) {
 	var fundsUsage []types.FundsUsage
@@ -5,6 +5,7 @@ package db
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
@@ -13,6 +14,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"gopkg.in/guregu/null.v4"
 
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/constants"
 	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
 )
 
@@ -48,13 +50,14 @@ func Test_GrantDB_CreateAndGetGrants(t *testing.T) {
 	require.NoError(t, err)
 
 	// Get all grants
-	grants, err := db.GetGrants(t.Context())
+	grants, err := db.GetGrants(t.Context(), types.GrantFilter{Limit: 1000})
 	require.NoError(t, err)
-	require.NotEmpty(t, grants)
+	require.NotEmpty(t, grants.Data)
+	require.GreaterOrEqual(t, grants.Total, len(grants.Data))
 
 	// Check if our test grant is in the results
 	var found bool
-	for _, g := range grants {
+	for _, g := range grants.Data {
 		if g.ID == grantID {
 			found = true
 			require.Equal(t, grant.Name, g.Name)
@@ -69,6 +72,63 @@ func Test_GrantDB_CreateAndGetGrants(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func Test_GrantDB_SearchGrants(t *testing.T) {
+	var (
+		db        = GetTestGrantDB(t)
+		recipient = ethutils.GenRandEVMAddr()
+		start     = time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+		ids       []uuid.UUID
+	)
+	for i, name := range []string{"Zk_Light Client", "Light client research", "Unrelated tooling"} {
+		id, err := db.CreateGrant(t.Context(), types.CreateGrant{
+			Name:                   name,
+			RecipientName:          "Search Recipient",
+			RecipientAddress:       recipient,
+			Description:            "Testing grant search",
+			TotalGrantAmount:       fmt.Sprintf("%d", (i+1)*100),
+			InitialGrantAmount:     "0",
+			StartDate:              start.AddDate(0, i, 0),
+			ExpectedCompletionDate: start.AddDate(1, i, 0),
+			AmountGivenSoFar:       "0",
+			Status:                 types.GrantStatusActive,
+		})
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+
+	grants, err := db.GetGrants(t.Context(), types.GrantFilter{
+		Search: "light CLIENT", RecipientAddress: recipient, Sort: types.GrantSortTotalGrantAmount, Descending: true, Limit: 10,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, grants.Total)
+	require.Len(t, grants.Data, 2)
+	require.Equal(t, ids[1], grants.Data[0].ID)
+	require.Equal(t, ids[0], grants.Data[1].ID)
+
+	// LIKE wildcards are searched for literally
+	grants, err = db.GetGrants(t.Context(), types.GrantFilter{Search: "k_l", RecipientAddress: recipient, Limit: 10})
+	require.NoError(t, err)
+	require.Equal(t, 1, grants.Total)
+
+	grants, err = db.GetGrants(t.Context(), types.GrantFilter{
+		RecipientAddress: recipient, StartFrom: null.TimeFrom(start.AddDate(0, 1, 0)), MaxAmount: null.StringFrom("300"),
+		Sort: types.GrantSortStartDate, Limit: 1, Offset: 1,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, grants.Total, "the total counts every page")
+	require.Len(t, grants.Data, 1)
+	require.Equal(t, ids[2], grants.Data[0].ID)
+
+	_, err = db.GetGrants(t.Context(), types.GrantFilter{Sort: "recipient_name; DROP TABLE grants", Limit: 10})
+	require.Error(t, err)
+
+	// Clean up
+	for _, id := range ids {
+		_, err = dbConn.ExecContext(t.Context(), "DELETE FROM grants WHERE id = $1", id)
+		require.NoError(t, err)
+	}
+}
+
 func Test_GrantDB_GetGrantByID(t *testing.T) {
 	var (
 		db    = GetTestGrantDB(t)
@@ -127,7 +187,7 @@ func Test_GrantDB_UpdateGrant(t *testing.T) {
 		TotalGrantAmount: null.StringFrom("12000"),
 	}
 
-	err = db.UpdateGrant(t.Context(), grantID, updates)
+	err = db.UpdateGrant(t.Context(), grantID, updates, "")
 	require.NoError(t, err)
 
 	// Verify updates
@@ -142,6 +202,72 @@ func Test_GrantDB_UpdateGrant(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func Test_GrantDB_StatusTransitions(t *testing.T) {
+	var (
+		db           = GetTestGrantDB(t)
+		adminDB      = GetTestAdminDB(t)
+		adminAddress = ethutils.GenRandEVMAddr()
+		grant        = types.CreateGrant{
+			Name:                   "Test Grant Lifecycle",
+			RecipientName:          "Test Recipient Lifecycle",
+			RecipientAddress:       ethutils.GenRandEVMAddr(),
+			Description:            "Testing status transitions",
+			TotalGrantAmount:       "100",
+			InitialGrantAmount:     "0",
+			StartDate:              time.Now().AddDate(-1, 0, 0),
+			ExpectedCompletionDate: time.Now().AddDate(0, 0, -2),
+			AmountGivenSoFar:       "0",
+			Status:                 types.GrantStatusProposed,
+		}
+	)
+	err := adminDB.AddAdmin(t.Context(), types.Admin{Address: adminAddress, Name: "Lifecycle Admin"})
+	require.NoError(t, err)
+
+	grantID, err := db.CreateGrant(t.Context(), grant)
+	require.NoError(t, err)
+
+	transition := func(status types.GrantStatus, reason string) error {
+		return db.UpdateGrant(t.Context(), grantID, types.UpdateGrantRequest{
+			Status:       null.StringFrom(string(status)),
+			StatusReason: null.StringFrom(reason),
+		}, adminAddress)
+	}
+
+	require.ErrorContains(t, transition(types.GrantStatusActive, "Skipping approval"), "invalid grant status transition")
+	require.ErrorContains(t, transition(types.GrantStatusApproved, " "), "reason is required")
+	require.NoError(t, transition(types.GrantStatusApproved, "Approved by the committee"))
+	require.NoError(t, transition(types.GrantStatusApproved, ""), "staying in the same status isn't a transition")
+	require.NoError(t, transition(types.GrantStatusActive, "Initial payment sent"))
+
+	retrieved, err := db.GetGrantByID(t.Context(), grantID)
+	require.NoError(t, err)
+	require.Equal(t, types.GrantStatusActive, retrieved.Status)
+	require.True(t, retrieved.Overdue, "active past its expected completion date")
+
+	require.NoError(t, transition(types.GrantStatusCompleted, "Delivered"))
+	retrieved, err = db.GetGrantByID(t.Context(), grantID)
+	require.NoError(t, err)
+	require.False(t, retrieved.Overdue)
+	require.ErrorContains(t, transition(types.GrantStatusActive, "Reopened"), "invalid grant status transition")
+
+	events, err := db.GetStatusEvents(t.Context(), grantID)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	require.Equal(t, types.GrantStatusProposed, events[0].From)
+	require.Equal(t, types.GrantStatusApproved, events[0].To)
+	require.Equal(t, "Approved by the committee", events[0].Reason)
+	require.Equal(t, adminAddress, events[0].ChangedBy.String)
+	require.Equal(t, types.GrantStatusCompleted, events[2].To)
+
+	// Clean up
+	_, err = dbConn.ExecContext(t.Context(), "DELETE FROM admin_actions WHERE resource_id = $1", grantID.String())
+	require.NoError(t, err)
+	_, err = dbConn.ExecContext(t.Context(), "DELETE FROM grants WHERE id = $1", grantID)
+	require.NoError(t, err)
+	_, err = dbConn.ExecContext(t.Context(), "DELETE FROM admins WHERE address = $1", adminAddress)
+	require.NoError(t, err)
+}
+
 func Test_GrantDB_GrantExists(t *testing.T) {
 	var (
 		db    = GetTestGrantDB(t)
@@ -219,7 +345,7 @@ func Test_GrantDB_MilestoneOperations(t *testing.T) {
 	}
 
 	// Update grant milestones
-	err = db.UpdateGrantMilestones(t.Context(), grantID, milestones)
+	_, err = db.UpdateGrantMilestones(t.Context(), grantID, milestones)
 	require.NoError(t, err)
 
 	// Get milestones
@@ -250,6 +376,232 @@ func Test_GrantDB_MilestoneOperations(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func Test_GrantDB_MilestoneSignOff(t *testing.T) {
+	var (
+		db    = GetTestGrantDB(t)
+		grant = types.CreateGrant{
+			Name:               "Test Grant Sign Off",
+			RecipientName:      "Test Recipient Sign Off",
+			RecipientAddress:   ethutils.GenRandEVMAddr(),
+			Description:        "Testing milestone sign-off",
+			TotalGrantAmount:   "3",
+			InitialGrantAmount: "0",
+			AmountGivenSoFar:   "0",
+			Status:             types.GrantStatusActive,
+		}
+		settings    = types.MilestoneSignOffSettings{RequiredApprovals: 2, ProposeDisbursement: true}
+		firstAdmin  = ethutils.GenRandEVMAddr()
+		secondAdmin = ethutils.GenRandEVMAddr()
+	)
+
+	grantID, err := db.CreateGrant(t.Context(), grant)
+	require.NoError(t, err)
+
+	milestones := []types.Milestone{
+		{ID: uuid.New(), Name: "First Milestone", Description: "Build it", GrantAmount: "1"},
+		{ID: uuid.New(), Name: "Second Milestone", Description: "Ship it", GrantAmount: "2"},
+	}
+	_, err = db.UpdateGrantMilestones(t.Context(), grantID, milestones)
+	require.NoError(t, err)
+	first := milestones[0].ID
+
+	approve := func(admin string) (*types.Milestone, error) {
+		return db.ApproveMilestone(t.Context(), grantID, first, types.MilestoneApproval{AdminAddress: admin}, settings)
+	}
+
+	_, err = approve(firstAdmin)
+	require.ErrorContains(t, err, "milestone is not completed")
+
+	milestone, err := db.CompleteMilestone(t.Context(), grantID, first, "https://example.com/report", grant.RecipientAddress)
+	require.NoError(t, err)
+	require.Equal(t, types.MilestoneStatusCompleted, milestone.Status)
+	require.True(t, milestone.Completed)
+	require.Equal(t, "https://example.com/report", milestone.Evidence.String)
+	require.True(t, milestone.CompletedAt.Valid)
+
+	_, err = db.CompleteMilestone(t.Context(), grantID, first, "again", grant.RecipientAddress)
+	require.ErrorContains(t, err, "already completed")
+	_, err = db.CompleteMilestone(t.Context(), uuid.New(), first, "wrong grant", grant.RecipientAddress)
+	require.ErrorContains(t, err, "milestone not found")
+
+	// One approval out of two doesn't sign it off, and an admin can't approve twice
+	milestone, err = approve(firstAdmin)
+	require.NoError(t, err)
+	require.Equal(t, types.MilestoneStatusCompleted, milestone.Status)
+	require.Len(t, milestone.Approvals, 1)
+	_, err = approve(firstAdmin)
+	require.ErrorContains(t, err, "already approved by this admin")
+
+	// Editing the milestones keeps the sign-off progress of the ones kept
+	milestones[0].Name = "First Milestone, renamed"
+	reset, err := db.UpdateGrantMilestones(t.Context(), grantID, milestones)
+	require.NoError(t, err)
+	require.Empty(t, reset)
+	milestone, err = db.GetMilestone(t.Context(), grantID, first)
+	require.NoError(t, err)
+	require.Equal(t, "First Milestone, renamed", milestone.Name)
+	require.Equal(t, types.MilestoneStatusCompleted, milestone.Status)
+	require.Len(t, milestone.Approvals, 1)
+
+	milestone, err = approve(secondAdmin)
+	require.NoError(t, err)
+	require.Equal(t, types.MilestoneStatusSignedOff, milestone.Status)
+	require.True(t, milestone.SignedOff)
+	require.True(t, milestone.SignedOffAt.Valid)
+	_, err = approve(ethutils.GenRandEVMAddr())
+	require.ErrorContains(t, err, "already signed off")
+
+	proposals, err := db.GetDisbursementProposals(t.Context(), grantID)
+	require.NoError(t, err)
+	require.Len(t, proposals, 1)
+	require.Equal(t, first, proposals[0].MilestoneID)
+	require.Equal(t, "1", proposals[0].Amount)
+	require.Equal(t, constants.EtherAddress, proposals[0].Asset)
+	require.Equal(t, grant.RecipientAddress, proposals[0].RecipientAddress)
+	require.Equal(t, types.DisbursementProposalProposed, proposals[0].Status)
+
+	// Repricing a signed off milestone takes it back to completed, to be approved again for the new amount
+	milestones[0].GrantAmount = "1.5"
+	reset, err = db.UpdateGrantMilestones(t.Context(), grantID, milestones)
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{first}, reset)
+	milestone, err = db.GetMilestone(t.Context(), grantID, first)
+	require.NoError(t, err)
+	require.Equal(t, types.MilestoneStatusCompleted, milestone.Status)
+	require.False(t, milestone.SignedOff)
+	require.False(t, milestone.SignedOffAt.Valid)
+	require.Empty(t, milestone.Approvals)
+	require.Equal(t, "https://example.com/report", milestone.Evidence.String)
+	proposals, err = db.GetDisbursementProposals(t.Context(), grantID)
+	require.NoError(t, err)
+	require.Empty(t, proposals)
+
+	_, err = approve(firstAdmin)
+	require.NoError(t, err)
+	milestone, err = approve(secondAdmin)
+	require.NoError(t, err)
+	require.Equal(t, types.MilestoneStatusSignedOff, milestone.Status)
+	proposals, err = db.GetDisbursementProposals(t.Context(), grantID)
+	require.NoError(t, err)
+	require.Len(t, proposals, 1)
+	require.Equal(t, "1.5", proposals[0].Amount)
+
+	require.NoError(t, db.ReviewDisbursementProposal(t.Context(), grantID, proposals[0].ID, types.DisbursementProposalDisbursed, firstAdmin))
+	err = db.ReviewDisbursementProposal(t.Context(), grantID, proposals[0].ID, types.DisbursementProposalDismissed, firstAdmin)
+	require.ErrorContains(t, err, "already reviewed")
+	err = db.ReviewDisbursementProposal(t.Context(), grantID, uuid.New(), types.DisbursementProposalDismissed, firstAdmin)
+	require.ErrorContains(t, err, "not found")
+
+	// A paid milestone can't be repriced, and a completed one can't be removed
+	milestones[0].GrantAmount = "2"
+	_, err = db.UpdateGrantMilestones(t.Context(), grantID, milestones)
+	require.ErrorContains(t, err, "has been paid")
+	milestones[0].GrantAmount = "1.5"
+	_, err = db.UpdateGrantMilestones(t.Context(), grantID, milestones[1:])
+	require.ErrorContains(t, err, "is already signed_off and can't be removed")
+
+	// Removing a pending milestone from the list deletes it
+	milestones[0].Name = "First Milestone"
+	_, err = db.UpdateGrantMilestones(t.Context(), grantID, milestones[:1])
+	require.NoError(t, err)
+	retrieved, err := db.GetMilestonesByGrantID(t.Context(), grantID)
+	require.NoError(t, err)
+	require.Len(t, retrieved, 1)
+	require.Equal(t, first, retrieved[0].ID)
+	require.Equal(t, "First Milestone", retrieved[0].Name)
+	require.Equal(t, types.MilestoneStatusSignedOff, retrieved[0].Status)
+
+	// Clean up
+	_, err = dbConn.ExecContext(t.Context(), "DELETE FROM grants WHERE id = $1", grantID)
+	require.NoError(t, err)
+}
+
+func Test_GrantDB_MilestoneSchedule(t *testing.T) {
+	var (
+		db    = GetTestGrantDB(t)
+		today = time.Now().UTC().Truncate(24 * time.Hour)
+		grant = types.CreateGrant{
+			Name:                   "Test Grant Schedule",
+			RecipientName:          "Test Recipient Schedule",
+			RecipientAddress:       ethutils.GenRandEVMAddr(),
+			Description:            "Testing milestone schedules",
+			TotalGrantAmount:       "4",
+			InitialGrantAmount:     "0",
+			AmountGivenSoFar:       "0",
+			StartDate:              today.AddDate(0, -1, 0),
+			ExpectedCompletionDate: today.AddDate(0, 3, 0),
+			Status:                 types.GrantStatusActive,
+		}
+	)
+
+	grantID, err := db.CreateGrant(t.Context(), grant)
+	require.NoError(t, err)
+
+	milestones := []types.Milestone{
+		{ID: uuid.New(), Name: "Late", Description: "Past due", GrantAmount: "1", TargetDate: null.TimeFrom(today.AddDate(0, 0, -3))},
+		{ID: uuid.New(), Name: "Soon", Description: "Due this week", GrantAmount: "1", TargetDate: null.TimeFrom(today.AddDate(0, 0, 5))},
+		{ID: uuid.New(), Name: "Later", Description: "Due in months", GrantAmount: "1", TargetDate: null.TimeFrom(today.AddDate(0, 2, 0))},
+		{ID: uuid.New(), Name: "Whenever", Description: "Not scheduled", GrantAmount: "1"},
+	}
+	_, err = db.UpdateGrantMilestones(t.Context(), grantID, milestones)
+	require.NoError(t, err)
+
+	retrieved, err := db.GetMilestonesByGrantID(t.Context(), grantID)
+	require.NoError(t, err)
+	require.Len(t, retrieved, 4)
+	require.Equal(t, types.MilestoneOverdue, retrieved[0].Schedule)
+	require.Equal(t, types.MilestoneDueSoon, retrieved[1].Schedule)
+	require.Equal(t, types.MilestoneOnTrack, retrieved[2].Schedule)
+	require.Equal(t, types.MilestoneUnscheduled, retrieved[3].Schedule)
+
+	overdueIDs := func() map[uuid.UUID]types.OverdueMilestone {
+		overdue, err := db.GetOverdueMilestones(t.Context())
+		require.NoError(t, err)
+		out := map[uuid.UUID]types.OverdueMilestone{}
+		for _, milestone := range overdue {
+			out[milestone.ID] = milestone
+		}
+		return out
+	}
+	overdue := overdueIDs()
+	require.Contains(t, overdue, milestones[0].ID)
+	require.NotContains(t, overdue, milestones[1].ID)
+	require.Equal(t, 3, overdue[milestones[0].ID].DaysOverdue)
+	require.Equal(t, grant.Name, overdue[milestones[0].ID].GrantName)
+
+	// The tracker reports a milestone becoming overdue once
+	countEvents := func(events []types.MilestoneEvent) int {
+		var count int
+		for _, event := range events {
+			if event.GrantID == grantID {
+				count++
+			}
+		}
+		return count
+	}
+	events, err := db.RecordOverdueMilestones(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, 1, countEvents(events))
+	events, err = db.RecordOverdueMilestones(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, 0, countEvents(events))
+
+	events, err = db.GetMilestoneEvents(t.Context(), grantID)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, milestones[0].ID, events[0].MilestoneID)
+	require.Equal(t, types.MilestoneEventOverdue, events[0].Event)
+
+	// Completing it takes it off the list
+	_, err = db.CompleteMilestone(t.Context(), grantID, milestones[0].ID, "https://example.com/late", grant.RecipientAddress)
+	require.NoError(t, err)
+	require.NotContains(t, overdueIDs(), milestones[0].ID)
+
+	// Clean up
+	_, err = dbConn.ExecContext(t.Context(), "DELETE FROM grants WHERE id = $1", grantID)
+	require.NoError(t, err)
+}
+
 func Test_GrantDB_DisbursementOperations(t *testing.T) {
 	var (
 		db    = GetTestGrantDB(t)
@@ -305,6 +657,8 @@ func Test_GrantDB_DisbursementOperations(t *testing.T) {
 			require.Equal(t, "3000", d.Amount)
 			require.Equal(t, createDisbursement1.TxHash, d.TxHash)
 			require.Equal(t, grantID, d.GrantID)
+			require.Equal(t, constants.EtherAddress, d.Asset)
+			require.Equal(t, types.DisbursementUnverified, d.VerificationStatus)
 			disbursementToUpdate = d
 		}
 		if d.TxHash == createDisbursement2.TxHash {
@@ -321,6 +675,8 @@ func Test_GrantDB_DisbursementOperations(t *testing.T) {
 	disbursementToUpdate.Amount = "4000"
 	disbursementToUpdate.TxHash = ethutils.GenRandEVMHash()
 	disbursementToUpdate.BlockNumber = 18500002
+	disbursementToUpdate.VerificationStatus = types.DisbursementMismatch
+	disbursementToUpdate.VerificationReason = null.StringFrom("Transaction reverted")
 
 	err = db.UpdateDisbursement(t.Context(), disbursementToUpdate.ID, disbursementToUpdate)
 	require.NoError(t, err)
@@ -337,10 +693,24 @@ func Test_GrantDB_DisbursementOperations(t *testing.T) {
 			require.Equal(t, "4000", d.Amount)
 			require.Equal(t, disbursementToUpdate.TxHash, d.TxHash)
 			require.Equal(t, int64(18500002), d.BlockNumber)
+			require.Equal(t, types.DisbursementMismatch, d.VerificationStatus)
+			require.Equal(t, "Transaction reverted", d.VerificationReason.String)
 		}
 	}
 	require.True(t, foundUpdated, "updated disbursement not found")
 
+	// The grant totals follow its disbursements
+	retrieved, err := db.GetGrantByID(t.Context(), grantID)
+	require.NoError(t, err)
+	require.Equal(t, "6500", retrieved.AmountGivenSoFar)
+	require.Equal(t, "8000", retrieved.TotalGrantAmount)
+	require.Equal(t, "6500", retrieved.Totals.Disbursed)
+	require.Equal(t, "1500", retrieved.Totals.Remaining)
+	require.Equal(t, 81.25, retrieved.Totals.PercentDisbursed)
+	require.Equal(t, constants.EtherAddress, retrieved.Asset, "grants are in ether by default")
+	require.Len(t, retrieved.Totals.Assets, 1)
+	require.Equal(t, "6500", retrieved.Totals.Assets[0].Disbursed)
+
 	// Test updating non-existent disbursement
 	nonExistentID := uuid.New()
 	err = db.UpdateDisbursement(t.Context(), nonExistentID, disbursementToUpdate)
@@ -354,6 +724,105 @@ func Test_GrantDB_DisbursementOperations(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func Test_GrantDB_DisbursementPrice(t *testing.T) {
+	var (
+		db    = GetTestGrantDB(t)
+		asset = ethutils.GenRandEVMAddr()
+		now   = time.Now()
+	)
+
+	_, err := dbConn.ExecContext(t.Context(),
+		"INSERT INTO assets (chain_id, address, name, symbol, decimals) VALUES (1, $1, 'Test', 'TST', 6)", asset)
+	require.NoError(t, err)
+	_, err = dbConn.ExecContext(t.Context(),
+		"INSERT INTO asset_prices (chain_id, address, usd_price, updated_at) VALUES (1, $1, 2, $2)", asset, now)
+	require.NoError(t, err)
+
+	grantID, err := db.CreateGrant(t.Context(), types.CreateGrant{
+		Name:               "Test Grant Disbursement Price",
+		RecipientName:      "Test Recipient Price",
+		RecipientAddress:   ethutils.GenRandEVMAddr(),
+		Description:        "Testing disbursement prices",
+		Asset:              asset,
+		TotalGrantAmount:   "100",
+		InitialGrantAmount: "0",
+		AmountGivenSoFar:   "0",
+		Status:             types.GrantStatusActive,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		for _, query := range []string{
+			"DELETE FROM grants WHERE id = $1",
+			"DELETE FROM asset_prices WHERE address = $2",
+			"DELETE FROM assets WHERE address = $2",
+		} {
+			_, err := dbConn.ExecContext(context.Background(), query, grantID, asset)
+			require.NoError(t, err)
+		}
+	})
+
+	// The latest price is only the price of a disbursement made around when it was read
+	recent := types.CreateDisbursement{Asset: asset, Amount: "10", TxHash: ethutils.GenRandEVMHash(), BlockTimestamp: now.Add(-10 * time.Minute).Unix()}
+	old := types.CreateDisbursement{Asset: asset, Amount: "20", TxHash: ethutils.GenRandEVMHash(), BlockTimestamp: now.AddDate(0, -6, 0).Unix()}
+	require.NoError(t, db.InsertDisbursement(t.Context(), grantID, recent))
+	require.NoError(t, db.InsertDisbursement(t.Context(), grantID, old))
+
+	disbursements, err := db.GetDisbursementsByGrantID(t.Context(), grantID)
+	require.NoError(t, err)
+	require.Len(t, disbursements, 2)
+	for _, d := range disbursements {
+		switch d.TxHash {
+		case recent.TxHash:
+			require.Equal(t, 2.0, d.UsdPrice.Float64)
+			require.Equal(t, 20.0, d.UsdValue.Float64)
+		case old.TxHash:
+			require.False(t, d.UsdPrice.Valid, "no price from six months ago")
+			require.False(t, d.UsdValue.Valid)
+		}
+	}
+
+	grant, err := db.GetGrantByID(t.Context(), grantID)
+	require.NoError(t, err)
+	require.Equal(t, "30", grant.Totals.Disbursed)
+	require.Equal(t, 20.0, grant.Totals.DisbursedUsd)
+	require.Equal(t, 1, grant.Totals.Unpriced)
+	require.Equal(t, 1, grant.Totals.Assets[0].Unpriced)
+
+	// Correcting when a disbursement was paid prices it again at that time
+	prices := func() map[string]null.Float {
+		disbursements, err := db.GetDisbursementsByGrantID(t.Context(), grantID)
+		require.NoError(t, err)
+		out := map[string]null.Float{}
+		for _, d := range disbursements {
+			out[d.TxHash] = d.UsdPrice
+		}
+		return out
+	}
+	for _, d := range disbursements {
+		d.BlockTimestamp = now.AddDate(0, -6, 0).Unix()
+		if d.TxHash == old.TxHash {
+			d.BlockTimestamp = now.Unix()
+		}
+		d.UsdPrice = null.Float{}
+		require.NoError(t, db.UpdateDisbursement(t.Context(), d.ID, d))
+	}
+	require.Equal(t, null.FloatFrom(2), prices()[old.TxHash])
+	require.False(t, prices()[recent.TxHash].Valid)
+
+	// Otherwise the price it was recorded with is kept
+	_, err = dbConn.ExecContext(t.Context(), "UPDATE asset_prices SET usd_price = 3 WHERE address = $1", asset)
+	require.NoError(t, err)
+	for _, d := range disbursements {
+		if d.TxHash == old.TxHash {
+			d.BlockTimestamp = now.Unix()
+			d.Amount = "21"
+			d.UsdPrice = null.Float{}
+			require.NoError(t, db.UpdateDisbursement(t.Context(), d.ID, d))
+		}
+	}
+	require.Equal(t, null.FloatFrom(2), prices()[old.TxHash])
+}
+
 func Test_GrantDB_FundsUsageOperations(t *testing.T) {
 	var (
 		db    = GetTestGrantDB(t)
@@ -0,0 +1,219 @@
+package db
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/numbergroup/errors"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/config"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+type LabelDB interface {
+	GetLabelSources(ctx context.Context) ([]types.LabelSource, error)
+	GetLabelSource(ctx context.Context, name string) (*types.LabelSource, error)
+	GetLabelsBySource(ctx context.Context, source string) ([]types.TransferPartyLabel, error)
+	GetLabelsByAddress(ctx context.Context, address string) ([]types.TransferPartyLabel, error)
+	// ReplaceSourceLabels swaps all of the labels in an import source for the given ones, creating the source
+	// if it doesn't exist. The priority is only changed when it is set.
+	ReplaceSourceLabels(ctx context.Context, source string, priority null.Int, labels []types.TransferPartyLabel) error
+	UpdateLabelSourcePriority(ctx context.Context, source string, priority int) error
+	DeleteLabelSource(ctx context.Context, source string) error
+}
+
+type label struct {
+	log                logrus.Ext1FieldLogger
+	dbConn             *sqlx.DB
+	getLabelSources    *sqlx.Stmt
+	getLabelSource     *sqlx.Stmt
+	getLabelsBySource  *sqlx.Stmt
+	getLabelsByAddress *sqlx.Stmt
+	updatePriority     *sqlx.Stmt
+	deleteLabelSource  *sqlx.Stmt
+}
+
+func NewLabelDB(ctx context.Context, conf *config.Config, dbConn *sqlx.DB) (LabelDB, error) {
+	labelSourcesQuery := `
+		SELECT s.name, s.priority, s.created_at, s.updated_at, COUNT(l.address) AS label_count
+		FROM label_sources s
+			LEFT JOIN party_labels l ON (l.source = s.name)`
+
+	getLabelSources, err := dbConn.PreparexContext(ctx, labelSourcesQuery+`
+		GROUP BY s.name ORDER BY s.priority, s.name`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetLabelSources statement")
+	}
+
+	getLabelSource, err := dbConn.PreparexContext(ctx, labelSourcesQuery+`
+		WHERE s.name = $1 GROUP BY s.name`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetLabelSource statement")
+	}
+
+	getLabelsBySource, err := dbConn.PreparexContext(ctx, `
+		SELECT address, source, label, created_at FROM party_labels WHERE source = $1 ORDER BY address`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetLabelsBySource statement")
+	}
+
+	getLabelsByAddress, err := dbConn.PreparexContext(ctx, `
+		SELECT l.address, l.source, l.label, l.created_at
+		FROM party_labels l
+			JOIN label_sources s ON (l.source = s.name)
+		WHERE l.address = $1
+		ORDER BY s.priority, l.source`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetLabelsByAddress statement")
+	}
+
+	updatePriority, err := dbConn.PreparexContext(ctx, `
+		UPDATE label_sources SET priority = $2, updated_at = NOW() WHERE name = $1`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare UpdateLabelSourcePriority statement")
+	}
+
+	deleteLabelSource, err := dbConn.PreparexContext(ctx, `DELETE FROM label_sources WHERE name = $1`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare DeleteLabelSource statement")
+	}
+
+	return &label{
+		log:                conf.GetLogger(),
+		dbConn:             dbConn,
+		getLabelSources:    getLabelSources,
+		getLabelSource:     getLabelSource,
+		getLabelsBySource:  getLabelsBySource,
+		getLabelsByAddress: getLabelsByAddress,
+		updatePriority:     updatePriority,
+		deleteLabelSource:  deleteLabelSource,
+	}, nil
+}
+
+func (l *label) GetLabelSources(ctx context.Context) ([]types.LabelSource, error) {
+	var sources []types.LabelSource
+	err := l.getLabelSources.SelectContext(ctx, &sources)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get label sources")
+	}
+	if len(sources) == 0 {
+		return []types.LabelSource{}, nil
+	}
+	return sources, nil
+}
+
+func (l *label) GetLabelSource(ctx context.Context, name string) (*types.LabelSource, error) {
+	var source types.LabelSource
+	err := l.getLabelSource.GetContext(ctx, &source, name)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get label source")
+	}
+	return &source, nil
+}
+
+func (l *label) GetLabelsBySource(ctx context.Context, source string) ([]types.TransferPartyLabel, error) {
+	var labels []types.TransferPartyLabel
+	err := l.getLabelsBySource.SelectContext(ctx, &labels, source)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get labels by source")
+	}
+	if len(labels) == 0 {
+		return []types.TransferPartyLabel{}, nil
+	}
+	return labels, nil
+}
+
+func (l *label) GetLabelsByAddress(ctx context.Context, address string) ([]types.TransferPartyLabel, error) {
+	var labels []types.TransferPartyLabel
+	err := l.getLabelsByAddress.SelectContext(ctx, &labels, address)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get labels by address")
+	}
+	if len(labels) == 0 {
+		return []types.TransferPartyLabel{}, nil
+	}
+	return labels, nil
+}
+
+func (l *label) ReplaceSourceLabels(ctx context.Context, source string, priority null.Int, labels []types.TransferPartyLabel) error {
+	if source == types.LabelSourceManual || source == types.LabelSourceENS {
+		return errors.Errorf("labels from the %s source can't be replaced", source)
+	}
+
+	tx, err := l.dbConn.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO label_sources (name, priority) VALUES ($1, COALESCE($2, 100))
+		ON CONFLICT (name) DO UPDATE SET
+			priority = COALESCE($2, label_sources.priority),
+			updated_at = NOW()`, source, priority)
+	if err != nil {
+		return errors.Wrap(err, "failed to upsert label source")
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM transfer_party_labels WHERE source = $1", source)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete existing labels")
+	}
+
+	for _, label := range labels {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO transfer_party_labels (address, source, label) VALUES ($1, $2, $3)`,
+			label.Address, source, label.Label)
+		if err != nil {
+			return errors.Wrapf(err, "failed to insert label for %s", label.Address)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, "failed to commit transaction")
+	}
+	return nil
+}
+
+func (l *label) UpdateLabelSourcePriority(ctx context.Context, source string, priority int) error {
+	result, err := l.updatePriority.ExecContext(ctx, source, priority)
+	if err != nil {
+		return errors.Wrap(err, "failed to update label source priority")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to get rows affected")
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("label source not found")
+	}
+
+	return nil
+}
+
+// DeleteLabelSource removes an import source along with all of its labels
+func (l *label) DeleteLabelSource(ctx context.Context, source string) error {
+	if source == types.LabelSourceManual || source == types.LabelSourceENS {
+		return errors.Errorf("the %s label source can't be deleted", source)
+	}
+	result, err := l.deleteLabelSource.ExecContext(ctx, source)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete label source")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to get rows affected")
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("label source not found")
+	}
+
+	return nil
+}
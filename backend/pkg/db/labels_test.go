@@ -0,0 +1,103 @@
+//go:build integration
+// +build integration
+
+package db
+
+import (
+	"testing"
+
+	"github.com/ETHCF/ethutils"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+func GetTestLabelDB(t *testing.T) LabelDB {
+	ldb, err := NewLabelDB(t.Context(), conf, dbConn)
+	require.NoError(t, err)
+	return ldb
+}
+
+func Test_LabelDB_ImportAndRollback(t *testing.T) {
+	var (
+		db       = GetTestLabelDB(t)
+		treasury = GetTestTreasuryDB(t)
+		source   = "import:test-" + ethutils.GenRandEVMAddr()[2:10]
+		address  = ethutils.GenRandEVMAddr()
+		other    = ethutils.GenRandEVMAddr()
+		labels   = []types.TransferPartyLabel{
+			{Address: address, Label: "Test Exchange"},
+			{Address: other, Label: "Test Bridge"},
+		}
+	)
+
+	// Import creates the source with the given priority
+	err := db.ReplaceSourceLabels(t.Context(), source, null.IntFrom(50), labels)
+	require.NoError(t, err)
+
+	retrieved, err := db.GetLabelSource(t.Context(), source)
+	require.NoError(t, err)
+	require.Equal(t, 50, retrieved.Priority)
+	require.Equal(t, 2, retrieved.LabelCount)
+
+	sources, err := db.GetLabelSources(t.Context())
+	require.NoError(t, err)
+	var found bool
+	for _, s := range sources {
+		if s.Name == source {
+			found = true
+		}
+	}
+	require.True(t, found, "imported label source not found in label sources")
+
+	// Importing again replaces the labels and keeps the priority
+	err = db.ReplaceSourceLabels(t.Context(), source, null.Int{}, labels[:1])
+	require.NoError(t, err)
+
+	sourceLabels, err := db.GetLabelsBySource(t.Context(), source)
+	require.NoError(t, err)
+	require.Len(t, sourceLabels, 1)
+	require.Equal(t, address, sourceLabels[0].Address)
+	require.Equal(t, "Test Exchange", sourceLabels[0].Label)
+
+	retrieved, err = db.GetLabelSource(t.Context(), source)
+	require.NoError(t, err)
+	require.Equal(t, 50, retrieved.Priority)
+
+	// The manual name outranks the import, which outranks ENS
+	err = treasury.SetTransferPartyENSName(t.Context(), address, null.StringFrom("exchange.eth"))
+	require.NoError(t, err)
+	err = treasury.UpsertTransferParty(t.Context(), types.TransferParty{Address: address, Name: "Manual Name"})
+	require.NoError(t, err)
+
+	addressLabels, err := db.GetLabelsByAddress(t.Context(), address)
+	require.NoError(t, err)
+	require.Len(t, addressLabels, 3)
+	require.Equal(t, types.LabelSourceManual, addressLabels[0].Source)
+	require.Equal(t, source, addressLabels[1].Source)
+	require.Equal(t, types.LabelSourceENS, addressLabels[2].Source)
+
+	err = db.UpdateLabelSourcePriority(t.Context(), source, -1)
+	require.NoError(t, err)
+	addressLabels, err = db.GetLabelsByAddress(t.Context(), address)
+	require.NoError(t, err)
+	require.Equal(t, source, addressLabels[0].Source)
+
+	// Built in sources can't be removed
+	err = db.DeleteLabelSource(t.Context(), types.LabelSourceManual)
+	require.Error(t, err)
+
+	// Rolling back the source removes all of its labels
+	err = db.DeleteLabelSource(t.Context(), source)
+	require.NoError(t, err)
+	sourceLabels, err = db.GetLabelsBySource(t.Context(), source)
+	require.NoError(t, err)
+	require.Empty(t, sourceLabels)
+	err = db.DeleteLabelSource(t.Context(), source)
+	require.Error(t, err)
+
+	// Clean up
+	_, err = dbConn.ExecContext(t.Context(), "DELETE FROM transfer_parties WHERE address = $1", address)
+	require.NoError(t, err)
+}
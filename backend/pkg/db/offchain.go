@@ -0,0 +1,400 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/innodv/psql"
+	"github.com/jmoiron/sqlx"
+	"github.com/numbergroup/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/config"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+type OffchainDB interface {
+	// Account methods
+	CreateAccount(ctx context.Context, account types.OffchainAccount) error
+	// GetAccounts returns every account along with its latest statement
+	GetAccounts(ctx context.Context) ([]types.OffchainAccount, error)
+	GetAccountByID(ctx context.Context, id uuid.UUID) (*types.OffchainAccount, error)
+	UpdateAccount(ctx context.Context, account types.OffchainAccount) error
+	DeleteAccount(ctx context.Context, id uuid.UUID) error
+
+	// Statement methods
+	CreateStatement(ctx context.Context, statement types.OffchainStatement) error
+	// GetStatements returns the statements of an account with their documents, newest first. The private documents
+	// are only included when asked for.
+	GetStatements(ctx context.Context, accountID uuid.UUID, includePrivate bool) ([]types.OffchainStatement, error)
+	GetStatementByID(ctx context.Context, id uuid.UUID) (*types.OffchainStatement, error)
+	DeleteStatement(ctx context.Context, id uuid.UUID) error
+
+	// Document methods
+	CreateDocument(ctx context.Context, document types.OffchainDocument) error
+	GetDocument(ctx context.Context, id uuid.UUID) (*types.OffchainDocument, error)
+	SetDocumentVisibility(ctx context.Context, id uuid.UUID, visibility types.DocumentVisibility) error
+	// DeleteDocument deletes the record of a document and returns it, so its file can be removed from storage
+	DeleteDocument(ctx context.Context, id uuid.UUID) (*types.OffchainDocument, error)
+}
+
+type offchain struct {
+	log                     logrus.Ext1FieldLogger
+	dbConn                  *sqlx.DB
+	createAccount           *sqlx.NamedStmt
+	getAccounts             *sqlx.Stmt
+	getAccountByID          *sqlx.Stmt
+	updateAccount           *sqlx.NamedStmt
+	deleteAccount           *sqlx.Stmt
+	getLatestStatements     *sqlx.Stmt
+	createStatement         *sqlx.NamedStmt
+	getStatements           *sqlx.Stmt
+	getStatementByID        *sqlx.Stmt
+	deleteStatement         *sqlx.Stmt
+	createDocument          *sqlx.NamedStmt
+	getDocumentsByAccount   *sqlx.Stmt
+	getDocumentsByStatement *sqlx.Stmt
+	getDocument             *sqlx.Stmt
+	setDocumentVisibility   *sqlx.Stmt
+	deleteDocument          *sqlx.Stmt
+}
+
+func NewOffchainDB(ctx context.Context, conf *config.Config, dbConn *sqlx.DB) (OffchainDB, error) {
+	accountCols := psql.GetSQLColumnsQuoted[types.OffchainAccount]()
+	accountColsNoQuote := psql.GetSQLColumns[types.OffchainAccount]()
+	statementCols := psql.GetSQLColumnsQuoted[types.OffchainStatement]()
+	statementColsNoQuote := psql.GetSQLColumns[types.OffchainStatement]()
+	documentCols := psql.GetSQLColumnsQuoted[types.OffchainDocument]()
+	documentColsNoQuote := psql.GetSQLColumns[types.OffchainDocument]()
+
+	// Account queries
+	createAccount, err := dbConn.PrepareNamedContext(ctx, fmt.Sprintf(`
+		INSERT INTO offchain_accounts (%s) VALUES (%s)`,
+		strings.Join(accountCols, ", "), ":"+strings.Join(accountColsNoQuote, ", :")))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare CreateAccount statement")
+	}
+
+	getAccounts, err := dbConn.PreparexContext(ctx, fmt.Sprintf(`
+		SELECT %s FROM offchain_accounts ORDER BY name`, strings.Join(accountCols, ", ")))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetAccounts statement")
+	}
+
+	getAccountByID, err := dbConn.PreparexContext(ctx, fmt.Sprintf(`
+		SELECT %s FROM offchain_accounts WHERE id = $1`, strings.Join(accountCols, ", ")))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetAccountByID statement")
+	}
+
+	updateAccount, err := dbConn.PrepareNamedContext(ctx, `
+		UPDATE offchain_accounts SET name = :name, institution = :institution, currency = :currency, notes = :notes, updated_at = NOW()
+		WHERE id = :id`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare UpdateAccount statement")
+	}
+
+	deleteAccount, err := dbConn.PreparexContext(ctx, `DELETE FROM offchain_accounts WHERE id = $1`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare DeleteAccount statement")
+	}
+
+	// Statement queries
+	getLatestStatements, err := dbConn.PreparexContext(ctx, fmt.Sprintf(`
+		SELECT DISTINCT ON (account_id) %s FROM offchain_statements
+		ORDER BY account_id, statement_date DESC`, strings.Join(statementCols, ", ")))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetLatestStatements statement")
+	}
+
+	createStatement, err := dbConn.PrepareNamedContext(ctx, fmt.Sprintf(`
+		INSERT INTO offchain_statements (%s) VALUES (%s)`,
+		strings.Join(statementCols, ", "), ":"+strings.Join(statementColsNoQuote, ", :")))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare CreateStatement statement")
+	}
+
+	getStatements, err := dbConn.PreparexContext(ctx, fmt.Sprintf(`
+		SELECT %s FROM offchain_statements WHERE account_id = $1
+		ORDER BY statement_date DESC`, strings.Join(statementCols, ", ")))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetStatements statement")
+	}
+
+	getStatementByID, err := dbConn.PreparexContext(ctx, fmt.Sprintf(`
+		SELECT %s FROM offchain_statements WHERE id = $1`, strings.Join(statementCols, ", ")))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetStatementByID statement")
+	}
+
+	deleteStatement, err := dbConn.PreparexContext(ctx, `DELETE FROM offchain_statements WHERE id = $1`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare DeleteStatement statement")
+	}
+
+	// Document queries
+	createDocument, err := dbConn.PrepareNamedContext(ctx, fmt.Sprintf(`
+		INSERT INTO offchain_documents (%s) VALUES (%s)`,
+		strings.Join(documentCols, ", "), ":"+strings.Join(documentColsNoQuote, ", :")))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare CreateDocument statement")
+	}
+
+	quotedDocumentCols := make([]string, len(documentCols))
+	for i, col := range documentCols {
+		quotedDocumentCols[i] = "d." + col
+	}
+	getDocumentsByAccount, err := dbConn.PreparexContext(ctx, fmt.Sprintf(`
+		SELECT %s FROM offchain_documents d
+			JOIN offchain_statements s ON (d.statement_id = s.id)
+		WHERE s.account_id = $1 AND ($2 OR d.visibility = 'public')
+		ORDER BY d.created_at`, strings.Join(quotedDocumentCols, ", ")))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetDocumentsByAccount statement")
+	}
+
+	getDocumentsByStatement, err := dbConn.PreparexContext(ctx, fmt.Sprintf(`
+		SELECT %s FROM offchain_documents WHERE statement_id = $1 ORDER BY created_at`, strings.Join(documentCols, ", ")))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetDocumentsByStatement statement")
+	}
+
+	getDocument, err := dbConn.PreparexContext(ctx, fmt.Sprintf(`
+		SELECT %s FROM offchain_documents WHERE id = $1`, strings.Join(documentCols, ", ")))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetDocument statement")
+	}
+
+	setDocumentVisibility, err := dbConn.PreparexContext(ctx, `UPDATE offchain_documents SET visibility = $2 WHERE id = $1`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare SetDocumentVisibility statement")
+	}
+
+	deleteDocument, err := dbConn.PreparexContext(ctx, fmt.Sprintf(`
+		DELETE FROM offchain_documents WHERE id = $1 RETURNING %s`, strings.Join(documentCols, ", ")))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare DeleteDocument statement")
+	}
+
+	return &offchain{
+		log:                     conf.GetLogger(),
+		dbConn:                  dbConn,
+		createAccount:           createAccount,
+		getAccounts:             getAccounts,
+		getAccountByID:          getAccountByID,
+		updateAccount:           updateAccount,
+		deleteAccount:           deleteAccount,
+		getLatestStatements:     getLatestStatements,
+		createStatement:         createStatement,
+		getStatements:           getStatements,
+		getStatementByID:        getStatementByID,
+		deleteStatement:         deleteStatement,
+		createDocument:          createDocument,
+		getDocumentsByAccount:   getDocumentsByAccount,
+		getDocumentsByStatement: getDocumentsByStatement,
+		getDocument:             getDocument,
+		setDocumentVisibility:   setDocumentVisibility,
+		deleteDocument:          deleteDocument,
+	}, nil
+}
+
+func (o *offchain) CreateAccount(ctx context.Context, account types.OffchainAccount) error {
+	_, err := o.createAccount.ExecContext(ctx, account)
+	if err != nil {
+		return errors.Wrap(err, "failed to create off-chain account")
+	}
+	return nil
+}
+
+func (o *offchain) GetAccounts(ctx context.Context) ([]types.OffchainAccount, error) {
+	var accounts []types.OffchainAccount
+	err := o.getAccounts.SelectContext(ctx, &accounts)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get off-chain accounts")
+	}
+	if len(accounts) == 0 {
+		return []types.OffchainAccount{}, nil
+	}
+
+	var statements []types.OffchainStatement
+	err = o.getLatestStatements.SelectContext(ctx, &statements)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get latest off-chain statements")
+	}
+	latest := make(map[uuid.UUID]*types.OffchainStatement, len(statements))
+	for i := range statements {
+		normalizeStatement(&statements[i])
+		latest[statements[i].AccountID] = &statements[i]
+	}
+	for i := range accounts {
+		accounts[i].LatestStatement = latest[accounts[i].ID]
+	}
+	return accounts, nil
+}
+
+func (o *offchain) GetAccountByID(ctx context.Context, id uuid.UUID) (*types.OffchainAccount, error) {
+	var account types.OffchainAccount
+	err := o.getAccountByID.GetContext(ctx, &account, id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get off-chain account (%s)", id)
+	}
+	return &account, nil
+}
+
+func (o *offchain) UpdateAccount(ctx context.Context, account types.OffchainAccount) error {
+	result, err := o.updateAccount.ExecContext(ctx, account)
+	if err != nil {
+		return errors.Wrap(err, "failed to update off-chain account")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to get rows affected")
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("off-chain account not found")
+	}
+
+	return nil
+}
+
+func (o *offchain) DeleteAccount(ctx context.Context, id uuid.UUID) error {
+	result, err := o.deleteAccount.ExecContext(ctx, id)
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete off-chain account (%s)", id)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to get rows affected")
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("off-chain account not found")
+	}
+
+	return nil
+}
+
+func (o *offchain) CreateStatement(ctx context.Context, statement types.OffchainStatement) error {
+	_, err := o.createStatement.ExecContext(ctx, statement)
+	if err != nil {
+		return errors.Wrap(err, "failed to create off-chain statement")
+	}
+	return nil
+}
+
+func (o *offchain) GetStatements(ctx context.Context, accountID uuid.UUID, includePrivate bool) ([]types.OffchainStatement, error) {
+	var statements []types.OffchainStatement
+	err := o.getStatements.SelectContext(ctx, &statements, accountID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get off-chain statements (%s)", accountID)
+	}
+	if len(statements) == 0 {
+		return []types.OffchainStatement{}, nil
+	}
+
+	var documents []types.OffchainDocument
+	err = o.getDocumentsByAccount.SelectContext(ctx, &documents, accountID, includePrivate)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get off-chain documents (%s)", accountID)
+	}
+	byStatement := make(map[uuid.UUID][]types.OffchainDocument)
+	for _, document := range documents {
+		byStatement[document.StatementID] = append(byStatement[document.StatementID], document)
+	}
+	for i := range statements {
+		normalizeStatement(&statements[i])
+		statements[i].Documents = byStatement[statements[i].ID]
+	}
+	return statements, nil
+}
+
+func (o *offchain) GetStatementByID(ctx context.Context, id uuid.UUID) (*types.OffchainStatement, error) {
+	var statement types.OffchainStatement
+	err := o.getStatementByID.GetContext(ctx, &statement, id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get off-chain statement (%s)", id)
+	}
+
+	err = o.getDocumentsByStatement.SelectContext(ctx, &statement.Documents, id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get off-chain documents of statement (%s)", id)
+	}
+	normalizeStatement(&statement)
+	return &statement, nil
+}
+
+func (o *offchain) DeleteStatement(ctx context.Context, id uuid.UUID) error {
+	result, err := o.deleteStatement.ExecContext(ctx, id)
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete off-chain statement (%s)", id)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to get rows affected")
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("off-chain statement not found")
+	}
+
+	return nil
+}
+
+func (o *offchain) CreateDocument(ctx context.Context, document types.OffchainDocument) error {
+	_, err := o.createDocument.ExecContext(ctx, document)
+	if err != nil {
+		return errors.Wrap(err, "failed to create off-chain document")
+	}
+	return nil
+}
+
+func (o *offchain) GetDocument(ctx context.Context, id uuid.UUID) (*types.OffchainDocument, error) {
+	var document types.OffchainDocument
+	err := o.getDocument.GetContext(ctx, &document, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("off-chain document not found")
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get off-chain document (%s)", id)
+	}
+	return &document, nil
+}
+
+func (o *offchain) SetDocumentVisibility(ctx context.Context, id uuid.UUID, visibility types.DocumentVisibility) error {
+	result, err := o.setDocumentVisibility.ExecContext(ctx, id, visibility)
+	if err != nil {
+		return errors.Wrapf(err, "failed to set off-chain document visibility (%s)", id)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to get rows affected")
+	}
+	if rowsAffected == 0 {
+		return errors.New("off-chain document not found")
+	}
+	return nil
+}
+
+func (o *offchain) DeleteDocument(ctx context.Context, id uuid.UUID) (*types.OffchainDocument, error) {
+	var document types.OffchainDocument
+	err := o.deleteDocument.GetContext(ctx, &document, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("off-chain document not found")
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to delete off-chain document (%s)", id)
+	}
+	return &document, nil
+}
+
+func normalizeStatement(statement *types.OffchainStatement) {
+	statement.Balance = TrimZeros(statement.Balance)
+	statement.SelfReported = true
+}
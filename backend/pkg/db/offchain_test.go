@@ -0,0 +1,155 @@
+//go:build integration
+// +build integration
+
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ETHCF/ethutils"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+func GetTestOffchainDB(t *testing.T) OffchainDB {
+	odb, err := NewOffchainDB(t.Context(), conf, dbConn)
+	require.NoError(t, err)
+	return odb
+}
+
+func Test_OffchainDB_Statements(t *testing.T) {
+	var (
+		db       = GetTestOffchainDB(t)
+		treasury = GetTestTreasuryDB(t)
+		admin    = ethutils.GenRandEVMAddr()
+		account  = types.OffchainAccount{
+			ID:          uuid.New(),
+			Name:        "Operating Account",
+			Institution: "Test Bank",
+			Currency:    "EUR",
+			Notes:       null.StringFrom("Test account"),
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+		}
+		older = types.OffchainStatement{
+			ID:            uuid.New(),
+			AccountID:     account.ID,
+			StatementDate: time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC),
+			Balance:       "1000.5",
+			UsdValue:      1050.25,
+			CreatedBy:     admin,
+			CreatedAt:     time.Now(),
+		}
+		newer = types.OffchainStatement{
+			ID:            uuid.New(),
+			AccountID:     account.ID,
+			StatementDate: time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC),
+			Balance:       "2000",
+			UsdValue:      2100,
+			CreatedBy:     admin,
+			CreatedAt:     time.Now(),
+		}
+	)
+
+	err := db.CreateAccount(t.Context(), account)
+	require.NoError(t, err)
+	err = db.CreateStatement(t.Context(), older)
+	require.NoError(t, err)
+	err = db.CreateStatement(t.Context(), newer)
+	require.NoError(t, err)
+
+	// Only one statement per date
+	duplicate := newer
+	duplicate.ID = uuid.New()
+	err = db.CreateStatement(t.Context(), duplicate)
+	require.Error(t, err)
+
+	document := types.OffchainDocument{
+		ID:          uuid.New(),
+		StatementID: older.ID,
+		Name:        "January statement",
+		FileName:    null.StringFrom("january.pdf"),
+		FileSize:    1024,
+		MimeType:    null.StringFrom("application/pdf"),
+		StorageID:   uuid.New().String(),
+		Visibility:  types.DocumentPrivate,
+		CreatedAt:   time.Now(),
+	}
+	err = db.CreateDocument(t.Context(), document)
+	require.NoError(t, err)
+	retrievedDocument, err := db.GetDocument(t.Context(), document.ID)
+	require.NoError(t, err)
+	require.Equal(t, document.StorageID, retrievedDocument.StorageID)
+
+	// The account is listed with its latest statement
+	accounts, err := db.GetAccounts(t.Context())
+	require.NoError(t, err)
+	var found *types.OffchainAccount
+	for i := range accounts {
+		if accounts[i].ID == account.ID {
+			found = &accounts[i]
+		}
+	}
+	require.NotNil(t, found)
+	require.NotNil(t, found.LatestStatement)
+	require.Equal(t, newer.ID, found.LatestStatement.ID)
+	require.True(t, found.LatestStatement.SelfReported)
+
+	statements, err := db.GetStatements(t.Context(), account.ID, true)
+	require.NoError(t, err)
+	require.Len(t, statements, 2)
+	require.Equal(t, newer.ID, statements[0].ID)
+	require.Equal(t, "2000", statements[0].Balance)
+	require.Empty(t, statements[0].Documents)
+	require.Equal(t, "1000.5", statements[1].Balance)
+	require.Len(t, statements[1].Documents, 1)
+	require.Equal(t, document.ID, statements[1].Documents[0].ID)
+
+	// Private documents are left out unless asked for
+	statements, err = db.GetStatements(t.Context(), account.ID, false)
+	require.NoError(t, err)
+	require.Len(t, statements, 2)
+	require.Empty(t, statements[1].Documents)
+
+	err = db.SetDocumentVisibility(t.Context(), document.ID, types.DocumentPublic)
+	require.NoError(t, err)
+	statements, err = db.GetStatements(t.Context(), account.ID, false)
+	require.NoError(t, err)
+	require.Len(t, statements[1].Documents, 1)
+	require.Equal(t, types.DocumentPublic, statements[1].Documents[0].Visibility)
+	err = db.SetDocumentVisibility(t.Context(), uuid.New(), types.DocumentPublic)
+	require.ErrorContains(t, err, "not found")
+
+	// The latest statement is counted in the treasury
+	balances, err := treasury.GetOffchainBalances(t.Context())
+	require.NoError(t, err)
+	var balance *types.OffchainBalance
+	for i := range balances {
+		if balances[i].AccountID == account.ID {
+			balance = &balances[i]
+		}
+	}
+	require.NotNil(t, balance)
+	require.Equal(t, "2000", balance.Balance)
+	require.Equal(t, 2100.0, balance.UsdValue)
+	require.True(t, balance.SelfReported)
+
+	account.Name = "Reserve Account"
+	err = db.UpdateAccount(t.Context(), account)
+	require.NoError(t, err)
+	retrieved, err := db.GetAccountByID(t.Context(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, "Reserve Account", retrieved.Name)
+
+	// Deleting the account removes its statements and documents
+	err = db.DeleteAccount(t.Context(), account.ID)
+	require.NoError(t, err)
+	_, err = db.GetStatementByID(t.Context(), older.ID)
+	require.Error(t, err)
+	_, err = db.DeleteDocument(t.Context(), document.ID)
+	require.ErrorContains(t, err, "not found")
+}
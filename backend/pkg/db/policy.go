@@ -0,0 +1,287 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/innodv/psql"
+	"github.com/jmoiron/sqlx"
+	"github.com/numbergroup/errors"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/config"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+type PolicyDB interface {
+	// Rule methods
+	GetRules(ctx context.Context) ([]types.PolicyRule, error)
+	GetRuleByID(ctx context.Context, id uuid.UUID) (*types.PolicyRule, error)
+	CreateRule(ctx context.Context, rule types.PolicyRule) error
+	// UpdateRule replaces the definition of a rule. Disabling a rule, or changing what it measures, resolves its open violation.
+	UpdateRule(ctx context.Context, rule types.PolicyRule) error
+	// DeleteRule deletes a rule, keeping its violations
+	DeleteRule(ctx context.Context, id uuid.UUID) error
+
+	// RecordEvaluation stores the result of evaluating a rule, opening a violation when it starts being broken,
+	// updating the open violation while it stays broken and resolving it once the rule passes again
+	RecordEvaluation(ctx context.Context, rule types.PolicyRule, value null.Float, violated bool, at time.Time) error
+	// GetViolations returns violations, newest first
+	GetViolations(ctx context.Context, openOnly bool, limit, offset int) ([]types.PolicyViolation, error)
+
+	// GetOutflowSummary sums the priced outgoing transfers to addresses outside the treasury since the given time
+	GetOutflowSummary(ctx context.Context, since time.Time) (types.OutflowSummary, error)
+}
+
+type policy struct {
+	log               logrus.Ext1FieldLogger
+	dbConn            *sqlx.DB
+	getRules          *sqlx.Stmt
+	getRuleByID       *sqlx.Stmt
+	createRule        *sqlx.NamedStmt
+	deleteRule        *sqlx.Stmt
+	getViolations     *sqlx.Stmt
+	getOutflowSummary *sqlx.Stmt
+}
+
+func NewPolicyDB(ctx context.Context, conf *config.Config, dbConn *sqlx.DB) (PolicyDB, error) {
+	ruleCols := psql.GetSQLColumnsQuoted[types.PolicyRule]()
+	violationCols := psql.GetSQLColumnsQuoted[types.PolicyViolation]()
+
+	getRules, err := dbConn.PreparexContext(ctx, fmt.Sprintf(`
+		SELECT %s FROM policy_rules ORDER BY created_at`, strings.Join(ruleCols, ", ")))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetRules statement")
+	}
+
+	getRuleByID, err := dbConn.PreparexContext(ctx, fmt.Sprintf(`
+		SELECT %s FROM policy_rules WHERE id = $1`, strings.Join(ruleCols, ", ")))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetRuleByID statement")
+	}
+
+	createRule, err := dbConn.PrepareNamedContext(ctx, `
+		INSERT INTO policy_rules (id, name, description, metric, comparison, threshold, asset_class, asset, window_days, enabled, created_by, created_at, updated_at)
+		VALUES (:id, :name, :description, :metric, :comparison, :threshold, :asset_class, :asset, :window_days, :enabled, :created_by, :created_at, :updated_at)`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare CreateRule statement")
+	}
+
+	// Violations are kept for the record, but can't stay open once their rule is gone
+	deleteRule, err := dbConn.PreparexContext(ctx, `
+		WITH resolved AS (
+			UPDATE policy_violations SET resolved_at = NOW() WHERE rule_id = $1 AND resolved_at IS NULL
+		)
+		DELETE FROM policy_rules WHERE id = $1`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare DeleteRule statement")
+	}
+
+	getViolations, err := dbConn.PreparexContext(ctx, fmt.Sprintf(`
+		SELECT %s FROM policy_violations WHERE NOT $1 OR resolved_at IS NULL
+		ORDER BY started_at DESC LIMIT $2 OFFSET $3`, strings.Join(violationCols, ", ")))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetViolations statement")
+	}
+
+	// Transfers between treasury wallets are not outflows, and spam or unpriced assets can't be valued
+	getOutflowSummary, err := dbConn.PreparexContext(ctx, `
+		SELECT COALESCE(SUM(o.usd), 0) AS total_usd,
+			COALESCE(MAX(o.usd), 0) AS largest_usd,
+			(ARRAY_AGG(o.id ORDER BY o.usd DESC))[1] AS largest_transfer_id,
+			COUNT(*) AS transfers
+		FROM (
+			SELECT t.id, (t.amount / POWER(10::NUMERIC, a.decimals) * ap.usd_price::NUMERIC)::DOUBLE PRECISION AS usd
+			FROM transfers t
+				JOIN assets a ON (t.chain_id = a.chain_id AND t.asset = a.address)
+				JOIN asset_prices ap ON (t.chain_id = ap.chain_id AND t.asset = ap.address)
+				LEFT JOIN asset_statuses st ON (t.chain_id = st.chain_id AND t.asset = st.address)
+			WHERE t.direction = 'outgoing'
+				AND t.block_timestamp >= $1
+				AND t.payee_address NOT IN (SELECT address FROM wallets)
+				AND (st.status IS NULL OR st.status <> 'spam')
+		) o`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetOutflowSummary statement")
+	}
+
+	return &policy{
+		log:               conf.GetLogger(),
+		dbConn:            dbConn,
+		getRules:          getRules,
+		getRuleByID:       getRuleByID,
+		createRule:        createRule,
+		deleteRule:        deleteRule,
+		getViolations:     getViolations,
+		getOutflowSummary: getOutflowSummary,
+	}, nil
+}
+
+func (p *policy) GetRules(ctx context.Context) ([]types.PolicyRule, error) {
+	var rules []types.PolicyRule
+	err := p.getRules.SelectContext(ctx, &rules)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get policy rules")
+	}
+	if len(rules) == 0 {
+		return []types.PolicyRule{}, nil
+	}
+	return rules, nil
+}
+
+func (p *policy) GetRuleByID(ctx context.Context, id uuid.UUID) (*types.PolicyRule, error) {
+	var rule types.PolicyRule
+	err := p.getRuleByID.GetContext(ctx, &rule, id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get policy rule (%s)", id)
+	}
+	return &rule, nil
+}
+
+func (p *policy) CreateRule(ctx context.Context, rule types.PolicyRule) error {
+	_, err := p.createRule.ExecContext(ctx, rule)
+	if err != nil {
+		return errors.Wrap(err, "failed to create policy rule")
+	}
+	return nil
+}
+
+func (p *policy) UpdateRule(ctx context.Context, rule types.PolicyRule) error {
+	tx, err := p.dbConn.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	var previous types.PolicyRule
+	err = tx.GetContext(ctx, &previous, `
+		SELECT metric, comparison, asset_class, asset, violated FROM policy_rules WHERE id = $1 FOR UPDATE`, rule.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errors.New("policy rule not found")
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to get policy rule")
+	}
+
+	// The open violation tracks values of the old definition, which can't be compared to the new one
+	redefined := rule.Metric != previous.Metric || rule.Comparison != previous.Comparison ||
+		rule.AssetClass != previous.AssetClass || rule.Asset != previous.Asset
+	rule.Violated = previous.Violated && rule.Enabled && !redefined
+
+	_, err = tx.NamedExecContext(ctx, `
+		UPDATE policy_rules SET name = :name, description = :description, metric = :metric, comparison = :comparison,
+			threshold = :threshold, asset_class = :asset_class, asset = :asset, window_days = :window_days,
+			enabled = :enabled, violated = :violated, updated_at = NOW()
+		WHERE id = :id`, rule)
+	if err != nil {
+		return errors.Wrap(err, "failed to update policy rule")
+	}
+
+	if !rule.Enabled || redefined {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE policy_violations SET resolved_at = NOW() WHERE rule_id = $1 AND resolved_at IS NULL`, rule.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to resolve open violation")
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, "failed to commit transaction")
+	}
+	return nil
+}
+
+func (p *policy) DeleteRule(ctx context.Context, id uuid.UUID) error {
+	result, err := p.deleteRule.ExecContext(ctx, id)
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete policy rule (%s)", id)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to get rows affected")
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("policy rule not found")
+	}
+
+	return nil
+}
+
+func (p *policy) RecordEvaluation(ctx context.Context, rule types.PolicyRule, value null.Float, violated bool, at time.Time) error {
+	if violated && !value.Valid {
+		return errors.New("a violation needs a value")
+	}
+
+	tx, err := p.dbConn.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE policy_rules SET violated = $2, last_value = $3, last_evaluated_at = $4 WHERE id = $1`,
+		rule.ID, violated, value, at)
+	if err != nil {
+		return errors.Wrap(err, "failed to update policy rule")
+	}
+
+	if violated {
+		// The worst value is the lowest seen for minimums and the highest for maximums
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO policy_violations (rule_id, rule_name, metric, comparison, threshold, first_value, worst_value, last_value, started_at, last_seen_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $6, $6, $7, $7)
+			ON CONFLICT (rule_id) WHERE resolved_at IS NULL DO UPDATE SET
+				rule_name = EXCLUDED.rule_name,
+				threshold = EXCLUDED.threshold,
+				last_value = EXCLUDED.last_value,
+				last_seen_at = EXCLUDED.last_seen_at,
+				worst_value = CASE WHEN policy_violations.comparison = 'min'
+					THEN LEAST(policy_violations.worst_value, EXCLUDED.last_value)
+					ELSE GREATEST(policy_violations.worst_value, EXCLUDED.last_value) END`,
+			rule.ID, rule.Name, rule.Metric, rule.Comparison, rule.Threshold, value.Float64, at)
+		if err != nil {
+			return errors.Wrap(err, "failed to record policy violation")
+		}
+	} else {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE policy_violations SET resolved_at = $2 WHERE rule_id = $1 AND resolved_at IS NULL`, rule.ID, at)
+		if err != nil {
+			return errors.Wrap(err, "failed to resolve policy violation")
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, "failed to commit transaction")
+	}
+	return nil
+}
+
+func (p *policy) GetViolations(ctx context.Context, openOnly bool, limit, offset int) ([]types.PolicyViolation, error) {
+	var violations []types.PolicyViolation
+	err := p.getViolations.SelectContext(ctx, &violations, openOnly, limit, offset)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get policy violations")
+	}
+	if len(violations) == 0 {
+		return []types.PolicyViolation{}, nil
+	}
+	return violations, nil
+}
+
+func (p *policy) GetOutflowSummary(ctx context.Context, since time.Time) (types.OutflowSummary, error) {
+	var summary types.OutflowSummary
+	err := p.getOutflowSummary.GetContext(ctx, &summary, since.Unix())
+	if err != nil {
+		return types.OutflowSummary{}, errors.Wrap(err, "failed to get outflow summary")
+	}
+	return summary, nil
+}
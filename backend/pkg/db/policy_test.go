@@ -0,0 +1,214 @@
+//go:build integration
+// +build integration
+
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ETHCF/ethutils"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+func GetTestPolicyDB(t *testing.T) PolicyDB {
+	pdb, err := NewPolicyDB(t.Context(), conf, dbConn)
+	require.NoError(t, err)
+	return pdb
+}
+
+func getOpenViolation(t *testing.T, db PolicyDB, ruleID uuid.UUID) *types.PolicyViolation {
+	t.Helper()
+	violations, err := db.GetViolations(t.Context(), true, 1000, 0)
+	require.NoError(t, err)
+	for _, violation := range violations {
+		if violation.RuleID.Valid && violation.RuleID.UUID == ruleID {
+			return &violation
+		}
+	}
+	return nil
+}
+
+func Test_PolicyDB_Rules(t *testing.T) {
+	var (
+		db   = GetTestPolicyDB(t)
+		rule = types.PolicyRule{
+			ID:         uuid.New(),
+			Name:       "Stablecoin runway",
+			Metric:     types.PolicyMetricRunwayMonths,
+			Comparison: types.PolicyComparisonMin,
+			Threshold:  12,
+			WindowDays: 90,
+			Enabled:    true,
+			CreatedBy:  ethutils.GenRandEVMAddr(),
+			CreatedAt:  time.Now(),
+			UpdatedAt:  time.Now(),
+		}
+		now = time.Now().UTC().Truncate(time.Second)
+	)
+
+	err := db.CreateRule(t.Context(), rule)
+	require.NoError(t, err)
+
+	// Breaking the rule opens a violation, which tracks the worst value until the rule passes
+	err = db.RecordEvaluation(t.Context(), rule, null.FloatFrom(8), true, now)
+	require.NoError(t, err)
+	err = db.RecordEvaluation(t.Context(), rule, null.FloatFrom(5), true, now.Add(time.Hour))
+	require.NoError(t, err)
+	err = db.RecordEvaluation(t.Context(), rule, null.FloatFrom(9), true, now.Add(2*time.Hour))
+	require.NoError(t, err)
+
+	violation := getOpenViolation(t, db, rule.ID)
+	require.NotNil(t, violation)
+	require.Equal(t, rule.Name, violation.RuleName)
+	require.Equal(t, 8.0, violation.FirstValue)
+	require.Equal(t, 5.0, violation.WorstValue)
+	require.Equal(t, 9.0, violation.LastValue)
+	require.True(t, violation.StartedAt.Equal(now))
+
+	found, err := db.GetRuleByID(t.Context(), rule.ID)
+	require.NoError(t, err)
+	require.True(t, found.Violated)
+	require.Equal(t, 9.0, found.LastValue.Float64)
+
+	err = db.RecordEvaluation(t.Context(), rule, null.FloatFrom(13), false, now.Add(3*time.Hour))
+	require.NoError(t, err)
+	require.Nil(t, getOpenViolation(t, db, rule.ID))
+
+	// Changing what a broken rule measures resolves its violation, the next one is tracked on the new definition
+	err = db.RecordEvaluation(t.Context(), rule, null.FloatFrom(4), true, now.Add(4*time.Hour))
+	require.NoError(t, err)
+	require.NotNil(t, getOpenViolation(t, db, rule.ID))
+
+	rule.Metric = types.PolicyMetricTotalValueUsd
+	rule.Comparison = types.PolicyComparisonMax
+	rule.Threshold = 1000
+	err = db.UpdateRule(t.Context(), rule)
+	require.NoError(t, err)
+	require.Nil(t, getOpenViolation(t, db, rule.ID))
+
+	found, err = db.GetRuleByID(t.Context(), rule.ID)
+	require.NoError(t, err)
+	require.False(t, found.Violated)
+
+	err = db.RecordEvaluation(t.Context(), rule, null.FloatFrom(2000), true, now.Add(5*time.Hour))
+	require.NoError(t, err)
+	violation = getOpenViolation(t, db, rule.ID)
+	require.NotNil(t, violation)
+	require.Equal(t, types.PolicyMetricTotalValueUsd, violation.Metric)
+	require.Equal(t, types.PolicyComparisonMax, violation.Comparison)
+	require.Equal(t, 2000.0, violation.FirstValue)
+	require.Equal(t, 2000.0, violation.WorstValue)
+
+	// Changing only the threshold keeps it open
+	rule.Threshold = 1500
+	err = db.UpdateRule(t.Context(), rule)
+	require.NoError(t, err)
+	require.NotNil(t, getOpenViolation(t, db, rule.ID))
+
+	// Disabling a broken rule resolves its violation
+
+	rule.Enabled = false
+	rule.Threshold = 6
+	err = db.UpdateRule(t.Context(), rule)
+	require.NoError(t, err)
+	require.Nil(t, getOpenViolation(t, db, rule.ID))
+
+	found, err = db.GetRuleByID(t.Context(), rule.ID)
+	require.NoError(t, err)
+	require.False(t, found.Enabled)
+	require.False(t, found.Violated)
+	require.Equal(t, 6.0, found.Threshold)
+
+	// Violations outlive their rule
+	err = db.RecordEvaluation(t.Context(), rule, null.FloatFrom(4), true, now.Add(6*time.Hour))
+	require.NoError(t, err)
+	err = db.DeleteRule(t.Context(), rule.ID)
+	require.NoError(t, err)
+	err = db.DeleteRule(t.Context(), rule.ID)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "not found")
+
+	var kept []types.PolicyViolation
+	err = dbConn.SelectContext(t.Context(), &kept,
+		"SELECT id, rule_id, rule_name, metric, comparison, threshold, first_value, worst_value, last_value, started_at, last_seen_at, resolved_at FROM policy_violations WHERE rule_name = $1 AND started_at >= $2", rule.Name, now)
+	require.NoError(t, err)
+	require.Len(t, kept, 4)
+	for _, violation := range kept {
+		require.False(t, violation.RuleID.Valid)
+		require.True(t, violation.ResolvedAt.Valid)
+	}
+
+	// Cleanup
+	_, err = dbConn.ExecContext(t.Context(), "DELETE FROM policy_violations WHERE rule_name = $1 AND started_at >= $2", rule.Name, now)
+	require.NoError(t, err)
+}
+
+func Test_PolicyDB_OutflowSummary(t *testing.T) {
+	var (
+		db       = GetTestPolicyDB(t)
+		treasury = GetTestTreasuryDB(t)
+		wallet   = ethutils.GenRandEVMAddr()
+		otherOne = ethutils.GenRandEVMAddr()
+		asset    = ethutils.GenRandEVMAddr()
+		// Far enough ahead that no other transfer falls in the window
+		since = time.Now().AddDate(50, 0, 0)
+	)
+
+	_, err := dbConn.ExecContext(t.Context(), "INSERT INTO wallets (address) VALUES ($1), ($2)", wallet, otherOne)
+	require.NoError(t, err)
+	_, err = dbConn.ExecContext(t.Context(),
+		"INSERT INTO assets (chain_id, address, name, symbol, decimals) VALUES (1, $1, 'Test', 'TST', 2)", asset)
+	require.NoError(t, err)
+	_, err = dbConn.ExecContext(t.Context(), "INSERT INTO asset_prices (chain_id, address, usd_price) VALUES (1, $1, 2)", asset)
+	require.NoError(t, err)
+
+	transfers := []types.CreateTransfer{
+		// 10.00 and 50.00 TST paid out, 20 and 100 USD
+		{ToAddress: ethutils.GenRandEVMAddr(), Amount: "1000", Direction: types.TransferTypeOutgoing},
+		{ToAddress: ethutils.GenRandEVMAddr(), Amount: "5000", Direction: types.TransferTypeOutgoing},
+		// Moves between treasury wallets and incoming transfers aren't outflows
+		{ToAddress: otherOne, Amount: "90000", Direction: types.TransferTypeOutgoing},
+		{ToAddress: wallet, Amount: "90000", Direction: types.TransferTypeIncoming},
+	}
+	for i := range transfers {
+		transfers[i].ChainID = 1
+		transfers[i].TxHash = ethutils.GenRandEVMHash()
+		transfers[i].BlockNumber = 1
+		transfers[i].BlockTimestamp = since.Add(time.Hour).Unix()
+		transfers[i].FromAddress = wallet
+		transfers[i].Asset = asset
+		if transfers[i].Direction == types.TransferTypeIncoming {
+			transfers[i].FromAddress = ethutils.GenRandEVMAddr()
+		}
+		err = treasury.CreateTransfer(t.Context(), transfers[i])
+		require.NoError(t, err)
+	}
+
+	summary, err := db.GetOutflowSummary(t.Context(), since)
+	require.NoError(t, err)
+	require.Equal(t, 2, summary.Transfers)
+	require.InDelta(t, 120.0, summary.TotalUsd, 0.0001)
+	require.InDelta(t, 100.0, summary.LargestUsd, 0.0001)
+	require.True(t, summary.LargestTransferID.Valid)
+
+	summary, err = db.GetOutflowSummary(t.Context(), since.Add(2*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 0, summary.Transfers)
+	require.Equal(t, 0.0, summary.TotalUsd)
+	require.False(t, summary.LargestTransferID.Valid)
+
+	// Cleanup
+	_, err = dbConn.ExecContext(t.Context(), "DELETE FROM transfers WHERE asset = $1", asset)
+	require.NoError(t, err)
+	_, err = dbConn.ExecContext(t.Context(), "DELETE FROM asset_prices WHERE address = $1", asset)
+	require.NoError(t, err)
+	_, err = dbConn.ExecContext(t.Context(), "DELETE FROM assets WHERE address = $1", asset)
+	require.NoError(t, err)
+	_, err = dbConn.ExecContext(t.Context(), "DELETE FROM wallets WHERE address IN ($1, $2)", wallet, otherOne)
+	require.NoError(t, err)
+}
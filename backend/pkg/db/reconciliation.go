@@ -0,0 +1,247 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/innodv/psql"
+	"github.com/jmoiron/sqlx"
+	"github.com/numbergroup/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/config"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/constants"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+type ReconciliationDB interface {
+	// GetTxHashesWithoutFees returns the transactions behind a wallet's outgoing transfers whose fee hasn't been read yet
+	GetTxHashesWithoutFees(ctx context.Context, chainID int64, wallet string) ([]string, error)
+	SaveTransactionFee(ctx context.Context, fee types.TransactionFee) error
+
+	GetStartingBalances(ctx context.Context) ([]types.StartingBalance, error)
+	SetStartingBalance(ctx context.Context, balance types.StartingBalance) error
+	DeleteStartingBalance(ctx context.Context, chainID int64, wallet, asset string) error
+
+	// GetExpectedBalances sums the starting balance, transfers and fees of each asset a wallet has held, up to the checkpoint block
+	GetExpectedBalances(ctx context.Context, chainID int64, wallet string, checkpoint uint64) ([]types.ExpectedBalance, error)
+	// ReplaceResults replaces the reconciliation results of a wallet
+	ReplaceResults(ctx context.Context, chainID int64, wallet string, results []types.ReconciliationResult) error
+	// GetResults returns the latest reconciliation results, by wallet and asset
+	GetResults(ctx context.Context) ([]types.ReconciliationResult, error)
+}
+
+type reconciliation struct {
+	log                   logrus.Ext1FieldLogger
+	dbConn                *sqlx.DB
+	getTxHashesWithoutFee *sqlx.Stmt
+	saveTransactionFee    *sqlx.NamedStmt
+	getStartingBalances   *sqlx.Stmt
+	setStartingBalance    *sqlx.NamedStmt
+	deleteStartingBalance *sqlx.Stmt
+	getExpectedBalances   *sqlx.Stmt
+	getResults            *sqlx.Stmt
+}
+
+func NewReconciliationDB(ctx context.Context, conf *config.Config, dbConn *sqlx.DB) (ReconciliationDB, error) {
+	getTxHashesWithoutFee, err := dbConn.PreparexContext(ctx, `
+		SELECT DISTINCT t.tx_hash FROM transfers t
+		WHERE t.chain_id = $1 AND t.payer_address = $2
+			AND NOT EXISTS (SELECT 1 FROM transaction_fees f WHERE f.chain_id = t.chain_id AND f.tx_hash = t.tx_hash)`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetTxHashesWithoutFees statement")
+	}
+
+	saveTransactionFee, err := dbConn.PrepareNamedContext(ctx, `
+		INSERT INTO transaction_fees (chain_id, tx_hash, payer, fee, block_number)
+		VALUES (:chain_id, :tx_hash, :payer, :fee, :block_number)
+		ON CONFLICT (chain_id, tx_hash) DO NOTHING`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare SaveTransactionFee statement")
+	}
+
+	getStartingBalances, err := dbConn.PreparexContext(ctx, fmt.Sprintf(`
+		SELECT %s FROM reconciliation_starting_balances ORDER BY wallet, asset`,
+		strings.Join(psql.GetSQLColumnsQuoted[types.StartingBalance](), ", ")))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetStartingBalances statement")
+	}
+
+	setStartingBalance, err := dbConn.PrepareNamedContext(ctx, `
+		INSERT INTO reconciliation_starting_balances (chain_id, wallet, asset, amount, block_number, set_by, updated_at)
+		VALUES (:chain_id, :wallet, :asset, :amount, :block_number, :set_by, NOW())
+		ON CONFLICT (chain_id, wallet, asset) DO UPDATE SET
+			amount = EXCLUDED.amount,
+			block_number = EXCLUDED.block_number,
+			set_by = EXCLUDED.set_by,
+			updated_at = NOW()`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare SetStartingBalance statement")
+	}
+
+	deleteStartingBalance, err := dbConn.PreparexContext(ctx, `
+		DELETE FROM reconciliation_starting_balances WHERE chain_id = $1 AND wallet = $2 AND asset = $3`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare DeleteStartingBalance statement")
+	}
+
+	// Transfers are counted by payer and payee rather than direction, as a transfer between two treasury wallets is
+	// only stored once. Transfers up to the starting balance block are already part of the starting balance.
+	// Spam is left out, its balances are often made up.
+	getExpectedBalances, err := dbConn.PreparexContext(ctx, `
+		WITH held AS (
+			SELECT asset FROM transfers WHERE chain_id = $1 AND (payer_address = $2 OR payee_address = $2) AND block_number <= $3
+			UNION SELECT address FROM wallet_balances WHERE chain_id = $1 AND wallet = $2
+			UNION SELECT asset FROM reconciliation_starting_balances WHERE chain_id = $1 AND wallet = $2
+			UNION SELECT $4::ETH_ADDR_T
+		)
+		SELECT h.asset,
+			COALESCE(sb.amount, 0) AS starting_balance,
+			COALESCE((SELECT SUM(t.amount) FROM transfers t
+				WHERE t.chain_id = $1 AND t.asset = h.asset AND t.payee_address = $2 AND t.payer_address <> $2
+					AND t.block_number > COALESCE(sb.block_number, -1) AND t.block_number <= $3), 0) AS transfers_in,
+			COALESCE((SELECT SUM(t.amount) FROM transfers t
+				WHERE t.chain_id = $1 AND t.asset = h.asset AND t.payer_address = $2 AND t.payee_address <> $2
+					AND t.block_number > COALESCE(sb.block_number, -1) AND t.block_number <= $3), 0) AS transfers_out,
+			CASE WHEN h.asset = $4 THEN COALESCE((SELECT SUM(f.fee) FROM transaction_fees f
+				WHERE f.chain_id = $1 AND f.payer = $2
+					AND f.block_number > COALESCE(sb.block_number, -1) AND f.block_number <= $3), 0) ELSE 0 END AS fees
+		FROM held h
+			LEFT JOIN reconciliation_starting_balances sb ON (sb.chain_id = $1 AND sb.wallet = $2 AND sb.asset = h.asset)
+			LEFT JOIN asset_statuses st ON (st.chain_id = $1 AND st.address = h.asset)
+		WHERE st.status IS NULL OR st.status <> 'spam'
+		ORDER BY h.asset`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetExpectedBalances statement")
+	}
+
+	getResults, err := dbConn.PreparexContext(ctx, fmt.Sprintf(`
+		SELECT %s FROM reconciliation_results ORDER BY wallet, asset`,
+		strings.Join(psql.GetSQLColumnsQuoted[types.ReconciliationResult](), ", ")))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetResults statement")
+	}
+
+	return &reconciliation{
+		log:                   conf.GetLogger(),
+		dbConn:                dbConn,
+		getTxHashesWithoutFee: getTxHashesWithoutFee,
+		saveTransactionFee:    saveTransactionFee,
+		getStartingBalances:   getStartingBalances,
+		setStartingBalance:    setStartingBalance,
+		deleteStartingBalance: deleteStartingBalance,
+		getExpectedBalances:   getExpectedBalances,
+		getResults:            getResults,
+	}, nil
+}
+
+func (r *reconciliation) GetTxHashesWithoutFees(ctx context.Context, chainID int64, wallet string) ([]string, error) {
+	var hashes []string
+	err := r.getTxHashesWithoutFee.SelectContext(ctx, &hashes, chainID, wallet)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get transactions without fees for wallet %s", wallet)
+	}
+	if len(hashes) == 0 {
+		return []string{}, nil
+	}
+	return hashes, nil
+}
+
+func (r *reconciliation) SaveTransactionFee(ctx context.Context, fee types.TransactionFee) error {
+	_, err := r.saveTransactionFee.ExecContext(ctx, fee)
+	if err != nil {
+		return errors.Wrapf(err, "failed to save fee of transaction %s", fee.TxHash)
+	}
+	return nil
+}
+
+func (r *reconciliation) GetStartingBalances(ctx context.Context) ([]types.StartingBalance, error) {
+	var balances []types.StartingBalance
+	err := r.getStartingBalances.SelectContext(ctx, &balances)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get starting balances")
+	}
+	if len(balances) == 0 {
+		return []types.StartingBalance{}, nil
+	}
+	return balances, nil
+}
+
+func (r *reconciliation) SetStartingBalance(ctx context.Context, balance types.StartingBalance) error {
+	_, err := r.setStartingBalance.ExecContext(ctx, balance)
+	if err != nil {
+		return errors.Wrapf(err, "failed to set starting balance of %s for wallet %s", balance.Asset, balance.Wallet)
+	}
+	return nil
+}
+
+func (r *reconciliation) DeleteStartingBalance(ctx context.Context, chainID int64, wallet, asset string) error {
+	result, err := r.deleteStartingBalance.ExecContext(ctx, chainID, wallet, asset)
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete starting balance of %s for wallet %s", asset, wallet)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to get rows affected")
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("starting balance not found")
+	}
+
+	return nil
+}
+
+func (r *reconciliation) GetExpectedBalances(ctx context.Context, chainID int64, wallet string, checkpoint uint64) ([]types.ExpectedBalance, error) {
+	var balances []types.ExpectedBalance
+	err := r.getExpectedBalances.SelectContext(ctx, &balances, chainID, wallet, int64(checkpoint), constants.EtherAddress)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get expected balances for wallet %s", wallet)
+	}
+	if len(balances) == 0 {
+		return []types.ExpectedBalance{}, nil
+	}
+	return balances, nil
+}
+
+func (r *reconciliation) ReplaceResults(ctx context.Context, chainID int64, wallet string, results []types.ReconciliationResult) error {
+	tx, err := r.dbConn.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM reconciliation_results WHERE chain_id = $1 AND wallet = $2", chainID, wallet)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete existing reconciliation results")
+	}
+
+	for _, result := range results {
+		_, err = tx.NamedExecContext(ctx, fmt.Sprintf(`INSERT INTO reconciliation_results (%s) VALUES (%s)`,
+			strings.Join(psql.GetSQLColumnsQuoted[types.ReconciliationResult](), ", "),
+			":"+strings.Join(psql.GetSQLColumns[types.ReconciliationResult](), ", :")), result)
+		if err != nil {
+			return errors.Wrapf(err, "failed to insert reconciliation result of %s for wallet %s", result.Asset, wallet)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, "failed to commit transaction")
+	}
+	return nil
+}
+
+func (r *reconciliation) GetResults(ctx context.Context) ([]types.ReconciliationResult, error) {
+	var results []types.ReconciliationResult
+	err := r.getResults.SelectContext(ctx, &results)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get reconciliation results")
+	}
+	if len(results) == 0 {
+		return []types.ReconciliationResult{}, nil
+	}
+	return results, nil
+}
@@ -0,0 +1,133 @@
+//go:build integration
+// +build integration
+
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ETHCF/ethutils"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/constants"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+func GetTestReconciliationDB(t *testing.T) ReconciliationDB {
+	rdb, err := NewReconciliationDB(t.Context(), conf, dbConn)
+	require.NoError(t, err)
+	return rdb
+}
+
+func Test_ReconciliationDB(t *testing.T) {
+	var (
+		db       = GetTestReconciliationDB(t)
+		treasury = GetTestTreasuryDB(t)
+		wallet   = ethutils.GenRandEVMAddr()
+		other    = ethutils.GenRandEVMAddr()
+		token    = ethutils.GenRandEVMAddr()
+		admin    = ethutils.GenRandEVMAddr()
+	)
+
+	_, err := dbConn.ExecContext(t.Context(), "INSERT INTO wallets (address) VALUES ($1)", wallet)
+	require.NoError(t, err)
+
+	transfers := []types.CreateTransfer{
+		// Before the starting balance, already counted in it
+		{BlockNumber: 90, FromAddress: other, ToAddress: wallet, Asset: token, Amount: "999", Direction: types.TransferTypeIncoming},
+		{BlockNumber: 110, FromAddress: other, ToAddress: wallet, Asset: token, Amount: "500", Direction: types.TransferTypeIncoming},
+		{BlockNumber: 120, FromAddress: wallet, ToAddress: other, Asset: token, Amount: "200", Direction: types.TransferTypeOutgoing},
+		{BlockNumber: 130, FromAddress: wallet, ToAddress: other, Asset: constants.EtherAddress, Amount: "1000", Direction: types.TransferTypeOutgoing},
+		// After the checkpoint
+		{BlockNumber: 300, FromAddress: other, ToAddress: wallet, Asset: token, Amount: "7", Direction: types.TransferTypeIncoming},
+	}
+	for i := range transfers {
+		transfers[i].ChainID = 1
+		transfers[i].TxHash = ethutils.GenRandEVMHash()
+		transfers[i].BlockTimestamp = time.Now().Unix()
+		err = treasury.CreateTransfer(t.Context(), transfers[i])
+		require.NoError(t, err)
+	}
+
+	// Only the transactions sent by the wallet need their fee read
+	hashes, err := db.GetTxHashesWithoutFees(t.Context(), 1, wallet)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{transfers[2].TxHash, transfers[3].TxHash}, hashes)
+
+	for _, hash := range hashes {
+		err = db.SaveTransactionFee(t.Context(), types.TransactionFee{ChainID: 1, TxHash: hash, Payer: wallet, Fee: "21", BlockNumber: 125})
+		require.NoError(t, err)
+	}
+	hashes, err = db.GetTxHashesWithoutFees(t.Context(), 1, wallet)
+	require.NoError(t, err)
+	require.Empty(t, hashes)
+
+	err = db.SetStartingBalance(t.Context(), types.StartingBalance{ChainID: 1, Wallet: wallet, Asset: token, Amount: "1", BlockNumber: 50, SetBy: admin})
+	require.NoError(t, err)
+	err = db.SetStartingBalance(t.Context(), types.StartingBalance{ChainID: 1, Wallet: wallet, Asset: token, Amount: "1000", BlockNumber: 100, SetBy: admin})
+	require.NoError(t, err)
+	err = db.SetStartingBalance(t.Context(), types.StartingBalance{ChainID: 1, Wallet: other, Asset: token, Amount: "1", BlockNumber: 100, SetBy: admin})
+	require.Error(t, err, "starting balances are for treasury wallets")
+
+	starting, err := db.GetStartingBalances(t.Context())
+	require.NoError(t, err)
+	var found []types.StartingBalance
+	for _, balance := range starting {
+		if balance.Wallet == wallet {
+			found = append(found, balance)
+		}
+	}
+	require.Len(t, found, 1)
+	require.Equal(t, "1000", found[0].Amount)
+
+	expected, err := db.GetExpectedBalances(t.Context(), 1, wallet, 200)
+	require.NoError(t, err)
+	byAsset := map[string]types.ExpectedBalance{}
+	for _, balance := range expected {
+		byAsset[balance.Asset] = balance
+	}
+	require.Len(t, byAsset, 2)
+	require.Equal(t, types.ExpectedBalance{Asset: token, StartingBalance: "1000", TransfersIn: "500", TransfersOut: "200", Fees: "0"}, byAsset[token])
+	require.Equal(t, types.ExpectedBalance{Asset: constants.EtherAddress, StartingBalance: "0", TransfersIn: "0", TransfersOut: "1000", Fees: "42"}, byAsset[constants.EtherAddress])
+
+	results := []types.ReconciliationResult{
+		{ChainID: 1, Wallet: wallet, Asset: token, CheckpointBlock: 200, StartingBalance: "1000", TransfersIn: "500", TransfersOut: "200", Fees: "0",
+			ExpectedBalance: "1300", OnchainBalance: "1300", Difference: "0", Reconciled: true, CheckedAt: time.Now()},
+		{ChainID: 1, Wallet: wallet, Asset: constants.EtherAddress, CheckpointBlock: 200, StartingBalance: "0", TransfersIn: "0", TransfersOut: "1000", Fees: "42",
+			ExpectedBalance: "-1042", OnchainBalance: "5", Difference: "1047", Reconciled: false, CheckedAt: time.Now()},
+	}
+	err = db.ReplaceResults(t.Context(), 1, wallet, results)
+	require.NoError(t, err)
+	err = db.ReplaceResults(t.Context(), 1, wallet, results[1:])
+	require.NoError(t, err)
+
+	stored, err := db.GetResults(t.Context())
+	require.NoError(t, err)
+	var ours []types.ReconciliationResult
+	for _, result := range stored {
+		if result.Wallet == wallet {
+			ours = append(ours, result)
+		}
+	}
+	require.Len(t, ours, 1)
+	require.Equal(t, "-1042", ours[0].ExpectedBalance)
+	require.Equal(t, "1047", ours[0].Difference)
+	require.False(t, ours[0].Reconciled)
+
+	err = db.DeleteStartingBalance(t.Context(), 1, wallet, token)
+	require.NoError(t, err)
+	err = db.DeleteStartingBalance(t.Context(), 1, wallet, token)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "not found")
+
+	// Cleanup
+	_, err = dbConn.ExecContext(t.Context(), "DELETE FROM transaction_fees WHERE payer = $1", wallet)
+	require.NoError(t, err)
+	for _, transfer := range transfers {
+		_, err = dbConn.ExecContext(t.Context(), "DELETE FROM transfers WHERE tx_hash = $1", transfer.TxHash)
+		require.NoError(t, err)
+	}
+	_, err = dbConn.ExecContext(t.Context(), "DELETE FROM wallets WHERE address = $1", wallet)
+	require.NoError(t, err)
+}
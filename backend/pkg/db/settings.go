@@ -9,6 +9,7 @@ import (
 	"database/sql"
 	"encoding/pem"
 	"fmt"
+	"strconv"
 
 	"github.com/numbergroup/errors"
 	"github.com/jmoiron/sqlx"
@@ -16,6 +17,7 @@ import (
 
 	"github.com/ETHCF/transparency-dashboard/backend/pkg/config"
 	"github.com/ETHCF/transparency-dashboard/backend/pkg/constants"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
 )
 
 type SettingsDB interface {
@@ -25,6 +27,10 @@ type SettingsDB interface {
 	SetTotalFundsRaised(ctx context.Context, amount float64) error
 	GetTotalFundsRaisedUnit(ctx context.Context) (string, error)
 	SetTotalFundsRaisedUnit(ctx context.Context, unit string) error
+	GetDustThresholds(ctx context.Context) (types.DustThresholds, error)
+	SetDustThresholds(ctx context.Context, thresholds types.DustThresholds) error
+	GetMilestoneSignOffSettings(ctx context.Context) (types.MilestoneSignOffSettings, error)
+	SetMilestoneSignOffSettings(ctx context.Context, settings types.MilestoneSignOffSettings) error
 	LoadJWTKey(ctx context.Context) (*ecdsa.PrivateKey, error)
 }
 
@@ -87,6 +93,80 @@ func (sb *settingsDB) SetTotalFundsRaisedUnit(ctx context.Context, unit string)
 	return sb.Set(ctx, constants.SettingTotalFundsRaisedUnit, unit)
 }
 
+func (sb *settingsDB) GetDustThresholds(ctx context.Context) (types.DustThresholds, error) {
+	// Default to filtering anything worth less than a dollar
+	thresholds := types.DustThresholds{BalanceUSD: 1, TransferUSD: 1}
+	for key, out := range map[string]*float64{
+		constants.SettingDustThresholdBalanceUSD:  &thresholds.BalanceUSD,
+		constants.SettingDustThresholdTransferUSD: &thresholds.TransferUSD,
+	} {
+		value, err := sb.Get(ctx, key)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return types.DustThresholds{}, err
+		}
+		*out, err = strconv.ParseFloat(value, 64)
+		if err != nil {
+			return types.DustThresholds{}, errors.Wrapf(err, "failed to parse %s value", key)
+		}
+	}
+	return thresholds, nil
+}
+
+func (sb *settingsDB) SetDustThresholds(ctx context.Context, thresholds types.DustThresholds) error {
+	err := sb.Set(ctx, constants.SettingDustThresholdBalanceUSD, strconv.FormatFloat(thresholds.BalanceUSD, 'f', -1, 64))
+	if err != nil {
+		return errors.Wrap(err, "failed to set balance dust threshold")
+	}
+	err = sb.Set(ctx, constants.SettingDustThresholdTransferUSD, strconv.FormatFloat(thresholds.TransferUSD, 'f', -1, 64))
+	if err != nil {
+		return errors.Wrap(err, "failed to set transfer dust threshold")
+	}
+	return nil
+}
+
+func (sb *settingsDB) GetMilestoneSignOffSettings(ctx context.Context) (types.MilestoneSignOffSettings, error) {
+	// Default to a single approval, which is how milestones were signed off before approvals were counted
+	settings := types.MilestoneSignOffSettings{RequiredApprovals: 1}
+
+	value, err := sb.Get(ctx, constants.SettingMilestoneRequiredApprovals)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return types.MilestoneSignOffSettings{}, err
+	}
+	if err == nil {
+		settings.RequiredApprovals, err = strconv.Atoi(value)
+		if err != nil {
+			return types.MilestoneSignOffSettings{}, errors.Wrapf(err, "failed to parse %s value", constants.SettingMilestoneRequiredApprovals)
+		}
+	}
+
+	value, err = sb.Get(ctx, constants.SettingMilestoneProposeDisbursement)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return types.MilestoneSignOffSettings{}, err
+	}
+	if err == nil {
+		settings.ProposeDisbursement, err = strconv.ParseBool(value)
+		if err != nil {
+			return types.MilestoneSignOffSettings{}, errors.Wrapf(err, "failed to parse %s value", constants.SettingMilestoneProposeDisbursement)
+		}
+	}
+	return settings, nil
+}
+
+func (sb *settingsDB) SetMilestoneSignOffSettings(ctx context.Context, settings types.MilestoneSignOffSettings) error {
+	err := sb.Set(ctx, constants.SettingMilestoneRequiredApprovals, strconv.Itoa(settings.RequiredApprovals))
+	if err != nil {
+		return errors.Wrap(err, "failed to set milestone required approvals")
+	}
+	err = sb.Set(ctx, constants.SettingMilestoneProposeDisbursement, strconv.FormatBool(settings.ProposeDisbursement))
+	if err != nil {
+		return errors.Wrap(err, "failed to set milestone disbursement proposals")
+	}
+	return nil
+}
+
 func (sb *settingsDB) Get(ctx context.Context, key string) (string, error) {
 	var value string
 	err := sb.dbConn.GetContext(ctx, &value, "SELECT value FROM settings WHERE key = $1", key)
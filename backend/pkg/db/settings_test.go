@@ -8,6 +8,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
 )
 
 func GetTestSettingsDB(t *testing.T) SettingsDB {
@@ -111,3 +113,58 @@ func Test_SettingsDB_GenericGetSet(t *testing.T) {
 		"DELETE FROM settings WHERE key = $1", testKey)
 	require.NoError(t, err)
 }
+
+func Test_SettingsDB_DustThresholds(t *testing.T) {
+	var (
+		db         = GetTestSettingsDB(t)
+		thresholds = types.DustThresholds{BalanceUSD: 5, TransferUSD: 0.25}
+	)
+
+	// Clean up any existing thresholds first so the defaults are used
+	_, err := dbConn.ExecContext(t.Context(),
+		"DELETE FROM settings WHERE key IN ('dust_threshold_balance_usd', 'dust_threshold_transfer_usd')")
+	require.NoError(t, err)
+
+	retrieved, err := db.GetDustThresholds(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, types.DustThresholds{BalanceUSD: 1, TransferUSD: 1}, retrieved)
+
+	err = db.SetDustThresholds(t.Context(), thresholds)
+	require.NoError(t, err)
+
+	retrieved, err = db.GetDustThresholds(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, thresholds, retrieved)
+
+	// Clean up
+	_, err = dbConn.ExecContext(t.Context(),
+		"DELETE FROM settings WHERE key IN ('dust_threshold_balance_usd', 'dust_threshold_transfer_usd')")
+	require.NoError(t, err)
+}
+
+func Test_SettingsDB_MilestoneSignOffSettings(t *testing.T) {
+	var (
+		db       = GetTestSettingsDB(t)
+		settings = types.MilestoneSignOffSettings{RequiredApprovals: 3, ProposeDisbursement: true}
+	)
+
+	_, err := dbConn.ExecContext(t.Context(),
+		"DELETE FROM settings WHERE key IN ('milestone_required_approvals', 'milestone_propose_disbursement')")
+	require.NoError(t, err)
+
+	retrieved, err := db.GetMilestoneSignOffSettings(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, types.MilestoneSignOffSettings{RequiredApprovals: 1}, retrieved)
+
+	err = db.SetMilestoneSignOffSettings(t.Context(), settings)
+	require.NoError(t, err)
+
+	retrieved, err = db.GetMilestoneSignOffSettings(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, settings, retrieved)
+
+	// Clean up
+	_, err = dbConn.ExecContext(t.Context(),
+		"DELETE FROM settings WHERE key IN ('milestone_required_approvals', 'milestone_propose_disbursement')")
+	require.NoError(t, err)
+}
@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -11,6 +12,7 @@ import (
 	"github.com/jmoiron/sqlx"
 	"github.com/numbergroup/errors"
 	"github.com/sirupsen/logrus"
+	"gopkg.in/guregu/null.v4"
 
 	"github.com/ETHCF/transparency-dashboard/backend/pkg/config"
 	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
@@ -18,29 +20,80 @@ import (
 
 type TreasuryDB interface {
 	// Treasury management methods
-	GetTreasuryResponse(ctx context.Context) (*types.TreasuryResponse, error)
+	// GetTreasuryResponse returns the whole treasury, or only the wallets in a group when it is set
+	GetTreasuryResponse(ctx context.Context, includeFiltered bool, group uuid.NullUUID) (*types.TreasuryResponse, error)
 	AddAsset(ctx context.Context, asset types.Asset) error
 	GetAssets(ctx context.Context) ([]types.Asset, error)
+	SetAssetClass(ctx context.Context, chainID int64, address string, class types.AssetClass) error
 	GetWallets(ctx context.Context) ([]types.Wallet, error)
 	AddWallet(ctx context.Context, wallet types.Wallet) error
+	UpdateWallet(ctx context.Context, address string, updates types.UpdateWalletRequest) error
 	DeleteWallet(ctx context.Context, address string) error
-	GetWalletBalances(ctx context.Context) ([]types.WalletBalance, error)
+	GetWallet(ctx context.Context, address string) (*types.Wallet, error)
+	// SetWalletChallenge stores the ownership challenge issued to a wallet, replacing any open one
+	SetWalletChallenge(ctx context.Context, challenge types.WalletChallenge) error
+	GetWalletChallenge(ctx context.Context, wallet string) (*types.WalletChallenge, error)
+	// SaveWalletVerification stores the proof of ownership and consumes the wallet's challenge
+	SaveWalletVerification(ctx context.Context, verification types.WalletVerification) error
+	GetWalletBalances(ctx context.Context, includeFiltered bool, group uuid.NullUUID) ([]types.WalletBalance, error)
 	UpdateWalletBalances(ctx context.Context, wallet string, balances []types.WalletBalance) error
+	// UpdateAssetPrices stores the price of each asset with a price, after its valuation policy is applied
+	UpdateAssetPrices(ctx context.Context, chainID int64, valuations map[string]types.Valuation) error
+
+	// Asset status methods, used to filter spam and dust
+	GetAssetStatuses(ctx context.Context) ([]types.AssetStatusEntry, error)
+	SetAssetStatus(ctx context.Context, status types.AssetStatusEntry) error
+	DeleteAssetStatus(ctx context.Context, chainID int64, address string) error
+
+	// DeFi position methods
+	GetWalletPositions(ctx context.Context) ([]types.Position, error)
+	UpdateWalletPositions(ctx context.Context, wallet string, positions []types.Position) error
+
+	// Valuation policy methods
+	GetValuationPolicies(ctx context.Context) ([]types.AssetValuationPolicy, error)
+	SetValuationPolicy(ctx context.Context, policy types.AssetValuationPolicy) error
+	DeleteValuationPolicy(ctx context.Context, chainID int64, address string) error
+
+	// Composition snapshot methods
+	// SaveCompositionSnapshot stores the composition for its day, replacing an earlier one from the same day
+	SaveCompositionSnapshot(ctx context.Context, snapshot types.CompositionSnapshot) error
+	GetCompositionSnapshots(ctx context.Context, since time.Time) ([]types.CompositionSnapshot, error)
+
+	// Wallet group methods
+	GetWalletGroups(ctx context.Context) ([]types.WalletGroup, error)
+	GetWalletGroupByID(ctx context.Context, id uuid.UUID) (*types.WalletGroup, error)
+	CreateWalletGroup(ctx context.Context, group types.WalletGroup) error
+	// UpdateWalletGroup updates the name and description of a group and replaces its wallets
+	UpdateWalletGroup(ctx context.Context, group types.WalletGroup) error
+	DeleteWalletGroup(ctx context.Context, id uuid.UUID) error
+
+	// GetOffchainBalances returns the latest statement of each off-chain account
+	GetOffchainBalances(ctx context.Context) ([]types.OffchainBalance, error)
+	// GetExchangeBalances returns the balances held in exchange accounts, as last read by the tracker
+	GetExchangeBalances(ctx context.Context) ([]types.ExchangeBalance, error)
 
 	// Transfer management methods
-	GetTransfers(ctx context.Context, limit, offset int) ([]types.Transfer, error)
+	GetTransfers(ctx context.Context, limit, offset int, includeFiltered bool, group uuid.NullUUID) ([]types.Transfer, error)
 	CreateTransfer(ctx context.Context, transfer types.CreateTransfer) error
+	ImportTransfers(ctx context.Context, transfers []types.CreateTransfer) (int, error)
 	GetTransferByID(ctx context.Context, id uuid.UUID) (*types.Transfer, error)
+	// GetTransfersByTxHash returns the ingested transfers of a transaction, in the order of their logs
+	GetTransfersByTxHash(ctx context.Context, chainID int64, txHash string) ([]types.CreateTransfer, error)
 
 	// Transfer party management methods
 	GetTransferParties(ctx context.Context, limit, offset int) ([]types.TransferParty, error)
 	GetTransferPartyByAddress(ctx context.Context, address string) (*types.TransferParty, error)
 	UpdateTransferPartyName(ctx context.Context, address, name string) error
 	UpsertTransferParty(ctx context.Context, party types.TransferParty) error
+	GetAddressesForENSResolution(ctx context.Context, resolvedBefore time.Time, limit int) ([]string, error)
+	SetTransferPartyENSName(ctx context.Context, address string, ensName null.String) error
 }
 
+// recentGroupTransfers is how many transfers are included when the treasury is scoped to a wallet group
+const recentGroupTransfers = 50
+
 // Treasury methods
-func (t *treasury) GetTreasuryResponse(ctx context.Context) (*types.TreasuryResponse, error) {
+func (t *treasury) GetTreasuryResponse(ctx context.Context, includeFiltered bool, group uuid.NullUUID) (*types.TreasuryResponse, error) {
 	// Get assets
 	assets, err := t.GetAssets(ctx)
 	if err != nil {
@@ -53,17 +106,85 @@ func (t *treasury) GetTreasuryResponse(ctx context.Context) (*types.TreasuryResp
 		return nil, errors.Wrap(err, "failed to get wallets")
 	}
 
-	balances, err := t.GetWalletBalances(ctx)
+	var walletGroup *types.WalletGroup
+	if group.Valid {
+		walletGroup, err = t.GetWalletGroupByID(ctx, group.UUID)
+		if err != nil {
+			return nil, err
+		}
+		members := make(map[string]bool, len(walletGroup.Wallets))
+		for _, address := range walletGroup.Wallets {
+			members[address] = true
+		}
+		groupWallets := make([]types.Wallet, 0, len(walletGroup.Wallets))
+		for _, wallet := range wallets {
+			if members[wallet.Address] {
+				groupWallets = append(groupWallets, wallet)
+			}
+		}
+		wallets = groupWallets
+	}
+
+	allBalances, err := t.GetWalletBalances(ctx, true, group)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get wallet balances")
 	}
 
-	// Calculate totals
+	// Calculate totals, spam and excluded assets are never counted even if they have a price but hidden assets and dust are
 	var totalValueUsd float64
 	var totalValueEth string = "0" // Placeholder until ETH calc is implemented
-	for _, asset := range balances {
-		totalValueUsd += asset.UsdWorth
+	balances := make([]types.WalletBalance, 0, len(allBalances))
+	for _, asset := range allBalances {
+		if asset.FilterReason.String != types.FilterReasonSpam && asset.ValuationPolicy != types.ValuationPolicyExcluded {
+			totalValueUsd += asset.UsdWorth
+		}
+		if includeFiltered || !asset.FilterReason.Valid {
+			balances = append(balances, asset)
+		}
+	}
+
+	allPositions, err := t.GetWalletPositions(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get wallet positions")
+	}
+	positions := make([]types.Position, 0, len(allPositions))
+	for _, position := range allPositions {
+		if walletGroup != nil && !slices.Contains(walletGroup.Wallets, position.Wallet) {
+			continue
+		}
+		positions = append(positions, position)
+		totalValueUsd += position.UsdWorth
+	}
+
+	// Off-chain and exchange accounts don't belong to a wallet group
+	offchainBalances := []types.OffchainBalance{}
+	exchangeBalances := []types.ExchangeBalance{}
+	var selfReportedValueUsd float64
+	var recentTransfers []types.Transfer
+	if walletGroup == nil {
+		offchainBalances, err = t.GetOffchainBalances(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get off-chain balances")
+		}
+		for _, balance := range offchainBalances {
+			selfReportedValueUsd += balance.UsdValue
+		}
+		totalValueUsd += selfReportedValueUsd
+
+		exchangeBalances, err = t.GetExchangeBalances(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get exchange balances")
+		}
+		for _, balance := range exchangeBalances {
+			totalValueUsd += balance.UsdValue.Float64
+		}
+	} else {
+		recentTransfers, err = t.GetTransfers(ctx, recentGroupTransfers, 0, includeFiltered, group)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get group transfers")
+		}
 	}
+
 	orgName, err := t.settingDB.GetOrganizationName(ctx)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get organization name")
@@ -83,12 +204,18 @@ func (t *treasury) GetTreasuryResponse(ctx context.Context) (*types.TreasuryResp
 		OrganizationName:     orgName,
 		Assets:               assets,
 		WalletBalances:       balances,
+		Positions:            positions,
+		OffchainBalances:     offchainBalances,
+		ExchangeBalances:     exchangeBalances,
 		Wallets:              wallets,
 		TotalValueUsd:        totalValueUsd,
+		SelfReportedValueUsd: selfReportedValueUsd,
 		TotalValueEth:        totalValueEth,
 		TotalFundsRaised:     totalFundsRaised,
 		TotalFundsRaisedUnit: totalFundsRaisedUnit,
 		LastUpdated:          time.Now(),
+		Group:                walletGroup,
+		RecentTransfers:      recentTransfers,
 	}, nil
 }
 
@@ -98,17 +225,40 @@ type treasury struct {
 	dbConn                    *sqlx.DB
 	getAssets                 *sqlx.Stmt
 	addAsset                  *sqlx.NamedStmt
+	setAssetClass             *sqlx.Stmt
+	saveCompositionSnapshot   *sqlx.NamedStmt
+	getCompositionSnapshots   *sqlx.Stmt
 	getWallets                *sqlx.Stmt
 	getWalletBalances         *sqlx.Stmt
 	addWallet                 *sqlx.NamedStmt
 	deleteWallet              *sqlx.Stmt
+	getWallet                 *sqlx.Stmt
+	setWalletChallenge        *sqlx.NamedStmt
+	getWalletChallenge        *sqlx.Stmt
+	getWalletVerifications    *sqlx.Stmt
+	getWalletGroupMembers     *sqlx.Stmt
+	getWalletGroups           *sqlx.Stmt
+	getWalletGroupByID        *sqlx.Stmt
+	deleteWalletGroup         *sqlx.Stmt
 	getTransfers              *sqlx.Stmt
 	createTransfer            *sqlx.NamedStmt
 	getTransferByID           *sqlx.Stmt
+	getTransfersByTxHash      *sqlx.Stmt
 	getTransferParties        *sqlx.Stmt
 	getTransferPartyByAddress *sqlx.Stmt
 	updateTransferPartyName   *sqlx.Stmt
 	upsertTransferParty       *sqlx.NamedStmt
+	getAddressesForENS        *sqlx.Stmt
+	setTransferPartyENSName   *sqlx.Stmt
+	getAssetStatuses          *sqlx.Stmt
+	setAssetStatus            *sqlx.NamedStmt
+	deleteAssetStatus         *sqlx.Stmt
+	getWalletPositions        *sqlx.Stmt
+	getValuationPolicies      *sqlx.Stmt
+	setValuationPolicy        *sqlx.NamedStmt
+	deleteValuationPolicy     *sqlx.Stmt
+	getOffchainBalances       *sqlx.Stmt
+	getExchangeBalances       *sqlx.Stmt
 }
 
 func NewTreasuryDB(ctx context.Context, conf *config.Config, dbConn *sqlx.DB, settingDB SettingsDB) (TreasuryDB, error) {
@@ -131,6 +281,28 @@ func NewTreasuryDB(ctx context.Context, conf *config.Config, dbConn *sqlx.DB, se
 		return nil, errors.Wrap(err, "failed to prepare AddAsset statement")
 	}
 
+	setAssetClass, err := dbConn.PreparexContext(ctx, `
+		UPDATE assets SET asset_class = $3 WHERE chain_id = $1 AND address = $2`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare SetAssetClass statement")
+	}
+
+	saveCompositionSnapshot, err := dbConn.PrepareNamedContext(ctx, `
+		INSERT INTO composition_snapshots (snapshot_date, total_value_usd, composition)
+		VALUES (:snapshot_date, :total_value_usd, :composition)
+		ON CONFLICT (snapshot_date) DO UPDATE SET total_value_usd = EXCLUDED.total_value_usd,
+			composition = EXCLUDED.composition, created_at = NOW()`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare SaveCompositionSnapshot statement")
+	}
+
+	getCompositionSnapshots, err := dbConn.PreparexContext(ctx, `
+		SELECT snapshot_date, total_value_usd, composition FROM composition_snapshots
+		WHERE snapshot_date >= $1 ORDER BY snapshot_date`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetCompositionSnapshots statement")
+	}
+
 	// Wallet queries
 	getWallets, err := dbConn.PreparexContext(ctx, fmt.Sprintf(`
 		SELECT %s FROM wallets`, strings.Join(walletCols, ", ")))
@@ -151,34 +323,129 @@ func NewTreasuryDB(ctx context.Context, conf *config.Config, dbConn *sqlx.DB, se
 		return nil, errors.Wrap(err, "failed to prepare DeleteWallet statement")
 	}
 
-	walletBalanceCols := psql.GetSQLColumnsQuoted[types.WalletBalance]()
-	getWalletBalances, err := dbConn.PreparexContext(ctx, fmt.Sprintf(`
-		SELECT %s FROM wallet_balances`, strings.Join(walletBalanceCols, ", ")))
+	getWallet, err := dbConn.PreparexContext(ctx, fmt.Sprintf(`
+		SELECT %s FROM wallets WHERE address = $1`, strings.Join(walletCols, ", ")))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetWallet statement")
+	}
+
+	// Wallet ownership queries
+	setWalletChallenge, err := dbConn.PrepareNamedContext(ctx, `
+		INSERT INTO wallet_challenges (wallet, nonce, issued_at) VALUES (:wallet, :nonce, :issued_at)
+		ON CONFLICT (wallet) DO UPDATE SET nonce = EXCLUDED.nonce, issued_at = EXCLUDED.issued_at`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare SetWalletChallenge statement")
+	}
+
+	getWalletChallenge, err := dbConn.PreparexContext(ctx, `
+		SELECT wallet, nonce, issued_at FROM wallet_challenges WHERE wallet = $1`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetWalletChallenge statement")
+	}
+
+	verificationCols := psql.GetSQLColumnsQuoted[types.WalletVerification]()
+	getWalletVerifications, err := dbConn.PreparexContext(ctx, fmt.Sprintf(`
+		SELECT %s FROM wallet_verifications`, strings.Join(verificationCols, ", ")))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetWalletVerifications statement")
+	}
+
+	// Wallet group queries
+	getWalletGroupMembers, err := dbConn.PreparexContext(ctx, `
+		SELECT group_id, wallet FROM wallet_group_members ORDER BY wallet`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetWalletGroupMembers statement")
+	}
+
+	walletGroupCols := psql.GetSQLColumnsQuoted[types.WalletGroup]()
+	getWalletGroups, err := dbConn.PreparexContext(ctx, fmt.Sprintf(`
+		SELECT %s FROM wallet_groups ORDER BY name`, strings.Join(walletGroupCols, ", ")))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetWalletGroups statement")
+	}
+
+	getWalletGroupByID, err := dbConn.PreparexContext(ctx, fmt.Sprintf(`
+		SELECT %s FROM wallet_groups WHERE id = $1`, strings.Join(walletGroupCols, ", ")))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetWalletGroupByID statement")
+	}
+
+	deleteWalletGroup, err := dbConn.PreparexContext(ctx, `DELETE FROM wallet_groups WHERE id = $1`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare DeleteWalletGroup statement")
+	}
+
+	// Balances of hidden or spam assets, or worth less than the dust threshold ($1), are only returned when $2 is set.
+	// When a wallet group ($3) is given only the balances of its wallets are returned.
+	getWalletBalances, err := dbConn.PreparexContext(ctx, `
+	SELECT * FROM (
+		SELECT wb.chain_id,
+			wb.address,
+			wb.wallet,
+			wb.amount,
+			wb.usd_worth,
+			wb.eth_worth,
+			wb.last_updated,
+			wb.usd_price,
+			wb.price_source,
+			wb.price_updated_at,
+			wb.valuation_policy,
+			CASE
+				WHEN st.status IN ('hidden', 'spam') THEN st.status::TEXT
+				WHEN wb.usd_price IS NOT NULL AND wb.usd_worth < $1 THEN 'dust'
+			END AS filter_reason
+		FROM wallet_balances wb
+			LEFT JOIN asset_statuses st ON (wb.chain_id = st.chain_id AND wb.address = st.address)
+	) b WHERE ($2 OR b.filter_reason IS NULL)
+		AND ($3::UUID IS NULL OR b.wallet IN (SELECT wallet FROM wallet_group_members WHERE group_id = $3))`)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to prepare GetWalletBalances statement")
 	}
 
-	getTransfersQuery := `
-	SELECT t.id AS id,
-		t.tx_hash,
-		t.block_number,
-		t.block_timestamp,
-		t.payer_address,
-		t.payee_address, 
-		t.asset AS asset,
-		COALESCE(a.name, 'unknown') AS asset_name,
-		COALESCE(a.symbol, 'unknown') AS asset_symbol,
-		t.amount AS amount,
-		t.direction AS direction,
-		t.log_index,
-		COALESCE(wf.name,'unknown') AS payer_name,  
-		COALESCE(wt.name, 'unknown') as payee_name  
-	FROM transfers t
-		LEFT JOIN transfer_parties wf ON (t.payer_address = wf.address)
-		LEFT JOIN transfer_parties wt ON (t.payee_address = wt.address)
-		LEFT JOIN assets a ON (t.asset = a.address)`
-
-	getTransfers, err := dbConn.PreparexContext(ctx, getTransfersQuery+" ORDER BY t.block_timestamp DESC, t.log_index DESC LIMIT $1 OFFSET $2")
+	// The transfers are wrapped in a subquery so they can be filtered on filter_reason. A transfer is filtered when
+	// its asset is hidden or spam, when it is an unknown token without a price that we have never sent, or when
+	// its value is below the dust threshold, which is passed as the parameter given to getTransfersQuery
+	getTransfersQuery := func(thresholdParam string) string {
+		return `
+	SELECT * FROM (
+		SELECT t.id AS id,
+			t.chain_id,
+			t.tx_hash,
+			t.block_number,
+			t.block_timestamp,
+			t.payer_address,
+			t.payee_address,
+			t.asset AS asset,
+			COALESCE(a.name, 'unknown') AS asset_name,
+			COALESCE(a.symbol, 'unknown') AS asset_symbol,
+			t.amount AS amount,
+			t.direction AS direction,
+			t.log_index,
+			COALESCE(wf.label, 'unknown') AS payer_name,
+			COALESCE(wt.label, 'unknown') as payee_name,
+			CASE
+				WHEN st.status IN ('hidden', 'spam') THEN st.status::TEXT
+				WHEN st.status IS NULL AND a.address IS NULL AND ap.address IS NULL AND NOT EXISTS (
+					SELECT 1 FROM transfers o WHERE o.chain_id = t.chain_id AND o.asset = t.asset AND o.direction = 'outgoing'
+				) THEN 'suspected_spam'
+				WHEN ap.usd_price IS NOT NULL AND a.decimals IS NOT NULL
+					AND t.amount / POWER(10::NUMERIC, a.decimals) * ap.usd_price::NUMERIC < ` + thresholdParam + ` THEN 'dust'
+			END AS filter_reason
+		FROM transfers t
+			LEFT JOIN party_display_labels wf ON (t.payer_address = wf.address)
+			LEFT JOIN party_display_labels wt ON (t.payee_address = wt.address)
+			LEFT JOIN assets a ON (t.asset = a.address)
+			LEFT JOIN asset_statuses st ON (t.chain_id = st.chain_id AND t.asset = st.address)
+			LEFT JOIN asset_prices ap ON (t.chain_id = ap.chain_id AND t.asset = ap.address)
+	) ft`
+	}
+
+	getTransfers, err := dbConn.PreparexContext(ctx, getTransfersQuery("$3")+`
+		WHERE ($4 OR ft.filter_reason IS NULL)
+			AND ($5::UUID IS NULL OR EXISTS (
+				SELECT 1 FROM wallet_group_members m WHERE m.group_id = $5 AND m.wallet IN (ft.payer_address, ft.payee_address)
+			))
+		ORDER BY ft.block_timestamp DESC, ft.log_index DESC LIMIT $1 OFFSET $2`)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to prepare GetTransfers statement")
 	}
@@ -190,11 +457,18 @@ func NewTreasuryDB(ctx context.Context, conf *config.Config, dbConn *sqlx.DB, se
 		return nil, errors.Wrap(err, "failed to prepare CreateTransfer statement")
 	}
 
-	getTransferByID, err := dbConn.PreparexContext(ctx, getTransfersQuery+" WHERE t.id = $1")
+	getTransferByID, err := dbConn.PreparexContext(ctx, getTransfersQuery("$2")+" WHERE ft.id = $1")
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to prepare GetTransferByID statement")
 	}
 
+	getTransfersByTxHash, err := dbConn.PreparexContext(ctx, fmt.Sprintf(`
+		SELECT %s FROM transfers WHERE chain_id = $1 AND tx_hash = $2 ORDER BY log_index`,
+		strings.Join(psql.GetSQLColumnsQuoted[types.CreateTransfer](), ", ")))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetTransfersByTxHash statement")
+	}
+
 	// Transfer party queries
 	getTransferParties, err := dbConn.PreparexContext(ctx, fmt.Sprintf(`
 		SELECT %s FROM transfer_parties LIMIT $1 OFFSET $2`, strings.Join(transferPartyCols, ", ")))
@@ -224,23 +498,147 @@ func NewTreasuryDB(ctx context.Context, conf *config.Config, dbConn *sqlx.DB, se
 		return nil, errors.Wrap(err, "failed to prepare UpsertTransferParty statement")
 	}
 
+	// Every wallet and counterparty whose ENS name was never looked up or was looked up before the cutoff
+	getAddressesForENS, err := dbConn.PreparexContext(ctx, `
+		SELECT a.address FROM (
+			SELECT address FROM wallets
+			UNION SELECT payer_address FROM transfers
+			UNION SELECT payee_address FROM transfers
+		) a
+		LEFT JOIN transfer_parties p ON (a.address = p.address)
+		WHERE p.ens_resolved_at IS NULL OR p.ens_resolved_at < $1
+		ORDER BY p.ens_resolved_at ASC NULLS FIRST
+		LIMIT $2`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetAddressesForENSResolution statement")
+	}
+
+	setTransferPartyENSName, err := dbConn.PreparexContext(ctx, `
+		INSERT INTO transfer_parties (address, name, ens_name, ens_resolved_at) VALUES ($1, '', $2, NOW())
+		ON CONFLICT (address) DO UPDATE SET
+			ens_name = EXCLUDED.ens_name,
+			ens_resolved_at = EXCLUDED.ens_resolved_at`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare SetTransferPartyENSName statement")
+	}
+
+	assetStatusCols := psql.GetSQLColumnsQuoted[types.AssetStatusEntry]()
+	getAssetStatuses, err := dbConn.PreparexContext(ctx, fmt.Sprintf(`
+		SELECT %s FROM asset_statuses ORDER BY chain_id, address`, strings.Join(assetStatusCols, ", ")))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetAssetStatuses statement")
+	}
+
+	setAssetStatus, err := dbConn.PrepareNamedContext(ctx, `
+		INSERT INTO asset_statuses (chain_id, address, status, reason) VALUES (:chain_id, :address, :status, :reason)
+		ON CONFLICT (chain_id, address) DO UPDATE SET
+			status = EXCLUDED.status,
+			reason = EXCLUDED.reason,
+			updated_at = NOW()`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare SetAssetStatus statement")
+	}
+
+	deleteAssetStatus, err := dbConn.PreparexContext(ctx, `DELETE FROM asset_statuses WHERE chain_id = $1 AND address = $2`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare DeleteAssetStatus statement")
+	}
+
+	positionCols := psql.GetSQLColumnsQuoted[types.Position]()
+	getWalletPositions, err := dbConn.PreparexContext(ctx, fmt.Sprintf(`
+		SELECT %s FROM wallet_positions ORDER BY usd_worth DESC, wallet, protocol, position_id`, strings.Join(positionCols, ", ")))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetWalletPositions statement")
+	}
+
+	valuationPolicyCols := psql.GetSQLColumnsQuoted[types.AssetValuationPolicy]()
+	getValuationPolicies, err := dbConn.PreparexContext(ctx, fmt.Sprintf(`
+		SELECT %s FROM valuation_policies ORDER BY chain_id, address`, strings.Join(valuationPolicyCols, ", ")))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetValuationPolicies statement")
+	}
+
+	setValuationPolicy, err := dbConn.PrepareNamedContext(ctx, `
+		INSERT INTO valuation_policies (chain_id, address, policy, manual_price, peg_address, discount_percent, notes)
+		VALUES (:chain_id, :address, :policy, :manual_price, :peg_address, :discount_percent, :notes)
+		ON CONFLICT (chain_id, address) DO UPDATE SET
+			policy = EXCLUDED.policy,
+			manual_price = EXCLUDED.manual_price,
+			peg_address = EXCLUDED.peg_address,
+			discount_percent = EXCLUDED.discount_percent,
+			notes = EXCLUDED.notes,
+			updated_at = NOW()`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare SetValuationPolicy statement")
+	}
+
+	deleteValuationPolicy, err := dbConn.PreparexContext(ctx, `DELETE FROM valuation_policies WHERE chain_id = $1 AND address = $2`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare DeleteValuationPolicy statement")
+	}
+
+	getOffchainBalances, err := dbConn.PreparexContext(ctx, `
+		SELECT DISTINCT ON (s.account_id) s.account_id,
+			a.name,
+			a.institution,
+			a.currency,
+			s.balance,
+			s.usd_value,
+			s.statement_date
+		FROM offchain_statements s
+			JOIN offchain_accounts a ON (s.account_id = a.id)
+		ORDER BY s.account_id, s.statement_date DESC`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetOffchainBalances statement")
+	}
+
+	exchangeBalanceCols := psql.GetSQLColumnsQuoted[types.ExchangeBalance]()
+	getExchangeBalances, err := dbConn.PreparexContext(ctx, fmt.Sprintf(`
+		SELECT %s FROM exchange_balances ORDER BY usd_value DESC NULLS LAST, exchange, currency`, strings.Join(exchangeBalanceCols, ", ")))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare GetExchangeBalances statement")
+	}
+
 	return &treasury{
 		log:                       conf.GetLogger(),
 		settingDB:                 settingDB,
 		dbConn:                    dbConn,
 		getAssets:                 getAssets,
 		addAsset:                  addAsset,
+		setAssetClass:             setAssetClass,
+		saveCompositionSnapshot:   saveCompositionSnapshot,
+		getCompositionSnapshots:   getCompositionSnapshots,
 		getWallets:                getWallets,
 		getWalletBalances:         getWalletBalances,
 		addWallet:                 addWallet,
 		deleteWallet:              deleteWallet,
+		getWallet:                 getWallet,
+		setWalletChallenge:        setWalletChallenge,
+		getWalletChallenge:        getWalletChallenge,
+		getWalletVerifications:    getWalletVerifications,
+		getWalletGroupMembers:     getWalletGroupMembers,
+		getWalletGroups:           getWalletGroups,
+		getWalletGroupByID:        getWalletGroupByID,
+		deleteWalletGroup:         deleteWalletGroup,
 		getTransfers:              getTransfers,
 		createTransfer:            createTransfer,
 		getTransferByID:           getTransferByID,
+		getTransfersByTxHash:      getTransfersByTxHash,
 		getTransferParties:        getTransferParties,
 		getTransferPartyByAddress: getTransferPartyByAddress,
 		updateTransferPartyName:   updateTransferPartyName,
 		upsertTransferParty:       upsertTransferParty,
+		getAddressesForENS:        getAddressesForENS,
+		setTransferPartyENSName:   setTransferPartyENSName,
+		getAssetStatuses:          getAssetStatuses,
+		setAssetStatus:            setAssetStatus,
+		deleteAssetStatus:         deleteAssetStatus,
+		getWalletPositions:        getWalletPositions,
+		getValuationPolicies:      getValuationPolicies,
+		setValuationPolicy:        setValuationPolicy,
+		deleteValuationPolicy:     deleteValuationPolicy,
+		getOffchainBalances:       getOffchainBalances,
+		getExchangeBalances:       getExchangeBalances,
 	}, nil
 }
 
@@ -256,9 +654,13 @@ func (t *treasury) GetAssets(ctx context.Context) ([]types.Asset, error) {
 	return assets, nil
 }
 
-func (t *treasury) GetWalletBalances(ctx context.Context) ([]types.WalletBalance, error) {
+func (t *treasury) GetWalletBalances(ctx context.Context, includeFiltered bool, group uuid.NullUUID) ([]types.WalletBalance, error) {
+	thresholds, err := t.settingDB.GetDustThresholds(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get dust thresholds")
+	}
 	var balances []types.WalletBalance
-	err := t.getWalletBalances.SelectContext(ctx, &balances)
+	err = t.getWalletBalances.SelectContext(ctx, &balances, thresholds.BalanceUSD, includeFiltered, group)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get wallet balances")
 	}
@@ -273,6 +675,9 @@ func (t *treasury) GetWalletBalances(ctx context.Context) ([]types.WalletBalance
 }
 
 func (t *treasury) AddAsset(ctx context.Context, asset types.Asset) error {
+	if asset.Class == "" {
+		asset.Class = types.AssetClassOther
+	}
 	_, err := t.addAsset.ExecContext(ctx, asset)
 	if err != nil {
 		return errors.Wrap(err, "failed to add asset")
@@ -280,6 +685,44 @@ func (t *treasury) AddAsset(ctx context.Context, asset types.Asset) error {
 	return nil
 }
 
+func (t *treasury) SetAssetClass(ctx context.Context, chainID int64, address string, class types.AssetClass) error {
+	result, err := t.setAssetClass.ExecContext(ctx, chainID, address, class)
+	if err != nil {
+		return errors.Wrap(err, "failed to set asset class")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to get rows affected")
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("asset not found")
+	}
+
+	return nil
+}
+
+func (t *treasury) SaveCompositionSnapshot(ctx context.Context, snapshot types.CompositionSnapshot) error {
+	_, err := t.saveCompositionSnapshot.ExecContext(ctx, snapshot)
+	if err != nil {
+		return errors.Wrap(err, "failed to save composition snapshot")
+	}
+	return nil
+}
+
+func (t *treasury) GetCompositionSnapshots(ctx context.Context, since time.Time) ([]types.CompositionSnapshot, error) {
+	var snapshots []types.CompositionSnapshot
+	err := t.getCompositionSnapshots.SelectContext(ctx, &snapshots, since)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get composition snapshots")
+	}
+	if len(snapshots) == 0 {
+		return []types.CompositionSnapshot{}, nil
+	}
+	return snapshots, nil
+}
+
 func (t *treasury) GetWallets(ctx context.Context) ([]types.Wallet, error) {
 	var wallets []types.Wallet
 	err := t.getWallets.SelectContext(ctx, &wallets)
@@ -289,10 +732,95 @@ func (t *treasury) GetWallets(ctx context.Context) ([]types.Wallet, error) {
 	if len(wallets) == 0 {
 		return []types.Wallet{}, nil
 	}
+
+	members, err := t.getGroupMembers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	walletGroups := map[string][]uuid.UUID{}
+	for _, member := range members {
+		walletGroups[member.Wallet] = append(walletGroups[member.Wallet], member.GroupID)
+	}
+
+	var verifications []types.WalletVerification
+	err = t.getWalletVerifications.SelectContext(ctx, &verifications)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get wallet verifications")
+	}
+	walletVerifications := map[string]*types.WalletVerification{}
+	for i := range verifications {
+		walletVerifications[verifications[i].Wallet] = &verifications[i]
+	}
+
+	for i := range wallets {
+		wallets[i].GroupIDs = walletGroups[wallets[i].Address]
+		if wallets[i].GroupIDs == nil {
+			wallets[i].GroupIDs = []uuid.UUID{}
+		}
+		wallets[i].Verification = walletVerifications[wallets[i].Address]
+		wallets[i].Verified = wallets[i].Verification != nil
+	}
 	return wallets, nil
 }
 
+func (t *treasury) GetWallet(ctx context.Context, address string) (*types.Wallet, error) {
+	var wallet types.Wallet
+	err := t.getWallet.GetContext(ctx, &wallet, address)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get wallet (%s)", address)
+	}
+	return &wallet, nil
+}
+
+func (t *treasury) SetWalletChallenge(ctx context.Context, challenge types.WalletChallenge) error {
+	_, err := t.setWalletChallenge.ExecContext(ctx, challenge)
+	if err != nil {
+		return errors.Wrap(err, "failed to set wallet challenge")
+	}
+	return nil
+}
+
+func (t *treasury) GetWalletChallenge(ctx context.Context, wallet string) (*types.WalletChallenge, error) {
+	var challenge types.WalletChallenge
+	err := t.getWalletChallenge.GetContext(ctx, &challenge, wallet)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get wallet challenge (%s)", wallet)
+	}
+	return &challenge, nil
+}
+
+func (t *treasury) SaveWalletVerification(ctx context.Context, verification types.WalletVerification) error {
+	tx, err := t.dbConn.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	_, err = tx.NamedExecContext(ctx, `
+		INSERT INTO wallet_verifications (wallet, method, challenge_hash, signature, verified_by, verified_at)
+		VALUES (:wallet, :method, :challenge_hash, :signature, :verified_by, :verified_at)
+		ON CONFLICT (wallet) DO UPDATE SET method = EXCLUDED.method, challenge_hash = EXCLUDED.challenge_hash,
+			signature = EXCLUDED.signature, verified_by = EXCLUDED.verified_by, verified_at = EXCLUDED.verified_at`, verification)
+	if err != nil {
+		return errors.Wrap(err, "failed to save wallet verification")
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM wallet_challenges WHERE wallet = $1", verification.Wallet)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete wallet challenge")
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, "failed to commit transaction")
+	}
+	return nil
+}
+
 func (t *treasury) AddWallet(ctx context.Context, wallet types.Wallet) error {
+	if wallet.ChainID == 0 {
+		wallet.ChainID = 1
+	}
 	_, err := t.addWallet.ExecContext(ctx, wallet)
 	if err != nil {
 		return errors.Wrap(err, "failed to add wallet")
@@ -300,6 +828,32 @@ func (t *treasury) AddWallet(ctx context.Context, wallet types.Wallet) error {
 	return nil
 }
 
+func (t *treasury) UpdateWallet(ctx context.Context, address string, updates types.UpdateWalletRequest) error {
+	setParts, args := updates.GetSQLUpdates(2)
+	if len(setParts) == 0 {
+		return nil
+	}
+
+	args = append([]any{address}, args...)
+	query := fmt.Sprintf("UPDATE wallets SET %s WHERE address = $1", strings.Join(setParts, ", "))
+
+	result, err := t.dbConn.ExecContext(ctx, query, args...)
+	if err != nil {
+		return errors.Wrap(err, "failed to update wallet")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to get rows affected")
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("wallet not found")
+	}
+
+	return nil
+}
+
 func (t *treasury) DeleteWallet(ctx context.Context, address string) error {
 	result, err := t.deleteWallet.ExecContext(ctx, address)
 	if err != nil {
@@ -319,9 +873,13 @@ func (t *treasury) DeleteWallet(ctx context.Context, address string) error {
 }
 
 // Transfer methods
-func (t *treasury) GetTransfers(ctx context.Context, limit, offset int) ([]types.Transfer, error) {
+func (t *treasury) GetTransfers(ctx context.Context, limit, offset int, includeFiltered bool, group uuid.NullUUID) ([]types.Transfer, error) {
+	thresholds, err := t.settingDB.GetDustThresholds(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get dust thresholds")
+	}
 	var transfers []types.Transfer
-	err := t.getTransfers.SelectContext(ctx, &transfers, limit, offset)
+	err = t.getTransfers.SelectContext(ctx, &transfers, limit, offset, thresholds.TransferUSD, includeFiltered, group)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get transfers")
 	}
@@ -335,6 +893,9 @@ func (t *treasury) GetTransfers(ctx context.Context, limit, offset int) ([]types
 }
 
 func (t *treasury) CreateTransfer(ctx context.Context, transfer types.CreateTransfer) error {
+	if transfer.ChainID == 0 {
+		transfer.ChainID = 1
+	}
 	_, err := t.createTransfer.ExecContext(ctx, transfer)
 	if err != nil {
 		return errors.Wrap(err, "failed to create transfer")
@@ -342,15 +903,64 @@ func (t *treasury) CreateTransfer(ctx context.Context, transfer types.CreateTran
 	return nil
 }
 
+// ImportTransfers inserts the transfers in a single transaction, skipping any that already exist,
+// and returns the number of rows that were actually inserted
+func (t *treasury) ImportTransfers(ctx context.Context, transfers []types.CreateTransfer) (int, error) {
+	tx, err := t.dbConn.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	stmt := tx.NamedStmtContext(ctx, t.createTransfer)
+	inserted := 0
+	for _, transfer := range transfers {
+		if transfer.ChainID == 0 {
+			transfer.ChainID = 1
+		}
+		result, err := stmt.ExecContext(ctx, transfer)
+		if err != nil {
+			return 0, errors.Wrapf(err, "failed to insert transfer for tx %s", transfer.TxHash)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to get rows affected")
+		}
+		inserted += int(rowsAffected)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to commit transaction")
+	}
+	return inserted, nil
+}
+
 func (t *treasury) GetTransferByID(ctx context.Context, id uuid.UUID) (*types.Transfer, error) {
+	thresholds, err := t.settingDB.GetDustThresholds(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get dust thresholds")
+	}
 	var transfer types.Transfer
-	err := t.getTransferByID.GetContext(ctx, &transfer, id)
+	err = t.getTransferByID.GetContext(ctx, &transfer, id, thresholds.TransferUSD)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get transfer by ID")
 	}
 	return &transfer, nil
 }
 
+func (t *treasury) GetTransfersByTxHash(ctx context.Context, chainID int64, txHash string) ([]types.CreateTransfer, error) {
+	var transfers []types.CreateTransfer
+	err := t.getTransfersByTxHash.SelectContext(ctx, &transfers, chainID, strings.ToLower(txHash))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get transfers of transaction %s", txHash)
+	}
+	if len(transfers) == 0 {
+		return []types.CreateTransfer{}, nil
+	}
+	return transfers, nil
+}
+
 // Transfer party methods
 func (t *treasury) GetTransferParties(ctx context.Context, limit, offset int) ([]types.TransferParty, error) {
 	var parties []types.TransferParty
@@ -392,6 +1002,9 @@ func (t *treasury) UpdateTransferPartyName(ctx context.Context, address, name st
 }
 
 func (t *treasury) UpsertTransferParty(ctx context.Context, party types.TransferParty) error {
+	// ENS names are only set by the resolver
+	party.EnsName = null.String{}
+	party.EnsResolvedAt = null.Time{}
 	_, err := t.upsertTransferParty.ExecContext(ctx, party)
 	if err != nil {
 		return errors.Wrap(err, "failed to upsert transfer party")
@@ -399,6 +1012,28 @@ func (t *treasury) UpsertTransferParty(ctx context.Context, party types.Transfer
 	return nil
 }
 
+func (t *treasury) GetAddressesForENSResolution(ctx context.Context, resolvedBefore time.Time, limit int) ([]string, error) {
+	var addresses []string
+	err := t.getAddressesForENS.SelectContext(ctx, &addresses, resolvedBefore, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get addresses for ENS resolution")
+	}
+	if len(addresses) == 0 {
+		return []string{}, nil
+	}
+	return addresses, nil
+}
+
+// SetTransferPartyENSName records the result of an ENS lookup, creating the party if needed.
+// An invalid ensName clears a name that no longer resolves.
+func (t *treasury) SetTransferPartyENSName(ctx context.Context, address string, ensName null.String) error {
+	_, err := t.setTransferPartyENSName.ExecContext(ctx, address, ensName)
+	if err != nil {
+		return errors.Wrap(err, "failed to set transfer party ENS name")
+	}
+	return nil
+}
+
 func (t *treasury) UpdateWalletBalances(ctx context.Context, wallet string, balances []types.WalletBalance) error {
 	// Use a transaction for batch update
 	tx, err := t.dbConn.BeginTxx(ctx, nil)
@@ -415,8 +1050,12 @@ func (t *treasury) UpdateWalletBalances(ctx context.Context, wallet string, bala
 
 	// Then insert the new balances
 	if len(balances) > 0 {
-		query := `INSERT INTO "wallet_balances" (chain_id, address, wallet, amount, usd_worth, eth_worth, last_updated) VALUES ($1, $2, $3, $4, $5, $6, $7)`
+		query := `INSERT INTO "wallet_balances" (chain_id, address, wallet, amount, usd_worth, eth_worth, last_updated,
+			usd_price, price_source, price_updated_at, valuation_policy) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
 		for _, balance := range balances {
+			if balance.ValuationPolicy == "" {
+				balance.ValuationPolicy = types.ValuationPolicyMarket
+			}
 			_, err = tx.ExecContext(ctx, query,
 				balance.ChainID,
 				balance.Address,
@@ -425,6 +1064,10 @@ func (t *treasury) UpdateWalletBalances(ctx context.Context, wallet string, bala
 				balance.UsdWorth,
 				balance.EthWorth,
 				balance.LastUpdated,
+				balance.UsdPrice,
+				balance.PriceSource,
+				balance.PriceUpdatedAt,
+				balance.ValuationPolicy,
 			)
 			if err != nil {
 				return errors.Wrap(err, "failed to insert wallet balance")
@@ -440,3 +1083,342 @@ func (t *treasury) UpdateWalletBalances(ctx context.Context, wallet string, bala
 
 	return nil
 }
+
+func (t *treasury) UpdateAssetPrices(ctx context.Context, chainID int64, valuations map[string]types.Valuation) error {
+	tx, err := t.dbConn.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	for address, valuation := range valuations {
+		if !valuation.UsdPrice.Valid {
+			continue
+		}
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO asset_prices (chain_id, address, usd_price, source, updated_at) VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (chain_id, address) DO UPDATE SET
+				usd_price = EXCLUDED.usd_price,
+				source = EXCLUDED.source,
+				updated_at = EXCLUDED.updated_at`,
+			chainID, address, valuation.UsdPrice, valuation.Source, valuation.UpdatedAt)
+		if err != nil {
+			return errors.Wrapf(err, "failed to update price of %s", address)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, "failed to commit transaction")
+	}
+	return nil
+}
+
+func (t *treasury) GetAssetStatuses(ctx context.Context) ([]types.AssetStatusEntry, error) {
+	var statuses []types.AssetStatusEntry
+	err := t.getAssetStatuses.SelectContext(ctx, &statuses)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get asset statuses")
+	}
+	if len(statuses) == 0 {
+		return []types.AssetStatusEntry{}, nil
+	}
+	return statuses, nil
+}
+
+func (t *treasury) SetAssetStatus(ctx context.Context, status types.AssetStatusEntry) error {
+	if status.ChainID == 0 {
+		status.ChainID = 1
+	}
+	_, err := t.setAssetStatus.ExecContext(ctx, status)
+	if err != nil {
+		return errors.Wrap(err, "failed to set asset status")
+	}
+	return nil
+}
+
+// DeleteAssetStatus clears the status set for an asset, so it is checked by the spam heuristics again
+func (t *treasury) DeleteAssetStatus(ctx context.Context, chainID int64, address string) error {
+	result, err := t.deleteAssetStatus.ExecContext(ctx, chainID, address)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete asset status")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to get rows affected")
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("asset status not found")
+	}
+
+	return nil
+}
+
+func (t *treasury) GetValuationPolicies(ctx context.Context) ([]types.AssetValuationPolicy, error) {
+	var policies []types.AssetValuationPolicy
+	err := t.getValuationPolicies.SelectContext(ctx, &policies)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get valuation policies")
+	}
+	if len(policies) == 0 {
+		return []types.AssetValuationPolicy{}, nil
+	}
+	return policies, nil
+}
+
+func (t *treasury) SetValuationPolicy(ctx context.Context, policy types.AssetValuationPolicy) error {
+	if policy.ChainID == 0 {
+		policy.ChainID = 1
+	}
+	_, err := t.setValuationPolicy.ExecContext(ctx, policy)
+	if err != nil {
+		return errors.Wrap(err, "failed to set valuation policy")
+	}
+	return nil
+}
+
+// DeleteValuationPolicy removes the policy for an asset, so it is valued at the market price again
+func (t *treasury) DeleteValuationPolicy(ctx context.Context, chainID int64, address string) error {
+	result, err := t.deleteValuationPolicy.ExecContext(ctx, chainID, address)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete valuation policy")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to get rows affected")
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("valuation policy not found")
+	}
+
+	return nil
+}
+
+func (t *treasury) GetWalletPositions(ctx context.Context) ([]types.Position, error) {
+	var positions []types.Position
+	err := t.getWalletPositions.SelectContext(ctx, &positions)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get wallet positions")
+	}
+	if len(positions) == 0 {
+		return []types.Position{}, nil
+	}
+	return positions, nil
+}
+
+// UpdateWalletPositions replaces all of the positions of a wallet
+func (t *treasury) UpdateWalletPositions(ctx context.Context, wallet string, positions []types.Position) error {
+	tx, err := t.dbConn.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM wallet_positions WHERE wallet = $1", wallet)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete existing wallet positions")
+	}
+
+	for _, position := range positions {
+		_, err = tx.NamedExecContext(ctx, `
+			INSERT INTO wallet_positions (chain_id, wallet, protocol, position_id, name, token_address, underlying, usd_worth, last_updated)
+			VALUES (:chain_id, :wallet, :protocol, :position_id, :name, :token_address, :underlying, :usd_worth, :last_updated)`, position)
+		if err != nil {
+			return errors.Wrapf(err, "failed to insert %s position %s", position.Protocol, position.PositionID)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, "failed to commit transaction")
+	}
+	return nil
+}
+
+func (t *treasury) GetOffchainBalances(ctx context.Context) ([]types.OffchainBalance, error) {
+	var balances []types.OffchainBalance
+	err := t.getOffchainBalances.SelectContext(ctx, &balances)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get off-chain balances")
+	}
+	for i := range balances {
+		balances[i].Balance = TrimZeros(balances[i].Balance)
+		balances[i].SelfReported = true
+	}
+	if len(balances) == 0 {
+		return []types.OffchainBalance{}, nil
+	}
+	return balances, nil
+}
+
+func (t *treasury) GetExchangeBalances(ctx context.Context) ([]types.ExchangeBalance, error) {
+	var balances []types.ExchangeBalance
+	err := t.getExchangeBalances.SelectContext(ctx, &balances)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get exchange balances")
+	}
+	if len(balances) == 0 {
+		return []types.ExchangeBalance{}, nil
+	}
+	for i := range balances {
+		balances[i].Amount = TrimZeros(balances[i].Amount)
+	}
+	return balances, nil
+}
+
+type walletGroupMember struct {
+	GroupID uuid.UUID `db:"group_id"`
+	Wallet  string    `db:"wallet"`
+}
+
+func (t *treasury) getGroupMembers(ctx context.Context) ([]walletGroupMember, error) {
+	var members []walletGroupMember
+	err := t.getWalletGroupMembers.SelectContext(ctx, &members)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get wallet group members")
+	}
+	return members, nil
+}
+
+func (t *treasury) GetWalletGroups(ctx context.Context) ([]types.WalletGroup, error) {
+	var groups []types.WalletGroup
+	err := t.getWalletGroups.SelectContext(ctx, &groups)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get wallet groups")
+	}
+	if len(groups) == 0 {
+		return []types.WalletGroup{}, nil
+	}
+
+	members, err := t.getGroupMembers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	groupWallets := map[uuid.UUID][]string{}
+	for _, member := range members {
+		groupWallets[member.GroupID] = append(groupWallets[member.GroupID], member.Wallet)
+	}
+	for i := range groups {
+		groups[i].Wallets = groupWallets[groups[i].ID]
+		if groups[i].Wallets == nil {
+			groups[i].Wallets = []string{}
+		}
+	}
+	return groups, nil
+}
+
+func (t *treasury) GetWalletGroupByID(ctx context.Context, id uuid.UUID) (*types.WalletGroup, error) {
+	var group types.WalletGroup
+	err := t.getWalletGroupByID.GetContext(ctx, &group, id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get wallet group (%s)", id)
+	}
+
+	members, err := t.getGroupMembers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	group.Wallets = []string{}
+	for _, member := range members {
+		if member.GroupID == id {
+			group.Wallets = append(group.Wallets, member.Wallet)
+		}
+	}
+	return &group, nil
+}
+
+func (t *treasury) CreateWalletGroup(ctx context.Context, group types.WalletGroup) error {
+	tx, err := t.dbConn.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	_, err = tx.NamedExecContext(ctx, `
+		INSERT INTO wallet_groups (id, name, description, created_at, updated_at)
+		VALUES (:id, :name, :description, :created_at, :updated_at)`, group)
+	if err != nil {
+		return errors.Wrap(err, "failed to create wallet group")
+	}
+
+	if err := insertGroupMembers(ctx, tx, group); err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, "failed to commit transaction")
+	}
+	return nil
+}
+
+func (t *treasury) UpdateWalletGroup(ctx context.Context, group types.WalletGroup) error {
+	tx, err := t.dbConn.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	result, err := tx.NamedExecContext(ctx, `
+		UPDATE wallet_groups SET name = :name, description = :description, updated_at = NOW() WHERE id = :id`, group)
+	if err != nil {
+		return errors.Wrap(err, "failed to update wallet group")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to get rows affected")
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("wallet group not found")
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM wallet_group_members WHERE group_id = $1", group.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete existing wallet group members")
+	}
+
+	if err := insertGroupMembers(ctx, tx, group); err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, "failed to commit transaction")
+	}
+	return nil
+}
+
+func insertGroupMembers(ctx context.Context, tx *sqlx.Tx, group types.WalletGroup) error {
+	for _, wallet := range group.Wallets {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO wallet_group_members (group_id, wallet) VALUES ($1, $2) ON CONFLICT DO NOTHING`, group.ID, wallet)
+		if err != nil {
+			return errors.Wrapf(err, "failed to add wallet %s to group", wallet)
+		}
+	}
+	return nil
+}
+
+func (t *treasury) DeleteWalletGroup(ctx context.Context, id uuid.UUID) error {
+	result, err := t.deleteWalletGroup.ExecContext(ctx, id)
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete wallet group (%s)", id)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to get rows affected")
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("wallet group not found")
+	}
+
+	return nil
+}
@@ -9,7 +9,9 @@ import (
 	"time"
 
 	"github.com/ETHCF/ethutils"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
 
 	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
 )
@@ -183,7 +185,7 @@ func Test_TreasuryDB_TransferOperations(t *testing.T) {
 	require.NoError(t, err)
 
 	// Get transfers
-	transfers, err := db.GetTransfers(t.Context(), 10, 0)
+	transfers, err := db.GetTransfers(t.Context(), 10, 0, true, uuid.NullUUID{})
 	require.NoError(t, err)
 	require.NotEmpty(t, transfers)
 
@@ -208,6 +210,15 @@ func Test_TreasuryDB_TransferOperations(t *testing.T) {
 	require.Equal(t, foundTransfer.ID, retrieved.ID)
 	require.Equal(t, createTransfer.TxHash, retrieved.TxHash)
 
+	// Get the transfers of its transaction
+	byTx, err := db.GetTransfersByTxHash(t.Context(), createTransfer.ChainID, createTransfer.TxHash)
+	require.NoError(t, err)
+	require.Len(t, byTx, 1)
+	require.Equal(t, createTransfer.ToAddress, byTx[0].ToAddress)
+	byTx, err = db.GetTransfersByTxHash(t.Context(), createTransfer.ChainID, ethutils.GenRandEVMHash())
+	require.NoError(t, err)
+	require.Empty(t, byTx)
+
 	// Clean up
 	_, err = dbConn.ExecContext(t.Context(), "DELETE FROM transfers WHERE id = $1", foundTransfer.ID)
 	require.NoError(t, err)
@@ -267,6 +278,72 @@ func Test_TreasuryDB_TransferPartyOperations(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func Test_TreasuryDB_ENSNames(t *testing.T) {
+	var (
+		db             = GetTestTreasuryDB(t)
+		namedAddress   = ethutils.GenRandEVMAddr()
+		unnamedAddress = ethutils.GenRandEVMAddr()
+		createTransfer = types.CreateTransfer{
+			TxHash:         ethutils.GenRandEVMHash(),
+			BlockNumber:    12545678,
+			BlockTimestamp: time.Now().Unix(),
+			FromAddress:    namedAddress,
+			ToAddress:      unnamedAddress,
+			Asset:          ethutils.GenRandEVMAddr(),
+			Amount:         "100",
+			Direction:      types.TransferTypeOutgoing,
+		}
+	)
+
+	err := db.CreateTransfer(t.Context(), createTransfer)
+	require.NoError(t, err)
+
+	// Both counterparties have never been resolved
+	addresses, err := db.GetAddressesForENSResolution(t.Context(), time.Now(), 1000)
+	require.NoError(t, err)
+	require.Contains(t, addresses, namedAddress)
+	require.Contains(t, addresses, unnamedAddress)
+
+	// An admin name is kept when the ENS name is set
+	err = db.UpsertTransferParty(t.Context(), types.TransferParty{Address: namedAddress, Name: "Admin Name"})
+	require.NoError(t, err)
+	err = db.SetTransferPartyENSName(t.Context(), namedAddress, null.StringFrom("treasury.eth"))
+	require.NoError(t, err)
+	err = db.SetTransferPartyENSName(t.Context(), unnamedAddress, null.StringFrom("payee.eth"))
+	require.NoError(t, err)
+
+	party, err := db.GetTransferPartyByAddress(t.Context(), namedAddress)
+	require.NoError(t, err)
+	require.Equal(t, "Admin Name", party.Name)
+	require.Equal(t, "treasury.eth", party.EnsName.String)
+	require.True(t, party.EnsResolvedAt.Valid)
+
+	// Names resolved after the cutoff are not returned again
+	addresses, err = db.GetAddressesForENSResolution(t.Context(), time.Now().Add(-time.Hour), 1000)
+	require.NoError(t, err)
+	require.NotContains(t, addresses, namedAddress)
+	require.NotContains(t, addresses, unnamedAddress)
+
+	// The admin name is shown over the ENS name, which is used when there is no admin name
+	transfers, err := db.GetTransfers(t.Context(), 1000, 0, true, uuid.NullUUID{})
+	require.NoError(t, err)
+	var found bool
+	for _, tr := range transfers {
+		if tr.TxHash == createTransfer.TxHash {
+			found = true
+			require.Equal(t, "Admin Name", tr.PayerName)
+			require.Equal(t, "payee.eth", tr.PayeeName)
+		}
+	}
+	require.True(t, found, "created transfer not found in retrieved transfers")
+
+	// Clean up
+	_, err = dbConn.ExecContext(t.Context(), "DELETE FROM transfers WHERE tx_hash = $1", createTransfer.TxHash)
+	require.NoError(t, err)
+	_, err = dbConn.ExecContext(t.Context(), "DELETE FROM transfer_parties WHERE address IN ($1, $2)", namedAddress, unnamedAddress)
+	require.NoError(t, err)
+}
+
 func Test_TreasuryDB_GetTreasuryResponse(t *testing.T) {
 	var (
 		db         = GetTestTreasuryDB(t)
@@ -315,7 +392,7 @@ func Test_TreasuryDB_GetTreasuryResponse(t *testing.T) {
 	require.NoError(t, err)
 
 	// Get treasury response
-	response, err := db.GetTreasuryResponse(t.Context())
+	response, err := db.GetTreasuryResponse(t.Context(), false, uuid.NullUUID{})
 	require.NoError(t, err)
 	require.NotNil(t, response)
 	require.Equal(t, "Test Organization", response.OrganizationName)
@@ -331,3 +408,523 @@ func Test_TreasuryDB_GetTreasuryResponse(t *testing.T) {
 	_, err = dbConn.ExecContext(t.Context(), "DELETE FROM wallets WHERE address = $1", wallet.Address)
 	require.NoError(t, err)
 }
+
+func Test_TreasuryDB_AssetFiltering(t *testing.T) {
+	var (
+		db         = GetTestTreasuryDB(t)
+		walletAddr = ethutils.GenRandEVMAddr()
+		spamAddr   = ethutils.GenRandEVMAddr()
+		dustAddr   = ethutils.GenRandEVMAddr()
+		dust       = types.Asset{
+			ChainID:  1,
+			Address:  dustAddr,
+			Name:     "Test Dust Token",
+			Symbol:   "TDT",
+			Decimals: 6,
+		}
+		spamTransfer = types.CreateTransfer{
+			TxHash:         ethutils.GenRandEVMHash(),
+			BlockNumber:    12545679,
+			BlockTimestamp: time.Now().Unix(),
+			FromAddress:    ethutils.GenRandEVMAddr(),
+			ToAddress:      walletAddr,
+			Asset:          spamAddr,
+			Amount:         "1000000000000000000000",
+			Direction:      types.TransferTypeIncoming,
+		}
+		dustTransfer = types.CreateTransfer{
+			TxHash:         ethutils.GenRandEVMHash(),
+			BlockNumber:    12545679,
+			BlockTimestamp: time.Now().Unix(),
+			FromAddress:    ethutils.GenRandEVMAddr(),
+			ToAddress:      walletAddr,
+			Asset:          dustAddr,
+			Amount:         "500000", // 0.5 tokens at $1
+			Direction:      types.TransferTypeIncoming,
+		}
+	)
+
+	err := db.AddAsset(t.Context(), dust)
+	require.NoError(t, err)
+	err = db.UpdateAssetPrices(t.Context(), 1, map[string]types.Valuation{dustAddr: {
+		Policy:    types.ValuationPolicyMarket,
+		UsdPrice:  null.FloatFrom(1),
+		Source:    null.StringFrom(types.PriceSourceMarket),
+		UpdatedAt: null.TimeFrom(time.Now()),
+	}})
+	require.NoError(t, err)
+	require.NoError(t, db.CreateTransfer(t.Context(), spamTransfer))
+	require.NoError(t, db.CreateTransfer(t.Context(), dustTransfer))
+
+	findTransfer := func(transfers []types.Transfer, txHash string) *types.Transfer {
+		for _, tr := range transfers {
+			if tr.TxHash == txHash {
+				return &tr
+			}
+		}
+		return nil
+	}
+
+	// Both transfers are only returned when filtered transfers are included
+	transfers, err := db.GetTransfers(t.Context(), 1000, 0, false, uuid.NullUUID{})
+	require.NoError(t, err)
+	require.Nil(t, findTransfer(transfers, spamTransfer.TxHash))
+	require.Nil(t, findTransfer(transfers, dustTransfer.TxHash))
+
+	transfers, err = db.GetTransfers(t.Context(), 1000, 0, true, uuid.NullUUID{})
+	require.NoError(t, err)
+	found := findTransfer(transfers, spamTransfer.TxHash)
+	require.NotNil(t, found)
+	require.Equal(t, types.FilterReasonSuspectedSpam, found.FilterReason.String)
+	found = findTransfer(transfers, dustTransfer.TxHash)
+	require.NotNil(t, found)
+	require.Equal(t, types.FilterReasonDust, found.FilterReason.String)
+
+	// Trusting the unknown token stops it being treated as spam
+	err = db.SetAssetStatus(t.Context(), types.AssetStatusEntry{Address: spamAddr, Status: types.AssetStatusTrusted})
+	require.NoError(t, err)
+	transfers, err = db.GetTransfers(t.Context(), 1000, 0, false, uuid.NullUUID{})
+	require.NoError(t, err)
+	require.NotNil(t, findTransfer(transfers, spamTransfer.TxHash))
+
+	// Marking it as spam filters it again and removes its balance from the totals
+	err = db.SetAssetStatus(t.Context(), types.AssetStatusEntry{Address: spamAddr, Status: types.AssetStatusSpam, Reason: null.StringFrom("airdrop")})
+	require.NoError(t, err)
+	transfers, err = db.GetTransfers(t.Context(), 1000, 0, true, uuid.NullUUID{})
+	require.NoError(t, err)
+	found = findTransfer(transfers, spamTransfer.TxHash)
+	require.NotNil(t, found)
+	require.Equal(t, types.FilterReasonSpam, found.FilterReason.String)
+
+	statuses, err := db.GetAssetStatuses(t.Context())
+	require.NoError(t, err)
+	var statusFound bool
+	for _, status := range statuses {
+		if status.Address == spamAddr {
+			statusFound = true
+			require.Equal(t, types.AssetStatusSpam, status.Status)
+			require.Equal(t, "airdrop", status.Reason.String)
+		}
+	}
+	require.True(t, statusFound, "asset status not found")
+
+	before, err := db.GetTreasuryResponse(t.Context(), true, uuid.NullUUID{})
+	require.NoError(t, err)
+	_, err = dbConn.ExecContext(t.Context(),
+		"INSERT INTO wallet_balances (chain_id, address, wallet, amount, usd_worth, eth_worth, last_updated) VALUES ($1, $2, $3, $4, $5, $6, $7)",
+		1, spamAddr, walletAddr, "1000", 5000.0, "0", time.Now())
+	require.NoError(t, err)
+
+	after, err := db.GetTreasuryResponse(t.Context(), true, uuid.NullUUID{})
+	require.NoError(t, err)
+	require.InDelta(t, before.TotalValueUsd, after.TotalValueUsd, 0.001)
+
+	balances, err := db.GetWalletBalances(t.Context(), false, uuid.NullUUID{})
+	require.NoError(t, err)
+	for _, balance := range balances {
+		require.NotEqual(t, spamAddr, balance.Address)
+	}
+
+	err = db.DeleteAssetStatus(t.Context(), 1, spamAddr)
+	require.NoError(t, err)
+	err = db.DeleteAssetStatus(t.Context(), 1, spamAddr)
+	require.Error(t, err)
+
+	// Clean up
+	_, err = dbConn.ExecContext(t.Context(), "DELETE FROM wallet_balances WHERE wallet = $1", walletAddr)
+	require.NoError(t, err)
+	_, err = dbConn.ExecContext(t.Context(), "DELETE FROM transfers WHERE tx_hash IN ($1, $2)", spamTransfer.TxHash, dustTransfer.TxHash)
+	require.NoError(t, err)
+	_, err = dbConn.ExecContext(t.Context(), "DELETE FROM asset_prices WHERE address = $1", dustAddr)
+	require.NoError(t, err)
+	_, err = dbConn.ExecContext(t.Context(), "DELETE FROM assets WHERE address = $1", dustAddr)
+	require.NoError(t, err)
+}
+
+func Test_TreasuryDB_ValuationPolicies(t *testing.T) {
+	var (
+		db         = GetTestTreasuryDB(t)
+		walletAddr = ethutils.GenRandEVMAddr()
+		pricedAddr = ethutils.GenRandEVMAddr()
+		lockedAddr = ethutils.GenRandEVMAddr()
+		policy     = types.AssetValuationPolicy{
+			Address:         lockedAddr,
+			Policy:          types.ValuationPolicyDiscount,
+			DiscountPercent: null.FloatFrom(50),
+			Notes:           null.StringFrom("Locked until 2027"),
+		}
+		now = time.Now().Truncate(time.Second)
+	)
+
+	err := db.SetValuationPolicy(t.Context(), policy)
+	require.NoError(t, err)
+
+	policies, err := db.GetValuationPolicies(t.Context())
+	require.NoError(t, err)
+	var found bool
+	for _, p := range policies {
+		if p.Address == lockedAddr {
+			found = true
+			require.Equal(t, int64(1), p.ChainID)
+			require.Equal(t, types.ValuationPolicyDiscount, p.Policy)
+			require.Equal(t, 50.0, p.DiscountPercent.Float64)
+			require.False(t, p.ManualPrice.Valid)
+		}
+	}
+	require.True(t, found, "valuation policy not found")
+
+	// Balances keep the price used, and excluded assets are left out of the totals
+	_, err = dbConn.ExecContext(t.Context(), "INSERT INTO wallets (address) VALUES ($1)", walletAddr)
+	require.NoError(t, err)
+
+	before, err := db.GetTreasuryResponse(t.Context(), true, uuid.NullUUID{})
+	require.NoError(t, err)
+
+	err = db.UpdateWalletBalances(t.Context(), walletAddr, []types.WalletBalance{
+		{
+			ChainID:         1,
+			Address:         pricedAddr,
+			Wallet:          walletAddr,
+			Amount:          "10",
+			UsdWorth:        20,
+			EthWorth:        "0.01",
+			LastUpdated:     now,
+			UsdPrice:        null.FloatFrom(2),
+			PriceSource:     null.StringFrom(types.PriceSourceManual),
+			PriceUpdatedAt:  null.TimeFrom(now),
+			ValuationPolicy: types.ValuationPolicyManual,
+		},
+		{
+			ChainID:         1,
+			Address:         lockedAddr,
+			Wallet:          walletAddr,
+			Amount:          "10",
+			UsdWorth:        1000,
+			EthWorth:        "0.5",
+			LastUpdated:     now,
+			UsdPrice:        null.FloatFrom(100),
+			PriceSource:     null.StringFrom(types.PriceSourceMarket),
+			PriceUpdatedAt:  null.TimeFrom(now),
+			ValuationPolicy: types.ValuationPolicyExcluded,
+		},
+	})
+	require.NoError(t, err)
+
+	after, err := db.GetTreasuryResponse(t.Context(), true, uuid.NullUUID{})
+	require.NoError(t, err)
+	require.InDelta(t, before.TotalValueUsd+20, after.TotalValueUsd, 0.001)
+
+	var priced *types.WalletBalance
+	for _, balance := range after.WalletBalances {
+		if balance.Address == pricedAddr && balance.Wallet == walletAddr {
+			priced = &balance
+		}
+	}
+	require.NotNil(t, priced)
+	require.Equal(t, types.ValuationPolicyManual, priced.ValuationPolicy)
+	require.Equal(t, 2.0, priced.UsdPrice.Float64)
+	require.Equal(t, types.PriceSourceManual, priced.PriceSource.String)
+	require.WithinDuration(t, now, priced.PriceUpdatedAt.Time, time.Second)
+
+	err = db.DeleteValuationPolicy(t.Context(), 1, lockedAddr)
+	require.NoError(t, err)
+	err = db.DeleteValuationPolicy(t.Context(), 1, lockedAddr)
+	require.Error(t, err)
+
+	// Clean up
+	_, err = dbConn.ExecContext(t.Context(), "DELETE FROM wallets WHERE address = $1", walletAddr)
+	require.NoError(t, err)
+}
+
+func Test_TreasuryDB_WalletPositions(t *testing.T) {
+	var (
+		db         = GetTestTreasuryDB(t)
+		walletAddr = ethutils.GenRandEVMAddr()
+		lpToken    = ethutils.GenRandEVMAddr()
+		position   = types.Position{
+			ChainID:      1,
+			Wallet:       walletAddr,
+			Protocol:     "uniswap-v2",
+			PositionID:   lpToken,
+			Name:         "Uniswap v2 LP",
+			TokenAddress: null.StringFrom(lpToken),
+			Underlying: types.PositionAssets{
+				{Address: ethutils.GenRandEVMAddr(), Amount: "1.5", UsdPrice: null.FloatFrom(2), UsdWorth: 3},
+				{Address: ethutils.GenRandEVMAddr(), Amount: "10", UsdWorth: 0},
+			},
+			UsdWorth:    3,
+			LastUpdated: time.Now(),
+		}
+	)
+
+	_, err := dbConn.ExecContext(t.Context(), "INSERT INTO wallets (address) VALUES ($1)", walletAddr)
+	require.NoError(t, err)
+
+	before, err := db.GetTreasuryResponse(t.Context(), false, uuid.NullUUID{})
+	require.NoError(t, err)
+
+	err = db.UpdateWalletPositions(t.Context(), walletAddr, []types.Position{position})
+	require.NoError(t, err)
+
+	positions, err := db.GetWalletPositions(t.Context())
+	require.NoError(t, err)
+	var found *types.Position
+	for _, p := range positions {
+		if p.Wallet == walletAddr {
+			found = &p
+		}
+	}
+	require.NotNil(t, found, "position not found")
+	require.Equal(t, position.Name, found.Name)
+	require.Equal(t, lpToken, found.TokenAddress.String)
+	require.Equal(t, position.Underlying, found.Underlying)
+
+	// Positions add to the treasury total
+	after, err := db.GetTreasuryResponse(t.Context(), false, uuid.NullUUID{})
+	require.NoError(t, err)
+	require.InDelta(t, before.TotalValueUsd+3, after.TotalValueUsd, 0.001)
+
+	// Updating replaces all of the wallet's positions
+	err = db.UpdateWalletPositions(t.Context(), walletAddr, []types.Position{})
+	require.NoError(t, err)
+	positions, err = db.GetWalletPositions(t.Context())
+	require.NoError(t, err)
+	for _, p := range positions {
+		require.NotEqual(t, walletAddr, p.Wallet)
+	}
+
+	// Clean up
+	_, err = dbConn.ExecContext(t.Context(), "DELETE FROM wallets WHERE address = $1", walletAddr)
+	require.NoError(t, err)
+}
+
+func Test_TreasuryDB_WalletGroups(t *testing.T) {
+	var (
+		db        = GetTestTreasuryDB(t)
+		opsWallet = ethutils.GenRandEVMAddr()
+		otherAddr = ethutils.GenRandEVMAddr()
+		assetAddr = ethutils.GenRandEVMAddr()
+		payee     = ethutils.GenRandEVMAddr()
+		group     = types.WalletGroup{
+			ID:          uuid.New(),
+			Name:        "Operations " + opsWallet[:10],
+			Description: null.StringFrom("Day to day spending"),
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+			Wallets:     []string{opsWallet},
+		}
+	)
+
+	require.NoError(t, db.AddWallet(t.Context(), types.Wallet{Address: opsWallet, Name: "Ops", Type: null.StringFrom("safe")}))
+	require.NoError(t, db.AddWallet(t.Context(), types.Wallet{Address: otherAddr}))
+
+	_, err := dbConn.ExecContext(t.Context(),
+		"INSERT INTO assets (chain_id, address, name, symbol, decimals) VALUES (1, $1, 'Group Token', 'GRP', 18)", assetAddr)
+	require.NoError(t, err)
+	for _, wallet := range []string{opsWallet, otherAddr} {
+		_, err = dbConn.ExecContext(t.Context(),
+			"INSERT INTO wallet_balances (chain_id, address, wallet, amount, usd_worth, eth_worth, last_updated) VALUES (1, $1, $2, '100', 1000, '0.5', NOW())",
+			assetAddr, wallet)
+		require.NoError(t, err)
+	}
+	transfer := types.CreateTransfer{
+		ChainID:        1,
+		TxHash:         ethutils.GenRandEVMHash(),
+		BlockNumber:    1,
+		BlockTimestamp: time.Now().Unix(),
+		FromAddress:    opsWallet,
+		ToAddress:      payee,
+		Asset:          assetAddr,
+		Amount:         "10",
+		Direction:      types.TransferTypeOutgoing,
+	}
+	require.NoError(t, db.CreateTransfer(t.Context(), transfer))
+
+	require.NoError(t, db.CreateWalletGroup(t.Context(), group))
+
+	// Wallet metadata and membership
+	require.NoError(t, db.UpdateWallet(t.Context(), otherAddr, types.UpdateWalletRequest{Name: null.StringFrom("Grants"), Type: null.StringFrom("eoa")}))
+	wallets, err := db.GetWallets(t.Context())
+	require.NoError(t, err)
+	for _, wallet := range wallets {
+		switch wallet.Address {
+		case opsWallet:
+			require.Equal(t, "Ops", wallet.Name)
+			require.Equal(t, int64(1), wallet.ChainID)
+			require.Equal(t, []uuid.UUID{group.ID}, wallet.GroupIDs)
+		case otherAddr:
+			require.Equal(t, "Grants", wallet.Name)
+			require.Equal(t, "eoa", wallet.Type.String)
+			require.Empty(t, wallet.GroupIDs)
+		}
+	}
+	require.Error(t, db.UpdateWallet(t.Context(), ethutils.GenRandEVMAddr(), types.UpdateWalletRequest{Name: null.StringFrom("Missing")}))
+
+	got, err := db.GetWalletGroupByID(t.Context(), group.ID)
+	require.NoError(t, err)
+	require.Equal(t, group.Name, got.Name)
+	require.Equal(t, []string{opsWallet}, got.Wallets)
+
+	// Scoped views only include the group's wallets
+	scope := uuid.NullUUID{UUID: group.ID, Valid: true}
+	balances, err := db.GetWalletBalances(t.Context(), true, scope)
+	require.NoError(t, err)
+	for _, balance := range balances {
+		require.Equal(t, opsWallet, balance.Wallet)
+	}
+	require.Len(t, balances, 1)
+
+	transfers, err := db.GetTransfers(t.Context(), 1000, 0, true, scope)
+	require.NoError(t, err)
+	require.Len(t, transfers, 1)
+	require.Equal(t, transfer.TxHash, transfers[0].TxHash)
+
+	response, err := db.GetTreasuryResponse(t.Context(), true, scope)
+	require.NoError(t, err)
+	require.NotNil(t, response.Group)
+	require.Len(t, response.Wallets, 1)
+	require.Len(t, response.RecentTransfers, 1)
+
+	// Replacing the members moves the scope to the other wallet
+	group.Wallets = []string{otherAddr}
+	require.NoError(t, db.UpdateWalletGroup(t.Context(), group))
+	transfers, err = db.GetTransfers(t.Context(), 1000, 0, true, scope)
+	require.NoError(t, err)
+	require.Empty(t, transfers)
+
+	// Members must be treasury wallets
+	group.Wallets = []string{ethutils.GenRandEVMAddr()}
+	require.Error(t, db.UpdateWalletGroup(t.Context(), group))
+
+	require.NoError(t, db.DeleteWalletGroup(t.Context(), group.ID))
+	require.Error(t, db.DeleteWalletGroup(t.Context(), group.ID))
+	_, err = db.GetTreasuryResponse(t.Context(), true, scope)
+	require.Error(t, err)
+
+	// Clean up
+	_, err = dbConn.ExecContext(t.Context(), "DELETE FROM transfers WHERE tx_hash = $1", transfer.TxHash)
+	require.NoError(t, err)
+	_, err = dbConn.ExecContext(t.Context(), "DELETE FROM wallet_balances WHERE address = $1", assetAddr)
+	require.NoError(t, err)
+	_, err = dbConn.ExecContext(t.Context(), "DELETE FROM assets WHERE address = $1", assetAddr)
+	require.NoError(t, err)
+	_, err = dbConn.ExecContext(t.Context(), "DELETE FROM wallets WHERE address IN ($1, $2)", opsWallet, otherAddr)
+	require.NoError(t, err)
+}
+
+func Test_TreasuryDB_WalletVerification(t *testing.T) {
+	var (
+		db        = GetTestTreasuryDB(t)
+		walletAdr = ethutils.GenRandEVMAddr()
+		challenge = types.WalletChallenge{
+			Wallet:   walletAdr,
+			Nonce:    uuid.New(),
+			IssuedAt: time.Now().UTC().Truncate(time.Second),
+		}
+	)
+
+	require.NoError(t, db.AddWallet(t.Context(), types.Wallet{Address: walletAdr}))
+
+	wallet, err := db.GetWallet(t.Context(), walletAdr)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), wallet.ChainID)
+
+	// Issuing a new challenge replaces the open one
+	require.NoError(t, db.SetWalletChallenge(t.Context(), types.WalletChallenge{Wallet: walletAdr, Nonce: uuid.New(), IssuedAt: time.Now()}))
+	require.NoError(t, db.SetWalletChallenge(t.Context(), challenge))
+	got, err := db.GetWalletChallenge(t.Context(), walletAdr)
+	require.NoError(t, err)
+	require.Equal(t, challenge.Nonce, got.Nonce)
+	require.True(t, challenge.IssuedAt.Equal(got.IssuedAt))
+
+	wallets, err := db.GetWallets(t.Context())
+	require.NoError(t, err)
+	for _, w := range wallets {
+		if w.Address == walletAdr {
+			require.False(t, w.Verified)
+			require.Nil(t, w.Verification)
+		}
+	}
+
+	verification := types.WalletVerification{
+		Wallet:        walletAdr,
+		Method:        types.WalletProofECDSA,
+		ChallengeHash: ethutils.GenRandEVMHash(),
+		Signature:     "0x1234",
+		VerifiedBy:    ethutils.GenRandEVMAddr(),
+		VerifiedAt:    time.Now(),
+	}
+	require.NoError(t, db.SaveWalletVerification(t.Context(), verification))
+
+	// The challenge is consumed by the verification
+	_, err = db.GetWalletChallenge(t.Context(), walletAdr)
+	require.Error(t, err)
+
+	wallets, err = db.GetWallets(t.Context())
+	require.NoError(t, err)
+	found := false
+	for _, w := range wallets {
+		if w.Address == walletAdr {
+			found = true
+			require.True(t, w.Verified)
+			require.NotNil(t, w.Verification)
+			require.Equal(t, verification.ChallengeHash, w.Verification.ChallengeHash)
+			require.Equal(t, types.WalletProofECDSA, w.Verification.Method)
+		}
+	}
+	require.True(t, found)
+
+	// Clean up
+	_, err = dbConn.ExecContext(t.Context(), "DELETE FROM wallets WHERE address = $1", walletAdr)
+	require.NoError(t, err)
+}
+
+func Test_TreasuryDB_Composition(t *testing.T) {
+	var (
+		db        = GetTestTreasuryDB(t)
+		assetAddr = ethutils.GenRandEVMAddr()
+		day       = time.Date(2001, 2, 3, 0, 0, 0, 0, time.UTC)
+	)
+
+	// Assets default to the other class
+	require.NoError(t, db.AddAsset(t.Context(), types.Asset{ChainID: 1, Address: assetAddr, Name: "Class Token", Symbol: "CLS", Decimals: 18}))
+	require.NoError(t, db.SetAssetClass(t.Context(), 1, assetAddr, types.AssetClassGovernance))
+	require.Error(t, db.SetAssetClass(t.Context(), 1, ethutils.GenRandEVMAddr(), types.AssetClassGovernance))
+
+	assets, err := db.GetAssets(t.Context())
+	require.NoError(t, err)
+	for _, asset := range assets {
+		if asset.Address == assetAddr {
+			require.Equal(t, types.AssetClassGovernance, asset.Class)
+		}
+	}
+
+	snapshot := types.CompositionSnapshot{
+		SnapshotDate:  day,
+		TotalValueUsd: 100,
+		Composition: types.TreasuryComposition{
+			TotalValueUsd: 100,
+			ByClass:       []types.CompositionEntry{{Key: "native", Name: "native", ValueUsd: 100, Percent: 100}},
+		},
+	}
+	require.NoError(t, db.SaveCompositionSnapshot(t.Context(), snapshot))
+
+	// A later snapshot from the same day replaces the earlier one
+	snapshot.TotalValueUsd = 200
+	snapshot.Composition.TotalValueUsd = 200
+	require.NoError(t, db.SaveCompositionSnapshot(t.Context(), snapshot))
+
+	snapshots, err := db.GetCompositionSnapshots(t.Context(), day)
+	require.NoError(t, err)
+	require.NotEmpty(t, snapshots)
+	require.True(t, day.Equal(snapshots[0].SnapshotDate.UTC()))
+	require.Equal(t, 200.0, snapshots[0].TotalValueUsd)
+	require.Equal(t, 200.0, snapshots[0].Composition.TotalValueUsd)
+	require.Len(t, snapshots[0].Composition.ByClass, 1)
+
+	// Clean up
+	_, err = dbConn.ExecContext(t.Context(), "DELETE FROM composition_snapshots WHERE snapshot_date = $1", day)
+	require.NoError(t, err)
+	_, err = dbConn.ExecContext(t.Context(), "DELETE FROM assets WHERE address = $1", assetAddr)
+	require.NoError(t, err)
+}
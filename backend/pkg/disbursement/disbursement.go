@@ -0,0 +1,69 @@
+package disbursement
+
+import (
+	"math/big"
+
+	"github.com/google/uuid"
+	"github.com/numbergroup/errors"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+// Plan turns the candidates into matches. A transfer which could only have paid one grant is confirmed straight away
+// when autoConfirm is set, a transfer which could have paid several grants is always proposed to each of them.
+// Candidates have to be grouped by transfer, in the order they should be matched.
+func Plan(candidates []types.DisbursementCandidate, autoConfirm bool) []types.DisbursementMatch {
+	grants := map[uuid.UUID]int{}
+	for _, candidate := range candidates {
+		grants[candidate.TransferID]++
+	}
+
+	matches := make([]types.DisbursementMatch, 0, len(candidates))
+	for _, candidate := range candidates {
+		status := types.DisbursementMatchProposed
+		if autoConfirm && grants[candidate.TransferID] == 1 {
+			status = types.DisbursementMatchConfirmed
+		}
+		matches = append(matches, types.DisbursementMatch{
+			TransferID: candidate.TransferID,
+			GrantID:    candidate.GrantID,
+			Status:     status,
+		})
+	}
+	return matches
+}
+
+// ValidateSplit checks that a transfer of the given amount can be divided between grants as allocated. Each grant
+// gets a positive amount once, and the amounts add up to the whole transfer.
+func ValidateSplit(transferAmount string, allocations []types.SplitAllocation) error {
+	total, ok := new(big.Rat).SetString(transferAmount)
+	if !ok {
+		return errors.Errorf("invalid transfer amount %q", transferAmount)
+	}
+	if len(allocations) == 0 {
+		return errors.New("at least one allocation is required")
+	}
+
+	seen := map[uuid.UUID]bool{}
+	sum := new(big.Rat)
+	for _, allocation := range allocations {
+		if seen[allocation.GrantID] {
+			return errors.Errorf("grant %s is allocated more than once", allocation.GrantID)
+		}
+		seen[allocation.GrantID] = true
+
+		amount, ok := new(big.Rat).SetString(allocation.Amount)
+		if !ok {
+			return errors.Errorf("invalid amount %q for grant %s", allocation.Amount, allocation.GrantID)
+		}
+		if amount.Sign() <= 0 {
+			return errors.Errorf("amount for grant %s must be positive", allocation.GrantID)
+		}
+		sum.Add(sum, amount)
+	}
+
+	if sum.Cmp(total) != 0 {
+		return errors.Errorf("allocations add up to %s, the transfer is %s", sum.FloatString(18), total.FloatString(18))
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package disbursement
+
+import (
+	"testing"
+
+	"github.com/ETHCF/ethutils"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+func TestPlan(t *testing.T) {
+	var (
+		single    = uuid.New()
+		shared    = uuid.New()
+		grantA    = uuid.New()
+		grantB    = uuid.New()
+		candidate = func(transferID, grantID uuid.UUID) types.DisbursementCandidate {
+			return types.DisbursementCandidate{TransferID: transferID, GrantID: grantID, TxHash: ethutils.GenRandEVMHash(), Amount: "1"}
+		}
+		candidates = []types.DisbursementCandidate{
+			candidate(single, grantA),
+			candidate(shared, grantA),
+			candidate(shared, grantB),
+		}
+	)
+
+	matches := Plan(candidates, false)
+	require.Len(t, matches, 3)
+	for _, match := range matches {
+		require.Equal(t, types.DisbursementMatchProposed, match.Status)
+	}
+
+	matches = Plan(candidates, true)
+	require.Len(t, matches, 3)
+	require.Equal(t, single, matches[0].TransferID)
+	require.Equal(t, grantA, matches[0].GrantID)
+	require.Equal(t, types.DisbursementMatchConfirmed, matches[0].Status)
+	require.Equal(t, types.DisbursementMatchProposed, matches[1].Status)
+	require.Equal(t, grantB, matches[2].GrantID)
+	require.Equal(t, types.DisbursementMatchProposed, matches[2].Status)
+
+	require.Empty(t, Plan(nil, true))
+}
+
+func TestValidateSplit(t *testing.T) {
+	var (
+		grantA = uuid.New()
+		grantB = uuid.New()
+	)
+
+	require.NoError(t, ValidateSplit("1.5", []types.SplitAllocation{{GrantID: grantA, Amount: "1"}, {GrantID: grantB, Amount: "0.5"}}))
+	require.NoError(t, ValidateSplit("1.500000000000000000", []types.SplitAllocation{{GrantID: grantA, Amount: "1.5"}}))
+
+	err := ValidateSplit("1.5", []types.SplitAllocation{{GrantID: grantA, Amount: "1"}, {GrantID: grantB, Amount: "0.4"}})
+	require.ErrorContains(t, err, "add up to")
+
+	err = ValidateSplit("1.5", []types.SplitAllocation{{GrantID: grantA, Amount: "1"}, {GrantID: grantA, Amount: "0.5"}})
+	require.ErrorContains(t, err, "more than once")
+
+	err = ValidateSplit("1.5", []types.SplitAllocation{{GrantID: grantA, Amount: "2"}, {GrantID: grantB, Amount: "-0.5"}})
+	require.ErrorContains(t, err, "must be positive")
+
+	err = ValidateSplit("1.5", []types.SplitAllocation{{GrantID: grantA, Amount: "lots"}})
+	require.ErrorContains(t, err, "invalid amount")
+
+	require.Error(t, ValidateSplit("1.5", nil))
+}
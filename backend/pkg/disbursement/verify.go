@@ -0,0 +1,185 @@
+package disbursement
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/numbergroup/errors"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/constants"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/eth"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+// Verify checks a disbursement against its transaction, which has to have succeeded and paid the disbursed amount of
+// the asset to the grant's addresses. The block number and timestamp of the disbursement are replaced by the chain's.
+// An error is only returned when the chain couldn't be read, the disbursement is left as it was then.
+//
+// Ether sent by a contract wallet within the transaction, like a Safe, doesn't show in the transaction or its receipt.
+// When the transaction's own value doesn't match, the ingested transfers of the transaction are counted instead.
+func Verify(ctx context.Context, client eth.Client, disbursement *types.CreateDisbursement, recipients []string, decimals int, ingested []types.CreateTransfer, now time.Time) error {
+	receipt, err := client.GetTransactionReceipt(ctx, disbursement.TxHash)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get receipt of transaction %s", disbursement.TxHash)
+	}
+	if receipt == nil || receipt.TransactionHash == "" {
+		disbursement.DisbursementVerification = mismatch("Transaction not found", now)
+		return nil
+	}
+
+	blockNumber, err := strconv.ParseUint(strings.TrimPrefix(receipt.BlockNumber, "0x"), 16, 64)
+	if err != nil {
+		return errors.Wrapf(err, "invalid block number %q", receipt.BlockNumber)
+	}
+	timestamp, err := client.GetBlockTimestamp(ctx, blockNumber)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get timestamp of block %d", blockNumber)
+	}
+	disbursement.BlockNumber = int64(blockNumber)
+	disbursement.BlockTimestamp = timestamp.Unix()
+
+	if receipt.Status != "0x1" {
+		disbursement.DisbursementVerification = mismatch("Transaction reverted", now)
+		return nil
+	}
+
+	expected, err := ToRawUnits(disbursement.Amount, decimals)
+	if err != nil {
+		return err
+	}
+
+	var paid *big.Int
+	if strings.EqualFold(disbursement.Asset, constants.EtherAddress) {
+		tx, err := client.GetTransactionByHash(ctx, disbursement.TxHash)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get transaction %s", disbursement.TxHash)
+		}
+		paid = EtherPaid(tx, recipients)
+		if paid.Cmp(expected) != 0 {
+			if internal := TransfersPaid(ingested, constants.EtherAddress, recipients); internal.Sign() > 0 {
+				paid = internal
+			}
+		}
+	} else {
+		paid = TokensPaid(receipt, disbursement.Asset, recipients)
+	}
+
+	switch {
+	case paid.Sign() == 0:
+		disbursement.DisbursementVerification = mismatch("Transaction didn't pay the grant recipient", now)
+	case paid.Cmp(expected) != 0:
+		disbursement.DisbursementVerification = mismatch(fmt.Sprintf("Transaction paid %s, the disbursement is for %s",
+			FromRawUnits(paid, decimals), FromRawUnits(expected, decimals)), now)
+	default:
+		disbursement.DisbursementVerification = types.DisbursementVerification{
+			VerificationStatus: types.DisbursementVerified,
+			VerifiedAt:         null.TimeFrom(now),
+		}
+	}
+	return nil
+}
+
+// Unverified is the verification of a disbursement which couldn't be checked
+func Unverified(reason string) types.DisbursementVerification {
+	return types.DisbursementVerification{
+		VerificationStatus: types.DisbursementUnverified,
+		VerificationReason: null.StringFrom(reason),
+	}
+}
+
+func mismatch(reason string, now time.Time) types.DisbursementVerification {
+	return types.DisbursementVerification{
+		VerificationStatus: types.DisbursementMismatch,
+		VerificationReason: null.StringFrom(reason),
+		VerifiedAt:         null.TimeFrom(now),
+	}
+}
+
+// EtherPaid returns the ether a transaction sent to any of the recipients, in wei
+func EtherPaid(tx *eth.Transaction, recipients []string) *big.Int {
+	paid := new(big.Int)
+	if tx == nil || !isRecipient(tx.To, recipients) {
+		return paid
+	}
+	value, ok := new(big.Int).SetString(strings.TrimPrefix(tx.Value, "0x"), 16)
+	if !ok {
+		return paid
+	}
+	return paid.Add(paid, value)
+}
+
+// TransfersPaid returns the sum of the ingested transfers of the asset to any of the recipients, in raw units. Unlike
+// the transaction itself, they include ether sent by contracts the transaction called.
+func TransfersPaid(transfers []types.CreateTransfer, asset string, recipients []string) *big.Int {
+	paid := new(big.Int)
+	for _, transfer := range transfers {
+		if !strings.EqualFold(transfer.Asset, asset) || !isRecipient(transfer.ToAddress, recipients) {
+			continue
+		}
+		amount, ok := new(big.Rat).SetString(transfer.Amount)
+		if !ok || !amount.IsInt() {
+			continue
+		}
+		paid.Add(paid, amount.Num())
+	}
+	return paid
+}
+
+// TokensPaid returns the sum of the token's Transfer events to any of the recipients, in raw units
+func TokensPaid(receipt *eth.TransactionReceipt, token string, recipients []string) *big.Int {
+	paid := new(big.Int)
+	for _, log := range receipt.Logs {
+		// ERC-721 transfers have the token ID as a third indexed topic, ERC-20 transfers have the amount as data
+		if log.Removed || len(log.Topics) != 3 || !strings.EqualFold(log.Topics[0], constants.TransferEventTopic) ||
+			!strings.EqualFold(log.Address, token) {
+			continue
+		}
+		to := log.Topics[2]
+		if len(to) < 40 || !isRecipient("0x"+to[len(to)-40:], recipients) {
+			continue
+		}
+		amount, ok := new(big.Int).SetString(strings.TrimPrefix(log.Data, "0x"), 16)
+		if !ok {
+			continue
+		}
+		paid.Add(paid, amount)
+	}
+	return paid
+}
+
+func isRecipient(address string, recipients []string) bool {
+	for _, recipient := range recipients {
+		if strings.EqualFold(address, recipient) {
+			return true
+		}
+	}
+	return false
+}
+
+// ToRawUnits converts a decimal amount of an asset to its raw units, it can't have more decimals than the asset
+func ToRawUnits(amount string, decimals int) (*big.Int, error) {
+	value, ok := new(big.Rat).SetString(amount)
+	if !ok {
+		return nil, errors.Errorf("invalid amount %q", amount)
+	}
+	value.Mul(value, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)))
+	if !value.IsInt() {
+		return nil, errors.Errorf("amount %q has more than %d decimals", amount, decimals)
+	}
+	return new(big.Int).Set(value.Num()), nil
+}
+
+// FromRawUnits converts raw units of an asset to a decimal amount, without trailing zeros
+func FromRawUnits(raw *big.Int, decimals int) string {
+	value := new(big.Rat).SetFrac(raw, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
+	formatted := value.FloatString(decimals)
+	if strings.Contains(formatted, ".") {
+		formatted = strings.TrimRight(strings.TrimRight(formatted, "0"), ".")
+	}
+	return formatted
+}
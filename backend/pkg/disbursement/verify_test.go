@@ -0,0 +1,162 @@
+package disbursement
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ETHCF/ethutils"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/constants"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/eth"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+// mockClient answers for a single transaction, mined in block 16
+type mockClient struct {
+	eth.Client
+	tx      eth.Transaction
+	receipt eth.TransactionReceipt
+}
+
+func (m *mockClient) GetTransactionByHash(ctx context.Context, hash string) (*eth.Transaction, error) {
+	if hash != m.tx.Hash {
+		return &eth.Transaction{}, nil
+	}
+	return &m.tx, nil
+}
+
+func (m *mockClient) GetTransactionReceipt(ctx context.Context, hash string) (*eth.TransactionReceipt, error) {
+	if hash != m.receipt.TransactionHash {
+		return &eth.TransactionReceipt{}, nil
+	}
+	return &m.receipt, nil
+}
+
+func (m *mockClient) GetBlockTimestamp(ctx context.Context, blockNumber uint64) (time.Time, error) {
+	return time.Unix(1700000000+int64(blockNumber), 0), nil
+}
+
+func topic(address string) string {
+	return "0x" + strings.Repeat("0", 24) + strings.TrimPrefix(address, "0x")
+}
+
+func TestVerifyEther(t *testing.T) {
+	var (
+		now       = time.Now()
+		txHash    = ethutils.GenRandEVMHash()
+		recipient = ethutils.GenRandEVMAddr()
+		client    = &mockClient{
+			tx:      eth.Transaction{Hash: txHash, To: strings.ToUpper(recipient[:2]) + recipient[2:], Value: "0x14d1120d7b160000"}, // 1.5 ether
+			receipt: eth.TransactionReceipt{TransactionHash: txHash, BlockNumber: "0x10", Status: "0x1"},
+		}
+		newDisbursement = func(amount string) *types.CreateDisbursement {
+			return &types.CreateDisbursement{Asset: constants.EtherAddress, Amount: amount, TxHash: txHash, BlockNumber: 1}
+		}
+	)
+
+	disbursement := newDisbursement("1.5")
+	require.NoError(t, Verify(t.Context(), client, disbursement, []string{recipient}, 18, nil, now))
+	require.Equal(t, types.DisbursementVerified, disbursement.VerificationStatus)
+	require.False(t, disbursement.VerificationReason.Valid)
+	require.Equal(t, int64(16), disbursement.BlockNumber, "block metadata is read from the chain")
+	require.Equal(t, int64(1700000016), disbursement.BlockTimestamp)
+
+	disbursement = newDisbursement("2")
+	require.NoError(t, Verify(t.Context(), client, disbursement, []string{recipient}, 18, nil, now))
+	require.Equal(t, types.DisbursementMismatch, disbursement.VerificationStatus)
+	require.Equal(t, "Transaction paid 1.5, the disbursement is for 2", disbursement.VerificationReason.String)
+
+	disbursement = newDisbursement("1.5")
+	require.NoError(t, Verify(t.Context(), client, disbursement, []string{ethutils.GenRandEVMAddr()}, 18, nil, now))
+	require.Equal(t, types.DisbursementMismatch, disbursement.VerificationStatus)
+	require.Equal(t, "Transaction didn't pay the grant recipient", disbursement.VerificationReason.String)
+
+	// Paid through a Safe, the transaction calls the Safe with no value and the ether moves within it
+	safe := ethutils.GenRandEVMAddr()
+	safeClient := &mockClient{
+		tx:      eth.Transaction{Hash: txHash, To: safe, Value: "0x0"},
+		receipt: client.receipt,
+	}
+	ingested := []types.CreateTransfer{
+		{TxHash: txHash, FromAddress: safe, ToAddress: recipient, Asset: constants.EtherAddress, Amount: "1500000000000000000.000000000000000000"},
+		{TxHash: txHash, FromAddress: safe, ToAddress: recipient, Asset: ethutils.GenRandEVMAddr(), Amount: "7"}, // Another asset
+	}
+	disbursement = newDisbursement("1.5")
+	require.NoError(t, Verify(t.Context(), safeClient, disbursement, []string{recipient}, 18, ingested, now))
+	require.Equal(t, types.DisbursementVerified, disbursement.VerificationStatus)
+
+	disbursement = newDisbursement("2")
+	require.NoError(t, Verify(t.Context(), safeClient, disbursement, []string{recipient}, 18, ingested, now))
+	require.Equal(t, types.DisbursementMismatch, disbursement.VerificationStatus)
+	require.Equal(t, "Transaction paid 1.5, the disbursement is for 2", disbursement.VerificationReason.String)
+
+	disbursement = newDisbursement("1.5")
+	require.NoError(t, Verify(t.Context(), safeClient, disbursement, []string{recipient}, 18, nil, now))
+	require.Equal(t, types.DisbursementMismatch, disbursement.VerificationStatus, "nothing ingested accounts for it")
+
+	client.receipt.Status = "0x0"
+	disbursement = newDisbursement("1.5")
+	require.NoError(t, Verify(t.Context(), client, disbursement, []string{recipient}, 18, nil, now))
+	require.Equal(t, types.DisbursementMismatch, disbursement.VerificationStatus)
+	require.Equal(t, "Transaction reverted", disbursement.VerificationReason.String)
+
+	disbursement = newDisbursement("1.5")
+	disbursement.TxHash = ethutils.GenRandEVMHash()
+	require.NoError(t, Verify(t.Context(), client, disbursement, []string{recipient}, 18, nil, now))
+	require.Equal(t, types.DisbursementMismatch, disbursement.VerificationStatus)
+	require.Equal(t, "Transaction not found", disbursement.VerificationReason.String)
+	require.Equal(t, int64(1), disbursement.BlockNumber, "left as it was")
+}
+
+func TestVerifyTokens(t *testing.T) {
+	var (
+		now       = time.Now()
+		txHash    = ethutils.GenRandEVMHash()
+		token     = ethutils.GenRandEVMAddr()
+		recipient = ethutils.GenRandEVMAddr()
+		alternate = ethutils.GenRandEVMAddr()
+		amount    = func(raw int64) string { return "0x" + big.NewInt(raw).Text(16) }
+		client    = &mockClient{
+			receipt: eth.TransactionReceipt{TransactionHash: txHash, BlockNumber: "0x10", Status: "0x1", Logs: []eth.Log{
+				{Address: token, Topics: []string{constants.TransferEventTopic, topic(ethutils.GenRandEVMAddr()), topic(recipient)}, Data: amount(1_000_000)},
+				{Address: token, Topics: []string{constants.TransferEventTopic, topic(ethutils.GenRandEVMAddr()), topic(alternate)}, Data: amount(500_000)},
+				// Other tokens and recipients aren't counted
+				{Address: ethutils.GenRandEVMAddr(), Topics: []string{constants.TransferEventTopic, topic(ethutils.GenRandEVMAddr()), topic(recipient)}, Data: amount(7)},
+				{Address: token, Topics: []string{constants.TransferEventTopic, topic(ethutils.GenRandEVMAddr()), topic(ethutils.GenRandEVMAddr())}, Data: amount(7)},
+			}},
+		}
+	)
+
+	disbursement := &types.CreateDisbursement{Asset: token, Amount: "1.5", TxHash: txHash}
+	require.NoError(t, Verify(t.Context(), client, disbursement, []string{recipient, alternate}, 6, nil, now))
+	require.Equal(t, types.DisbursementVerified, disbursement.VerificationStatus)
+
+	disbursement = &types.CreateDisbursement{Asset: token, Amount: "1.5", TxHash: txHash}
+	require.NoError(t, Verify(t.Context(), client, disbursement, []string{recipient}, 6, nil, now))
+	require.Equal(t, types.DisbursementMismatch, disbursement.VerificationStatus)
+	require.Equal(t, "Transaction paid 1, the disbursement is for 1.5", disbursement.VerificationReason.String)
+
+	disbursement = &types.CreateDisbursement{Asset: token, Amount: "1.0000001", TxHash: txHash}
+	require.ErrorContains(t, Verify(t.Context(), client, disbursement, []string{recipient}, 6, nil, now), "more than 6 decimals")
+}
+
+func TestRawUnits(t *testing.T) {
+	raw, err := ToRawUnits("1.500000000000000000", 18)
+	require.NoError(t, err)
+	require.Equal(t, "1500000000000000000", raw.String())
+
+	raw, err = ToRawUnits("12", 0)
+	require.NoError(t, err)
+	require.Equal(t, "12", raw.String())
+
+	_, err = ToRawUnits("abc", 18)
+	require.Error(t, err)
+
+	require.Equal(t, "1.5", FromRawUnits(big.NewInt(1_500_000), 6))
+	require.Equal(t, "2", FromRawUnits(big.NewInt(2_000_000), 6))
+	require.Equal(t, "12", FromRawUnits(big.NewInt(12), 0))
+}
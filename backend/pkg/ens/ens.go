@@ -0,0 +1,166 @@
+package ens
+
+import (
+	"context"
+	"encoding/hex"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/numbergroup/errors"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/constants"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/eth"
+)
+
+const (
+	resolverSelector = "0x0178b8bf" // resolver(bytes32)
+	nameSelector     = "0x691f3431" // name(bytes32)
+	addrSelector     = "0x3b3b57de" // addr(bytes32)
+
+	zeroAddress = "0x0000000000000000000000000000000000000000"
+)
+
+// Resolver performs ENS lookups directly against the registry and resolver contracts
+type Resolver interface {
+	// LookupAddress returns the primary ENS name of the address, or an empty string if it doesn't
+	// have one or the name doesn't resolve back to the address
+	LookupAddress(ctx context.Context, address string) (string, error)
+	// ResolveName returns the address the name resolves to, or an empty string if it isn't set
+	ResolveName(ctx context.Context, name string) (string, error)
+}
+
+type resolver struct {
+	ethClient eth.Client
+	registry  string
+}
+
+func NewResolver(ethClient eth.Client) Resolver {
+	return &resolver{
+		ethClient: ethClient,
+		registry:  constants.ENSRegistryAddress,
+	}
+}
+
+func (r *resolver) LookupAddress(ctx context.Context, address string) (string, error) {
+	address = strings.ToLower(address)
+	node := NameHash(strings.TrimPrefix(address, "0x") + ".addr.reverse")
+
+	resolverAddr, err := r.getResolver(ctx, node)
+	if err != nil {
+		return "", err
+	}
+	if resolverAddr == "" {
+		return "", nil
+	}
+
+	result, err := r.ethClient.Call(ctx, resolverAddr, nameSelector+hex.EncodeToString(node), "latest")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get reverse record")
+	}
+	name, err := decodeString(result)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to decode reverse record")
+	}
+	if name == "" {
+		return "", nil
+	}
+
+	// Anyone can set a reverse record to any name, so it only counts if the name points back to the address
+	forward, err := r.ResolveName(ctx, name)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to verify %s", name)
+	}
+	if forward != address {
+		return "", nil
+	}
+	return name, nil
+}
+
+func (r *resolver) ResolveName(ctx context.Context, name string) (string, error) {
+	node := NameHash(name)
+	resolverAddr, err := r.getResolver(ctx, node)
+	if err != nil {
+		return "", err
+	}
+	if resolverAddr == "" {
+		return "", nil
+	}
+
+	result, err := r.ethClient.Call(ctx, resolverAddr, addrSelector+hex.EncodeToString(node), "latest")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get address record")
+	}
+	address, err := decodeAddress(result)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to decode address record")
+	}
+	return address, nil
+}
+
+func (r *resolver) getResolver(ctx context.Context, node []byte) (string, error) {
+	result, err := r.ethClient.Call(ctx, r.registry, resolverSelector+hex.EncodeToString(node), "latest")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get resolver from registry")
+	}
+	return decodeAddress(result)
+}
+
+// NameHash implements the ENS namehash algorithm (EIP-137). Names are lowercased, but not
+// otherwise normalized, which is enough for checking a reverse record against its forward record.
+func NameHash(name string) []byte {
+	node := make([]byte, 32)
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return node
+	}
+	labels := strings.Split(name, ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		node = crypto.Keccak256(node, crypto.Keccak256([]byte(labels[i])))
+	}
+	return node
+}
+
+// decodeAddress decodes an ABI encoded address, returning an empty string for the zero address
+func decodeAddress(result string) (string, error) {
+	data, err := hex.DecodeString(strings.TrimPrefix(result, "0x"))
+	if err != nil {
+		return "", errors.Wrap(err, "invalid hex")
+	}
+	if len(data) == 0 {
+		// Calls to addresses without code return nothing
+		return "", nil
+	}
+	if len(data) < 32 {
+		return "", errors.Errorf("result too short for an address: %d bytes", len(data))
+	}
+	address := "0x" + hex.EncodeToString(data[12:32])
+	if address == zeroAddress {
+		return "", nil
+	}
+	return address, nil
+}
+
+// decodeString decodes an ABI encoded string return value
+func decodeString(result string) (string, error) {
+	data, err := hex.DecodeString(strings.TrimPrefix(result, "0x"))
+	if err != nil {
+		return "", errors.Wrap(err, "invalid hex")
+	}
+	if len(data) == 0 {
+		return "", nil
+	}
+	if len(data) < 64 {
+		return "", errors.Errorf("result too short for a string: %d bytes", len(data))
+	}
+	offset := new(big.Int).SetBytes(data[:32])
+	if !offset.IsUint64() || offset.Uint64()+32 > uint64(len(data)) {
+		return "", errors.New("string offset out of range")
+	}
+	start := offset.Uint64() + 32
+	length := new(big.Int).SetBytes(data[offset.Uint64():start])
+	if !length.IsUint64() || start+length.Uint64() > uint64(len(data)) {
+		return "", errors.New("string length out of range")
+	}
+	return string(data[start : start+length.Uint64()]), nil
+}
@@ -0,0 +1,106 @@
+package ens
+
+import (
+	"context"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/constants"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/eth"
+)
+
+func TestNameHash(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "empty", input: "", expected: "0000000000000000000000000000000000000000000000000000000000000000"},
+		{name: "tld", input: "eth", expected: "93cdeb708b7545dc668eb9280176169d1c33cfd8ed6f04690a0bcc88a93fc4ae"},
+		{name: "subdomain", input: "foo.eth", expected: "de9b09fd7c5f901e23a3f19fecc54828e9c848539801e86591bd9801b019f84f"},
+		{name: "uppercase", input: "FOO.eth", expected: "de9b09fd7c5f901e23a3f19fecc54828e9c848539801e86591bd9801b019f84f"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, hex.EncodeToString(NameHash(tt.input)))
+		})
+	}
+}
+
+func TestSelectors(t *testing.T) {
+	require.Equal(t, resolverSelector, "0x"+hex.EncodeToString(crypto.Keccak256([]byte("resolver(bytes32)"))[:4]))
+	require.Equal(t, nameSelector, "0x"+hex.EncodeToString(crypto.Keccak256([]byte("name(bytes32)"))[:4]))
+	require.Equal(t, addrSelector, "0x"+hex.EncodeToString(crypto.Keccak256([]byte("addr(bytes32)"))[:4]))
+}
+
+// mockClient answers eth_calls from a map of to+data to results
+type mockClient struct {
+	eth.Client
+	results map[string]string
+}
+
+func (m *mockClient) Call(ctx context.Context, to string, data string, blockTag string) (string, error) {
+	return m.results[to+data], nil
+}
+
+func encodeAddress(address string) string {
+	return "0x" + strings.Repeat("0", 24) + strings.TrimPrefix(address, "0x")
+}
+
+func encodeString(s string) string {
+	offset := make([]byte, 32)
+	offset[31] = 32
+	length := make([]byte, 32)
+	new(big.Int).SetInt64(int64(len(s))).FillBytes(length)
+	data := []byte(s)
+	if pad := len(data) % 32; pad != 0 {
+		data = append(data, make([]byte, 32-pad)...)
+	}
+	return "0x" + hex.EncodeToString(offset) + hex.EncodeToString(length) + hex.EncodeToString(data)
+}
+
+func TestLookupAddress(t *testing.T) {
+	const (
+		address     = "0x1111111111111111111111111111111111111111"
+		other       = "0x2222222222222222222222222222222222222222"
+		reverseRes  = "0x3333333333333333333333333333333333333333"
+		forwardRes  = "0x4444444444444444444444444444444444444444"
+		ensName     = "treasury.eth"
+		reverseName = "1111111111111111111111111111111111111111.addr.reverse"
+	)
+	reverseNode := hex.EncodeToString(NameHash(reverseName))
+	forwardNode := hex.EncodeToString(NameHash(ensName))
+
+	newClient := func(forwardAddr string) *mockClient {
+		return &mockClient{results: map[string]string{
+			constants.ENSRegistryAddress + resolverSelector + reverseNode: encodeAddress(reverseRes),
+			reverseRes + nameSelector + reverseNode:                       encodeString(ensName),
+			constants.ENSRegistryAddress + resolverSelector + forwardNode: encodeAddress(forwardRes),
+			forwardRes + addrSelector + forwardNode:                       encodeAddress(forwardAddr),
+		}}
+	}
+
+	t.Run("verified", func(t *testing.T) {
+		name, err := NewResolver(newClient(address)).LookupAddress(context.Background(), address)
+		require.NoError(t, err)
+		require.Equal(t, ensName, name)
+	})
+
+	t.Run("forward record points elsewhere", func(t *testing.T) {
+		name, err := NewResolver(newClient(other)).LookupAddress(context.Background(), address)
+		require.NoError(t, err)
+		require.Empty(t, name)
+	})
+
+	t.Run("no reverse record", func(t *testing.T) {
+		name, err := NewResolver(&mockClient{results: map[string]string{}}).LookupAddress(context.Background(), address)
+		require.NoError(t, err)
+		require.Empty(t, name)
+	})
+}
@@ -8,6 +8,7 @@ import (
 	"math/big"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/numbergroup/errors"
@@ -22,6 +23,10 @@ type Client interface {
 	GetTransactionReceipt(ctx context.Context, hash string) (*TransactionReceipt, error)
 	GetBalance(ctx context.Context, address string, blockTag string) (*big.Int, error)
 	BlockNumber(ctx context.Context) (uint64, error)
+	// Call executes a read-only eth_call against the contract and returns the hex encoded result
+	Call(ctx context.Context, to string, data string, blockTag string) (string, error)
+	GetLogs(ctx context.Context, filter LogFilter) ([]Log, error)
+	GetBlockTimestamp(ctx context.Context, blockNumber uint64) (time.Time, error)
 }
 
 type client struct {
@@ -147,3 +152,83 @@ func (c *client) BlockNumber(ctx context.Context) (uint64, error) {
 
 	return strconv.ParseUint(resp.Result[2:], 16, 64)
 }
+
+func (c *client) Call(ctx context.Context, to string, data string, blockTag string) (string, error) {
+	req, err := c.newRequest(ctx, "eth_call", []any{map[string]string{"to": to, "data": data}, blockTag})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create request")
+	}
+	respData, err := c.doRequest(req)
+	if err != nil {
+		return "", errors.Wrap(err, "request failed")
+	}
+
+	var resp JSONRPCResponse[string]
+	err = json.Unmarshal(respData, &resp)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to unmarshal response")
+	}
+	if resp.Error != nil {
+		return "", errors.Errorf("eth_call failed: %s", resp.Error.Message)
+	}
+	return resp.Result, nil
+}
+
+func (c *client) GetLogs(ctx context.Context, filter LogFilter) ([]Log, error) {
+	topics := make([]any, 0, len(filter.Topics))
+	for _, options := range filter.Topics {
+		if len(options) == 0 {
+			topics = append(topics, nil)
+			continue
+		}
+		topics = append(topics, options)
+	}
+	params := map[string]any{
+		"fromBlock": "0x" + strconv.FormatUint(filter.FromBlock, 16),
+		"toBlock":   "0x" + strconv.FormatUint(filter.ToBlock, 16),
+		"topics":    topics,
+	}
+	req, err := c.newRequest(ctx, "eth_getLogs", []any{params})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create request")
+	}
+	respData, err := c.doRequest(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "request failed")
+	}
+
+	var resp JSONRPCResponse[[]Log]
+	err = json.Unmarshal(respData, &resp)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal response")
+	}
+	if resp.Error != nil {
+		return nil, errors.Errorf("eth_getLogs failed: %s", resp.Error.Message)
+	}
+	return resp.Result, nil
+}
+
+func (c *client) GetBlockTimestamp(ctx context.Context, blockNumber uint64) (time.Time, error) {
+	req, err := c.newRequest(ctx, "eth_getBlockByNumber", []any{"0x" + strconv.FormatUint(blockNumber, 16), false})
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "failed to create request")
+	}
+	respData, err := c.doRequest(req)
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "request failed")
+	}
+
+	var resp JSONRPCResponse[*Block]
+	err = json.Unmarshal(respData, &resp)
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "failed to unmarshal response")
+	}
+	if resp.Result == nil {
+		return time.Time{}, errors.Errorf("block %d not found", blockNumber)
+	}
+	timestamp, err := strconv.ParseInt(strings.TrimPrefix(resp.Result.Timestamp, "0x"), 16, 64)
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "invalid block timestamp")
+	}
+	return time.Unix(timestamp, 0), nil
+}
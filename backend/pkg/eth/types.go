@@ -4,6 +4,10 @@ type JSONRPCResponse[T any] struct {
 	JSONRPC string `json:"jsonrpc"`
 	ID      int    `json:"id"`
 	Result  T      `json:"result"`
+	Error   *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
 }
 
 type Transaction struct {
@@ -34,21 +38,37 @@ type TransactionReceipt struct {
 	EffectiveGasPrice string `json:"effectiveGasPrice"`
 	From              string `json:"from"`
 	GasUsed           string `json:"gasUsed"`
-	Logs              []struct {
-		Address          string   `json:"address"`
-		Topics           []string `json:"topics"`
-		Data             string   `json:"data"`
-		BlockNumber      string   `json:"blockNumber"`
-		TransactionHash  string   `json:"transactionHash"`
-		TransactionIndex string   `json:"transactionIndex"`
-		BlockHash        string   `json:"blockHash"`
-		LogIndex         string   `json:"logIndex"`
-		Removed          bool     `json:"removed"`
-	} `json:"logs"`
-	LogsBloom        string `json:"logsBloom"`
-	Status           string `json:"status"`
-	To               string `json:"to"`
-	TransactionHash  string `json:"transactionHash"`
-	TransactionIndex string `json:"transactionIndex"`
-	Type             string `json:"type"`
+	Logs              []Log  `json:"logs"`
+	LogsBloom         string `json:"logsBloom"`
+	Status            string `json:"status"`
+	To                string `json:"to"`
+	TransactionHash   string `json:"transactionHash"`
+	TransactionIndex  string `json:"transactionIndex"`
+	Type              string `json:"type"`
+}
+
+type Log struct {
+	Address          string   `json:"address"`
+	Topics           []string `json:"topics"`
+	Data             string   `json:"data"`
+	BlockNumber      string   `json:"blockNumber"`
+	TransactionHash  string   `json:"transactionHash"`
+	TransactionIndex string   `json:"transactionIndex"`
+	BlockHash        string   `json:"blockHash"`
+	LogIndex         string   `json:"logIndex"`
+	Removed          bool     `json:"removed"`
+}
+
+// LogFilter selects logs from every contract in a block range. Each position in Topics matches any of
+// the given values, and an empty position matches anything.
+type LogFilter struct {
+	FromBlock uint64
+	ToBlock   uint64
+	Topics    [][]string
+}
+
+type Block struct {
+	Number    string `json:"number"`
+	Hash      string `json:"hash"`
+	Timestamp string `json:"timestamp"`
 }
@@ -0,0 +1,228 @@
+package exchange
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ETHCF/ethutils"
+	"github.com/numbergroup/errors"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/config"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+const coinbaseAPIVersion = "2024-01-01"
+
+type coinbase struct {
+	baseURL   string
+	apiKey    string
+	apiSecret string
+	client    *http.Client
+	now       func() time.Time
+}
+
+// NewCoinbase returns a connector for the Coinbase v2 REST API, authenticated with an API key and secret.
+// The base URL is configurable so it can be pointed at a mock server.
+func NewCoinbase(conf *config.Config) Connector {
+	return &coinbase{
+		baseURL:   strings.TrimSuffix(conf.CoinbaseAPIURL, "/"),
+		apiKey:    conf.CoinbaseAPIKey,
+		apiSecret: conf.CoinbaseAPISecret,
+		client:    &http.Client{Timeout: conf.ExchangeAPITimeout},
+		now:       time.Now,
+	}
+}
+
+func (c *coinbase) Name() string {
+	return "coinbase"
+}
+
+type coinbaseMoney struct {
+	Amount   string `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+type coinbasePage[T any] struct {
+	Pagination struct {
+		NextURI string `json:"next_uri"`
+	} `json:"pagination"`
+	Data []T `json:"data"`
+}
+
+type coinbaseAccount struct {
+	ID            string        `json:"id"`
+	Balance       coinbaseMoney `json:"balance"`
+	NativeBalance coinbaseMoney `json:"native_balance"`
+}
+
+type coinbaseTransaction struct {
+	ID        string        `json:"id"`
+	Type      string        `json:"type"`
+	Status    string        `json:"status"`
+	Amount    coinbaseMoney `json:"amount"`
+	CreatedAt time.Time     `json:"created_at"`
+	Network   *struct {
+		Hash string `json:"hash"`
+	} `json:"network"`
+	To *struct {
+		Address string `json:"address"`
+	} `json:"to"`
+	From *struct {
+		Address string `json:"address"`
+	} `json:"from"`
+}
+
+// sign implements Coinbase's API key authentication, an HMAC of the timestamp, method, path and body
+func (c *coinbase) sign(req *http.Request, timestamp string) {
+	mac := hmac.New(sha256.New, []byte(c.apiSecret))
+	mac.Write([]byte(timestamp + req.Method + req.URL.RequestURI()))
+	req.Header.Set("CB-ACCESS-KEY", c.apiKey)
+	req.Header.Set("CB-ACCESS-SIGN", hex.EncodeToString(mac.Sum(nil)))
+	req.Header.Set("CB-ACCESS-TIMESTAMP", timestamp)
+	req.Header.Set("CB-VERSION", coinbaseAPIVersion)
+}
+
+func (c *coinbase) get(ctx context.Context, uri string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+uri, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to create request")
+	}
+	c.sign(req, strconv.FormatInt(c.now().Unix(), 10))
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform request")
+	}
+	defer resp.Body.Close()
+
+	respData, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrap(err, "failed to read response body")
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("unexpected status code: %d\n: %s", resp.StatusCode, string(respData))
+	}
+	return errors.Wrap(json.Unmarshal(respData, out), "failed to unmarshal response")
+}
+
+func (c *coinbase) accounts(ctx context.Context) ([]coinbaseAccount, error) {
+	accounts := []coinbaseAccount{}
+	for uri := "/v2/accounts?limit=100"; uri != ""; {
+		var page coinbasePage[coinbaseAccount]
+		if err := c.get(ctx, uri, &page); err != nil {
+			return nil, errors.Wrap(err, "failed to get accounts")
+		}
+		accounts = append(accounts, page.Data...)
+		uri = page.Pagination.NextURI
+	}
+	return accounts, nil
+}
+
+func (c *coinbase) Balances(ctx context.Context) ([]types.ExchangeBalance, error) {
+	accounts, err := c.accounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := c.now()
+	balances := []types.ExchangeBalance{}
+	for _, account := range accounts {
+		amount, ok := new(big.Float).SetPrec(256).SetString(account.Balance.Amount)
+		if !ok {
+			return nil, errors.Errorf("invalid balance %q for account %s", account.Balance.Amount, account.ID)
+		}
+		if amount.Sign() == 0 {
+			continue
+		}
+
+		balance := types.ExchangeBalance{
+			Exchange:    c.Name(),
+			Currency:    strings.ToUpper(account.Balance.Currency),
+			Amount:      amount.Text('f', -1),
+			LastUpdated: now,
+		}
+		if strings.EqualFold(account.NativeBalance.Currency, "USD") {
+			if usdValue, err := strconv.ParseFloat(account.NativeBalance.Amount, 64); err == nil {
+				balance.UsdValue = null.FloatFrom(usdValue)
+			}
+		}
+		balances = append(balances, balance)
+	}
+	return balances, nil
+}
+
+func (c *coinbase) Movements(ctx context.Context, since time.Time) ([]types.ExchangeMovement, error) {
+	accounts, err := c.accounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	movements := []types.ExchangeMovement{}
+	for _, account := range accounts {
+		// Transactions are returned newest first, so stop paging once they are older than since
+	pages:
+		for uri := "/v2/accounts/" + account.ID + "/transactions?limit=100"; uri != ""; {
+			var page coinbasePage[coinbaseTransaction]
+			if err := c.get(ctx, uri, &page); err != nil {
+				return nil, errors.Wrapf(err, "failed to get transactions for account %s", account.ID)
+			}
+			for _, tx := range page.Data {
+				if tx.CreatedAt.Before(since) {
+					break pages
+				}
+				movement, ok := c.toMovement(tx)
+				if ok {
+					movements = append(movements, movement)
+				}
+			}
+			uri = page.Pagination.NextURI
+		}
+	}
+	return movements, nil
+}
+
+// toMovement converts on-chain sends into deposits and withdrawals, trades and transfers between
+// Coinbase accounts aren't movements
+func (c *coinbase) toMovement(tx coinbaseTransaction) (types.ExchangeMovement, bool) {
+	if tx.Type != "send" || tx.Network == nil {
+		return types.ExchangeMovement{}, false
+	}
+	amount, ok := new(big.Float).SetPrec(256).SetString(tx.Amount.Amount)
+	if !ok || amount.Sign() == 0 {
+		return types.ExchangeMovement{}, false
+	}
+
+	movement := types.ExchangeMovement{
+		Exchange:   c.Name(),
+		ExternalID: tx.ID,
+		Direction:  types.ExchangeDeposit,
+		Currency:   strings.ToUpper(tx.Amount.Currency),
+		Amount:     new(big.Float).Abs(amount).Text('f', -1),
+		Status:     tx.Status,
+		OccurredAt: tx.CreatedAt,
+	}
+	if amount.Sign() < 0 {
+		movement.Direction = types.ExchangeWithdrawal
+		if tx.To != nil && tx.To.Address != "" {
+			movement.Address = null.StringFrom(tx.To.Address)
+		}
+	} else if tx.From != nil && tx.From.Address != "" {
+		movement.Address = null.StringFrom(tx.From.Address)
+	}
+	// Movements on other chains keep their hash out, it could never match a transfer
+	if txHash, err := ethutils.SanitizeEthHash(tx.Network.Hash); err == nil {
+		movement.TxHash = null.StringFrom(txHash)
+	}
+	return movement, true
+}
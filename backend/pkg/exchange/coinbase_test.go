@@ -0,0 +1,137 @@
+package exchange
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/config"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+const (
+	testKey    = "test-key"
+	testSecret = "test-secret"
+	testHash   = "0x1111111111111111111111111111111111111111111111111111111111111111"
+)
+
+// newMockCoinbase serves canned responses for the Coinbase v2 endpoints the connector uses, rejecting
+// requests which aren't signed with the test credentials
+func newMockCoinbase(t *testing.T) *httptest.Server {
+	responses := map[string]string{
+		"/v2/accounts?limit=100": `{
+			"pagination": {"next_uri": "/v2/accounts?limit=100&starting_after=eth"},
+			"data": [
+				{"id": "eth", "balance": {"amount": "1.500000000000000001", "currency": "ETH"}, "native_balance": {"amount": "4500.00", "currency": "USD"}},
+				{"id": "empty", "balance": {"amount": "0.00", "currency": "BTC"}, "native_balance": {"amount": "0.00", "currency": "USD"}}
+			]
+		}`,
+		"/v2/accounts?limit=100&starting_after=eth": `{
+			"pagination": {"next_uri": null},
+			"data": [
+				{"id": "usdc", "balance": {"amount": "2500", "currency": "usdc"}, "native_balance": {"amount": "2300", "currency": "EUR"}}
+			]
+		}`,
+		"/v2/accounts/eth/transactions?limit=100": `{
+			"pagination": {"next_uri": null},
+			"data": [
+				{"id": "withdrawal", "type": "send", "status": "completed", "amount": {"amount": "-0.5", "currency": "ETH"}, "created_at": "2025-03-01T00:00:00Z",
+					"network": {"hash": "` + testHash + `"}, "to": {"address": "0x2222222222222222222222222222222222222222"}},
+				{"id": "trade", "type": "buy", "status": "completed", "amount": {"amount": "1", "currency": "ETH"}, "created_at": "2025-02-15T00:00:00Z"},
+				{"id": "deposit", "type": "send", "status": "pending", "amount": {"amount": "2", "currency": "ETH"}, "created_at": "2025-02-01T00:00:00Z",
+					"network": {"hash": ""}, "from": {"address": "0x3333333333333333333333333333333333333333"}},
+				{"id": "old", "type": "send", "status": "completed", "amount": {"amount": "3", "currency": "ETH"}, "created_at": "2024-12-01T00:00:00Z",
+					"network": {"hash": "` + testHash + `"}}
+			]
+		}`,
+		"/v2/accounts/empty/transactions?limit=100": `{"pagination": {"next_uri": null}, "data": []}`,
+		"/v2/accounts/usdc/transactions?limit=100":  `{"pagination": {"next_uri": null}, "data": []}`,
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mac := hmac.New(sha256.New, []byte(testSecret))
+		mac.Write([]byte(r.Header.Get("CB-ACCESS-TIMESTAMP") + r.Method + r.URL.RequestURI()))
+		if r.Header.Get("CB-ACCESS-KEY") != testKey || r.Header.Get("CB-ACCESS-SIGN") != hex.EncodeToString(mac.Sum(nil)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		response, ok := responses[r.URL.RequestURI()]
+		if !ok {
+			t.Errorf("unexpected request: %s", r.URL.RequestURI())
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(response))
+	}))
+}
+
+func newTestCoinbase(url, secret string) Connector {
+	return NewCoinbase(&config.Config{
+		CoinbaseAPIURL:     url,
+		CoinbaseAPIKey:     testKey,
+		CoinbaseAPISecret:  secret,
+		ExchangeAPITimeout: time.Second,
+	})
+}
+
+func TestCoinbaseBalances(t *testing.T) {
+	server := newMockCoinbase(t)
+	defer server.Close()
+
+	balances, err := newTestCoinbase(server.URL, testSecret).Balances(t.Context())
+	require.NoError(t, err)
+	require.Len(t, balances, 2)
+
+	require.Equal(t, "coinbase", balances[0].Exchange)
+	require.Equal(t, "ETH", balances[0].Currency)
+	require.Equal(t, "1.500000000000000001", balances[0].Amount)
+	require.Equal(t, 4500.0, balances[0].UsdValue.Float64)
+
+	// Only USD native balances are used as the USD value
+	require.Equal(t, "USDC", balances[1].Currency)
+	require.Equal(t, "2500", balances[1].Amount)
+	require.False(t, balances[1].UsdValue.Valid)
+}
+
+func TestCoinbaseMovements(t *testing.T) {
+	server := newMockCoinbase(t)
+	defer server.Close()
+
+	movements, err := newTestCoinbase(server.URL, testSecret).Movements(t.Context(), time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Len(t, movements, 2)
+
+	require.Equal(t, "withdrawal", movements[0].ExternalID)
+	require.Equal(t, types.ExchangeWithdrawal, movements[0].Direction)
+	require.Equal(t, "0.5", movements[0].Amount)
+	require.Equal(t, testHash, movements[0].TxHash.String)
+	require.Equal(t, "0x2222222222222222222222222222222222222222", movements[0].Address.String)
+
+	// Pending deposits don't have a hash yet
+	require.Equal(t, "deposit", movements[1].ExternalID)
+	require.Equal(t, types.ExchangeDeposit, movements[1].Direction)
+	require.Equal(t, "pending", movements[1].Status)
+	require.False(t, movements[1].TxHash.Valid)
+	require.Equal(t, "0x3333333333333333333333333333333333333333", movements[1].Address.String)
+}
+
+func TestCoinbaseBadCredentials(t *testing.T) {
+	server := newMockCoinbase(t)
+	defer server.Close()
+
+	_, err := newTestCoinbase(server.URL, "wrong-secret").Balances(t.Context())
+	require.Error(t, err)
+}
+
+func TestNewConnectors(t *testing.T) {
+	require.Empty(t, NewConnectors(&config.Config{}))
+	connectors := NewConnectors(&config.Config{CoinbaseAPIKey: testKey})
+	require.Len(t, connectors, 1)
+	require.Equal(t, "coinbase", connectors[0].Name())
+}
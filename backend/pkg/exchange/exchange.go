@@ -0,0 +1,28 @@
+package exchange
+
+import (
+	"context"
+	"time"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/config"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+// Connector reads balances and deposits/withdrawals from a custodial exchange account. Connectors only
+// need read-only API credentials and never move funds.
+type Connector interface {
+	// Name identifies the exchange account the balances and movements are stored under
+	Name() string
+	Balances(ctx context.Context) ([]types.ExchangeBalance, error)
+	// Movements returns the deposits and withdrawals which occurred at or after since
+	Movements(ctx context.Context, since time.Time) ([]types.ExchangeMovement, error)
+}
+
+// NewConnectors returns a connector for each exchange with credentials configured
+func NewConnectors(conf *config.Config) []Connector {
+	connectors := []Connector{}
+	if conf.CoinbaseAPIKey != "" {
+		connectors = append(connectors, NewCoinbase(conf))
+	}
+	return connectors
+}
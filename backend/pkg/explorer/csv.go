@@ -0,0 +1,312 @@
+package explorer
+
+import (
+	"encoding/csv"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ETHCF/ethutils"
+	"github.com/numbergroup/errors"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+// ExportKind is the type of transaction list exported from Etherscan or Blockscout
+type ExportKind string
+
+const (
+	ExportKindAuto     ExportKind = ""
+	ExportKindNormal   ExportKind = "normal"
+	ExportKindInternal ExportKind = "internal"
+	ExportKindERC20    ExportKind = "erc20"
+	ExportKindERC721   ExportKind = "erc721"
+)
+
+func ParseExportKind(s string) (ExportKind, error) {
+	switch kind := ExportKind(strings.ToLower(strings.TrimSpace(s))); kind {
+	case ExportKindAuto, ExportKindNormal, ExportKindInternal, ExportKindERC20, ExportKindERC721:
+		return kind, nil
+	}
+	return "", errors.Errorf("unknown export type %q, expected one of normal, internal, erc20, erc721", s)
+}
+
+// Row is a single transfer read from an explorer export, before it is matched against the tracked wallets
+type Row struct {
+	Line        int
+	TxHash      string
+	BlockNumber int64
+	Timestamp   int64
+	From        string
+	To          string
+	Contract    string   // Token contract, empty for native transfers
+	Amount      string   // Decimal amount, in whole units unless Raw is set
+	Raw         bool     // Amount is already in the smallest unit (wei, or a token count for NFTs)
+	TokenID     string   // Only set for ERC-721 transfers
+	LogIndex    null.Int // Only set when the export includes it
+	Failed      bool     // The transaction reverted, so nothing was transferred
+}
+
+// header maps normalized column names to their index in a record
+type header map[string]int
+
+func newHeader(record []string) header {
+	out := header{}
+	for i, name := range record {
+		out[normalizeColumn(name)] = i
+	}
+	return out
+}
+
+// normalizeColumn lowercases a column name and strips everything that is not a letter or digit,
+// so "Value_IN(ETH)", "Transaction Hash" and "UnixTimestamp" become "valueineth", "transactionhash" and "unixtimestamp"
+func normalizeColumn(name string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(strings.TrimPrefix(name, "\ufeff")) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+func (h header) has(names ...string) bool {
+	for _, name := range names {
+		if _, ok := h[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// get returns the value of the first of the named columns present in the record
+func (h header) get(record []string, names ...string) string {
+	for _, name := range names {
+		if i, ok := h[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+	}
+	return ""
+}
+
+// getPrefix returns the value of the first column starting with the prefix, used for
+// Etherscan's per-chain value columns such as Value_IN(ETH) or Value_IN(POL)
+func (h header) getPrefix(record []string, prefix string) string {
+	for name, i := range h {
+		if strings.HasPrefix(name, prefix) && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+	}
+	return ""
+}
+
+// isBlockscout checks for the column names Blockscout uses, which differ from Etherscan
+// and report native values in wei rather than ether
+func (h header) isBlockscout() bool {
+	return h.has("fromaddress") && h.has("toaddress")
+}
+
+func (h header) detectKind() ExportKind {
+	switch {
+	case h.has("tokenid") && !h.has("tokenvalue", "tokenstransferred", "value"):
+		return ExportKindERC721
+	case h.has("tokenvalue", "tokenstransferred", "tokensymbol"):
+		return ExportKindERC20
+	case h.has("parenttxfrom", "calltype", "traceid"):
+		return ExportKindInternal
+	}
+	return ExportKindNormal
+}
+
+// ParseCSV reads an Etherscan or Blockscout transaction export. When kind is ExportKindAuto the type is
+// detected from the header. Rows which can't be read are returned as errors instead of failing the whole file.
+func ParseCSV(r io.Reader, kind ExportKind) (ExportKind, []Row, []types.ImportRowError, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	headerRecord, err := reader.Read()
+	if err != nil {
+		return kind, nil, nil, errors.Wrap(err, "failed to read csv header")
+	}
+	h := newHeader(headerRecord)
+	if !h.has("txhash", "transactionhash", "hash") {
+		return kind, nil, nil, errors.New("csv header has no transaction hash column")
+	}
+	if kind == ExportKindAuto {
+		kind = h.detectKind()
+	}
+
+	rows := []Row{}
+	rowErrors := []types.ImportRowError{}
+	line := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			rowErrors = append(rowErrors, types.ImportRowError{Row: line, Error: err.Error()})
+			continue
+		}
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+		row, err := h.parseRow(record, kind)
+		if err != nil {
+			rowErrors = append(rowErrors, types.ImportRowError{Row: line, Error: err.Error()})
+			continue
+		}
+		row.Line = line
+		rows = append(rows, row)
+	}
+	return kind, rows, rowErrors, nil
+}
+
+func (h header) parseRow(record []string, kind ExportKind) (Row, error) {
+	var (
+		row Row
+		err error
+	)
+	row.TxHash, err = ethutils.SanitizeEthHash(h.get(record, "txhash", "transactionhash", "hash"))
+	if err != nil {
+		return Row{}, errors.New("invalid transaction hash")
+	}
+
+	row.BlockNumber, err = strconv.ParseInt(h.get(record, "blockno", "blocknumber"), 10, 64)
+	if err != nil {
+		return Row{}, errors.New("invalid block number")
+	}
+
+	row.Timestamp, err = h.parseTimestamp(record)
+	if err != nil {
+		return Row{}, err
+	}
+
+	from := h.get(record, "from", "fromaddress")
+	to := h.get(record, "to", "txto", "toaddress")
+	if to == "" {
+		// Contract creations have no recipient, the value goes to the created contract
+		to = h.get(record, "contractaddress")
+	}
+	row.From, err = ethutils.SanitizeEthAddr(from)
+	if err != nil {
+		return Row{}, errors.Errorf("invalid from address %q", from)
+	}
+	row.To, err = ethutils.SanitizeEthAddr(to)
+	if err != nil {
+		return Row{}, errors.Errorf("invalid to address %q", to)
+	}
+
+	if logIndex := h.get(record, "logindex"); logIndex != "" {
+		idx, err := strconv.ParseInt(logIndex, 10, 64)
+		if err != nil {
+			return Row{}, errors.Errorf("invalid log index %q", logIndex)
+		}
+		row.LogIndex = null.IntFrom(idx)
+	}
+
+	row.Failed = h.isFailed(record)
+
+	switch kind {
+	case ExportKindNormal, ExportKindInternal:
+		if h.isBlockscout() {
+			row.Amount = h.get(record, "value")
+			row.Raw = true
+		} else {
+			row.Amount = h.getPrefix(record, "valuein")
+			if isZero(row.Amount) {
+				row.Amount = h.getPrefix(record, "valueout")
+			}
+			if row.Amount == "" {
+				row.Amount = h.get(record, "value")
+			}
+		}
+	case ExportKindERC20:
+		row.Contract, err = ethutils.SanitizeEthAddr(h.get(record, "contractaddress", "tokencontractaddress"))
+		if err != nil {
+			return Row{}, errors.New("invalid token contract address")
+		}
+		row.Amount = h.get(record, "tokenvalue", "tokenstransferred", "value", "quantity")
+	case ExportKindERC721:
+		row.Contract, err = ethutils.SanitizeEthAddr(h.get(record, "contractaddress", "tokencontractaddress"))
+		if err != nil {
+			return Row{}, errors.New("invalid token contract address")
+		}
+		row.TokenID = h.get(record, "tokenid")
+		if row.TokenID == "" {
+			return Row{}, errors.New("missing token id")
+		}
+		row.Amount = "1"
+		row.Raw = true
+	default:
+		return Row{}, errors.Errorf("unsupported export type %q", kind)
+	}
+
+	row.Amount = strings.ReplaceAll(row.Amount, ",", "")
+	if row.Amount == "" {
+		return Row{}, errors.New("missing value")
+	}
+	return row, nil
+}
+
+func (h header) parseTimestamp(record []string) (int64, error) {
+	if ts := h.get(record, "unixtimestamp", "timestamp"); ts != "" {
+		out, err := strconv.ParseInt(ts, 10, 64)
+		if err == nil {
+			return out, nil
+		}
+	}
+	if dt := h.get(record, "datetimeutc", "datetime"); dt != "" {
+		parsed, err := time.Parse(time.DateTime, dt)
+		if err == nil {
+			return parsed.Unix(), nil
+		}
+	}
+	return 0, errors.New("invalid or missing timestamp")
+}
+
+func (h header) isFailed(record []string) bool {
+	if h.get(record, "errcode") != "" || h.get(record, "iserror") == "1" {
+		return true
+	}
+	status := strings.ToLower(h.get(record, "status"))
+	return strings.Contains(status, "error") || strings.Contains(status, "fail")
+}
+
+func isZero(amount string) bool {
+	amount = strings.Trim(strings.ReplaceAll(amount, ",", ""), "0.")
+	return amount == ""
+}
+
+// ParseUnits converts a decimal amount such as "1.5" into its integer representation in the smallest unit
+func ParseUnits(amount string, decimals int) (*big.Int, error) {
+	amount = strings.TrimSpace(strings.ReplaceAll(amount, ",", ""))
+	if amount == "" {
+		return nil, errors.New("empty amount")
+	}
+	if strings.ContainsAny(amount, "eE") {
+		f, _, err := big.ParseFloat(amount, 10, 256, big.ToNearestEven)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid amount %q", amount)
+		}
+		f.Mul(f, new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)))
+		out, _ := f.Int(nil)
+		return out, nil
+	}
+
+	whole, frac, _ := strings.Cut(amount, ".")
+	frac = strings.TrimRight(frac, "0")
+	if len(frac) > decimals {
+		return nil, errors.Errorf("amount %q has more than %d decimal places", amount, decimals)
+	}
+	out, ok := new(big.Int).SetString(whole+frac+strings.Repeat("0", decimals-len(frac)), 10)
+	if !ok || out.Sign() < 0 {
+		return nil, errors.Errorf("invalid amount %q", amount)
+	}
+	return out, nil
+}
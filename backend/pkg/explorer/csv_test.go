@@ -0,0 +1,119 @@
+package explorer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testHash = "0x5c504ed432cb51138bcf09aa5e8a410dd4a1e204ef84bfed1be16dfba1b22060"
+	testFrom = "0x1111111111111111111111111111111111111111"
+	testTo   = "0x2222222222222222222222222222222222222222"
+	testUSDC = "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+)
+
+func TestParseUnits(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		decimals int
+		expected string
+		wantErr  bool
+	}{
+		{name: "whole number", input: "2", decimals: 18, expected: "2000000000000000000"},
+		{name: "decimal", input: "1.5", decimals: 6, expected: "1500000"},
+		{name: "thousands separator", input: "1,234.5", decimals: 2, expected: "123450"},
+		{name: "trailing zeros beyond precision", input: "1.500000000", decimals: 6, expected: "1500000"},
+		{name: "scientific notation", input: "1.5e-6", decimals: 18, expected: "1500000000000"},
+		{name: "too many decimals", input: "1.1234567", decimals: 6, wantErr: true},
+		{name: "empty", input: "", decimals: 18, wantErr: true},
+		{name: "not a number", input: "abc", decimals: 18, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseUnits(tt.input, tt.decimals)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, result.String())
+		})
+	}
+}
+
+func TestParseCSVEtherscanNormal(t *testing.T) {
+	data := "\ufeff\"Transaction Hash\",\"Blockno\",\"UnixTimestamp\",\"DateTime (UTC)\",\"From\",\"To\",\"ContractAddress\",\"Value_IN(ETH)\",\"Value_OUT(ETH)\",\"CurrentValue @ $3000/Eth\",\"TxnFee(ETH)\",\"TxnFee(USD)\",\"Historical $Price/Eth\",\"Status\",\"ErrCode\",\"Method\"\n" +
+		"\"" + testHash + "\",\"100\",\"1700000000\",\"2023-11-14 22:13:20\",\"" + testFrom + "\",\"" + testTo + "\",\"\",\"0\",\"1.25\",\"3750\",\"0.001\",\"3\",\"3000\",\"\",\"\",\"Transfer\"\n" +
+		"\"" + testHash + "\",\"101\",\"1700000012\",\"2023-11-14 22:13:32\",\"" + testTo + "\",\"" + testFrom + "\",\"\",\"0.5\",\"0\",\"1500\",\"0.001\",\"3\",\"3000\",\"Error(0)\",\"Out of gas\",\"Transfer\"\n" +
+		"\"not-a-hash\",\"102\",\"1700000024\",\"2023-11-14 22:13:44\",\"" + testFrom + "\",\"" + testTo + "\",\"\",\"1\",\"0\",\"3000\",\"0.001\",\"3\",\"3000\",\"\",\"\",\"Transfer\"\n"
+
+	kind, rows, rowErrors, err := ParseCSV(strings.NewReader(data), ExportKindAuto)
+	require.NoError(t, err)
+	require.Equal(t, ExportKindNormal, kind)
+	require.Len(t, rows, 2)
+	require.Len(t, rowErrors, 1)
+	require.Equal(t, 4, rowErrors[0].Row)
+
+	require.Equal(t, testHash, rows[0].TxHash)
+	require.Equal(t, int64(100), rows[0].BlockNumber)
+	require.Equal(t, int64(1700000000), rows[0].Timestamp)
+	require.Equal(t, testFrom, rows[0].From)
+	require.Equal(t, testTo, rows[0].To)
+	require.Equal(t, "1.25", rows[0].Amount)
+	require.False(t, rows[0].Raw)
+	require.False(t, rows[0].Failed)
+
+	require.Equal(t, "0.5", rows[1].Amount)
+	require.True(t, rows[1].Failed)
+}
+
+func TestParseCSVEtherscanTokens(t *testing.T) {
+	data := "\"Transaction Hash\",\"Blockno\",\"UnixTimestamp\",\"DateTime (UTC)\",\"From\",\"To\",\"TokenValue\",\"USDValueDayOfTx\",\"ContractAddress\",\"TokenName\",\"TokenSymbol\"\n" +
+		"\"" + testHash + "\",\"100\",\"1700000000\",\"2023-11-14 22:13:20\",\"" + testFrom + "\",\"" + testTo + "\",\"1,000.5\",\"$1,000.50\",\"" + testUSDC + "\",\"USD Coin\",\"USDC\"\n"
+
+	kind, rows, rowErrors, err := ParseCSV(strings.NewReader(data), ExportKindAuto)
+	require.NoError(t, err)
+	require.Equal(t, ExportKindERC20, kind)
+	require.Empty(t, rowErrors)
+	require.Len(t, rows, 1)
+	require.Equal(t, testUSDC, rows[0].Contract)
+	require.Equal(t, "1000.5", rows[0].Amount)
+	require.False(t, rows[0].LogIndex.Valid)
+}
+
+func TestParseCSVEtherscanNFTs(t *testing.T) {
+	data := "\"Transaction Hash\",\"Blockno\",\"UnixTimestamp\",\"DateTime (UTC)\",\"From\",\"To\",\"ContractAddress\",\"TokenId\",\"TokenName\",\"TokenSymbol\"\n" +
+		"\"" + testHash + "\",\"100\",\"1700000000\",\"2023-11-14 22:13:20\",\"" + testFrom + "\",\"" + testTo + "\",\"" + testUSDC + "\",\"42\",\"Thing\",\"THG\"\n"
+
+	kind, rows, rowErrors, err := ParseCSV(strings.NewReader(data), ExportKindAuto)
+	require.NoError(t, err)
+	require.Equal(t, ExportKindERC721, kind)
+	require.Empty(t, rowErrors)
+	require.Len(t, rows, 1)
+	require.Equal(t, "42", rows[0].TokenID)
+	require.Equal(t, "1", rows[0].Amount)
+	require.True(t, rows[0].Raw)
+}
+
+func TestParseCSVBlockscout(t *testing.T) {
+	data := "TxHash,BlockNumber,UnixTimestamp,FromAddress,ToAddress,ContractAddress,Type,Value,Fee,Status,ErrCode,CurrentPrice,TxDateOpeningPrice,TxDateClosingPrice,MethodName\n" +
+		testHash + ",100,1700000000," + testFrom + "," + testTo + ",,OUT,1250000000000000000,21000,ok,,3000,3000,3000,\n"
+
+	kind, rows, rowErrors, err := ParseCSV(strings.NewReader(data), ExportKindAuto)
+	require.NoError(t, err)
+	require.Equal(t, ExportKindNormal, kind)
+	require.Empty(t, rowErrors)
+	require.Len(t, rows, 1)
+	require.Equal(t, "1250000000000000000", rows[0].Amount)
+	require.True(t, rows[0].Raw)
+	require.False(t, rows[0].Failed)
+}
+
+func TestParseCSVMissingHashColumn(t *testing.T) {
+	_, _, _, err := ParseCSV(strings.NewReader("a,b,c\n1,2,3\n"), ExportKindAuto)
+	require.Error(t, err)
+}
@@ -0,0 +1,235 @@
+package explorer
+
+import (
+	"context"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/numbergroup/errors"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/config"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/constants"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/db"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/eth"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+type ImportOptions struct {
+	ChainID int64
+	Kind    ExportKind
+}
+
+// Importer backfills transfer history from explorer exports, for when the indexer API is unavailable
+// or history from before the tracker was running is needed
+type Importer interface {
+	ImportTransfers(ctx context.Context, r io.Reader, opts ImportOptions) (*types.TransferImportReport, error)
+}
+
+type importer struct {
+	conf       *config.Config
+	treasuryDB db.TreasuryDB
+	ethClient  eth.Client
+}
+
+// NewImporter creates an importer. ethClient is optional, without it token transfers from exports
+// that don't include a log index can't be imported, as their index can't be looked up in the receipt.
+func NewImporter(conf *config.Config, treasuryDB db.TreasuryDB, ethClient eth.Client) Importer {
+	return &importer{
+		conf:       conf,
+		treasuryDB: treasuryDB,
+		ethClient:  ethClient,
+	}
+}
+
+func (im *importer) ImportTransfers(ctx context.Context, r io.Reader, opts ImportOptions) (*types.TransferImportReport, error) {
+	if opts.ChainID == 0 {
+		opts.ChainID = 1
+	}
+	kind, rows, rowErrors, err := ParseCSV(r, opts.Kind)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid export")
+	}
+
+	wallets, err := im.treasuryDB.GetWallets(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get wallets")
+	}
+	tracked := make(map[string]bool, len(wallets))
+	for _, wallet := range wallets {
+		tracked[strings.ToLower(wallet.Address)] = true
+	}
+
+	assets, err := im.treasuryDB.GetAssets(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get assets")
+	}
+	decimals := map[string]int{constants.EtherAddress: 18}
+	for _, asset := range assets {
+		if asset.ChainID == opts.ChainID {
+			decimals[strings.ToLower(asset.Address)] = asset.Decimals
+		}
+	}
+
+	report := &types.TransferImportReport{
+		Rows:   len(rows) + len(rowErrors),
+		Errors: rowErrors,
+	}
+	rowFailed := func(row Row, err error) {
+		report.Errors = append(report.Errors, types.ImportRowError{Row: row.Line, Error: err.Error()})
+	}
+
+	transfers := make([]types.CreateTransfer, 0, len(rows))
+	resolver := &logIndexResolver{ethClient: im.ethClient, used: map[string]map[int]bool{}}
+	for _, row := range rows {
+		var direction types.TransferType
+		switch {
+		case tracked[row.From]:
+			direction = types.TransferTypeOutgoing
+		case tracked[row.To]:
+			direction = types.TransferTypeIncoming
+		default:
+			rowFailed(row, errors.New("neither address is a tracked wallet"))
+			continue
+		}
+
+		if row.Failed {
+			report.Skipped++
+			continue
+		}
+
+		asset := constants.EtherAddress
+		if row.Contract != "" {
+			asset = row.Contract
+		}
+
+		amount, err := rawAmount(row, decimals, asset)
+		if err != nil {
+			rowFailed(row, err)
+			continue
+		}
+		if amount.Sign() == 0 {
+			report.Skipped++
+			continue
+		}
+
+		transfer := types.CreateTransfer{
+			ChainID:        opts.ChainID,
+			TxHash:         row.TxHash,
+			BlockNumber:    row.BlockNumber,
+			BlockTimestamp: row.Timestamp,
+			FromAddress:    row.From,
+			ToAddress:      row.To,
+			Asset:          asset,
+			Amount:         amount.String(),
+			Direction:      direction,
+		}
+		if kind == ExportKindERC20 || kind == ExportKindERC721 {
+			transfer.LogIndex, err = resolver.resolve(ctx, row, amount)
+			if err != nil {
+				rowFailed(row, err)
+				continue
+			}
+		}
+		transfers = append(transfers, transfer)
+	}
+	report.Failed = len(report.Errors)
+
+	inserted, err := im.treasuryDB.ImportTransfers(ctx, transfers)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to import transfers")
+	}
+	report.Inserted = inserted
+	report.Skipped += len(transfers) - inserted
+
+	return report, nil
+}
+
+func rawAmount(row Row, decimals map[string]int, asset string) (*big.Int, error) {
+	if row.Raw {
+		amount, ok := new(big.Int).SetString(row.Amount, 10)
+		if !ok || amount.Sign() < 0 {
+			return nil, errors.Errorf("invalid amount %q", row.Amount)
+		}
+		return amount, nil
+	}
+	dec, ok := decimals[asset]
+	if !ok {
+		return nil, errors.Errorf("unknown asset %s, add it to the assets before importing", asset)
+	}
+	return ParseUnits(row.Amount, dec)
+}
+
+// logIndexResolver finds the log index of token transfers, which is part of the transfer's
+// identity but isn't included in Etherscan's token exports
+type logIndexResolver struct {
+	ethClient eth.Client
+	receipts  map[string]*eth.TransactionReceipt
+	used      map[string]map[int]bool
+}
+
+func (lr *logIndexResolver) resolve(ctx context.Context, row Row, amount *big.Int) (int, error) {
+	if row.LogIndex.Valid {
+		return int(row.LogIndex.Int64), nil
+	}
+	if lr.ethClient == nil {
+		// A made up index wouldn't match the one the indexer records for the same transfer, which would then
+		// be counted twice
+		return 0, errors.New("export has no log index and no RPC is configured to look it up")
+	}
+
+	if lr.receipts == nil {
+		lr.receipts = map[string]*eth.TransactionReceipt{}
+	}
+	receipt, ok := lr.receipts[row.TxHash]
+	if !ok {
+		var err error
+		receipt, err = lr.ethClient.GetTransactionReceipt(ctx, row.TxHash)
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to get transaction receipt")
+		}
+		lr.receipts[row.TxHash] = receipt
+	}
+	if lr.used[row.TxHash] == nil {
+		lr.used[row.TxHash] = map[int]bool{}
+	}
+
+	for _, log := range receipt.Logs {
+		if len(log.Topics) < 3 || !strings.EqualFold(log.Topics[0], constants.TransferEventTopic) ||
+			!strings.EqualFold(log.Address, row.Contract) ||
+			!topicIsAddress(log.Topics[1], row.From) || !topicIsAddress(log.Topics[2], row.To) {
+			continue
+		}
+		value := log.Data
+		if row.TokenID != "" {
+			if len(log.Topics) < 4 {
+				continue
+			}
+			value = log.Topics[3]
+			amount, ok = new(big.Int).SetString(row.TokenID, 10)
+			if !ok {
+				return 0, errors.Errorf("invalid token id %q", row.TokenID)
+			}
+		}
+		logValue, ok := new(big.Int).SetString(strings.TrimPrefix(value, "0x"), 16)
+		if !ok || logValue.Cmp(amount) != 0 {
+			continue
+		}
+		idx, err := strconv.ParseInt(strings.TrimPrefix(log.LogIndex, "0x"), 16, 64)
+		if err != nil {
+			return 0, errors.Wrapf(err, "invalid log index %s in receipt", log.LogIndex)
+		}
+		if lr.used[row.TxHash][int(idx)] {
+			continue
+		}
+		lr.used[row.TxHash][int(idx)] = true
+		return int(idx), nil
+	}
+	return 0, errors.New("no matching transfer event in the transaction receipt")
+}
+
+func topicIsAddress(topic, address string) bool {
+	topic = strings.TrimPrefix(strings.ToLower(topic), "0x")
+	return len(topic) == 64 && "0x"+topic[24:] == address
+}
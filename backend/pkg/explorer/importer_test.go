@@ -0,0 +1,25 @@
+package explorer
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
+)
+
+func TestLogIndexResolverWithoutClient(t *testing.T) {
+	resolver := &logIndexResolver{used: map[string]map[int]bool{}}
+	row := Row{TxHash: testHash, From: testFrom, To: testTo, Contract: testUSDC, Amount: "1"}
+
+	// The index from the export is kept as is
+	row.LogIndex = null.IntFrom(7)
+	idx, err := resolver.resolve(t.Context(), row, big.NewInt(1))
+	require.NoError(t, err)
+	require.Equal(t, 7, idx)
+
+	// Without it there is no receipt to look it up in, so the row can't be imported
+	row.LogIndex = null.Int{}
+	_, err = resolver.resolve(t.Context(), row, big.NewInt(1))
+	require.ErrorContains(t, err, "no RPC is configured")
+}
@@ -0,0 +1,203 @@
+package labels
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ETHCF/ethutils"
+	"github.com/numbergroup/errors"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+
+	maxLabelLength = 255
+)
+
+var importNameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,63}$`)
+
+// ImportSource returns the source name for labels imported under the given name, such as import:exchanges
+func ImportSource(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, types.LabelSourceImportPrefix)))
+	if !importNameRegex.MatchString(name) {
+		return "", errors.Errorf("invalid import name %q, use lowercase letters, digits, '.', '_' and '-'", name)
+	}
+	return types.LabelSourceImportPrefix + name, nil
+}
+
+func IsImportSource(source string) bool {
+	return strings.HasPrefix(source, types.LabelSourceImportPrefix)
+}
+
+// DetectFormat picks the format from the explicit value if set, otherwise from the file extension
+func DetectFormat(format, fileName string) (string, error) {
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(fileName), ".")
+	}
+	switch format = strings.ToLower(format); format {
+	case FormatCSV, FormatJSON:
+		return format, nil
+	}
+	return "", errors.Errorf("unknown label file format %q, expected csv or json", format)
+}
+
+type rawLabel struct {
+	Row     int    `json:"-"`
+	Address string `json:"address"`
+	Label   string `json:"label"`
+	Name    string `json:"name"`
+}
+
+// Parse reads labels from either a CSV file with address and label (or name) columns, or a JSON file
+// holding an array of {"address", "label"} objects or an object mapping addresses to labels.
+// Rows that can't be read are returned as errors, and later rows for the same address win.
+func Parse(r io.Reader, format, source string) ([]types.TransferPartyLabel, []types.ImportRowError, error) {
+	var (
+		raw       []rawLabel
+		rowErrors = []types.ImportRowError{}
+		err       error
+	)
+	switch format {
+	case FormatJSON:
+		raw, err = readJSON(r)
+	case FormatCSV:
+		raw, rowErrors, err = readCSV(r)
+	default:
+		err = errors.Errorf("unknown label file format %q", format)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	byAddress := map[string]int{}
+	out := []types.TransferPartyLabel{}
+	for _, item := range raw {
+		label := strings.TrimSpace(item.Label)
+		if label == "" {
+			label = strings.TrimSpace(item.Name)
+		}
+		address, err := ethutils.SanitizeEthAddr(strings.TrimSpace(item.Address))
+		if err != nil {
+			rowErrors = append(rowErrors, types.ImportRowError{Row: item.Row, Error: "invalid address"})
+			continue
+		}
+		if label == "" || len(label) > maxLabelLength {
+			rowErrors = append(rowErrors, types.ImportRowError{Row: item.Row, Error: "label must be between 1 and 255 characters"})
+			continue
+		}
+		if idx, ok := byAddress[address]; ok {
+			out[idx].Label = label
+			continue
+		}
+		byAddress[address] = len(out)
+		out = append(out, types.TransferPartyLabel{Address: address, Source: source, Label: label})
+	}
+	sort.Slice(rowErrors, func(i, j int) bool { return rowErrors[i].Row < rowErrors[j].Row })
+	return out, rowErrors, nil
+}
+
+func readJSON(r io.Reader) ([]rawLabel, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read labels")
+	}
+	var raw []rawLabel
+	if err := json.Unmarshal(data, &raw); err != nil {
+		byAddress := map[string]string{}
+		if mapErr := json.Unmarshal(data, &byAddress); mapErr != nil {
+			return nil, errors.Wrap(err, "invalid labels json")
+		}
+		for address, label := range byAddress {
+			raw = append(raw, rawLabel{Address: address, Label: label})
+		}
+		sort.Slice(raw, func(i, j int) bool { return raw[i].Address < raw[j].Address })
+	}
+	for i := range raw {
+		raw[i].Row = i + 1
+	}
+	return raw, nil
+}
+
+func readCSV(r io.Reader) ([]rawLabel, []types.ImportRowError, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to read csv header")
+	}
+	addressCol, labelCol := -1, -1
+	for i, name := range header {
+		switch strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))) {
+		case "address":
+			addressCol = i
+		case "label", "name":
+			labelCol = i
+		}
+	}
+	if addressCol < 0 || labelCol < 0 {
+		return nil, nil, errors.New("csv header must have address and label columns")
+	}
+
+	raw := []rawLabel{}
+	rowErrors := []types.ImportRowError{}
+	// Rows are numbered by their line in the file, so the first one after the header is 2
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			rowErrors = append(rowErrors, types.ImportRowError{Row: row, Error: err.Error()})
+			continue
+		}
+		if addressCol >= len(record) || labelCol >= len(record) {
+			rowErrors = append(rowErrors, types.ImportRowError{Row: row, Error: "missing columns"})
+			continue
+		}
+		raw = append(raw, rawLabel{Row: row, Address: record[addressCol], Label: record[labelCol]})
+	}
+	return raw, rowErrors, nil
+}
+
+// Diff compares the labels currently in a source with the labels that would replace them
+func Diff(source string, existing, incoming []types.TransferPartyLabel) types.LabelDiff {
+	diff := types.LabelDiff{
+		Source:  source,
+		Added:   []types.TransferPartyLabel{},
+		Changed: []types.LabelChange{},
+		Removed: []types.TransferPartyLabel{},
+		Errors:  []types.ImportRowError{},
+	}
+	current := make(map[string]string, len(existing))
+	for _, label := range existing {
+		current[label.Address] = label.Label
+	}
+	seen := make(map[string]bool, len(incoming))
+	for _, label := range incoming {
+		seen[label.Address] = true
+		old, ok := current[label.Address]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, label)
+		case old != label.Label:
+			diff.Changed = append(diff.Changed, types.LabelChange{Address: label.Address, OldLabel: old, NewLabel: label.Label})
+		default:
+			diff.Unchanged++
+		}
+	}
+	for _, label := range existing {
+		if !seen[label.Address] {
+			diff.Removed = append(diff.Removed, label)
+		}
+	}
+	return diff
+}
@@ -0,0 +1,112 @@
+package labels
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+const (
+	addrA = "0x1111111111111111111111111111111111111111"
+	addrB = "0x2222222222222222222222222222222222222222"
+	addrC = "0x3333333333333333333333333333333333333333"
+)
+
+func TestImportSource(t *testing.T) {
+	source, err := ImportSource("Exchanges")
+	require.NoError(t, err)
+	require.Equal(t, "import:exchanges", source)
+
+	source, err = ImportSource("import:bridges")
+	require.NoError(t, err)
+	require.Equal(t, "import:bridges", source)
+
+	_, err = ImportSource("")
+	require.Error(t, err)
+	_, err = ImportSource("has space")
+	require.Error(t, err)
+}
+
+func TestParseCSV(t *testing.T) {
+	data := "address,label\n" +
+		addrA + ",Coinbase\n" +
+		"0xnotanaddress,Bad\n" +
+		addrB + ",\n" +
+		"0x" + strings.ToUpper(addrA[2:]) + ",Coinbase 2\n" +
+		addrC + ",Bridge\n"
+
+	result, rowErrors, err := Parse(strings.NewReader(data), FormatCSV, "import:test")
+	require.NoError(t, err)
+	require.Equal(t, []types.TransferPartyLabel{
+		{Address: addrA, Source: "import:test", Label: "Coinbase 2"},
+		{Address: addrC, Source: "import:test", Label: "Bridge"},
+	}, result)
+	require.Len(t, rowErrors, 2)
+	require.Equal(t, 3, rowErrors[0].Row)
+	require.Equal(t, 4, rowErrors[1].Row)
+}
+
+func TestParseCSVMissingColumns(t *testing.T) {
+	_, _, err := Parse(strings.NewReader("address,description\n"+addrA+",x\n"), FormatCSV, "import:test")
+	require.Error(t, err)
+}
+
+func TestParseJSON(t *testing.T) {
+	array := `[{"address": "` + addrA + `", "label": "Coinbase"}, {"address": "` + addrB + `", "name": "Grantee"}]`
+	result, rowErrors, err := Parse(strings.NewReader(array), FormatJSON, "import:test")
+	require.NoError(t, err)
+	require.Empty(t, rowErrors)
+	require.Len(t, result, 2)
+	require.Equal(t, "Grantee", result[1].Label)
+
+	object := `{"` + addrB + `": "Grantee", "` + addrA + `": "Coinbase"}`
+	result, rowErrors, err = Parse(strings.NewReader(object), FormatJSON, "import:test")
+	require.NoError(t, err)
+	require.Empty(t, rowErrors)
+	require.Equal(t, []types.TransferPartyLabel{
+		{Address: addrA, Source: "import:test", Label: "Coinbase"},
+		{Address: addrB, Source: "import:test", Label: "Grantee"},
+	}, result)
+
+	_, _, err = Parse(strings.NewReader(`"just a string"`), FormatJSON, "import:test")
+	require.Error(t, err)
+}
+
+func TestDetectFormat(t *testing.T) {
+	format, err := DetectFormat("", "labels.CSV")
+	require.NoError(t, err)
+	require.Equal(t, FormatCSV, format)
+
+	format, err = DetectFormat("json", "labels.txt")
+	require.NoError(t, err)
+	require.Equal(t, FormatJSON, format)
+
+	_, err = DetectFormat("", "labels.txt")
+	require.Error(t, err)
+}
+
+func TestDiff(t *testing.T) {
+	existing := []types.TransferPartyLabel{
+		{Address: addrA, Label: "Coinbase"},
+		{Address: addrB, Label: "Old Name"},
+	}
+	incoming := []types.TransferPartyLabel{
+		{Address: addrA, Label: "Coinbase"},
+		{Address: addrB, Label: "New Name"},
+		{Address: addrC, Label: "Bridge"},
+	}
+
+	diff := Diff("import:test", existing, incoming)
+	require.Equal(t, "import:test", diff.Source)
+	require.Equal(t, 1, diff.Unchanged)
+	require.Equal(t, []types.LabelChange{{Address: addrB, OldLabel: "Old Name", NewLabel: "New Name"}}, diff.Changed)
+	require.Equal(t, []types.TransferPartyLabel{{Address: addrC, Label: "Bridge"}}, diff.Added)
+	require.Empty(t, diff.Removed)
+
+	diff = Diff("import:test", existing, nil)
+	require.Len(t, diff.Removed, 2)
+	require.Empty(t, diff.Added)
+}
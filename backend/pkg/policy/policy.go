@@ -0,0 +1,115 @@
+package policy
+
+import (
+	"strings"
+
+	"github.com/numbergroup/errors"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+const (
+	DefaultWindowDays = 90
+	daysPerMonth      = 30
+)
+
+// Validate checks that a rule has what its metric needs, and fills in the defaults
+func Validate(req *types.PolicyRuleRequest) error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return errors.New("name is required")
+	}
+	if req.WindowDays == 0 {
+		req.WindowDays = DefaultWindowDays
+	}
+	if req.AssetClass.Valid && !types.AssetClass(req.AssetClass.String).Valid() {
+		return errors.New("assetClass must be native, stablecoin, governance, lp or other")
+	}
+
+	switch req.Metric {
+	case types.PolicyMetricClassPercent:
+		if !req.AssetClass.Valid {
+			return errors.New("assetClass is required for class_percent rules")
+		}
+	case types.PolicyMetricRunwayMonths, types.PolicyMetricAssetPercent, types.PolicyMetricTotalValueUsd, types.PolicyMetricSingleOutflow:
+	default:
+		return errors.Errorf("unknown metric %q", req.Metric)
+	}
+	if req.Asset.Valid && req.Metric != types.PolicyMetricAssetPercent {
+		return errors.New("asset is only used by asset_percent rules")
+	}
+	if (req.Metric == types.PolicyMetricClassPercent || req.Metric == types.PolicyMetricAssetPercent) && req.Threshold > 100 {
+		return errors.New("percent thresholds must be between 0 and 100")
+	}
+	return nil
+}
+
+// Evaluate returns the current value of the rule's metric and whether it breaks the rule.
+// The value is null when the metric can't be computed, such as runway without any outflows, which never breaks a rule.
+func Evaluate(rule types.PolicyRule, composition types.TreasuryComposition, outflows types.OutflowSummary) (null.Float, bool) {
+	value := metric(rule, composition, outflows)
+	if !value.Valid {
+		return value, false
+	}
+	switch rule.Comparison {
+	case types.PolicyComparisonMin:
+		return value, value.Float64 < rule.Threshold
+	case types.PolicyComparisonMax:
+		return value, value.Float64 > rule.Threshold
+	}
+	return value, false
+}
+
+func metric(rule types.PolicyRule, composition types.TreasuryComposition, outflows types.OutflowSummary) null.Float {
+	switch rule.Metric {
+	case types.PolicyMetricRunwayMonths:
+		class := types.AssetClassStablecoin
+		if rule.AssetClass.Valid {
+			class = types.AssetClass(rule.AssetClass.String)
+		}
+		if outflows.TotalUsd <= 0 || rule.WindowDays <= 0 {
+			return null.Float{}
+		}
+		monthlyBurn := outflows.TotalUsd / (float64(rule.WindowDays) / daysPerMonth)
+		return null.FloatFrom(entryValue(composition.ByClass, string(class)).ValueUsd / monthlyBurn)
+
+	case types.PolicyMetricClassPercent:
+		return null.FloatFrom(entryValue(composition.ByClass, rule.AssetClass.String).Percent)
+
+	case types.PolicyMetricAssetPercent:
+		if rule.Asset.Valid {
+			// Rules are about the asset on any chain
+			var percent float64
+			for _, entry := range composition.ByAsset {
+				if strings.HasSuffix(entry.Key, ":"+rule.Asset.String) {
+					percent += entry.Percent
+				}
+			}
+			return null.FloatFrom(percent)
+		}
+		if composition.Concentration.LargestNonStableAsset == nil {
+			return null.FloatFrom(0)
+		}
+		return null.FloatFrom(composition.Concentration.LargestNonStableAsset.Percent)
+
+	case types.PolicyMetricTotalValueUsd:
+		if rule.AssetClass.Valid {
+			return null.FloatFrom(entryValue(composition.ByClass, rule.AssetClass.String).ValueUsd)
+		}
+		return null.FloatFrom(composition.TotalValueUsd)
+
+	case types.PolicyMetricSingleOutflow:
+		return null.FloatFrom(outflows.LargestUsd)
+	}
+	return null.Float{}
+}
+
+func entryValue(entries []types.CompositionEntry, key string) types.CompositionEntry {
+	for _, entry := range entries {
+		if entry.Key == key {
+			return entry
+		}
+	}
+	return types.CompositionEntry{Key: key}
+}
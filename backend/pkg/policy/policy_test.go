@@ -0,0 +1,128 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+const usdc = "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+
+func testComposition() types.TreasuryComposition {
+	eth := types.CompositionEntry{Key: "1:0xeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee", Name: "ETH", ValueUsd: 6000, Percent: 60}
+	return types.TreasuryComposition{
+		TotalValueUsd: 10000,
+		ByClass: []types.CompositionEntry{
+			{Key: string(types.AssetClassNative), ValueUsd: 6000, Percent: 60},
+			{Key: string(types.AssetClassStablecoin), ValueUsd: 3000, Percent: 30},
+			{Key: string(types.AssetClassOther), ValueUsd: 1000, Percent: 10},
+		},
+		ByAsset: []types.CompositionEntry{
+			eth,
+			{Key: "1:" + usdc, Name: "USDC", ValueUsd: 2000, Percent: 20},
+			{Key: "10:" + usdc, Name: "USDC", ValueUsd: 1000, Percent: 10},
+			{Key: "offchain:bank", Name: "Bank", ValueUsd: 1000, Percent: 10},
+		},
+		Concentration: types.Concentration{LargestAsset: &eth, LargestNonStableAsset: &eth},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	req := types.PolicyRuleRequest{Name: "  Runway  ", Metric: types.PolicyMetricRunwayMonths, Comparison: types.PolicyComparisonMin, Threshold: 12}
+	require.NoError(t, Validate(&req))
+	require.Equal(t, "Runway", req.Name)
+	require.Equal(t, DefaultWindowDays, req.WindowDays)
+
+	tests := []struct {
+		name string
+		req  types.PolicyRuleRequest
+	}{
+		{"blank name", types.PolicyRuleRequest{Name: " ", Metric: types.PolicyMetricTotalValueUsd}},
+		{"class percent without class", types.PolicyRuleRequest{Name: "a", Metric: types.PolicyMetricClassPercent}},
+		{"unknown class", types.PolicyRuleRequest{Name: "a", Metric: types.PolicyMetricClassPercent, AssetClass: null.StringFrom("memecoin")}},
+		{"asset on class rule", types.PolicyRuleRequest{Name: "a", Metric: types.PolicyMetricTotalValueUsd, Asset: null.StringFrom(usdc)}},
+		{"percent over 100", types.PolicyRuleRequest{Name: "a", Metric: types.PolicyMetricAssetPercent, Threshold: 150}},
+		{"unknown metric", types.PolicyRuleRequest{Name: "a", Metric: "apy"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Error(t, Validate(&tt.req))
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	composition := testComposition()
+	// 1500 over 90 days is 500 a month
+	outflows := types.OutflowSummary{TotalUsd: 1500, LargestUsd: 800, Transfers: 3}
+
+	tests := []struct {
+		name     string
+		rule     types.PolicyRule
+		value    null.Float
+		violated bool
+	}{
+		{
+			name:     "runway in stablecoins",
+			rule:     types.PolicyRule{Metric: types.PolicyMetricRunwayMonths, Comparison: types.PolicyComparisonMin, Threshold: 12, WindowDays: 90},
+			value:    null.FloatFrom(6),
+			violated: true,
+		},
+		{
+			name:  "runway in another class",
+			rule:  types.PolicyRule{Metric: types.PolicyMetricRunwayMonths, Comparison: types.PolicyComparisonMin, Threshold: 12, WindowDays: 90, AssetClass: null.StringFrom(string(types.AssetClassNative))},
+			value: null.FloatFrom(12),
+		},
+		{
+			name:     "class percent",
+			rule:     types.PolicyRule{Metric: types.PolicyMetricClassPercent, Comparison: types.PolicyComparisonMax, Threshold: 50, AssetClass: null.StringFrom(string(types.AssetClassNative))},
+			value:    null.FloatFrom(60),
+			violated: true,
+		},
+		{
+			name:  "missing class is zero",
+			rule:  types.PolicyRule{Metric: types.PolicyMetricClassPercent, Comparison: types.PolicyComparisonMax, Threshold: 5, AssetClass: null.StringFrom(string(types.AssetClassLP))},
+			value: null.FloatFrom(0),
+		},
+		{
+			name:     "asset percent across chains",
+			rule:     types.PolicyRule{Metric: types.PolicyMetricAssetPercent, Comparison: types.PolicyComparisonMin, Threshold: 35, Asset: null.StringFrom(usdc)},
+			value:    null.FloatFrom(30),
+			violated: true,
+		},
+		{
+			name:     "largest non-stable asset",
+			rule:     types.PolicyRule{Metric: types.PolicyMetricAssetPercent, Comparison: types.PolicyComparisonMax, Threshold: 50},
+			value:    null.FloatFrom(60),
+			violated: true,
+		},
+		{
+			name:  "total value",
+			rule:  types.PolicyRule{Metric: types.PolicyMetricTotalValueUsd, Comparison: types.PolicyComparisonMin, Threshold: 10000},
+			value: null.FloatFrom(10000),
+		},
+		{
+			name:     "single outflow",
+			rule:     types.PolicyRule{Metric: types.PolicyMetricSingleOutflow, Comparison: types.PolicyComparisonMax, Threshold: 500, WindowDays: 90},
+			value:    null.FloatFrom(800),
+			violated: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, violated := Evaluate(tt.rule, composition, outflows)
+			require.Equal(t, tt.value, value)
+			require.Equal(t, tt.violated, violated)
+		})
+	}
+
+	t.Run("runway without outflows", func(t *testing.T) {
+		rule := types.PolicyRule{Metric: types.PolicyMetricRunwayMonths, Comparison: types.PolicyComparisonMin, Threshold: 12, WindowDays: 90}
+		value, violated := Evaluate(rule, composition, types.OutflowSummary{})
+		require.False(t, value.Valid)
+		require.False(t, violated)
+	})
+}
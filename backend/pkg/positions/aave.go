@@ -0,0 +1,125 @@
+package positions
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/numbergroup/errors"
+)
+
+const (
+	aaveV3PoolAddress = "0x87870bca3f3fd6335c3f4ce8392d69350b4fa4e2"
+	aaveReserveTTL    = time.Hour
+)
+
+var (
+	getReservesListSelector = methodID("getReservesList()")
+	getReserveDataSelector  = methodID("getReserveData(address)")
+)
+
+type aaveReserve struct {
+	asset             string
+	aToken            string
+	variableDebtToken string
+}
+
+type aaveV3Adapter struct {
+	reader *reader
+	pool   string
+
+	lock     sync.Mutex
+	reserves []aaveReserve
+	loadedAt time.Time
+}
+
+// NewAaveV3Adapter reads deposits and variable rate debt in the Aave v3 pool. aTokens and debt tokens
+// are 1:1 with the underlying asset, so their balances are the underlying amounts.
+func NewAaveV3Adapter(r *reader) Adapter {
+	return &aaveV3Adapter{reader: r, pool: aaveV3PoolAddress}
+}
+
+func (a *aaveV3Adapter) Name() string {
+	return AdapterAaveV3
+}
+
+func (a *aaveV3Adapter) Positions(ctx context.Context, wallet string, tokens []string) ([]Holding, error) {
+	reserves, err := a.getReserves(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	held := tokenSet(tokens)
+	out := []Holding{}
+	for _, reserve := range reserves {
+		for _, position := range []struct {
+			token string
+			name  string
+			debt  bool
+		}{
+			{token: reserve.aToken, name: "Aave v3 deposit"},
+			{token: reserve.variableDebtToken, name: "Aave v3 debt", debt: true},
+		} {
+			if !held[position.token] {
+				continue
+			}
+			balance, err := a.reader.balanceOf(ctx, position.token, wallet)
+			if err != nil {
+				return nil, err
+			}
+			if balance.Sign() == 0 {
+				continue
+			}
+			if position.debt {
+				balance.Neg(balance)
+			}
+			underlying, err := a.reader.underlying(ctx, reserve.asset, balance)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, Holding{
+				Protocol:     AdapterAaveV3,
+				PositionID:   position.token,
+				Name:         position.name,
+				TokenAddress: position.token,
+				Underlying:   []Underlying{underlying},
+			})
+		}
+	}
+	return out, nil
+}
+
+// getReserves returns the pool's reserves, which are cached as they rarely change
+func (a *aaveV3Adapter) getReserves(ctx context.Context) ([]aaveReserve, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if a.reserves != nil && time.Since(a.loadedAt) < aaveReserveTTL {
+		return a.reserves, nil
+	}
+
+	words, err := a.reader.call(ctx, a.pool, getReservesListSelector, 2)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get aave reserves")
+	}
+	assets, err := decodeAddressArray(words)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode aave reserves")
+	}
+
+	reserves := make([]aaveReserve, 0, len(assets))
+	for _, asset := range assets {
+		// The aToken and variable debt token are the 9th and 11th fields of the ReserveData struct
+		data, err := a.reader.call(ctx, a.pool, getReserveDataSelector+encodeAddress(asset), 11)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get aave reserve data for %s", asset)
+		}
+		reserves = append(reserves, aaveReserve{
+			asset:             asset,
+			aToken:            wordToAddress(data[8]),
+			variableDebtToken: wordToAddress(data[10]),
+		})
+	}
+	a.reserves = reserves
+	a.loadedAt = time.Now()
+	return reserves, nil
+}
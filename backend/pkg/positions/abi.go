@@ -0,0 +1,172 @@
+package positions
+
+import (
+	"context"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/numbergroup/errors"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/eth"
+)
+
+var (
+	balanceOfSelector   = methodID("balanceOf(address)")
+	totalSupplySelector = methodID("totalSupply()")
+	decimalsSelector    = methodID("decimals()")
+)
+
+// methodID returns the hex encoded 4 byte selector of a function signature
+func methodID(signature string) string {
+	return "0x" + hex.EncodeToString(crypto.Keccak256([]byte(signature))[:4])
+}
+
+func encodeAddress(address string) string {
+	return strings.Repeat("0", 24) + strings.TrimPrefix(strings.ToLower(address), "0x")
+}
+
+func encodeUint(n *big.Int) string {
+	return hex.EncodeToString(n.FillBytes(make([]byte, 32)))
+}
+
+// decodeWords splits an ABI encoded result into 32 byte words
+func decodeWords(result string) ([][]byte, error) {
+	data, err := hex.DecodeString(strings.TrimPrefix(result, "0x"))
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid hex")
+	}
+	if len(data)%32 != 0 {
+		return nil, errors.Errorf("result is not a whole number of words: %d bytes", len(data))
+	}
+	words := make([][]byte, 0, len(data)/32)
+	for i := 0; i < len(data); i += 32 {
+		words = append(words, data[i:i+32])
+	}
+	return words, nil
+}
+
+func wordToAddress(word []byte) string {
+	return "0x" + hex.EncodeToString(word[12:32])
+}
+
+// wordToInt decodes a signed integer, such as a Uniswap v3 tick
+func wordToInt(word []byte) *big.Int {
+	n := new(big.Int).SetBytes(word)
+	if word[0]&0x80 != 0 {
+		n.Sub(n, new(big.Int).Lsh(big.NewInt(1), 256))
+	}
+	return n
+}
+
+// decodeAddressArray decodes a result holding a single dynamic address[]
+func decodeAddressArray(words [][]byte) ([]string, error) {
+	if len(words) < 2 {
+		return nil, errors.New("result too short for an array")
+	}
+	offset := new(big.Int).SetBytes(words[0])
+	if !offset.IsUint64() || offset.Uint64()%32 != 0 || offset.Uint64()/32 >= uint64(len(words)) {
+		return nil, errors.New("array offset out of range")
+	}
+	start := offset.Uint64() / 32
+	length := new(big.Int).SetBytes(words[start])
+	if !length.IsUint64() || start+1+length.Uint64() > uint64(len(words)) {
+		return nil, errors.New("array length out of range")
+	}
+	out := make([]string, 0, length.Uint64())
+	for _, word := range words[start+1 : start+1+length.Uint64()] {
+		out = append(out, wordToAddress(word))
+	}
+	return out, nil
+}
+
+// errShortResult is returned for calls which returned fewer words than expected, nothing at all when the contract
+// doesn't have the function
+var errShortResult = errors.New("result too short")
+
+// notImplemented is true when a call failed because the contract reverted or returned too little, as contracts which
+// don't have the function called do. Any other error means the node couldn't answer.
+func notImplemented(err error) bool {
+	return errors.Is(err, errShortResult) || strings.Contains(strings.ToLower(err.Error()), "revert")
+}
+
+// reader makes the contract calls shared by the adapters
+type reader struct {
+	ethClient eth.Client
+
+	lock     sync.Mutex
+	decimals map[string]int
+}
+
+func newReader(ethClient eth.Client) *reader {
+	return &reader{
+		ethClient: ethClient,
+		decimals:  map[string]int{},
+	}
+}
+
+// call runs an eth_call and decodes the result, which must have at least minWords words
+func (r *reader) call(ctx context.Context, to, data string, minWords int) ([][]byte, error) {
+	result, err := r.ethClient.Call(ctx, to, data, "latest")
+	if err != nil {
+		return nil, err
+	}
+	words, err := decodeWords(result)
+	if err != nil {
+		return nil, err
+	}
+	if len(words) < minWords {
+		return nil, errors.Wrapf(errShortResult, "expected %d words from %s, got %d", minWords, to, len(words))
+	}
+	return words, nil
+}
+
+func (r *reader) callUint(ctx context.Context, to, data string) (*big.Int, error) {
+	words, err := r.call(ctx, to, data, 1)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(words[0]), nil
+}
+
+func (r *reader) balanceOf(ctx context.Context, token, owner string) (*big.Int, error) {
+	balance, err := r.callUint(ctx, token, balanceOfSelector+encodeAddress(owner))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get balance of %s", token)
+	}
+	return balance, nil
+}
+
+// tokenDecimals returns the decimals of an ERC-20 token, which are cached as they never change
+func (r *reader) tokenDecimals(ctx context.Context, token string) (int, error) {
+	r.lock.Lock()
+	decimals, ok := r.decimals[token]
+	r.lock.Unlock()
+	if ok {
+		return decimals, nil
+	}
+
+	n, err := r.callUint(ctx, token, decimalsSelector)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to get decimals of %s", token)
+	}
+	if !n.IsInt64() || n.Int64() > 255 {
+		return 0, errors.Errorf("invalid decimals for %s", token)
+	}
+
+	r.lock.Lock()
+	r.decimals[token] = int(n.Int64())
+	r.lock.Unlock()
+	return int(n.Int64()), nil
+}
+
+// underlying builds an Underlying for a token, looking up its decimals
+func (r *reader) underlying(ctx context.Context, token string, amount *big.Int) (Underlying, error) {
+	decimals, err := r.tokenDecimals(ctx, token)
+	if err != nil {
+		return Underlying{}, err
+	}
+	return Underlying{Address: token, Decimals: decimals, Amount: amount}, nil
+}
@@ -0,0 +1,127 @@
+package positions
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ETHCF/ethutils"
+	"github.com/numbergroup/errors"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/eth"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+// Names of the available adapters, used to enable them in the config
+const (
+	AdapterStakedETH = "staked-eth"
+	AdapterAaveV3    = "aave-v3"
+	AdapterUniswapV2 = "uniswap-v2"
+	AdapterUniswapV3 = "uniswap-v3"
+)
+
+// Adapter reads the positions a wallet holds in a DeFi protocol from the chain
+type Adapter interface {
+	Name() string
+	// Positions returns the wallet's positions. tokens are the ERC-20 tokens held by the wallet,
+	// which adapters for protocols that issue position tokens use to avoid unnecessary calls.
+	Positions(ctx context.Context, wallet string, tokens []string) ([]Holding, error)
+}
+
+// Holding is a position as read from the chain, before it is valued
+type Holding struct {
+	Protocol     string
+	PositionID   string
+	Name         string
+	TokenAddress string // The ERC-20 token representing the position, empty if there isn't one
+	Underlying   []Underlying
+}
+
+// Underlying is the raw amount of an asset in a position, negative if it is borrowed
+type Underlying struct {
+	Address  string
+	Decimals int
+	Amount   *big.Int
+}
+
+// NewAdapters creates the adapters with the given names
+func NewAdapters(names []string, ethClient eth.Client) ([]Adapter, error) {
+	r := newReader(ethClient)
+	adapters := make([]Adapter, 0, len(names))
+	for _, name := range names {
+		switch name {
+		case AdapterStakedETH:
+			adapters = append(adapters, NewStakedETHAdapter(r))
+		case AdapterAaveV3:
+			adapters = append(adapters, NewAaveV3Adapter(r))
+		case AdapterUniswapV2:
+			adapters = append(adapters, NewUniswapV2Adapter(r))
+		case AdapterUniswapV3:
+			adapters = append(adapters, NewUniswapV3Adapter(r))
+		case "":
+		default:
+			return nil, errors.Errorf("unknown position adapter %q", name)
+		}
+	}
+	return adapters, nil
+}
+
+// UnderlyingAddresses returns every asset underlying the holdings, which need to be priced
+func UnderlyingAddresses(holdings []Holding) []string {
+	seen := map[string]bool{}
+	out := []string{}
+	for _, holding := range holdings {
+		for _, u := range holding.Underlying {
+			if !seen[u.Address] {
+				seen[u.Address] = true
+				out = append(out, u.Address)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Value prices a holding using the valuations of its underlying assets. Assets without a price are
+// kept with a zero value.
+func Value(wallet string, holding Holding, valuations map[string]types.Valuation, now time.Time) types.Position {
+	position := types.Position{
+		ChainID:     1,
+		Wallet:      wallet,
+		Protocol:    holding.Protocol,
+		PositionID:  holding.PositionID,
+		Name:        holding.Name,
+		Underlying:  make(types.PositionAssets, 0, len(holding.Underlying)),
+		LastUpdated: now,
+	}
+	if holding.TokenAddress != "" {
+		position.TokenAddress = null.StringFrom(holding.TokenAddress)
+	}
+	for _, u := range holding.Underlying {
+		amount := ethutils.ToDecimal(u.Amount, u.Decimals)
+		asset := types.PositionAsset{
+			Address: u.Address,
+			Amount:  amount.Text('f', -1),
+		}
+		if valuation, ok := valuations[u.Address]; ok && valuation.UsdPrice.Valid {
+			asset.UsdPrice = valuation.UsdPrice
+			asset.UsdWorth, _ = new(big.Float).Mul(amount, big.NewFloat(valuation.UsdPrice.Float64)).Float64()
+			// Excluded assets are shown but don't add to the value, as with balances
+			if valuation.Policy != types.ValuationPolicyExcluded {
+				position.UsdWorth += asset.UsdWorth
+			}
+		}
+		position.Underlying = append(position.Underlying, asset)
+	}
+	return position
+}
+
+func tokenSet(tokens []string) map[string]bool {
+	out := make(map[string]bool, len(tokens))
+	for _, token := range tokens {
+		out[token] = true
+	}
+	return out
+}
@@ -0,0 +1,234 @@
+package positions
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ETHCF/ethutils"
+	"github.com/numbergroup/errors"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/constants"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/eth"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+// mockClient answers eth_calls from a map of to+data to results, reverting when there is no result. Calls to an address
+// in down fail as if the node couldn't be reached.
+type mockClient struct {
+	eth.Client
+	results map[string]string
+	down    map[string]bool
+}
+
+func (m *mockClient) Call(ctx context.Context, to string, data string, blockTag string) (string, error) {
+	if m.down[to] {
+		return "", errors.New("request failed: 429 Too Many Requests")
+	}
+	result, ok := m.results[to+data]
+	if !ok {
+		return "", errors.New("execution reverted")
+	}
+	return result, nil
+}
+
+func words(values ...string) string {
+	return "0x" + strings.Join(values, "")
+}
+
+func uintWord(n int64) string {
+	return encodeUint(big.NewInt(n))
+}
+
+func bigWord(s string) string {
+	n, _ := new(big.Int).SetString(s, 10)
+	return encodeUint(n)
+}
+
+func TestSelectors(t *testing.T) {
+	require.Equal(t, "0x70a08231", balanceOfSelector)
+	require.Equal(t, "0x18160ddd", totalSupplySelector)
+	require.Equal(t, "0x313ce567", decimalsSelector)
+	require.Equal(t, "0x0dfe1681", token0Selector)
+	require.Equal(t, "0xd21220a7", token1Selector)
+	require.Equal(t, "0x0902f1ac", getReservesSelector)
+	require.Equal(t, "0x99fbab88", positionsSelector)
+	require.Equal(t, "0x3850c7bd", slot0Selector)
+}
+
+func TestWordToInt(t *testing.T) {
+	require.Equal(t, int64(-887220), wordToInt(encodeNegative(887220)).Int64())
+	require.Equal(t, int64(60), wordToInt(big.NewInt(60).FillBytes(make([]byte, 32))).Int64())
+}
+
+func encodeNegative(n int64) []byte {
+	v := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(n))
+	return v.FillBytes(make([]byte, 32))
+}
+
+func TestNewAdapters(t *testing.T) {
+	adapters, err := NewAdapters([]string{AdapterStakedETH, AdapterAaveV3, AdapterUniswapV2, AdapterUniswapV3}, &mockClient{})
+	require.NoError(t, err)
+	require.Len(t, adapters, 4)
+	require.Equal(t, AdapterUniswapV3, adapters[3].Name())
+
+	_, err = NewAdapters([]string{"compound"}, &mockClient{})
+	require.Error(t, err)
+}
+
+func TestStakedETHAdapter(t *testing.T) {
+	var (
+		wallet = ethutils.GenRandEVMAddr()
+		stETH  = stakedETHTokens[0]
+		wstETH = stakedETHTokens[1]
+		client = &mockClient{results: map[string]string{
+			stETH.address + balanceOfSelector + encodeAddress(wallet):  words(bigWord("2000000000000000000")),
+			wstETH.address + balanceOfSelector + encodeAddress(wallet): words(bigWord("1000000000000000000")),
+			wstETH.address + wstETH.rateSelector:                       words(bigWord("1150000000000000000")),
+		}}
+		adapter = NewStakedETHAdapter(newReader(client))
+	)
+
+	// Tokens the wallet doesn't hold aren't checked
+	holdings, err := adapter.Positions(context.Background(), wallet, []string{wstETH.address})
+	require.NoError(t, err)
+	require.Len(t, holdings, 1)
+	require.Equal(t, "lido", holdings[0].Protocol)
+	require.Equal(t, wstETH.address, holdings[0].TokenAddress)
+	require.Equal(t, constants.EtherAddress, holdings[0].Underlying[0].Address)
+	require.Equal(t, "1150000000000000000", holdings[0].Underlying[0].Amount.String())
+
+	holdings, err = adapter.Positions(context.Background(), wallet, []string{stETH.address, wstETH.address})
+	require.NoError(t, err)
+	require.Len(t, holdings, 2)
+	require.Equal(t, "2000000000000000000", holdings[0].Underlying[0].Amount.String())
+}
+
+func TestAaveV3Adapter(t *testing.T) {
+	var (
+		wallet   = ethutils.GenRandEVMAddr()
+		usdc     = ethutils.GenRandEVMAddr()
+		aUSDC    = ethutils.GenRandEVMAddr()
+		debtUSDC = ethutils.GenRandEVMAddr()
+	)
+	reserveData := make([]string, 15)
+	for i := range reserveData {
+		reserveData[i] = uintWord(0)
+	}
+	reserveData[8] = encodeAddress(aUSDC)
+	reserveData[10] = encodeAddress(debtUSDC)
+
+	client := &mockClient{results: map[string]string{
+		aaveV3PoolAddress + getReservesListSelector:                      words(uintWord(32), uintWord(1), encodeAddress(usdc)),
+		aaveV3PoolAddress + getReserveDataSelector + encodeAddress(usdc): words(reserveData...),
+		aUSDC + balanceOfSelector + encodeAddress(wallet):                words(uintWord(5000000)),
+		debtUSDC + balanceOfSelector + encodeAddress(wallet):             words(uintWord(1000000)),
+		usdc + decimalsSelector:                                          words(uintWord(6)),
+	}}
+	adapter := NewAaveV3Adapter(newReader(client))
+
+	holdings, err := adapter.Positions(context.Background(), wallet, []string{aUSDC, debtUSDC})
+	require.NoError(t, err)
+	require.Len(t, holdings, 2)
+	require.Equal(t, aUSDC, holdings[0].TokenAddress)
+	require.Equal(t, usdc, holdings[0].Underlying[0].Address)
+	require.Equal(t, 6, holdings[0].Underlying[0].Decimals)
+	require.Equal(t, int64(5000000), holdings[0].Underlying[0].Amount.Int64())
+	require.Equal(t, int64(-1000000), holdings[1].Underlying[0].Amount.Int64())
+}
+
+func TestUniswapV2Adapter(t *testing.T) {
+	var (
+		wallet = ethutils.GenRandEVMAddr()
+		pair   = ethutils.GenRandEVMAddr()
+		other  = ethutils.GenRandEVMAddr()
+		token0 = ethutils.GenRandEVMAddr()
+		token1 = ethutils.GenRandEVMAddr()
+		client = &mockClient{results: map[string]string{
+			pair + token0Selector: words(encodeAddress(token0)),
+			pair + token1Selector: words(encodeAddress(token1)),
+			uniswapV2FactoryAddress + getPairSelector + encodeAddress(token0) + encodeAddress(token1): words(encodeAddress(pair)),
+			pair + balanceOfSelector + encodeAddress(wallet):                                          words(uintWord(25)),
+			pair + totalSupplySelector:                                                                words(uintWord(100)),
+			pair + getReservesSelector:                                                                words(uintWord(4000), uintWord(800), uintWord(0)),
+			token0 + decimalsSelector:                                                                 words(uintWord(18)),
+			token1 + decimalsSelector:                                                                 words(uintWord(6)),
+		}}
+		adapter = NewUniswapV2Adapter(newReader(client))
+	)
+
+	// A pair which can't be read yet fails the lookup, rather than being remembered as not a pair
+	client.down = map[string]bool{pair: true}
+	_, err := adapter.Positions(context.Background(), wallet, []string{pair})
+	require.ErrorContains(t, err, "failed to get token0")
+	client.down = nil
+
+	// Other tokens revert on token0 and are skipped
+	holdings, err := adapter.Positions(context.Background(), wallet, []string{other, pair})
+	require.NoError(t, err)
+	require.Len(t, holdings, 1)
+	require.Equal(t, pair, holdings[0].TokenAddress)
+	require.Equal(t, int64(1000), holdings[0].Underlying[0].Amount.Int64())
+	require.Equal(t, int64(200), holdings[0].Underlying[1].Amount.Int64())
+	require.Equal(t, 6, holdings[0].Underlying[1].Decimals)
+}
+
+func TestLiquidityAmounts(t *testing.T) {
+	liquidity := big.NewInt(1e18)
+	q96 := new(big.Int).Lsh(big.NewInt(1), 96)
+
+	// At a price of 1 in the middle of a symmetric range the position holds equal amounts
+	amount0, amount1 := LiquidityAmounts(liquidity, q96, -100, 100)
+	require.InDelta(t, 0, new(big.Int).Sub(amount0, amount1).Int64(), 1e6)
+	require.Greater(t, amount0.Sign(), 0)
+
+	// Below the range it is all token0, above it all token1
+	amount0, amount1 = LiquidityAmounts(liquidity, q96, 100, 200)
+	require.Greater(t, amount0.Sign(), 0)
+	require.Equal(t, 0, amount1.Sign())
+	amount0, amount1 = LiquidityAmounts(liquidity, q96, -200, -100)
+	require.Equal(t, 0, amount0.Sign())
+	require.Greater(t, amount1.Sign(), 0)
+}
+
+func TestValue(t *testing.T) {
+	var (
+		token  = ethutils.GenRandEVMAddr()
+		other  = ethutils.GenRandEVMAddr()
+		now    = time.Now()
+		wallet = ethutils.GenRandEVMAddr()
+	)
+	holding := Holding{
+		Protocol:     AdapterUniswapV2,
+		PositionID:   token,
+		Name:         "Uniswap v2 LP",
+		TokenAddress: token,
+		Underlying: []Underlying{
+			{Address: constants.EtherAddress, Decimals: 18, Amount: big.NewInt(5e17)},
+			{Address: other, Decimals: 6, Amount: big.NewInt(2000000)},
+		},
+	}
+
+	position := Value(wallet, holding, map[string]types.Valuation{
+		constants.EtherAddress: {Policy: types.ValuationPolicyMarket, UsdPrice: null.FloatFrom(3000)},
+	}, now)
+	require.Equal(t, wallet, position.Wallet)
+	require.Equal(t, token, position.TokenAddress.String)
+	require.InDelta(t, 1500, position.UsdWorth, 0.0001)
+	require.Equal(t, "0.5", position.Underlying[0].Amount)
+	require.Equal(t, "2", position.Underlying[1].Amount)
+	require.False(t, position.Underlying[1].UsdPrice.Valid)
+
+	require.Equal(t, sortedPair(constants.EtherAddress, other), UnderlyingAddresses([]Holding{holding, holding}))
+}
+
+func sortedPair(a, b string) []string {
+	if a > b {
+		return []string{b, a}
+	}
+	return []string{a, b}
+}
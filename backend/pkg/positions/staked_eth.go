@@ -0,0 +1,77 @@
+package positions
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/numbergroup/errors"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/constants"
+)
+
+// stakedETHToken is a liquid staking token, redeemable for ETH at the rate returned by rateSelector
+type stakedETHToken struct {
+	address  string
+	protocol string
+	name     string
+	// Returns the ETH per token scaled by 1e18, empty for rebasing tokens which are 1:1 with ETH
+	rateSelector string
+}
+
+var stakedETHTokens = []stakedETHToken{
+	{address: "0xae7ab96520de3a18e5e111b5eaab095312d7fe84", protocol: "lido", name: "Lido stETH"},
+	{address: "0x7f39c581f595b53c5cb19bd0b3f8da6c935e2ca0", protocol: "lido", name: "Lido wstETH", rateSelector: methodID("stEthPerToken()")},
+	{address: "0xae78736cd615f374d3085123a210448e74fc6393", protocol: "rocket-pool", name: "Rocket Pool rETH", rateSelector: methodID("getExchangeRate()")},
+	{address: "0xbe9895146f7af43049ca1c1ae358b0541ea49704", protocol: "coinbase", name: "Coinbase cbETH", rateSelector: methodID("exchangeRate()")},
+}
+
+var oneEther = big.NewInt(1e18)
+
+type stakedETHAdapter struct {
+	reader *reader
+	tokens []stakedETHToken
+}
+
+// NewStakedETHAdapter values liquid staking tokens by the ETH they can be redeemed for
+func NewStakedETHAdapter(r *reader) Adapter {
+	return &stakedETHAdapter{reader: r, tokens: stakedETHTokens}
+}
+
+func (a *stakedETHAdapter) Name() string {
+	return AdapterStakedETH
+}
+
+func (a *stakedETHAdapter) Positions(ctx context.Context, wallet string, tokens []string) ([]Holding, error) {
+	held := tokenSet(tokens)
+	out := []Holding{}
+	for _, token := range a.tokens {
+		if !held[token.address] {
+			continue
+		}
+		balance, err := a.reader.balanceOf(ctx, token.address, wallet)
+		if err != nil {
+			return nil, err
+		}
+		if balance.Sign() == 0 {
+			continue
+		}
+
+		amount := balance
+		if token.rateSelector != "" {
+			rate, err := a.reader.callUint(ctx, token.address, token.rateSelector)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to get exchange rate of %s", token.name)
+			}
+			amount = new(big.Int).Div(new(big.Int).Mul(balance, rate), oneEther)
+		}
+
+		out = append(out, Holding{
+			Protocol:     token.protocol,
+			PositionID:   token.address,
+			Name:         token.name,
+			TokenAddress: token.address,
+			Underlying:   []Underlying{{Address: constants.EtherAddress, Decimals: 18, Amount: amount}},
+		})
+	}
+	return out, nil
+}
@@ -0,0 +1,289 @@
+package positions
+
+import (
+	"context"
+	"math"
+	"math/big"
+	"strconv"
+	"sync"
+
+	"github.com/numbergroup/errors"
+)
+
+const (
+	uniswapV2FactoryAddress         = "0x5c69bee701ef814a2b6a3edd4b1652cb9cc5aa6f"
+	uniswapV3FactoryAddress         = "0x1f98431c8ad98523631ae4a59f267346ea31f984"
+	uniswapV3PositionManagerAddress = "0xc36442b4a4522e871399cd717abdd847ab11fe88"
+
+	// Limits the calls made for wallets holding many v3 positions
+	maxUniswapV3Positions = 100
+)
+
+var (
+	token0Selector              = methodID("token0()")
+	token1Selector              = methodID("token1()")
+	getPairSelector             = methodID("getPair(address,address)")
+	getReservesSelector         = methodID("getReserves()")
+	tokenOfOwnerByIndexSelector = methodID("tokenOfOwnerByIndex(address,uint256)")
+	positionsSelector           = methodID("positions(uint256)")
+	getPoolSelector             = methodID("getPool(address,address,uint24)")
+	slot0Selector               = methodID("slot0()")
+)
+
+type uniswapPair struct {
+	token0 string
+	token1 string
+}
+
+type uniswapV2Adapter struct {
+	reader  *reader
+	factory string
+
+	lock sync.Mutex
+	// Whether each token the wallets hold is a pair, nil when it isn't
+	pairs map[string]*uniswapPair
+}
+
+// NewUniswapV2Adapter reads Uniswap v2 LP tokens, valued at their share of the pair's reserves
+func NewUniswapV2Adapter(r *reader) Adapter {
+	return &uniswapV2Adapter{reader: r, factory: uniswapV2FactoryAddress, pairs: map[string]*uniswapPair{}}
+}
+
+func (a *uniswapV2Adapter) Name() string {
+	return AdapterUniswapV2
+}
+
+func (a *uniswapV2Adapter) Positions(ctx context.Context, wallet string, tokens []string) ([]Holding, error) {
+	out := []Holding{}
+	for _, token := range tokens {
+		pair, err := a.getPair(ctx, token)
+		if err != nil {
+			return nil, err
+		}
+		if pair == nil {
+			continue
+		}
+
+		balance, err := a.reader.balanceOf(ctx, token, wallet)
+		if err != nil {
+			return nil, err
+		}
+		if balance.Sign() == 0 {
+			continue
+		}
+		totalSupply, err := a.reader.callUint(ctx, token, totalSupplySelector)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get total supply of %s", token)
+		}
+		if totalSupply.Sign() == 0 {
+			continue
+		}
+		reserves, err := a.reader.call(ctx, token, getReservesSelector, 2)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get reserves of %s", token)
+		}
+
+		holding := Holding{
+			Protocol:     AdapterUniswapV2,
+			PositionID:   token,
+			Name:         "Uniswap v2 LP",
+			TokenAddress: token,
+		}
+		for i, asset := range []string{pair.token0, pair.token1} {
+			amount := new(big.Int).SetBytes(reserves[i])
+			amount.Div(amount.Mul(amount, balance), totalSupply)
+			underlying, err := a.reader.underlying(ctx, asset, amount)
+			if err != nil {
+				return nil, err
+			}
+			holding.Underlying = append(holding.Underlying, underlying)
+		}
+		out = append(out, holding)
+	}
+	return out, nil
+}
+
+// getPair checks whether a token is a Uniswap v2 pair, by asking the factory for the pair of its tokens
+func (a *uniswapV2Adapter) getPair(ctx context.Context, token string) (*uniswapPair, error) {
+	a.lock.Lock()
+	pair, ok := a.pairs[token]
+	a.lock.Unlock()
+	if ok {
+		return pair, nil
+	}
+
+	pair, err := a.lookupPair(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	a.lock.Lock()
+	a.pairs[token] = pair
+	a.lock.Unlock()
+	return pair, nil
+}
+
+func (a *uniswapV2Adapter) lookupPair(ctx context.Context, token string) (*uniswapPair, error) {
+	// Tokens which aren't pairs revert or return nothing, other errors aren't cached so the token is looked up again
+	token0, err := a.reader.call(ctx, token, token0Selector, 1)
+	if err != nil {
+		if notImplemented(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to get token0 of %s", token)
+	}
+	token1, err := a.reader.call(ctx, token, token1Selector, 1)
+	if err != nil {
+		if notImplemented(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to get token1 of %s", token)
+	}
+	pair := &uniswapPair{token0: wordToAddress(token0[0]), token1: wordToAddress(token1[0])}
+
+	result, err := a.reader.call(ctx, a.factory, getPairSelector+encodeAddress(pair.token0)+encodeAddress(pair.token1), 1)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get pair from the uniswap v2 factory")
+	}
+	if wordToAddress(result[0]) != token {
+		return nil, nil
+	}
+	return pair, nil
+}
+
+type uniswapV3Adapter struct {
+	reader          *reader
+	factory         string
+	positionManager string
+}
+
+// NewUniswapV3Adapter reads Uniswap v3 LP positions held as NFTs, including fees which have been accounted but not collected
+func NewUniswapV3Adapter(r *reader) Adapter {
+	return &uniswapV3Adapter{reader: r, factory: uniswapV3FactoryAddress, positionManager: uniswapV3PositionManagerAddress}
+}
+
+func (a *uniswapV3Adapter) Name() string {
+	return AdapterUniswapV3
+}
+
+func (a *uniswapV3Adapter) Positions(ctx context.Context, wallet string, tokens []string) ([]Holding, error) {
+	count, err := a.reader.balanceOf(ctx, a.positionManager, wallet)
+	if err != nil {
+		return nil, err
+	}
+	n := count.Int64()
+	if !count.IsInt64() || n > maxUniswapV3Positions {
+		n = maxUniswapV3Positions
+	}
+
+	out := []Holding{}
+	for i := int64(0); i < n; i++ {
+		tokenID, err := a.reader.callUint(ctx, a.positionManager, tokenOfOwnerByIndexSelector+encodeAddress(wallet)+encodeUint(big.NewInt(i)))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get uniswap v3 position id")
+		}
+		holding, err := a.position(ctx, tokenID)
+		if err != nil {
+			return nil, err
+		}
+		if holding != nil {
+			out = append(out, *holding)
+		}
+	}
+	return out, nil
+}
+
+// position reads a single position, returning nil for closed positions
+func (a *uniswapV3Adapter) position(ctx context.Context, tokenID *big.Int) (*Holding, error) {
+	words, err := a.reader.call(ctx, a.positionManager, positionsSelector+encodeUint(tokenID), 12)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get uniswap v3 position %s", tokenID)
+	}
+	var (
+		token0      = wordToAddress(words[2])
+		token1      = wordToAddress(words[3])
+		fee         = new(big.Int).SetBytes(words[4])
+		tickLower   = wordToInt(words[5]).Int64()
+		tickUpper   = wordToInt(words[6]).Int64()
+		liquidity   = new(big.Int).SetBytes(words[7])
+		tokensOwed0 = new(big.Int).SetBytes(words[10])
+		tokensOwed1 = new(big.Int).SetBytes(words[11])
+	)
+	if liquidity.Sign() == 0 && tokensOwed0.Sign() == 0 && tokensOwed1.Sign() == 0 {
+		return nil, nil
+	}
+
+	amount0, amount1 := new(big.Int), new(big.Int)
+	if liquidity.Sign() > 0 {
+		pool, err := a.reader.call(ctx, a.factory, getPoolSelector+encodeAddress(token0)+encodeAddress(token1)+encodeUint(fee), 1)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get pool from the uniswap v3 factory")
+		}
+		slot0, err := a.reader.call(ctx, wordToAddress(pool[0]), slot0Selector, 1)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get uniswap v3 pool price")
+		}
+		amount0, amount1 = LiquidityAmounts(liquidity, new(big.Int).SetBytes(slot0[0]), tickLower, tickUpper)
+	}
+
+	holding := &Holding{
+		Protocol:   AdapterUniswapV3,
+		PositionID: tokenID.String(),
+		Name:       "Uniswap v3 LP #" + tokenID.String() + " (" + strconv.FormatFloat(float64(fee.Int64())/10000, 'f', -1, 64) + "%)",
+	}
+	for _, asset := range []struct {
+		token  string
+		amount *big.Int
+	}{
+		{token: token0, amount: amount0.Add(amount0, tokensOwed0)},
+		{token: token1, amount: amount1.Add(amount1, tokensOwed1)},
+	} {
+		underlying, err := a.reader.underlying(ctx, asset.token, asset.amount)
+		if err != nil {
+			return nil, err
+		}
+		holding.Underlying = append(holding.Underlying, underlying)
+	}
+	return holding, nil
+}
+
+// LiquidityAmounts returns the token amounts of a Uniswap v3 position with the given liquidity and tick
+// range at the pool's current price. The math is done with floats, which is precise enough for valuation.
+func LiquidityAmounts(liquidity, sqrtPriceX96 *big.Int, tickLower, tickUpper int64) (*big.Int, *big.Int) {
+	const prec = 256
+	var (
+		l       = new(big.Float).SetPrec(prec).SetInt(liquidity)
+		q96     = new(big.Float).SetPrec(prec).SetInt(new(big.Int).Lsh(big.NewInt(1), 96))
+		sqrtP   = new(big.Float).SetPrec(prec).Quo(new(big.Float).SetPrec(prec).SetInt(sqrtPriceX96), q96)
+		sqrtA   = new(big.Float).SetPrec(prec).SetFloat64(math.Pow(1.0001, float64(tickLower)/2))
+		sqrtB   = new(big.Float).SetPrec(prec).SetFloat64(math.Pow(1.0001, float64(tickUpper)/2))
+		amount0 = new(big.Float).SetPrec(prec)
+		amount1 = new(big.Float).SetPrec(prec)
+	)
+
+	// amount0 = L * (sqrtB - sqrtX) / (sqrtX * sqrtB), amount1 = L * (sqrtX - sqrtA)
+	token0Amount := func(sqrtX *big.Float) *big.Float {
+		num := new(big.Float).SetPrec(prec).Sub(sqrtB, sqrtX)
+		num.Mul(num, l)
+		den := new(big.Float).SetPrec(prec).Mul(sqrtX, sqrtB)
+		return num.Quo(num, den)
+	}
+	token1Amount := func(sqrtX *big.Float) *big.Float {
+		out := new(big.Float).SetPrec(prec).Sub(sqrtX, sqrtA)
+		return out.Mul(out, l)
+	}
+
+	switch {
+	case sqrtP.Cmp(sqrtA) <= 0:
+		amount0 = token0Amount(sqrtA)
+	case sqrtP.Cmp(sqrtB) >= 0:
+		amount1 = token1Amount(sqrtB)
+	default:
+		amount0 = token0Amount(sqrtP)
+		amount1 = token1Amount(sqrtP)
+	}
+
+	out0, _ := amount0.Int(nil)
+	out1, _ := amount1.Int(nil)
+	return out0, out1
+}
@@ -0,0 +1,139 @@
+package reconcile
+
+import (
+	"encoding/hex"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/numbergroup/errors"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/eth"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+// BalanceOfSelector is the selector of balanceOf(address)
+const BalanceOfSelector = "0x70a08231"
+
+// BlockTag encodes a block number for RPC calls
+func BlockTag(blockNumber uint64) string {
+	return "0x" + strconv.FormatUint(blockNumber, 16)
+}
+
+// BalanceOfCallData encodes a call to balanceOf(owner)
+func BalanceOfCallData(owner string) string {
+	return BalanceOfSelector + strings.Repeat("0", 24) + strings.TrimPrefix(strings.ToLower(owner), "0x")
+}
+
+// DecodeBalance decodes the result of a balanceOf call
+func DecodeBalance(result string) (*big.Int, error) {
+	data, err := hex.DecodeString(strings.TrimPrefix(result, "0x"))
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid hex")
+	}
+	if len(data) < 32 {
+		return nil, errors.Errorf("result too short for a balance: %d bytes", len(data))
+	}
+	return new(big.Int).SetBytes(data[:32]), nil
+}
+
+// TransactionFee reads the gas paid for a transaction from its receipt
+func TransactionFee(receipt *eth.TransactionReceipt) (types.TransactionFee, error) {
+	gasUsed, ok := new(big.Int).SetString(strings.TrimPrefix(receipt.GasUsed, "0x"), 16)
+	if !ok {
+		return types.TransactionFee{}, errors.Errorf("invalid gas used %q", receipt.GasUsed)
+	}
+	gasPrice, ok := new(big.Int).SetString(strings.TrimPrefix(receipt.EffectiveGasPrice, "0x"), 16)
+	if !ok {
+		return types.TransactionFee{}, errors.Errorf("invalid effective gas price %q", receipt.EffectiveGasPrice)
+	}
+	blockNumber, err := strconv.ParseInt(strings.TrimPrefix(receipt.BlockNumber, "0x"), 16, 64)
+	if err != nil {
+		return types.TransactionFee{}, errors.Wrap(err, "invalid block number")
+	}
+	return types.TransactionFee{
+		ChainID:     1,
+		TxHash:      strings.ToLower(receipt.TransactionHash),
+		Payer:       strings.ToLower(receipt.From),
+		Fee:         new(big.Int).Mul(gasUsed, gasPrice).String(),
+		BlockNumber: blockNumber,
+	}, nil
+}
+
+func parseAmount(name, amount string) (*big.Int, error) {
+	value, ok := new(big.Int).SetString(amount, 10)
+	if !ok {
+		return nil, errors.Errorf("invalid %s %q", name, amount)
+	}
+	return value, nil
+}
+
+// Compare reconciles the expected balance of a wallet with its on-chain balance at the checkpoint block.
+// The balance is expected to be the starting balance plus the transfers in, minus the transfers out and the gas paid.
+func Compare(wallet string, checkpoint uint64, expected types.ExpectedBalance, onchain *big.Int, now time.Time) (types.ReconciliationResult, error) {
+	balance := new(big.Int)
+	for _, part := range []struct {
+		name   string
+		amount string
+		sign   int
+	}{
+		{"starting balance", expected.StartingBalance, 1},
+		{"transfers in", expected.TransfersIn, 1},
+		{"transfers out", expected.TransfersOut, -1},
+		{"fees", expected.Fees, -1},
+	} {
+		value, err := parseAmount(part.name, part.amount)
+		if err != nil {
+			return types.ReconciliationResult{}, err
+		}
+		if part.sign < 0 {
+			value.Neg(value)
+		}
+		balance.Add(balance, value)
+	}
+
+	difference := new(big.Int).Sub(onchain, balance)
+	return types.ReconciliationResult{
+		ChainID:         1,
+		Wallet:          wallet,
+		Asset:           expected.Asset,
+		CheckpointBlock: int64(checkpoint),
+		StartingBalance: expected.StartingBalance,
+		TransfersIn:     expected.TransfersIn,
+		TransfersOut:    expected.TransfersOut,
+		Fees:            expected.Fees,
+		ExpectedBalance: balance.String(),
+		OnchainBalance:  onchain.String(),
+		Difference:      difference.String(),
+		Reconciled:      difference.Sign() == 0,
+		CheckedAt:       now,
+	}, nil
+}
+
+// BuildReport groups the results by wallet, keeping their order
+func BuildReport(results []types.ReconciliationResult) types.ReconciliationReport {
+	report := types.ReconciliationReport{Reconciled: true, Wallets: []types.WalletReconciliation{}}
+	index := map[string]int{}
+	for _, result := range results {
+		i, ok := index[result.Wallet]
+		if !ok {
+			i = len(report.Wallets)
+			index[result.Wallet] = i
+			report.Wallets = append(report.Wallets, types.WalletReconciliation{
+				Wallet:          result.Wallet,
+				Reconciled:      true,
+				CheckpointBlock: result.CheckpointBlock,
+				Assets:          []types.ReconciliationResult{},
+			})
+		}
+		wallet := &report.Wallets[i]
+		wallet.Assets = append(wallet.Assets, result)
+		if !result.Reconciled {
+			wallet.Reconciled = false
+			report.Reconciled = false
+			report.Discrepancies++
+		}
+	}
+	return report
+}
@@ -0,0 +1,95 @@
+package reconcile
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ETHCF/ethutils"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/eth"
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+func TestBalanceOf(t *testing.T) {
+	require.Equal(t, BalanceOfSelector, "0x"+hex.EncodeToString(crypto.Keccak256([]byte("balanceOf(address)"))[:4]))
+
+	owner := ethutils.GenRandEVMAddr()
+	data := BalanceOfCallData(owner)
+	require.Len(t, data, 2+8+64)
+	require.Equal(t, owner[2:], data[len(data)-40:])
+
+	balance, err := DecodeBalance("0x" + hex.EncodeToString(big.NewInt(1234).FillBytes(make([]byte, 32))))
+	require.NoError(t, err)
+	require.Equal(t, int64(1234), balance.Int64())
+
+	_, err = DecodeBalance("0x")
+	require.Error(t, err)
+
+	require.Equal(t, "0x10", BlockTag(16))
+}
+
+func TestTransactionFee(t *testing.T) {
+	txHash := ethutils.GenRandEVMHash()
+	fee, err := TransactionFee(&eth.TransactionReceipt{
+		BlockNumber:       "0x10",
+		From:              "0xAbC0000000000000000000000000000000000001",
+		GasUsed:           "0x5208",     // 21000
+		EffectiveGasPrice: "0x3b9aca00", // 1 gwei
+		TransactionHash:   txHash,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "21000000000000", fee.Fee)
+	require.Equal(t, "0xabc0000000000000000000000000000000000001", fee.Payer)
+	require.Equal(t, int64(16), fee.BlockNumber)
+	require.Equal(t, txHash, fee.TxHash)
+
+	_, err = TransactionFee(&eth.TransactionReceipt{BlockNumber: "0x10", GasUsed: "", EffectiveGasPrice: "0x1"})
+	require.Error(t, err)
+}
+
+func TestCompare(t *testing.T) {
+	wallet := ethutils.GenRandEVMAddr()
+	now := time.Now()
+	expected := types.ExpectedBalance{Asset: "0xeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee", StartingBalance: "100", TransfersIn: "50", TransfersOut: "30", Fees: "5"}
+
+	result, err := Compare(wallet, 42, expected, big.NewInt(115), now)
+	require.NoError(t, err)
+	require.True(t, result.Reconciled)
+	require.Equal(t, "115", result.ExpectedBalance)
+	require.Equal(t, "0", result.Difference)
+	require.Equal(t, int64(42), result.CheckpointBlock)
+
+	// A missed incoming transfer shows as a positive difference
+	result, err = Compare(wallet, 42, expected, big.NewInt(125), now)
+	require.NoError(t, err)
+	require.False(t, result.Reconciled)
+	require.Equal(t, "10", result.Difference)
+
+	expected.Fees = "not a number"
+	_, err = Compare(wallet, 42, expected, big.NewInt(125), now)
+	require.Error(t, err)
+}
+
+func TestBuildReport(t *testing.T) {
+	report := BuildReport(nil)
+	require.True(t, report.Reconciled)
+	require.Empty(t, report.Wallets)
+
+	report = BuildReport([]types.ReconciliationResult{
+		{Wallet: "0x1", Asset: "0xa", CheckpointBlock: 10, Reconciled: true},
+		{Wallet: "0x2", Asset: "0xa", CheckpointBlock: 12, Reconciled: true},
+		{Wallet: "0x1", Asset: "0xb", CheckpointBlock: 10, Reconciled: false},
+	})
+	require.False(t, report.Reconciled)
+	require.Equal(t, 1, report.Discrepancies)
+	require.Len(t, report.Wallets, 2)
+	require.Equal(t, "0x1", report.Wallets[0].Wallet)
+	require.False(t, report.Wallets[0].Reconciled)
+	require.Len(t, report.Wallets[0].Assets, 2)
+	require.True(t, report.Wallets[1].Reconciled)
+	require.Equal(t, int64(12), report.Wallets[1].CheckpointBlock)
+}
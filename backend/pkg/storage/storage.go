@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+	"github.com/numbergroup/errors"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/config"
+)
+
+// Storage keeps uploaded files, such as receipts and grant documents. The records of the files only hold the storage
+// ID they were given.
+type Storage interface {
+	Put(ctx context.Context, r io.Reader) (string, error)
+	Get(ctx context.Context, storageID string) (io.ReadCloser, error)
+	Delete(ctx context.Context, storageID string) error
+}
+
+type localStorage struct {
+	dir string
+}
+
+// NewStorage creates a storage keeping the files in the configured directory, which is created on the first upload
+func NewStorage(conf *config.Config) Storage {
+	return &localStorage{dir: conf.StorageDir}
+}
+
+func (ls *localStorage) Put(ctx context.Context, r io.Reader) (string, error) {
+	err := os.MkdirAll(ls.dir, 0o750)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create storage directory")
+	}
+
+	storageID := uuid.New().String()
+	file, err := os.OpenFile(filepath.Join(ls.dir, storageID), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o640)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create file")
+	}
+	_, err = io.Copy(file, r)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return "", errors.Wrap(err, "failed to write file")
+	}
+	return storageID, nil
+}
+
+func (ls *localStorage) Get(ctx context.Context, storageID string) (io.ReadCloser, error) {
+	path, err := ls.path(storageID)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, errors.New("file not found")
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open file")
+	}
+	return file, nil
+}
+
+func (ls *localStorage) Delete(ctx context.Context, storageID string) error {
+	path, err := ls.path(storageID)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "failed to delete file")
+	}
+	return nil
+}
+
+// path returns where a file is kept, storage IDs are UUIDs so they can't point outside of the directory
+func (ls *localStorage) path(storageID string) (string, error) {
+	id, err := uuid.Parse(storageID)
+	if err != nil {
+		return "", errors.New("invalid storage ID")
+	}
+	return filepath.Join(ls.dir, id.String()), nil
+}
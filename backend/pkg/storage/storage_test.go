@@ -0,0 +1,33 @@
+package storage
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/config"
+)
+
+func Test_LocalStorage(t *testing.T) {
+	s := NewStorage(&config.Config{StorageDir: t.TempDir() + "/uploads"})
+
+	storageID, err := s.Put(t.Context(), strings.NewReader("grant agreement"))
+	require.NoError(t, err)
+
+	file, err := s.Get(t.Context(), storageID)
+	require.NoError(t, err)
+	content, err := io.ReadAll(file)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+	require.Equal(t, "grant agreement", string(content))
+
+	require.NoError(t, s.Delete(t.Context(), storageID))
+	_, err = s.Get(t.Context(), storageID)
+	require.ErrorContains(t, err, "not found")
+	require.NoError(t, s.Delete(t.Context(), storageID))
+
+	_, err = s.Get(t.Context(), "../../etc/passwd")
+	require.ErrorContains(t, err, "invalid storage ID")
+}
@@ -0,0 +1,68 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gopkg.in/guregu/null.v4"
+)
+
+type AnomalyKind string
+
+const (
+	AnomalyLargeOutflow    AnomalyKind = "large_outflow"    // Worth more than the usual outflow
+	AnomalyNewCounterparty AnomalyKind = "new_counterparty" // First payment to an address nobody has named
+	AnomalyUnusualHour     AnomalyKind = "unusual_hour"     // Sent at an hour the treasury rarely sends at
+)
+
+type AnomalyStatus string
+
+const (
+	AnomalyStatusOpen         AnomalyStatus = "open"
+	AnomalyStatusAcknowledged AnomalyStatus = "acknowledged"
+	AnomalyStatusDismissed    AnomalyStatus = "dismissed"
+)
+
+// Outflow is an outgoing transfer to an address outside the treasury, as the anomaly detector sees it
+type Outflow struct {
+	ID             uuid.UUID  `json:"id" db:"id"`
+	PayeeAddress   string     `json:"payeeAddress" db:"payee_address"`
+	BlockTimestamp int64      `json:"blockTimestamp" db:"block_timestamp"`
+	UsdValue       null.Float `json:"usdValue" db:"usd_value"`      // At the current price, null when the asset has no price
+	KnownParty     bool       `json:"knownParty" db:"known_party"`  // Named by an admin, or a grant recipient
+	Checked        bool       `json:"checked" db:"anomaly_checked"` // Already scored, or ingested before scoring existed
+}
+
+// TransferAnomaly is an unusual outgoing transfer, open until an admin reviews it
+type TransferAnomaly struct {
+	ID         uuid.UUID     `json:"id" db:"id"`
+	TransferID uuid.UUID     `json:"transferId" db:"transfer_id"`
+	Kind       AnomalyKind   `json:"kind" db:"kind"`
+	Score      float64       `json:"score" db:"score"` // From 0 to 1, how unusual the transfer is
+	Reason     string        `json:"reason" db:"reason"`
+	Status     AnomalyStatus `json:"status" db:"status"`
+	Note       null.String   `json:"note" db:"note"`
+	ReviewedBy null.String   `json:"reviewedBy" db:"reviewed_by"`
+	ReviewedAt null.Time     `json:"reviewedAt" db:"reviewed_at"`
+	CreatedAt  time.Time     `json:"createdAt" db:"created_at"`
+}
+
+// TransferAnomalyListing is an anomaly with the transfer it was raised on
+type TransferAnomalyListing struct {
+	TransferAnomaly
+	TxHash         string      `json:"txHash" db:"tx_hash"`
+	BlockTimestamp int64       `json:"blockTimestamp" db:"block_timestamp"`
+	PayerAddress   string      `json:"payerAddress" db:"payer_address"`
+	PayeeAddress   string      `json:"payeeAddress" db:"payee_address"`
+	PayeeName      null.String `json:"payeeName" db:"payee_name"`
+	Asset          string      `json:"asset" db:"asset"`
+	AssetSymbol    null.String `json:"assetSymbol" db:"asset_symbol"`
+	Amount         string      `json:"amount" db:"amount"`
+	UsdValue       null.Float  `json:"usdValue" db:"usd_value"`
+}
+
+// ReviewAnomalyRequest changes the status of an anomaly, annotates it, or both
+type ReviewAnomalyRequest struct {
+	Status AnomalyStatus `json:"status" binding:"omitempty,oneof=open acknowledged dismissed"`
+	Note   null.String   `json:"note"`
+}
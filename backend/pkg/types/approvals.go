@@ -0,0 +1,33 @@
+package types
+
+import (
+	"time"
+
+	"gopkg.in/guregu/null.v4"
+)
+
+// TokenApproval is the allowance a treasury wallet has granted to a spender for an ERC-20 token
+type TokenApproval struct {
+	ChainID int64  `json:"chainId" db:"chain_id"`
+	Owner   string `json:"owner" db:"owner"`
+	Token   string `json:"token" db:"token"`
+	Spender string `json:"spender" db:"spender"`
+	// The amount in the latest Approval event, and the allowance left after any spending, in raw units
+	ApprovedAmount string `json:"approvedAmount" db:"approved_amount"`
+	Allowance      string `json:"allowance" db:"allowance"`
+	// The latest Approval event
+	BlockNumber    int64     `json:"blockNumber" db:"block_number"`
+	BlockTimestamp time.Time `json:"blockTimestamp" db:"block_timestamp"`
+	TxHash         string    `json:"txHash" db:"tx_hash"`
+	LogIndex       int       `json:"logIndex" db:"log_index"`
+	UpdatedAt      time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// ApprovalListing is an approval with labels for display, and flags for risky approvals
+type ApprovalListing struct {
+	TokenApproval
+	SpenderName null.String `json:"spenderName" db:"spender_name"`
+	TokenSymbol null.String `json:"tokenSymbol" db:"token_symbol"`
+	Unlimited   bool        `json:"unlimited" db:"-"`
+	Stale       bool        `json:"stale" db:"-"`
+}
@@ -0,0 +1,61 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+)
+
+// CompositionEntry is the value held in one bucket of the treasury, such as an asset class, chain or wallet group
+type CompositionEntry struct {
+	Key      string  `json:"key"`
+	Name     string  `json:"name"`
+	ValueUsd float64 `json:"valueUsd"`
+	Percent  float64 `json:"percent"` // Share of the total value, 0-100
+}
+
+// Concentration measures how much of the treasury sits in single assets
+type Concentration struct {
+	// Herfindahl-Hirschman index of the asset shares, from near 0 when spread out to 1 when everything is in one asset
+	HHI                   float64           `json:"hhi"`
+	LargestAsset          *CompositionEntry `json:"largestAsset"`
+	LargestNonStableAsset *CompositionEntry `json:"largestNonStableAsset"`
+}
+
+// TreasuryComposition breaks the counted treasury value down by asset class, chain, wallet group and asset.
+// Wallet groups can share wallets, so their percentages may add up to more than 100.
+type TreasuryComposition struct {
+	TotalValueUsd float64            `json:"totalValueUsd"`
+	ByClass       []CompositionEntry `json:"byClass"`
+	ByChain       []CompositionEntry `json:"byChain"`
+	ByGroup       []CompositionEntry `json:"byGroup"`
+	ByAsset       []CompositionEntry `json:"byAsset"`
+	Concentration Concentration      `json:"concentration"`
+	ComputedAt    time.Time          `json:"computedAt"`
+}
+
+func (tc TreasuryComposition) Value() (driver.Value, error) {
+	return json.Marshal(tc)
+}
+
+func (tc *TreasuryComposition) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, tc)
+	case string:
+		return json.Unmarshal([]byte(v), tc)
+	}
+	return nil
+}
+
+// CompositionSnapshot is the composition recorded by the tracker for a day, the last refresh of the day wins
+type CompositionSnapshot struct {
+	SnapshotDate  time.Time           `json:"snapshotDate" db:"snapshot_date"`
+	TotalValueUsd float64             `json:"totalValueUsd" db:"total_value_usd"`
+	Composition   TreasuryComposition `json:"composition" db:"composition"`
+}
+
+type CompositionResponse struct {
+	Current TreasuryComposition   `json:"current"`
+	History []CompositionSnapshot `json:"history"` // Oldest first
+}
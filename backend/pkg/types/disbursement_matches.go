@@ -0,0 +1,75 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gopkg.in/guregu/null.v4"
+)
+
+type DisbursementMatchStatus string
+
+const (
+	DisbursementMatchProposed  DisbursementMatchStatus = "proposed"
+	DisbursementMatchConfirmed DisbursementMatchStatus = "confirmed"
+	DisbursementMatchRejected  DisbursementMatchStatus = "rejected"
+)
+
+// GrantAddress is another address a grant recipient is paid at, besides the recipient address
+type GrantAddress struct {
+	GrantID   uuid.UUID   `json:"grantId" db:"grant_id"`
+	Address   string      `json:"address" db:"address"`
+	Label     null.String `json:"label" db:"label"`
+	CreatedAt time.Time   `json:"createdAt" db:"created_at"`
+}
+
+type AddGrantAddressRequest struct {
+	Address string      `json:"address" binding:"required"`
+	Label   null.String `json:"label"`
+}
+
+// DisbursementCandidate is an outgoing transfer to one of the addresses of a grant, in an asset the grant or one of its
+// milestones is paid in, not yet linked to a disbursement
+type DisbursementCandidate struct {
+	TransferID     uuid.UUID `json:"transferId" db:"transfer_id"`
+	GrantID        uuid.UUID `json:"grantId" db:"grant_id"`
+	TxHash         string    `json:"txHash" db:"tx_hash"`
+	BlockTimestamp int64     `json:"blockTimestamp" db:"block_timestamp"`
+	Asset          string    `json:"asset" db:"asset"`
+	Amount         string    `json:"amount" db:"amount"` // In whole units of the asset, like disbursements
+}
+
+// DisbursementMatch links a transfer to a grant it may have paid. A confirmed match is backed by a disbursement.
+type DisbursementMatch struct {
+	ID         uuid.UUID               `json:"id" db:"id"`
+	TransferID uuid.UUID               `json:"transferId" db:"transfer_id"`
+	GrantID    uuid.UUID               `json:"grantId" db:"grant_id"`
+	Status     DisbursementMatchStatus `json:"status" db:"status"`
+	ReviewedBy null.String             `json:"reviewedBy" db:"reviewed_by"`
+	ReviewedAt null.Time               `json:"reviewedAt" db:"reviewed_at"`
+	CreatedAt  time.Time               `json:"createdAt" db:"created_at"`
+}
+
+// DisbursementMatchListing is a match with the transfer and grant it links
+type DisbursementMatchListing struct {
+	DisbursementMatch
+	GrantName      string `json:"grantName" db:"grant_name"`
+	TxHash         string `json:"txHash" db:"tx_hash"`
+	BlockNumber    int64  `json:"blockNumber" db:"block_number"`
+	BlockTimestamp int64  `json:"blockTimestamp" db:"block_timestamp"`
+	PayerAddress   string `json:"payerAddress" db:"payer_address"`
+	PayeeAddress   string `json:"payeeAddress" db:"payee_address"`
+	Asset          string `json:"asset" db:"asset"`
+	Amount         string `json:"amount" db:"amount"` // In whole units of the asset
+}
+
+// SplitAllocation is the part of a transfer disbursed to one grant
+type SplitAllocation struct {
+	GrantID uuid.UUID `json:"grantId" binding:"required"`
+	Amount  string    `json:"amount" binding:"required"` // In ether
+}
+
+// SplitMatchRequest divides the transfer of a match between grants, the amounts have to add up to the transfer amount
+type SplitMatchRequest struct {
+	Allocations []SplitAllocation `json:"allocations" binding:"required,min=1,dive"`
+}
@@ -0,0 +1,41 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gopkg.in/guregu/null.v4"
+)
+
+type ExchangeMovementDirection string
+
+const (
+	ExchangeDeposit    ExchangeMovementDirection = "deposit"
+	ExchangeWithdrawal ExchangeMovementDirection = "withdrawal"
+)
+
+// ExchangeBalance is the balance of one currency held in a custodial exchange account
+type ExchangeBalance struct {
+	Exchange    string     `json:"exchange" db:"exchange"`
+	Currency    string     `json:"currency" db:"currency"`
+	Amount      string     `json:"amount" db:"amount"`      // High precision decimal as string
+	UsdValue    null.Float `json:"usdValue" db:"usd_value"` // Null when the exchange doesn't report a USD value
+	LastUpdated time.Time  `json:"lastUpdated" db:"last_updated"`
+}
+
+// ExchangeMovement is a deposit to or withdrawal from an exchange account
+type ExchangeMovement struct {
+	Exchange   string                    `json:"exchange" db:"exchange"`
+	ExternalID string                    `json:"externalId" db:"external_id"` // The exchange's ID for the movement
+	Direction  ExchangeMovementDirection `json:"direction" db:"direction"`
+	Currency   string                    `json:"currency" db:"currency"`
+	Amount     string                    `json:"amount" db:"amount"`
+	Status     string                    `json:"status" db:"status"`
+	TxHash     null.String               `json:"txHash" db:"tx_hash"` // Only set for Ethereum style transaction hashes
+	Address    null.String               `json:"address" db:"address"`
+	OccurredAt time.Time                 `json:"occurredAt" db:"occurred_at"`
+	UpdatedAt  time.Time                 `json:"updatedAt" db:"updated_at"`
+
+	// The on-chain transfer with the same tx hash, matched when reading movements
+	TransferID uuid.NullUUID `json:"transferId" db:"transfer_id"`
+}
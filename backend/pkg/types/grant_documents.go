@@ -0,0 +1,53 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gopkg.in/guregu/null.v4"
+)
+
+type GrantDocumentCategory string
+
+const (
+	GrantDocumentAgreement         GrantDocumentCategory = "agreement"
+	GrantDocumentReport            GrantDocumentCategory = "report"
+	GrantDocumentMilestoneEvidence GrantDocumentCategory = "milestone_evidence"
+	GrantDocumentOther             GrantDocumentCategory = "other"
+)
+
+type DocumentVisibility string
+
+const (
+	DocumentPublic  DocumentVisibility = "public"
+	DocumentPrivate DocumentVisibility = "private" // Only admins can list and download it
+)
+
+// GrantDocument is a file attached to a grant, or to one of its milestones
+type GrantDocument struct {
+	ID          uuid.UUID             `json:"id" db:"id"`
+	GrantID     uuid.UUID             `json:"grantId" db:"grant_id"`
+	MilestoneID uuid.NullUUID         `json:"milestoneId" db:"milestone_id"`
+	Category    GrantDocumentCategory `json:"category" db:"category"`
+	Name        string                `json:"name" db:"name"`
+	FileName    null.String           `json:"fileName" db:"file_name"`
+	FileSize    int64                 `json:"fileSize" db:"file_size"`
+	MimeType    null.String           `json:"mimeType" db:"mime_type"`
+	StorageID   string                `json:"-" db:"storage_id"`  // Internal storage path, not exposed in JSON
+	DownloadURL string                `json:"downloadUrl" db:"-"` // Generated URL, not stored in DB
+	Visibility  DocumentVisibility    `json:"visibility" db:"visibility"`
+	UploadedBy  string                `json:"uploadedBy" db:"uploaded_by"`
+	CreatedAt   time.Time             `json:"createdAt" db:"created_at"`
+}
+
+type UploadGrantDocumentRequest struct {
+	Name        string                `form:"name" binding:"required"`
+	Category    GrantDocumentCategory `form:"category" binding:"omitempty,oneof=agreement report milestone_evidence other"`
+	Visibility  DocumentVisibility    `form:"visibility" binding:"omitempty,oneof=public private"`
+	MilestoneID string                `form:"milestoneId"`
+	// File is handled separately by gin's multipart form handling
+}
+
+type UpdateDocumentVisibilityRequest struct {
+	Visibility DocumentVisibility `json:"visibility" binding:"required,oneof=public private"`
+}
@@ -0,0 +1,105 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gopkg.in/guregu/null.v4"
+)
+
+// GrantSubmissionKind is what a grant recipient submitted
+type GrantSubmissionKind string
+
+const (
+	GrantSubmissionMilestoneProgress GrantSubmissionKind = "milestone_progress"
+	GrantSubmissionFundsUsage        GrantSubmissionKind = "funds_usage"
+	GrantSubmissionDocument          GrantSubmissionKind = "document"
+)
+
+type GrantSubmissionStatus string
+
+const (
+	GrantSubmissionPending  GrantSubmissionStatus = "pending"
+	GrantSubmissionApproved GrantSubmissionStatus = "approved"
+	GrantSubmissionRejected GrantSubmissionStatus = "rejected"
+)
+
+// GrantSubmissionPayload holds what was submitted, only the fields of the submission's kind are set
+type GrantSubmissionPayload struct {
+	Evidence   string            `json:"evidence,omitempty"` // Milestone progress
+	FundsUsage *CreateFundsUsage `json:"fundsUsage,omitempty"`
+	Title      string            `json:"title,omitempty"` // Document
+	URL        string            `json:"url,omitempty"`
+	Note       string            `json:"note,omitempty"` // Anything the recipient wants the admins to know
+}
+
+func (p GrantSubmissionPayload) Value() (driver.Value, error) {
+	return json.Marshal(p)
+}
+
+func (p *GrantSubmissionPayload) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, p)
+	case string:
+		return json.Unmarshal([]byte(v), p)
+	}
+	return nil
+}
+
+type CreateGrantSubmission struct {
+	GrantID          uuid.UUID              `json:"grantId" db:"grant_id"`
+	MilestoneID      uuid.NullUUID          `json:"milestoneId" db:"milestone_id"`
+	RecipientAddress string                 `json:"recipientAddress" db:"recipient_address"`
+	Kind             GrantSubmissionKind    `json:"kind" db:"kind"`
+	Payload          GrantSubmissionPayload `json:"payload" db:"payload"`
+}
+
+// GrantSubmission is an update submitted by a grant recipient, which only takes effect once an admin approves it
+type GrantSubmission struct {
+	ID uuid.UUID `json:"id" db:"id"`
+	CreateGrantSubmission
+	Status     GrantSubmissionStatus `json:"status" db:"status"`
+	ReviewedBy null.String           `json:"reviewedBy" db:"reviewed_by"`
+	ReviewedAt null.Time             `json:"reviewedAt" db:"reviewed_at"`
+	ReviewNote null.String           `json:"reviewNote" db:"review_note"`
+	CreatedAt  time.Time             `json:"createdAt" db:"created_at"`
+}
+
+// GrantSubmissionFilter selects submissions, unset fields don't filter
+type GrantSubmissionFilter struct {
+	GrantID          uuid.NullUUID
+	RecipientAddress string
+	Status           GrantSubmissionStatus
+	Limit            int
+	Offset           int
+}
+
+type SubmitMilestoneProgressRequest struct {
+	Evidence string `json:"evidence" binding:"required"`
+	Note     string `json:"note"`
+}
+
+type SubmitFundsUsageRequest struct {
+	Item     string      `json:"item" binding:"required"`
+	Quantity int         `json:"quantity" binding:"min=1"`
+	Price    string      `json:"price" binding:"required"`
+	Purpose  string      `json:"purpose" binding:"required"`
+	Category string      `json:"category" binding:"required"`
+	Date     time.Time   `json:"date" binding:"required"`
+	TxHash   null.String `json:"txHash"`
+	Note     string      `json:"note"`
+}
+
+type SubmitDocumentRequest struct {
+	Title string `json:"title" binding:"required"`
+	URL   string `json:"url" binding:"required,url"`
+	Note  string `json:"note"`
+}
+
+type ReviewGrantSubmissionRequest struct {
+	Status GrantSubmissionStatus `json:"status" binding:"required,oneof=approved rejected"`
+	Note   null.String           `json:"note"`
+}
@@ -2,6 +2,7 @@ package types
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,10 +12,57 @@ import (
 type GrantStatus string
 
 const (
-	GrantStatusActive   GrantStatus = "active"
-	GrantStatusPrevious GrantStatus = "previous"
+	GrantStatusProposed   GrantStatus = "proposed"
+	GrantStatusApproved   GrantStatus = "approved"
+	GrantStatusActive     GrantStatus = "active"
+	GrantStatusPaused     GrantStatus = "paused"
+	GrantStatusCompleted  GrantStatus = "completed"
+	GrantStatusCancelled  GrantStatus = "cancelled"
+	GrantStatusClawedBack GrantStatus = "clawed_back"
 )
 
+// GrantTransitions are the statuses a grant can be moved to from each status
+var GrantTransitions = map[GrantStatus][]GrantStatus{
+	GrantStatusProposed:   {GrantStatusApproved, GrantStatusCancelled},
+	GrantStatusApproved:   {GrantStatusActive, GrantStatusCancelled},
+	GrantStatusActive:     {GrantStatusPaused, GrantStatusCompleted, GrantStatusCancelled, GrantStatusClawedBack},
+	GrantStatusPaused:     {GrantStatusActive, GrantStatusCancelled, GrantStatusClawedBack},
+	GrantStatusCompleted:  {GrantStatusClawedBack},
+	GrantStatusCancelled:  {GrantStatusClawedBack},
+	GrantStatusClawedBack: {},
+}
+
+// PreviousGrantStatuses are the statuses of grants which are over
+var PreviousGrantStatuses = []GrantStatus{GrantStatusCompleted, GrantStatusCancelled, GrantStatusClawedBack}
+
+func (s GrantStatus) Valid() bool {
+	_, ok := GrantTransitions[s]
+	return ok
+}
+
+func (s GrantStatus) CanTransitionTo(next GrantStatus) bool {
+	for _, allowed := range GrantTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// Open tells whether work on a grant with the status is expected, so that it can be overdue
+func (s GrantStatus) Open() bool {
+	return s == GrantStatusApproved || s == GrantStatusActive || s == GrantStatusPaused
+}
+
+// GrantStatusEvent is a status transition on the timeline of a grant, recorded in the admin actions
+type GrantStatusEvent struct {
+	From      GrantStatus `json:"from" db:"from_status"`
+	To        GrantStatus `json:"to" db:"to_status"`
+	Reason    string      `json:"reason" db:"reason"`
+	ChangedBy null.String `json:"changedBy" db:"admin_address"`
+	ChangedAt time.Time   `json:"changedAt" db:"created_at"`
+}
+
 type MilestoneStatus string
 
 const (
@@ -28,27 +76,168 @@ type Milestone struct {
 	GrantID     uuid.UUID       `json:"grantId" db:"grant_id"`
 	Name        string          `json:"name" db:"name"`
 	Description string          `json:"description" db:"description"`
-	GrantAmount string          `json:"grantAmount" db:"grant_amount"` // High precision decimal as string
+	Asset       string          `json:"asset" db:"asset"`
+	GrantAmount string          `json:"grantAmount" db:"grant_amount"` // High precision decimal as string, in the asset
 	Status      MilestoneStatus `json:"status" db:"status"`
 	Completed   bool            `json:"completed" db:"completed"`
 	OrderIndex  int             `json:"orderIndex" db:"order_index"`
 	SignedOff   bool            `json:"signedOff" db:"signed_off"`
+	TargetDate  null.Time       `json:"targetDate" db:"target_date"`   // When the milestone is due, null when it isn't scheduled
+	Evidence    null.String     `json:"evidence" db:"evidence"`        // What shows the milestone was completed, usually a link
+	CompletedBy null.String     `json:"completedBy" db:"completed_by"` // The recipient or the admin who marked it completed
+	CompletedAt null.Time       `json:"completedAt" db:"completed_at"`
+	SignedOffAt null.Time       `json:"signedOffAt" db:"signed_off_at"`
 	CreatedAt   time.Time       `json:"createdAt" db:"created_at"`
 	UpdatedAt   time.Time       `json:"updatedAt" db:"updated_at"`
+
+	Approvals []MilestoneApproval     `json:"approvals"`
+	Schedule  MilestoneScheduleStatus `json:"schedule"`
+}
+
+type MilestoneScheduleStatus string
+
+const (
+	MilestoneUnscheduled MilestoneScheduleStatus = "unscheduled"
+	MilestoneOnTrack     MilestoneScheduleStatus = "on_track"
+	MilestoneDueSoon     MilestoneScheduleStatus = "due_soon"
+	MilestoneOverdue     MilestoneScheduleStatus = "overdue"
+)
+
+// MilestoneDueSoonDays is how many days before its target date a pending milestone is due soon
+const MilestoneDueSoonDays = 14
+
+// ScheduleStatus tells whether the recipient is behind on the milestone. Only pending milestones can be due soon or
+// overdue, a milestone is overdue from the day after its target date.
+func (milestone Milestone) ScheduleStatus(now time.Time) MilestoneScheduleStatus {
+	if !milestone.TargetDate.Valid {
+		return MilestoneUnscheduled
+	}
+	if milestone.Status != MilestoneStatusPending {
+		return MilestoneOnTrack
+	}
+	dueBy := milestone.TargetDate.Time.AddDate(0, 0, 1)
+	switch {
+	case !now.Before(dueBy):
+		return MilestoneOverdue
+	case !now.Before(dueBy.AddDate(0, 0, -MilestoneDueSoonDays)):
+		return MilestoneDueSoon
+	}
+	return MilestoneOnTrack
+}
+
+// OverdueMilestone is a pending milestone past its target date, of a grant still expecting work
+type OverdueMilestone struct {
+	Milestone
+	GrantName     string `json:"grantName" db:"grant_name"`
+	RecipientName string `json:"recipientName" db:"recipient_name"`
+	DaysOverdue   int    `json:"daysOverdue" db:"days_overdue"`
+}
+
+type MilestoneEventType string
+
+const (
+	MilestoneEventOverdue MilestoneEventType = "overdue"
+)
+
+// MilestoneEvent is recorded by the tracker when something happens to a milestone on its own, such as passing its
+// target date
+type MilestoneEvent struct {
+	ID          uuid.UUID          `json:"id" db:"id"`
+	MilestoneID uuid.UUID          `json:"milestoneId" db:"milestone_id"`
+	GrantID     uuid.UUID          `json:"grantId" db:"grant_id"`
+	Event       MilestoneEventType `json:"event" db:"event"`
+	TargetDate  time.Time          `json:"targetDate" db:"target_date"`
+	CreatedAt   time.Time          `json:"createdAt" db:"created_at"`
+}
+
+// MilestoneApproval is an admin's approval of a completed milestone, it is signed off once enough admins approve it
+type MilestoneApproval struct {
+	MilestoneID  uuid.UUID   `json:"milestoneId" db:"milestone_id"`
+	AdminAddress string      `json:"adminAddress" db:"admin_address"`
+	Signature    null.String `json:"signature" db:"signature"` // EIP-712 signature over the approval, when the admin signed it
+	CreatedAt    time.Time   `json:"createdAt" db:"created_at"`
+}
+
+// MilestoneSignOffSettings are how many distinct admins have to approve a milestone to sign it off, and whether
+// signing it off proposes paying it
+type MilestoneSignOffSettings struct {
+	RequiredApprovals   int  `json:"requiredApprovals" binding:"min=1"`
+	ProposeDisbursement bool `json:"proposeDisbursement"`
+}
+
+type CompleteMilestoneRequest struct {
+	Evidence string `json:"evidence" binding:"required"`
+}
+
+// RecipientCompleteMilestoneRequest is a milestone marked completed by the grant recipient, who signs the completion
+// challenge for the evidence instead of logging in
+type RecipientCompleteMilestoneRequest struct {
+	Evidence  string `json:"evidence" binding:"required"`
+	Signature string `json:"signature" binding:"required"`
+}
+
+type ApproveMilestoneRequest struct {
+	Signature null.String `json:"signature"` // Optional EIP-712 signature over the approval challenge
+}
+
+type DisbursementProposalStatus string
+
+const (
+	DisbursementProposalProposed  DisbursementProposalStatus = "proposed"
+	DisbursementProposalDisbursed DisbursementProposalStatus = "disbursed"
+	DisbursementProposalDismissed DisbursementProposalStatus = "dismissed"
+)
+
+// DisbursementProposal is a payment of a signed off milestone for an admin to make
+type DisbursementProposal struct {
+	ID               uuid.UUID                  `json:"id" db:"id"`
+	GrantID          uuid.UUID                  `json:"grantId" db:"grant_id"`
+	MilestoneID      uuid.UUID                  `json:"milestoneId" db:"milestone_id"`
+	Asset            string                     `json:"asset" db:"asset"`
+	Amount           string                     `json:"amount" db:"amount"`
+	RecipientAddress string                     `json:"recipientAddress" db:"recipient_address"`
+	Status           DisbursementProposalStatus `json:"status" db:"status"`
+	ReviewedBy       null.String                `json:"reviewedBy" db:"reviewed_by"`
+	ReviewedAt       null.Time                  `json:"reviewedAt" db:"reviewed_at"`
+	CreatedAt        time.Time                  `json:"createdAt" db:"created_at"`
+}
+
+type ReviewDisbursementProposalRequest struct {
+	Status DisbursementProposalStatus `json:"status" binding:"required,oneof=disbursed dismissed"`
+}
+
+type DisbursementVerificationStatus string
+
+const (
+	DisbursementUnverified DisbursementVerificationStatus = "unverified" // Not checked yet, or the chain couldn't be read
+	DisbursementVerified   DisbursementVerificationStatus = "verified"
+	DisbursementMismatch   DisbursementVerificationStatus = "mismatch"
+)
+
+// DisbursementVerification is the outcome of checking a disbursement against its transaction
+type DisbursementVerification struct {
+	VerificationStatus DisbursementVerificationStatus `json:"verificationStatus" db:"verification_status"`
+	VerificationReason null.String                    `json:"verificationReason" db:"verification_reason"` // Why it doesn't match, or couldn't be checked
+	VerifiedAt         null.Time                      `json:"verifiedAt" db:"verified_at"`
 }
 
 type CreateDisbursement struct {
-	GrantID        uuid.UUID `json:"grantId" db:"grant_id"`
-	Amount         string    `json:"amount" db:"amount"` // High precision decimal as string
-	TxHash         string    `json:"txHash" db:"tx_hash"`
-	BlockNumber    int64     `json:"blockNumber" db:"block_number"`
-	BlockTimestamp int64     `json:"blockTimestamp" db:"block_timestamp"`
+	GrantID        uuid.UUID  `json:"grantId" db:"grant_id"`
+	Asset          string     `json:"asset" db:"asset"`
+	Amount         string     `json:"amount" db:"amount"` // High precision decimal as string
+	TxHash         string     `json:"txHash" db:"tx_hash"`
+	BlockNumber    int64      `json:"blockNumber" db:"block_number"`
+	BlockTimestamp int64      `json:"blockTimestamp" db:"block_timestamp"`
+	UsdPrice       null.Float `json:"usdPrice" db:"usd_price"` // Price of the asset at the block, null when unknown
+	DisbursementVerification
 }
 
 type Disbursement struct {
 	ID uuid.UUID `json:"id" db:"id"`
 	CreateDisbursement
-	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+	UsdValue   null.Float    `json:"usdValue"`                    // Null when the disbursement has no price
+	TransferID uuid.NullUUID `json:"transferId" db:"transfer_id"` // Set when matched to an ingested transfer
+	CreatedAt  time.Time     `json:"createdAt" db:"created_at"`
 }
 
 type CreateFundsUsage struct {
@@ -77,24 +266,191 @@ type CreateGrant struct {
 	Description            string      `json:"description" db:"description"`
 	TeamURL                null.String `json:"teamUrl" db:"team_url"`
 	ProjectURL             null.String `json:"projectUrl" db:"project_url"`
+	Asset                  string      `json:"asset" db:"asset"` // What the grant amounts are in
 	TotalGrantAmount       string      `json:"totalGrantAmount" db:"total_grant_amount"`
 	InitialGrantAmount     string      `json:"initialGrantAmount" db:"initial_grant_amount"`
 	StartDate              time.Time   `json:"startDate" db:"start_date"`
 	ExpectedCompletionDate time.Time   `json:"expectedCompletionDate" db:"expected_completion_date"`
-	AmountGivenSoFar       string      `json:"amountGivenSoFar" db:"amount_given_so_far"`
+	AmountGivenSoFar       string      `json:"amountGivenSoFar" db:"amount_given_so_far"` // Disbursed in the grant's asset
 	Status                 GrantStatus `json:"status" db:"status"`
 }
 
+// IsOverdue tells whether work on the grant is still expected after its expected completion date
+func (grant CreateGrant) IsOverdue(now time.Time) bool {
+	return grant.Status.Open() && !now.Before(grant.ExpectedCompletionDate.AddDate(0, 0, 1))
+}
+
+type MilestonePaymentStatus string
+
+const (
+	MilestoneUnpaid        MilestonePaymentStatus = "unpaid"
+	MilestonePartiallyPaid MilestonePaymentStatus = "partially_paid"
+	MilestonePaid          MilestonePaymentStatus = "paid"
+)
+
+// MilestonePayment is how much of a milestone has been disbursed. Disbursements of an asset pay the initial grant amount
+// first when it is the grant's asset, then the milestones in that asset in order.
+type MilestonePayment struct {
+	MilestoneID uuid.UUID              `json:"milestoneId"`
+	Paid        string                 `json:"paid"`
+	Status      MilestonePaymentStatus `json:"status"`
+}
+
+// AssetTotals are the figures of a grant in one asset. The grant's total amount is committed in its own asset,
+// milestones in other assets are committed on top of it.
+type AssetTotals struct {
+	Asset        string     `json:"asset"`
+	Committed    string     `json:"committed"`
+	Disbursed    string     `json:"disbursed"`
+	Remaining    string     `json:"remaining"`
+	DisbursedUsd float64    `json:"disbursedUsd"` // At the price of each disbursement, unpriced ones aren't counted
+	Unpriced     int        `json:"unpriced"`     // Disbursements with no price from when they were made
+	RemainingUsd null.Float `json:"remainingUsd"` // At the current price, null when the asset has no price
+}
+
+// GrantTotals are derived from the disbursements of a grant, against the total grant amount it was committed. The
+// top level amounts are in the grant's asset.
+type GrantTotals struct {
+	Disbursed        string             `json:"disbursed"`
+	Remaining        string             `json:"remaining"`
+	PercentDisbursed float64            `json:"percentDisbursed"`
+	Milestones       []MilestonePayment `json:"milestones"`
+	Assets           []AssetTotals      `json:"assets"` // The grant's asset first
+	DisbursedUsd     float64            `json:"disbursedUsd"`
+	Unpriced         int                `json:"unpriced"`     // Disbursements left out of DisbursedUsd, it is incomplete when set
+	RemainingUsd     float64            `json:"remainingUsd"` // Assets without a price aren't counted
+}
+
 type Grant struct {
 	ID uuid.UUID `json:"id" db:"id"`
 	CreateGrant
 
+	Overdue bool        `json:"overdue"` // Derived from the status and expected completion date
+	Totals  GrantTotals `json:"totals"`
+
 	// Related data (populated by joins)
 	Milestones    []Milestone    `json:"milestones,omitempty"`
 	Disbursements []Disbursement `json:"disbursements,omitempty"`
 	FundsUsage    []FundsUsage   `json:"fundsUsage,omitempty"`
 }
 
+type GrantSort string
+
+const (
+	GrantSortCreatedAt        GrantSort = "createdAt"
+	GrantSortName             GrantSort = "name"
+	GrantSortStartDate        GrantSort = "startDate"
+	GrantSortCompletionDate   GrantSort = "expectedCompletionDate"
+	GrantSortTotalGrantAmount GrantSort = "totalGrantAmount"
+	GrantSortAmountGivenSoFar GrantSort = "amountGivenSoFar"
+)
+
+// GrantFilter selects a page of grants, unset fields don't filter. Date ranges are inclusive and amounts are compared
+// in each grant's own asset.
+type GrantFilter struct {
+	Search           string // In the name or description, case insensitive
+	Statuses         []GrantStatus
+	Overdue          bool
+	RecipientAddress string
+	Asset            string
+	StartFrom        null.Time
+	StartTo          null.Time
+	CompletionFrom   null.Time
+	CompletionTo     null.Time
+	MinAmount        null.String // Of the total grant amount
+	MaxAmount        null.String
+	Sort             GrantSort
+	Descending       bool
+	Limit            int
+	Offset           int
+}
+
+// GetSQLConditions returns the conditions on grants for the filter, with their arguments numbered from argIndex
+func (filter GrantFilter) GetSQLConditions(argIndex int) ([]string, []any) {
+	conditions := []string{}
+	args := []any{}
+
+	if filter.Search != "" {
+		conditions = append(conditions, fmt.Sprintf("(name ILIKE $%d OR description ILIKE $%d)", argIndex, argIndex))
+		args = append(args, "%"+likeEscaper.Replace(filter.Search)+"%")
+		argIndex++
+	}
+
+	if len(filter.Statuses) > 0 {
+		placeholders := []string{}
+		for _, status := range filter.Statuses {
+			placeholders = append(placeholders, fmt.Sprintf("$%d", argIndex))
+			args = append(args, status)
+			argIndex++
+		}
+		conditions = append(conditions, fmt.Sprintf("status IN (%s)", strings.Join(placeholders, ", ")))
+	}
+
+	if filter.Overdue {
+		conditions = append(conditions, "status IN ('approved', 'active', 'paused') AND expected_completion_date < CURRENT_DATE")
+	}
+
+	if filter.RecipientAddress != "" {
+		conditions = append(conditions, fmt.Sprintf("recipient_address = $%d", argIndex))
+		args = append(args, filter.RecipientAddress)
+		argIndex++
+	}
+
+	if filter.Asset != "" {
+		conditions = append(conditions, fmt.Sprintf("asset = $%d", argIndex))
+		args = append(args, filter.Asset)
+		argIndex++
+	}
+
+	if filter.StartFrom.Valid {
+		conditions = append(conditions, fmt.Sprintf("start_date >= $%d", argIndex))
+		args = append(args, filter.StartFrom.Time)
+		argIndex++
+	}
+
+	if filter.StartTo.Valid {
+		conditions = append(conditions, fmt.Sprintf("start_date <= $%d", argIndex))
+		args = append(args, filter.StartTo.Time)
+		argIndex++
+	}
+
+	if filter.CompletionFrom.Valid {
+		conditions = append(conditions, fmt.Sprintf("expected_completion_date >= $%d", argIndex))
+		args = append(args, filter.CompletionFrom.Time)
+		argIndex++
+	}
+
+	if filter.CompletionTo.Valid {
+		conditions = append(conditions, fmt.Sprintf("expected_completion_date <= $%d", argIndex))
+		args = append(args, filter.CompletionTo.Time)
+		argIndex++
+	}
+
+	if filter.MinAmount.Valid {
+		conditions = append(conditions, fmt.Sprintf("total_grant_amount >= $%d", argIndex))
+		args = append(args, filter.MinAmount.String)
+		argIndex++
+	}
+
+	if filter.MaxAmount.Valid {
+		conditions = append(conditions, fmt.Sprintf("total_grant_amount <= $%d", argIndex))
+		args = append(args, filter.MaxAmount.String)
+		argIndex++
+	}
+
+	return conditions, args
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// GrantListing is a page of grants, with the number of grants matching the filter across all pages
+type GrantListing struct {
+	Data   []Grant `json:"data"`
+	Total  int     `json:"total"`
+	Limit  int     `json:"limit"`
+	Offset int     `json:"offset"`
+}
+
 type CreateGrantRequest struct {
 	Name                   string    `json:"name" binding:"required"`
 	RecipientName          string    `json:"recipientName" binding:"required"`
@@ -102,6 +458,7 @@ type CreateGrantRequest struct {
 	Description            string    `json:"description" binding:"required"`
 	TeamURL                string    `json:"teamUrl"`
 	ProjectURL             string    `json:"projectUrl"`
+	Asset                  string    `json:"asset"` // Ether when empty
 	TotalGrantAmount       string    `json:"totalGrantAmount" binding:"required"`
 	InitialGrantAmount     string    `json:"initialGrantAmount" binding:"required"`
 	StartDate              time.Time `json:"startDate" binding:"required"`
@@ -116,11 +473,13 @@ type UpdateGrantRequest struct {
 	Description            null.String `json:"description"`
 	TeamURL                null.String `json:"teamUrl"`
 	ProjectURL             null.String `json:"projectUrl"`
+	Asset                  null.String `json:"asset"`
 	TotalGrantAmount       null.String `json:"totalGrantAmount"`
 	InitialGrantAmount     null.String `json:"initialGrantAmount"`
 	StartDate              null.Time   `json:"startDate"`
 	ExpectedCompletionDate null.Time   `json:"expectedCompletionDate"`
 	Status                 null.String `json:"status"`
+	StatusReason           null.String `json:"statusReason"` // Required when the status changes
 }
 
 func (updates UpdateGrantRequest) GetSQLUpdates(argIndex int) ([]string, []any) {
@@ -163,6 +522,12 @@ func (updates UpdateGrantRequest) GetSQLUpdates(argIndex int) ([]string, []any)
 		argIndex++
 	}
 
+	if updates.Asset.Valid {
+		setParts = append(setParts, fmt.Sprintf("asset = $%d", argIndex))
+		args = append(args, updates.Asset.String)
+		argIndex++
+	}
+
 	if updates.TotalGrantAmount.Valid {
 		setParts = append(setParts, fmt.Sprintf("total_grant_amount = $%d", argIndex))
 		args = append(args, updates.TotalGrantAmount.String)
@@ -196,28 +561,28 @@ func (updates UpdateGrantRequest) GetSQLUpdates(argIndex int) ([]string, []any)
 	return setParts, args
 }
 
+// UpdateMilestonesRequest replaces the milestones of a grant. Milestones are matched by ID and keep their completion
+// and approvals, which only change through the sign-off workflow.
 type UpdateMilestonesRequest struct {
 	Milestones []struct {
-		ID          string `json:"id,omitempty"`
-		Title       string `json:"title" binding:"required"`
-		Description string `json:"description" binding:"required"`
-		Amount      string `json:"amount" binding:"required"`
-		Status      string `json:"status"`
-		Completed   bool   `json:"completed"`
-		SignedOff   bool   `json:"signedOff"`
+		ID          string    `json:"id,omitempty"`
+		Title       string    `json:"title" binding:"required"`
+		Description string    `json:"description" binding:"required"`
+		Asset       string    `json:"asset"` // The grant's asset when empty
+		Amount      string    `json:"amount" binding:"required"`
+		TargetDate  null.Time `json:"targetDate"`
 	} `json:"milestones" binding:"required"`
 }
 
+// CreateDisbursementRequest records a payment of a grant. The block number and timestamp are read from the chain,
+// they are only needed when no RPC endpoint is configured.
 type CreateDisbursementRequest struct {
-	Amount         string `json:"amount" binding:"required"`
-	TxHash         string `json:"txHash" binding:"required"`
-	BlockNumber    int64  `json:"blockNumber" binding:"required"`
-	BlockTimestamp int64  `json:"blockTimestamp" binding:"required"`
+	Asset          string     `json:"asset"` // The grant's asset when empty
+	Amount         string     `json:"amount" binding:"required"`
+	TxHash         string     `json:"txHash" binding:"required"`
+	BlockNumber    int64      `json:"blockNumber"`
+	BlockTimestamp int64      `json:"blockTimestamp"`
+	UsdPrice       null.Float `json:"usdPrice"` // The price of the asset at the block when known and not given
 }
 
-type UpdateDisbursementRequest struct {
-	Amount         string `json:"amount" binding:"required"`
-	TxHash         string `json:"txHash" binding:"required"`
-	BlockNumber    int64  `json:"blockNumber" binding:"required"`
-	BlockTimestamp int64  `json:"blockTimestamp" binding:"required"`
-}
+type UpdateDisbursementRequest CreateDisbursementRequest
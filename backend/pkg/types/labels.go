@@ -0,0 +1,50 @@
+package types
+
+import (
+	"time"
+
+	"gopkg.in/guregu/null.v4"
+)
+
+const (
+	LabelSourceManual       = "manual"  // The name set by admins on the transfer party
+	LabelSourceENS          = "ens"     // The name found by the ENS resolver
+	LabelSourceImportPrefix = "import:" // Prefix of sources created by bulk imports
+)
+
+// LabelSource is a set of address labels. When an address has labels from multiple sources
+// the one from the source with the lowest priority value is shown.
+type LabelSource struct {
+	Name       string    `json:"name" db:"name"`
+	Priority   int       `json:"priority" db:"priority"`
+	LabelCount int       `json:"labelCount" db:"label_count"`
+	CreatedAt  time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt  time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+type TransferPartyLabel struct {
+	Address   string    `json:"address" db:"address"`
+	Source    string    `json:"source" db:"source"`
+	Label     string    `json:"label" db:"label"`
+	CreatedAt null.Time `json:"createdAt" db:"created_at"`
+}
+
+type UpdateLabelSourceRequest struct {
+	Priority *int `json:"priority" binding:"required"`
+}
+
+type LabelChange struct {
+	Address  string `json:"address"`
+	OldLabel string `json:"oldLabel"`
+	NewLabel string `json:"newLabel"`
+}
+
+// LabelDiff is the change an import would make to the labels of a source
+type LabelDiff struct {
+	Source    string               `json:"source"`
+	Added     []TransferPartyLabel `json:"added"`
+	Changed   []LabelChange        `json:"changed"`
+	Removed   []TransferPartyLabel `json:"removed"`
+	Unchanged int                  `json:"unchanged"`
+	Errors    []ImportRowError     `json:"errors"`
+}
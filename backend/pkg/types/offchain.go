@@ -0,0 +1,82 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gopkg.in/guregu/null.v4"
+)
+
+// OffchainAccount is a bank or custodial account, its balances are entered by admins from statements
+// rather than read on-chain
+type OffchainAccount struct {
+	ID          uuid.UUID   `json:"id" db:"id"`
+	Name        string      `json:"name" db:"name"`
+	Institution string      `json:"institution" db:"institution"`
+	Currency    string      `json:"currency" db:"currency"`
+	Notes       null.String `json:"notes" db:"notes"`
+	CreatedAt   time.Time   `json:"createdAt" db:"created_at"`
+	UpdatedAt   time.Time   `json:"updatedAt" db:"updated_at"`
+
+	LatestStatement *OffchainStatement `json:"latestStatement" db:"-"`
+}
+
+type OffchainStatement struct {
+	ID            uuid.UUID   `json:"id" db:"id"`
+	AccountID     uuid.UUID   `json:"accountId" db:"account_id"`
+	StatementDate time.Time   `json:"statementDate" db:"statement_date"`
+	Balance       string      `json:"balance" db:"balance"` // In the account's currency, high precision decimal as string
+	UsdValue      float64     `json:"usdValue" db:"usd_value"`
+	Notes         null.String `json:"notes" db:"notes"`
+	CreatedBy     string      `json:"createdBy" db:"created_by"`
+	CreatedAt     time.Time   `json:"createdAt" db:"created_at"`
+	SelfReported  bool        `json:"selfReported" db:"-"` // Always true, set so clients can't mistake it for an on-chain balance
+
+	Documents []OffchainDocument `json:"documents,omitempty" db:"-"`
+}
+
+// OffchainDocument is evidence attached to a statement, such as the statement PDF
+type OffchainDocument struct {
+	ID          uuid.UUID          `json:"id" db:"id"`
+	StatementID uuid.UUID          `json:"statementId" db:"statement_id"`
+	Name        string             `json:"name" db:"name"`
+	FileName    null.String        `json:"fileName" db:"file_name"`
+	FileSize    int64              `json:"fileSize" db:"file_size"`
+	MimeType    null.String        `json:"mimeType" db:"mime_type"`
+	StorageID   string             `json:"-" db:"storage_id"`  // Internal storage path, not exposed in JSON
+	DownloadURL string             `json:"downloadUrl" db:"-"` // Generated URL, not stored in DB
+	Visibility  DocumentVisibility `json:"visibility" db:"visibility"`
+	CreatedAt   time.Time          `json:"createdAt" db:"created_at"`
+}
+
+// OffchainBalance is the latest statement of an off-chain account, as counted in the treasury totals
+type OffchainBalance struct {
+	AccountID     uuid.UUID `json:"accountId" db:"account_id"`
+	Name          string    `json:"name" db:"name"`
+	Institution   string    `json:"institution" db:"institution"`
+	Currency      string    `json:"currency" db:"currency"`
+	Balance       string    `json:"balance" db:"balance"`
+	UsdValue      float64   `json:"usdValue" db:"usd_value"`
+	StatementDate time.Time `json:"statementDate" db:"statement_date"`
+	SelfReported  bool      `json:"selfReported" db:"-"`
+}
+
+type OffchainAccountRequest struct {
+	Name        string      `json:"name" binding:"required"`
+	Institution string      `json:"institution" binding:"required"`
+	Currency    string      `json:"currency" binding:"required,max=16"`
+	Notes       null.String `json:"notes"`
+}
+
+type CreateOffchainStatementRequest struct {
+	StatementDate time.Time   `json:"statementDate" binding:"required"`
+	Balance       string      `json:"balance" binding:"required"`
+	UsdValue      null.Float  `json:"usdValue"` // Required unless the account is held in USD
+	Notes         null.String `json:"notes"`
+}
+
+type UploadOffchainDocumentRequest struct {
+	Name       string             `form:"name" binding:"required"`
+	Visibility DocumentVisibility `form:"visibility" binding:"omitempty,oneof=public private"`
+	// File is handled separately by gin's multipart form handling
+}
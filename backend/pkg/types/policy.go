@@ -0,0 +1,81 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gopkg.in/guregu/null.v4"
+)
+
+type PolicyMetric string
+
+const (
+	PolicyMetricRunwayMonths  PolicyMetric = "runway_months"      // Value of the asset class (stablecoins by default) over the average monthly outflow
+	PolicyMetricClassPercent  PolicyMetric = "class_percent"      // Share of the treasury in the asset class
+	PolicyMetricAssetPercent  PolicyMetric = "asset_percent"      // Share of the treasury in the asset, or in the largest non-stable asset
+	PolicyMetricTotalValueUsd PolicyMetric = "total_value_usd"    // Value of the treasury, or of the asset class when set
+	PolicyMetricSingleOutflow PolicyMetric = "single_outflow_usd" // Largest outgoing transfer in the window
+)
+
+type PolicyComparison string
+
+const (
+	PolicyComparisonMin PolicyComparison = "min" // The metric must be at least the threshold
+	PolicyComparisonMax PolicyComparison = "max" // The metric must be at most the threshold
+)
+
+// PolicyRule is a treasury policy over a metric, along with the result of its latest evaluation
+type PolicyRule struct {
+	ID              uuid.UUID        `json:"id" db:"id"`
+	Name            string           `json:"name" db:"name"`
+	Description     null.String      `json:"description" db:"description"`
+	Metric          PolicyMetric     `json:"metric" db:"metric"`
+	Comparison      PolicyComparison `json:"comparison" db:"comparison"`
+	Threshold       float64          `json:"threshold" db:"threshold"`
+	AssetClass      null.String      `json:"assetClass" db:"asset_class"`
+	Asset           null.String      `json:"asset" db:"asset"`
+	WindowDays      int              `json:"windowDays" db:"window_days"`
+	Enabled         bool             `json:"enabled" db:"enabled"`
+	Violated        bool             `json:"violated" db:"violated"`
+	LastValue       null.Float       `json:"lastValue" db:"last_value"` // Null when the metric couldn't be computed
+	LastEvaluatedAt null.Time        `json:"lastEvaluatedAt" db:"last_evaluated_at"`
+	CreatedBy       string           `json:"createdBy" db:"created_by"`
+	CreatedAt       time.Time        `json:"createdAt" db:"created_at"`
+	UpdatedAt       time.Time        `json:"updatedAt" db:"updated_at"`
+}
+
+type PolicyRuleRequest struct {
+	Name        string           `json:"name" binding:"required"`
+	Description null.String      `json:"description"`
+	Metric      PolicyMetric     `json:"metric" binding:"required,oneof=runway_months class_percent asset_percent total_value_usd single_outflow_usd"`
+	Comparison  PolicyComparison `json:"comparison" binding:"required,oneof=min max"`
+	Threshold   float64          `json:"threshold" binding:"min=0"`
+	AssetClass  null.String      `json:"assetClass"`
+	Asset       null.String      `json:"asset"`
+	WindowDays  int              `json:"windowDays" binding:"min=0,max=3650"` // Defaults to 90
+	Enabled     null.Bool        `json:"enabled"`                             // Defaults to true
+}
+
+// PolicyViolation is a period during which a rule was broken, open until the rule passes again
+type PolicyViolation struct {
+	ID         uuid.UUID        `json:"id" db:"id"`
+	RuleID     uuid.NullUUID    `json:"ruleId" db:"rule_id"` // Null once the rule is deleted
+	RuleName   string           `json:"ruleName" db:"rule_name"`
+	Metric     PolicyMetric     `json:"metric" db:"metric"`
+	Comparison PolicyComparison `json:"comparison" db:"comparison"`
+	Threshold  float64          `json:"threshold" db:"threshold"`
+	FirstValue float64          `json:"firstValue" db:"first_value"`
+	WorstValue float64          `json:"worstValue" db:"worst_value"`
+	LastValue  float64          `json:"lastValue" db:"last_value"`
+	StartedAt  time.Time        `json:"startedAt" db:"started_at"`
+	LastSeenAt time.Time        `json:"lastSeenAt" db:"last_seen_at"`
+	ResolvedAt null.Time        `json:"resolvedAt" db:"resolved_at"`
+}
+
+// OutflowSummary sums the priced transfers leaving the treasury for other addresses over a window
+type OutflowSummary struct {
+	TotalUsd          float64       `json:"totalUsd" db:"total_usd"`
+	LargestUsd        float64       `json:"largestUsd" db:"largest_usd"`
+	LargestTransferID uuid.NullUUID `json:"largestTransferId" db:"largest_transfer_id"`
+	Transfers         int           `json:"transfers" db:"transfers"`
+}
@@ -0,0 +1,55 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+
+	"gopkg.in/guregu/null.v4"
+)
+
+// Position is a DeFi position held by a treasury wallet, such as staked ETH, an Aave deposit or a Uniswap LP position
+type Position struct {
+	ChainID    int64  `json:"chainId" db:"chain_id"`
+	Wallet     string `json:"wallet" db:"wallet"`
+	Protocol   string `json:"protocol" db:"protocol"`
+	PositionID string `json:"positionId" db:"position_id"` // The token address, or the NFT id for Uniswap v3
+	Name       string `json:"name" db:"name"`
+	// The ERC-20 token representing the position, which is not counted again as a plain balance
+	TokenAddress null.String    `json:"tokenAddress" db:"token_address"`
+	Underlying   PositionAssets `json:"underlying" db:"underlying"`
+	UsdWorth     float64        `json:"usdWorth" db:"usd_worth"`
+	LastUpdated  time.Time      `json:"lastUpdated" db:"last_updated"`
+}
+
+// PositionAsset is an asset underlying a position. Borrowed assets have a negative amount.
+type PositionAsset struct {
+	Address  string     `json:"address"`
+	Amount   string     `json:"amount"` // High precision decimal as string
+	UsdPrice null.Float `json:"usdPrice"`
+	UsdWorth float64    `json:"usdWorth"`
+}
+
+type PositionAssets []PositionAsset
+
+func (p PositionAssets) Value() (driver.Value, error) {
+	if p == nil {
+		return json.Marshal([]PositionAsset{})
+	}
+	return json.Marshal(p)
+}
+
+func (p *PositionAssets) Scan(value interface{}) error {
+	if value == nil {
+		*p = PositionAssets{}
+		return nil
+	}
+
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, p)
+	case string:
+		return json.Unmarshal([]byte(v), p)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package types
+
+import (
+	"time"
+)
+
+// TransactionFee is the gas paid by the sender of a transaction, in wei
+type TransactionFee struct {
+	ChainID     int64  `json:"chainId" db:"chain_id"`
+	TxHash      string `json:"txHash" db:"tx_hash"`
+	Payer       string `json:"payer" db:"payer"`
+	Fee         string `json:"fee" db:"fee"`
+	BlockNumber int64  `json:"blockNumber" db:"block_number"`
+}
+
+// StartingBalance is what a wallet held at a block, transfers after it are reconciled against it
+type StartingBalance struct {
+	ChainID     int64     `json:"chainId" db:"chain_id"`
+	Wallet      string    `json:"wallet" db:"wallet"`
+	Asset       string    `json:"asset" db:"asset"`
+	Amount      string    `json:"amount" db:"amount"` // In raw units
+	BlockNumber int64     `json:"blockNumber" db:"block_number"`
+	SetBy       string    `json:"setBy" db:"set_by"`
+	UpdatedAt   time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+type SetStartingBalanceRequest struct {
+	Wallet      string `json:"wallet" binding:"required"`
+	Asset       string `json:"asset" binding:"required"`
+	Amount      string `json:"amount" binding:"required"` // In raw units
+	BlockNumber int64  `json:"blockNumber" binding:"min=0"`
+}
+
+// ExpectedBalance is what the ingested history says a wallet holds of an asset at a block, in raw units
+type ExpectedBalance struct {
+	Asset           string `json:"asset" db:"asset"`
+	StartingBalance string `json:"startingBalance" db:"starting_balance"`
+	TransfersIn     string `json:"transfersIn" db:"transfers_in"`
+	TransfersOut    string `json:"transfersOut" db:"transfers_out"`
+	Fees            string `json:"fees" db:"fees"` // Gas paid by the wallet, only for ether
+}
+
+// ReconciliationResult compares the expected balance of a wallet with its on-chain balance at the checkpoint block
+type ReconciliationResult struct {
+	ChainID         int64     `json:"chainId" db:"chain_id"`
+	Wallet          string    `json:"wallet" db:"wallet"`
+	Asset           string    `json:"asset" db:"asset"`
+	CheckpointBlock int64     `json:"checkpointBlock" db:"checkpoint_block"`
+	StartingBalance string    `json:"startingBalance" db:"starting_balance"`
+	TransfersIn     string    `json:"transfersIn" db:"transfers_in"`
+	TransfersOut    string    `json:"transfersOut" db:"transfers_out"`
+	Fees            string    `json:"fees" db:"fees"`
+	ExpectedBalance string    `json:"expectedBalance" db:"expected_balance"`
+	OnchainBalance  string    `json:"onchainBalance" db:"onchain_balance"`
+	Difference      string    `json:"difference" db:"difference"` // On-chain minus expected, positive when transfers in are missing
+	Reconciled      bool      `json:"reconciled" db:"reconciled"`
+	CheckedAt       time.Time `json:"checkedAt" db:"checked_at"`
+}
+
+// WalletReconciliation is a wallet's reconciliation results, the wallet is reconciled when every asset is
+type WalletReconciliation struct {
+	Wallet          string                 `json:"wallet"`
+	Reconciled      bool                   `json:"reconciled"`
+	CheckpointBlock int64                  `json:"checkpointBlock"`
+	Assets          []ReconciliationResult `json:"assets"`
+}
+
+type ReconciliationReport struct {
+	Reconciled    bool                   `json:"reconciled"`
+	Discrepancies int                    `json:"discrepancies"`
+	Wallets       []WalletReconciliation `json:"wallets"`
+}
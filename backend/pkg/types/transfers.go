@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gopkg.in/guregu/null.v4"
 )
 
 type TransferType string
@@ -18,9 +19,13 @@ type TransferParty struct {
 	Name      string    `json:"name" db:"name"`
 	CreatedAt time.Time `json:"createdAt" db:"created_at"`
 	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
+	// Set by the ENS resolver, the admin set name takes precedence when displaying the party
+	EnsName       null.String `json:"ensName" db:"ens_name"`
+	EnsResolvedAt null.Time   `json:"ensResolvedAt" db:"ens_resolved_at"`
 }
 
 type CreateTransfer struct {
+	ChainID        int64        `json:"chainId" db:"chain_id"`
 	TxHash         string       `json:"txHash" db:"tx_hash"`
 	BlockNumber    int64        `json:"blockNumber" db:"block_number"`
 	BlockTimestamp int64        `json:"blockTimestamp" db:"block_timestamp"`
@@ -39,8 +44,23 @@ type Transfer struct {
 	PayeeName   string    `json:"payeeName" db:"payee_name"`
 	AssetName   string    `json:"assetName" db:"asset_name"`
 	AssetSymbol string    `json:"assetSymbol" db:"asset_symbol"`
+	// Set when the transfer is hidden from the public lists by default
+	FilterReason null.String `json:"filterReason" db:"filter_reason"`
 }
 
 type UpdateTransferPartyNameRequest struct {
 	Name string `json:"name" binding:"required"`
 }
+
+type ImportRowError struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+type TransferImportReport struct {
+	Rows     int              `json:"rows"`
+	Inserted int              `json:"inserted"`
+	Skipped  int              `json:"skipped"` // Duplicates and rows that are not transfers (failed txs, zero value)
+	Failed   int              `json:"failed"`  // Rows that could not be parsed
+	Errors   []ImportRowError `json:"errors"`
+}
@@ -1,15 +1,76 @@
 package types
 
 import (
+	"fmt"
 	"time"
+
+	"github.com/google/uuid"
+	"gopkg.in/guregu/null.v4"
+)
+
+type AssetStatus string
+
+const (
+	AssetStatusTrusted AssetStatus = "trusted" // Never treated as spam by the heuristics
+	AssetStatusHidden  AssetStatus = "hidden"  // Filtered from lists but still counted in totals
+	AssetStatusSpam    AssetStatus = "spam"    // Filtered from lists and totals
+)
+
+// Reasons a balance or transfer is filtered from public lists
+const (
+	FilterReasonHidden        = "hidden"
+	FilterReasonSpam          = "spam"
+	FilterReasonSuspectedSpam = "suspected_spam" // Unknown token without a price which we have never sent
+	FilterReasonDust          = "dust"
+)
+
+type ValuationPolicy string
+
+const (
+	ValuationPolicyMarket   ValuationPolicy = "market"   // The price from the price feed, used when no policy is set
+	ValuationPolicyManual   ValuationPolicy = "manual"   // A fixed price set by an admin
+	ValuationPolicyPegged   ValuationPolicy = "pegged"   // The market price of another asset, or $1 when no asset is set
+	ValuationPolicyDiscount ValuationPolicy = "discount" // The market price less a percentage, for locked or illiquid tokens
+	ValuationPolicyExcluded ValuationPolicy = "excluded" // Valued at the market price but left out of the totals
+)
+
+// Where the price of a balance came from
+const (
+	PriceSourceMarket    = "market"
+	PriceSourceManual    = "manual"
+	PriceSourcePegPrefix = "peg:" // Followed by the address of the asset pegged to, or usd
+)
+
+type AssetClass string
+
+const (
+	AssetClassNative     AssetClass = "native" // ETH and wrapped ETH
+	AssetClassStablecoin AssetClass = "stablecoin"
+	AssetClassGovernance AssetClass = "governance"
+	AssetClassLP         AssetClass = "lp" // Liquidity pool positions and tokens
+	AssetClassOther      AssetClass = "other"
 )
 
+func (ac AssetClass) Valid() bool {
+	switch ac {
+	case AssetClassNative, AssetClassStablecoin, AssetClassGovernance, AssetClassLP, AssetClassOther:
+		return true
+	}
+	return false
+}
+
 type Asset struct {
-	ChainID  int64  `json:"chainId" db:"chain_id"`
-	Address  string `json:"address" db:"address"`
-	Name     string `json:"name" db:"name"`
-	Symbol   string `json:"symbol" db:"symbol"`
-	Decimals int    `json:"decimals" db:"decimals"`
+	ChainID  int64      `json:"chainId" db:"chain_id"`
+	Address  string     `json:"address" db:"address"`
+	Name     string     `json:"name" db:"name"`
+	Symbol   string     `json:"symbol" db:"symbol"`
+	Decimals int        `json:"decimals" db:"decimals"`
+	Class    AssetClass `json:"class" db:"asset_class"`
+}
+
+type SetAssetClassRequest struct {
+	ChainID int64      `json:"chainId"`
+	Class   AssetClass `json:"class" binding:"required,oneof=native stablecoin governance lp other"`
 }
 
 type WalletBalance struct {
@@ -20,20 +81,178 @@ type WalletBalance struct {
 	UsdWorth    float64   `json:"usdWorth" db:"usd_worth"`
 	EthWorth    string    `json:"ethWorth" db:"eth_worth"`
 	LastUpdated time.Time `json:"lastUpdated" db:"last_updated"`
+	// The price used for UsdWorth, null when the asset couldn't be priced
+	UsdPrice        null.Float      `json:"usdPrice" db:"usd_price"`
+	PriceSource     null.String     `json:"priceSource" db:"price_source"`
+	PriceUpdatedAt  null.Time       `json:"priceUpdatedAt" db:"price_updated_at"`
+	ValuationPolicy ValuationPolicy `json:"valuationPolicy" db:"valuation_policy"`
+	// Set when the balance is hidden from the public lists by default
+	FilterReason null.String `json:"filterReason" db:"filter_reason"`
+}
+
+// AssetValuationPolicy overrides how an asset is priced. Only the fields used by the policy are set.
+type AssetValuationPolicy struct {
+	ChainID         int64           `json:"chainId" db:"chain_id"`
+	Address         string          `json:"address" db:"address"`
+	Policy          ValuationPolicy `json:"policy" db:"policy" binding:"required,oneof=market manual pegged discount excluded"`
+	ManualPrice     null.Float      `json:"manualPrice" db:"manual_price"`
+	PegAddress      null.String     `json:"pegAddress" db:"peg_address"`
+	DiscountPercent null.Float      `json:"discountPercent" db:"discount_percent"`
+	Notes           null.String     `json:"notes" db:"notes"`
+	UpdatedAt       time.Time       `json:"updatedAt" db:"updated_at"`
+}
+
+// Valuation is the price of an asset after its valuation policy is applied
+type Valuation struct {
+	Policy    ValuationPolicy
+	UsdPrice  null.Float
+	Source    null.String
+	UpdatedAt null.Time
+}
+
+type AssetStatusEntry struct {
+	ChainID   int64       `json:"chainId" db:"chain_id"`
+	Address   string      `json:"address" db:"address"`
+	Status    AssetStatus `json:"status" db:"status" binding:"required,oneof=trusted hidden spam"`
+	Reason    null.String `json:"reason" db:"reason"`
+	UpdatedAt time.Time   `json:"updatedAt" db:"updated_at"`
+}
+
+// DustThresholds are the USD values below which balances and transfers are hidden by default
+type DustThresholds struct {
+	BalanceUSD  float64 `json:"balanceUsd" binding:"min=0"`
+	TransferUSD float64 `json:"transferUsd" binding:"min=0"`
+}
+
+type WalletType string
+
+const (
+	WalletTypeEOA      WalletType = "eoa"
+	WalletTypeSafe     WalletType = "safe"
+	WalletTypeContract WalletType = "contract"
+)
+
+func (wt WalletType) Valid() bool {
+	switch wt {
+	case WalletTypeEOA, WalletTypeSafe, WalletTypeContract:
+		return true
+	}
+	return false
 }
 
 type Wallet struct {
-	Address string `json:"address" db:"address"`
+	Address     string      `json:"address" db:"address"`
+	ChainID     int64       `json:"chainId" db:"chain_id"`
+	Name        string      `json:"name" db:"name"`
+	Description null.String `json:"description" db:"description"`
+	Type        null.String `json:"type" db:"wallet_type"` // One of the WalletTypes, null when unknown
+
+	// IDs of the groups the wallet belongs to, populated when reading wallets
+	GroupIDs []uuid.UUID `json:"groupIds" db:"-"`
+	// Set when the wallet has signed an ownership challenge, populated when reading wallets
+	Verified     bool                `json:"verified" db:"-"`
+	Verification *WalletVerification `json:"verification,omitempty" db:"-"`
+}
+
+type WalletProofMethod string
+
+const (
+	WalletProofECDSA   WalletProofMethod = "ecdsa"   // Signed by the wallet's own key
+	WalletProofEIP1271 WalletProofMethod = "eip1271" // Accepted by the wallet contract's isValidSignature, used by Safes
+)
+
+// WalletChallenge is the pending ownership challenge for a wallet, the typed data is rebuilt from it on verification
+type WalletChallenge struct {
+	Wallet   string    `json:"wallet" db:"wallet"`
+	Nonce    uuid.UUID `json:"nonce" db:"nonce"`
+	IssuedAt time.Time `json:"issuedAt" db:"issued_at"`
+}
+
+// WalletVerification is the stored proof that a wallet signed an EIP-712 ownership challenge
+type WalletVerification struct {
+	Wallet        string            `json:"wallet" db:"wallet"`
+	Method        WalletProofMethod `json:"method" db:"method"`
+	ChallengeHash string            `json:"challengeHash" db:"challenge_hash"`
+	Signature     string            `json:"signature" db:"signature"`
+	VerifiedBy    string            `json:"verifiedBy" db:"verified_by"`
+	VerifiedAt    time.Time         `json:"verifiedAt" db:"verified_at"`
+}
+
+type VerifyWalletRequest struct {
+	Signature string `json:"signature" binding:"required"` // 0x for Safes that approved the message on-chain
+}
+
+type UpdateWalletRequest struct {
+	ChainID     null.Int    `json:"chainId"`
+	Name        null.String `json:"name"`
+	Description null.String `json:"description"`
+	Type        null.String `json:"type"`
+}
+
+func (updates UpdateWalletRequest) GetSQLUpdates(argIndex int) ([]string, []any) {
+	setParts := []string{}
+	args := []any{}
+
+	if updates.ChainID.Valid {
+		setParts = append(setParts, fmt.Sprintf("chain_id = $%d", argIndex))
+		args = append(args, updates.ChainID.Int64)
+		argIndex++
+	}
+
+	if updates.Name.Valid {
+		setParts = append(setParts, fmt.Sprintf("name = $%d", argIndex))
+		args = append(args, updates.Name.String)
+		argIndex++
+	}
+
+	if updates.Description.Valid {
+		setParts = append(setParts, fmt.Sprintf("description = $%d", argIndex))
+		args = append(args, updates.Description.String)
+		argIndex++
+	}
+
+	if updates.Type.Valid {
+		setParts = append(setParts, fmt.Sprintf("wallet_type = $%d", argIndex))
+		args = append(args, updates.Type.String)
+	}
+
+	return setParts, args
+}
+
+// WalletGroup groups wallets by purpose, such as operations or grants, so they can be viewed as a sub-treasury
+type WalletGroup struct {
+	ID          uuid.UUID   `json:"id" db:"id"`
+	Name        string      `json:"name" db:"name"`
+	Description null.String `json:"description" db:"description"`
+	CreatedAt   time.Time   `json:"createdAt" db:"created_at"`
+	UpdatedAt   time.Time   `json:"updatedAt" db:"updated_at"`
+
+	// Addresses of the member wallets, populated when reading groups
+	Wallets []string `json:"wallets" db:"-"`
+}
+
+type WalletGroupRequest struct {
+	Name        string      `json:"name" binding:"required"`
+	Description null.String `json:"description"`
+	Wallets     []string    `json:"wallets"`
 }
 
 type TreasuryResponse struct {
-	OrganizationName     string          `json:"organizationName"`
-	Assets               []Asset         `json:"assets"`
-	WalletBalances       []WalletBalance `json:"walletBalances"`
-	Wallets              []Wallet        `json:"wallets"`
-	TotalValueUsd        float64         `json:"totalValueUsd"`
-	TotalValueEth        string          `json:"totalValueEth"` // High precision decimal as string
-	TotalFundsRaised     float64         `json:"totalFundsRaised"`
-	TotalFundsRaisedUnit string          `json:"totalFundsRaisedUnit"`
-	LastUpdated          time.Time       `json:"lastUpdated"`
+	OrganizationName     string            `json:"organizationName"`
+	Assets               []Asset           `json:"assets"`
+	WalletBalances       []WalletBalance   `json:"walletBalances"`
+	Positions            []Position        `json:"positions"`
+	OffchainBalances     []OffchainBalance `json:"offchainBalances"`
+	ExchangeBalances     []ExchangeBalance `json:"exchangeBalances"`
+	Wallets              []Wallet          `json:"wallets"`
+	TotalValueUsd        float64           `json:"totalValueUsd"`
+	SelfReportedValueUsd float64           `json:"selfReportedValueUsd"` // The part of TotalValueUsd from off-chain statements
+	TotalValueEth        string            `json:"totalValueEth"`        // High precision decimal as string
+	TotalFundsRaised     float64           `json:"totalFundsRaised"`
+	TotalFundsRaisedUnit string            `json:"totalFundsRaisedUnit"`
+	LastUpdated          time.Time         `json:"lastUpdated"`
+
+	// Set when the response is scoped to a wallet group, off-chain and exchange balances are left out
+	Group           *WalletGroup `json:"group,omitempty"`
+	RecentTransfers []Transfer   `json:"recentTransfers,omitempty"`
 }
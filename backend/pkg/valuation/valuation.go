@@ -0,0 +1,114 @@
+package valuation
+
+import (
+	"strings"
+	"time"
+
+	"github.com/ETHCF/ethutils"
+	"github.com/numbergroup/errors"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+// Validate checks that a policy has the fields it needs and clears the ones it doesn't use
+func Validate(policy *types.AssetValuationPolicy) error {
+	switch policy.Policy {
+	case types.ValuationPolicyManual:
+		if !policy.ManualPrice.Valid || policy.ManualPrice.Float64 < 0 {
+			return errors.New("manual policy requires a manualPrice of at least 0")
+		}
+		policy.PegAddress = null.String{}
+		policy.DiscountPercent = null.Float{}
+	case types.ValuationPolicyPegged:
+		if policy.PegAddress.Valid && policy.PegAddress.String != "" {
+			pegAddress, err := ethutils.SanitizeEthAddr(policy.PegAddress.String)
+			if err != nil {
+				return errors.New("invalid pegAddress")
+			}
+			if pegAddress == policy.Address {
+				return errors.New("an asset can't be pegged to itself")
+			}
+			policy.PegAddress = null.StringFrom(pegAddress)
+		} else {
+			policy.PegAddress = null.String{}
+		}
+		policy.ManualPrice = null.Float{}
+		policy.DiscountPercent = null.Float{}
+	case types.ValuationPolicyDiscount:
+		if !policy.DiscountPercent.Valid || policy.DiscountPercent.Float64 <= 0 || policy.DiscountPercent.Float64 >= 100 {
+			return errors.New("discount policy requires a discountPercent between 0 and 100")
+		}
+		policy.ManualPrice = null.Float{}
+		policy.PegAddress = null.String{}
+	case types.ValuationPolicyMarket, types.ValuationPolicyExcluded:
+		policy.ManualPrice = null.Float{}
+		policy.PegAddress = null.String{}
+		policy.DiscountPercent = null.Float{}
+	default:
+		return errors.Errorf("unknown valuation policy %q", policy.Policy)
+	}
+	return nil
+}
+
+// PegAddresses returns the assets that other assets are pegged to, which need market prices as well
+func PegAddresses(policies []types.AssetValuationPolicy) []string {
+	out := []string{}
+	for _, policy := range policies {
+		if policy.Policy == types.ValuationPolicyPegged && policy.PegAddress.Valid {
+			out = append(out, policy.PegAddress.String)
+		}
+	}
+	return out
+}
+
+// Apply prices an asset using its policy, or the market price when it has none. The market prices
+// are keyed by address and were fetched at fetchedAt. The price is null when the asset can't be priced.
+func Apply(policy *types.AssetValuationPolicy, address string, market map[string]float64, fetchedAt time.Time) types.Valuation {
+	marketValuation := func(policy types.ValuationPolicy) types.Valuation {
+		out := types.Valuation{Policy: policy}
+		if price, ok := market[address]; ok {
+			out.UsdPrice = null.FloatFrom(price)
+			out.Source = null.StringFrom(types.PriceSourceMarket)
+			out.UpdatedAt = null.TimeFrom(fetchedAt)
+		}
+		return out
+	}
+	if policy == nil {
+		return marketValuation(types.ValuationPolicyMarket)
+	}
+
+	switch policy.Policy {
+	case types.ValuationPolicyManual:
+		return types.Valuation{
+			Policy:    policy.Policy,
+			UsdPrice:  policy.ManualPrice,
+			Source:    null.StringFrom(types.PriceSourceManual),
+			UpdatedAt: null.TimeFrom(policy.UpdatedAt),
+		}
+	case types.ValuationPolicyPegged:
+		if !policy.PegAddress.Valid {
+			return types.Valuation{
+				Policy:    policy.Policy,
+				UsdPrice:  null.FloatFrom(1),
+				Source:    null.StringFrom(types.PriceSourcePegPrefix + "usd"),
+				UpdatedAt: null.TimeFrom(policy.UpdatedAt),
+			}
+		}
+		out := types.Valuation{Policy: policy.Policy}
+		if price, ok := market[strings.ToLower(policy.PegAddress.String)]; ok {
+			out.UsdPrice = null.FloatFrom(price)
+			out.Source = null.StringFrom(types.PriceSourcePegPrefix + policy.PegAddress.String)
+			out.UpdatedAt = null.TimeFrom(fetchedAt)
+		}
+		return out
+	case types.ValuationPolicyDiscount:
+		out := marketValuation(policy.Policy)
+		if out.UsdPrice.Valid {
+			out.UsdPrice.Float64 *= 1 - policy.DiscountPercent.Float64/100
+		}
+		return out
+	default:
+		return marketValuation(policy.Policy)
+	}
+}
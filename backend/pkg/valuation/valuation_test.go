@@ -0,0 +1,92 @@
+package valuation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ETHCF/ethutils"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/ETHCF/transparency-dashboard/backend/pkg/types"
+)
+
+func TestValidate(t *testing.T) {
+	address := ethutils.GenRandEVMAddr()
+
+	policy := types.AssetValuationPolicy{Address: address, Policy: types.ValuationPolicyManual}
+	require.Error(t, Validate(&policy))
+	policy.ManualPrice = null.FloatFrom(0.5)
+	policy.DiscountPercent = null.FloatFrom(10)
+	require.NoError(t, Validate(&policy))
+	require.False(t, policy.DiscountPercent.Valid)
+
+	policy = types.AssetValuationPolicy{Address: address, Policy: types.ValuationPolicyDiscount, DiscountPercent: null.FloatFrom(100)}
+	require.Error(t, Validate(&policy))
+	policy.DiscountPercent = null.FloatFrom(25)
+	require.NoError(t, Validate(&policy))
+
+	policy = types.AssetValuationPolicy{Address: address, Policy: types.ValuationPolicyPegged, PegAddress: null.StringFrom(address)}
+	require.Error(t, Validate(&policy))
+	policy.PegAddress = null.StringFrom("not an address")
+	require.Error(t, Validate(&policy))
+	policy.PegAddress = null.StringFrom("")
+	require.NoError(t, Validate(&policy))
+	require.False(t, policy.PegAddress.Valid)
+
+	policy = types.AssetValuationPolicy{Address: address, Policy: "unknown"}
+	require.Error(t, Validate(&policy))
+}
+
+func TestApply(t *testing.T) {
+	var (
+		address   = ethutils.GenRandEVMAddr()
+		pegged    = ethutils.GenRandEVMAddr()
+		fetchedAt = time.Now()
+		setAt     = fetchedAt.Add(-time.Hour)
+		market    = map[string]float64{address: 10, pegged: 2}
+	)
+
+	// No policy uses the market price
+	valuation := Apply(nil, address, market, fetchedAt)
+	require.Equal(t, types.ValuationPolicyMarket, valuation.Policy)
+	require.Equal(t, 10.0, valuation.UsdPrice.Float64)
+	require.Equal(t, types.PriceSourceMarket, valuation.Source.String)
+	require.Equal(t, fetchedAt, valuation.UpdatedAt.Time)
+
+	// Unpriced assets have no price rather than being skipped
+	valuation = Apply(nil, ethutils.GenRandEVMAddr(), market, fetchedAt)
+	require.False(t, valuation.UsdPrice.Valid)
+	require.False(t, valuation.Source.Valid)
+
+	valuation = Apply(&types.AssetValuationPolicy{Policy: types.ValuationPolicyManual, ManualPrice: null.FloatFrom(3), UpdatedAt: setAt}, address, market, fetchedAt)
+	require.Equal(t, 3.0, valuation.UsdPrice.Float64)
+	require.Equal(t, types.PriceSourceManual, valuation.Source.String)
+	require.Equal(t, setAt, valuation.UpdatedAt.Time)
+
+	valuation = Apply(&types.AssetValuationPolicy{Policy: types.ValuationPolicyPegged, UpdatedAt: setAt}, address, market, fetchedAt)
+	require.Equal(t, 1.0, valuation.UsdPrice.Float64)
+	require.Equal(t, "peg:usd", valuation.Source.String)
+
+	valuation = Apply(&types.AssetValuationPolicy{Policy: types.ValuationPolicyPegged, PegAddress: null.StringFrom(pegged)}, address, market, fetchedAt)
+	require.Equal(t, 2.0, valuation.UsdPrice.Float64)
+	require.Equal(t, "peg:"+pegged, valuation.Source.String)
+	require.Equal(t, fetchedAt, valuation.UpdatedAt.Time)
+
+	valuation = Apply(&types.AssetValuationPolicy{Policy: types.ValuationPolicyDiscount, DiscountPercent: null.FloatFrom(40)}, address, market, fetchedAt)
+	require.InDelta(t, 6.0, valuation.UsdPrice.Float64, 0.0001)
+	require.Equal(t, types.PriceSourceMarket, valuation.Source.String)
+
+	valuation = Apply(&types.AssetValuationPolicy{Policy: types.ValuationPolicyExcluded}, address, market, fetchedAt)
+	require.Equal(t, types.ValuationPolicyExcluded, valuation.Policy)
+	require.Equal(t, 10.0, valuation.UsdPrice.Float64)
+}
+
+func TestPegAddresses(t *testing.T) {
+	pegged := ethutils.GenRandEVMAddr()
+	require.Equal(t, []string{pegged}, PegAddresses([]types.AssetValuationPolicy{
+		{Policy: types.ValuationPolicyPegged, PegAddress: null.StringFrom(pegged)},
+		{Policy: types.ValuationPolicyPegged},
+		{Policy: types.ValuationPolicyManual, ManualPrice: null.FloatFrom(1)},
+	}))
+}